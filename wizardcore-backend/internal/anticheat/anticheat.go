@@ -0,0 +1,101 @@
+// Package anticheat decides which submission patterns are implausible
+// enough to send an account for review. The checks work on plain numbers
+// and strings gathered by the caller so they can be tested without a
+// database.
+package anticheat
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"strings"
+	"time"
+)
+
+// Reasons an account is flagged
+const (
+	ReasonFastSolve       = "fast_solve"
+	ReasonBurst           = "burst"
+	ReasonDuplicateSource = "duplicate_source"
+	ReasonXPSpike         = "xp_spike"
+)
+
+// Rules hold the thresholds of the checks
+type Rules struct {
+	// Accepted solves an exercise needs before its fastest solve is
+	// trusted as a baseline
+	MinBaselineSamples int
+	// More than BurstLimit graded submissions within BurstWindow is a burst
+	BurstWindow time.Duration
+	BurstLimit  int
+	// Sources shorter than this once whitespace is removed are too common
+	// to compare
+	MinFingerprintLength int
+	// XP earned within SpikeWindow is a spike when it exceeds SpikeFloor
+	// and SpikeFactor times the average for such a window over the
+	// preceding SpikeBaselineDays
+	SpikeWindow       time.Duration
+	SpikeBaselineDays int
+	SpikeFactor       float64
+	SpikeFloor        int
+}
+
+var DefaultRules = Rules{
+	MinBaselineSamples:   5,
+	BurstWindow:          time.Minute,
+	BurstLimit:           10,
+	MinFingerprintLength: 120,
+	SpikeWindow:          24 * time.Hour,
+	SpikeBaselineDays:    30,
+	SpikeFactor:          5,
+	SpikeFloor:           500,
+}
+
+// FastSolve reports whether a solve took less time than the fastest of an
+// exercise's accepted solves so far. The baseline is only trusted once it
+// covers enough solves.
+func (r Rules) FastSolve(seconds, baselineMin, samples int) bool {
+	return samples >= r.MinBaselineSamples && seconds < baselineMin
+}
+
+// Burst reports whether a number of submissions within the burst window is
+// more than a person can plausibly make
+func (r Rules) Burst(count int) bool {
+	return count > r.BurstLimit
+}
+
+// Spike reports whether XP earned within the spike window is far above the
+// player's own average
+func (r Rules) Spike(recentXP int, baselineXP int) bool {
+	average := float64(baselineXP) / float64(r.SpikeBaselineDays) * r.SpikeWindow.Hours() / 24
+	return recentXP > r.SpikeFloor && float64(recentXP) > r.SpikeFactor*average
+}
+
+// Normalize removes the whitespace from source code, the same characters
+// the \s class matches in a Postgres regular expression
+func Normalize(source string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '\t', '\n', '\r', '\f', '\v':
+			return -1
+		}
+		return r
+	}, source)
+}
+
+// SourceFingerprint returns the MD5 of normalized source code, which
+// matches the MD5 Postgres computes after stripping \s+ from it. It is
+// stored with every submission.
+func SourceFingerprint(source string) string {
+	sum := md5.Sum([]byte(Normalize(source)))
+	return hex.EncodeToString(sum[:])
+}
+
+// Fingerprint returns the SourceFingerprint of source code to compare with
+// other submissions. It reports false for sources too short to tell copying
+// from coincidence.
+func (r Rules) Fingerprint(source string) (string, bool) {
+	if len(Normalize(source)) < r.MinFingerprintLength {
+		return "", false
+	}
+	return SourceFingerprint(source), true
+}
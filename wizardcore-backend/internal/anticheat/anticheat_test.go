@@ -0,0 +1,54 @@
+package anticheat
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFastSolve_NeedsBaseline(t *testing.T) {
+	r := DefaultRules
+	if r.FastSolve(10, 90, r.MinBaselineSamples-1) {
+		t.Error("Expected no flag before the baseline has enough solves")
+	}
+	if !r.FastSolve(10, 90, r.MinBaselineSamples) {
+		t.Error("Expected a solve faster than the minimum to be flagged")
+	}
+	if r.FastSolve(90, 90, 50) {
+		t.Error("Expected matching the minimum not to be flagged")
+	}
+}
+
+func TestSpike(t *testing.T) {
+	r := DefaultRules
+	// 30 days at 200 XP a day
+	baseline := 200 * r.SpikeBaselineDays
+	if r.Spike(900, baseline) {
+		t.Error("Expected a busy day within 5x the average not to be flagged")
+	}
+	if !r.Spike(1200, baseline) {
+		t.Error("Expected 6x the daily average to be flagged")
+	}
+	if r.Spike(r.SpikeFloor, 0) {
+		t.Error("Expected XP at the floor not to be flagged even without history")
+	}
+}
+
+func TestFingerprint_IgnoresWhitespace(t *testing.T) {
+	r := DefaultRules
+	code := strings.Repeat("for i in range(10):\n    total += values[i] * weights[i]\n", 3)
+	spaced := strings.ReplaceAll(strings.ReplaceAll(code, "\n", "\r\n"), "    ", "\t")
+
+	a, ok := r.Fingerprint(code)
+	if !ok {
+		t.Fatal("Expected a fingerprint for a long enough source")
+	}
+	if b, _ := r.Fingerprint(spaced); a != b {
+		t.Error("Expected whitespace changes to keep the fingerprint")
+	}
+	if c, _ := r.Fingerprint(code + "x"); a == c {
+		t.Error("Expected different code to change the fingerprint")
+	}
+	if _, ok := r.Fingerprint("print('hello')"); ok {
+		t.Error("Expected no fingerprint for a short source")
+	}
+}
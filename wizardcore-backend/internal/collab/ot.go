@@ -0,0 +1,214 @@
+// Package collab implements operational transformation for plain-text
+// buffers shared by several editors.
+//
+// Positions and lengths are counted in Unicode code points. The server is the
+// single authority that orders operations: an operation produced against an
+// older revision is transformed against every operation applied since, then
+// applied and assigned the next revision.
+package collab
+
+import (
+	"fmt"
+	"unicode/utf8"
+)
+
+// OpType is the kind of an edit operation
+type OpType string
+
+const (
+	// Insert adds Text at Position
+	Insert OpType = "insert"
+	// Delete removes Length code points starting at Position
+	Delete OpType = "delete"
+)
+
+// Operation is a single edit to a buffer
+type Operation struct {
+	Type     OpType `json:"type"`
+	Position int    `json:"position"`
+	Text     string `json:"text,omitempty"`
+	Length   int    `json:"length,omitempty"`
+}
+
+// Validate checks that the operation is well-formed
+func (op Operation) Validate() error {
+	if op.Position < 0 {
+		return fmt.Errorf("position must not be negative")
+	}
+	switch op.Type {
+	case Insert:
+		if op.Text == "" {
+			return fmt.Errorf("insert requires text")
+		}
+	case Delete:
+		if op.Length <= 0 {
+			return fmt.Errorf("delete requires a positive length")
+		}
+	default:
+		return fmt.Errorf("unknown operation type: %s", op.Type)
+	}
+	return nil
+}
+
+func (op Operation) insertLen() int {
+	return utf8.RuneCountInString(op.Text)
+}
+
+// Apply applies the operation to doc
+func Apply(doc string, op Operation) (string, error) {
+	if err := op.Validate(); err != nil {
+		return "", err
+	}
+	runes := []rune(doc)
+	if op.Position > len(runes) {
+		return "", fmt.Errorf("position %d out of range (length %d)", op.Position, len(runes))
+	}
+	switch op.Type {
+	case Insert:
+		out := make([]rune, 0, len(runes)+op.insertLen())
+		out = append(out, runes[:op.Position]...)
+		out = append(out, []rune(op.Text)...)
+		out = append(out, runes[op.Position:]...)
+		return string(out), nil
+	default:
+		end := op.Position + op.Length
+		if end > len(runes) {
+			return "", fmt.Errorf("delete range %d-%d out of range (length %d)", op.Position, end, len(runes))
+		}
+		return string(runes[:op.Position]) + string(runes[end:]), nil
+	}
+}
+
+// Transform rewrites a so that it can be applied after b, where both were
+// produced against the same document. aFirst breaks the tie when both insert
+// at the same position: the side that goes first keeps its position.
+//
+// The result may be empty (a deletion fully covered by b) or hold two
+// operations (a deletion split by an insertion inside its range).
+func Transform(a, b Operation, aFirst bool) []Operation {
+	switch {
+	case a.Type == Insert && b.Type == Insert:
+		if b.Position < a.Position || (b.Position == a.Position && !aFirst) {
+			a.Position += b.insertLen()
+		}
+		return []Operation{a}
+
+	case a.Type == Insert && b.Type == Delete:
+		bEnd := b.Position + b.Length
+		switch {
+		case a.Position <= b.Position:
+		case a.Position >= bEnd:
+			a.Position -= b.Length
+		default:
+			a.Position = b.Position
+		}
+		return []Operation{a}
+
+	case a.Type == Delete && b.Type == Insert:
+		aEnd := a.Position + a.Length
+		switch {
+		case b.Position <= a.Position:
+			a.Position += b.insertLen()
+			return []Operation{a}
+		case b.Position >= aEnd:
+			return []Operation{a}
+		default:
+			// Keep the inserted text: delete around it in two steps
+			first := Operation{Type: Delete, Position: a.Position, Length: b.Position - a.Position}
+			second := Operation{Type: Delete, Position: a.Position + b.insertLen(), Length: aEnd - b.Position}
+			return []Operation{first, second}
+		}
+
+	default: // both deletes
+		aEnd := a.Position + a.Length
+		bEnd := b.Position + b.Length
+		switch {
+		case aEnd <= b.Position:
+			return []Operation{a}
+		case a.Position >= bEnd:
+			a.Position -= b.Length
+			return []Operation{a}
+		}
+		overlap := min(aEnd, bEnd) - max(a.Position, b.Position)
+		remaining := a.Length - overlap
+		if remaining == 0 {
+			return nil
+		}
+		return []Operation{{Type: Delete, Position: min(a.Position, b.Position), Length: remaining}}
+	}
+}
+
+// TransformSeq transforms two sequences of operations produced against the
+// same document. It returns a' and b' such that applying b then a' gives the
+// same result as applying a then b'.
+func TransformSeq(a, b []Operation, aFirst bool) ([]Operation, []Operation) {
+	switch {
+	case len(a) == 0 || len(b) == 0:
+		return a, b
+	case len(a) == 1 && len(b) == 1:
+		return Transform(a[0], b[0], aFirst), Transform(b[0], a[0], !aFirst)
+	case len(a) > 1:
+		a1, b1 := TransformSeq(a[:1], b, aFirst)
+		a2, b2 := TransformSeq(a[1:], b1, aFirst)
+		return append(a1, a2...), b2
+	default:
+		a1, b1 := TransformSeq(a, b[:1], aFirst)
+		a2, b2 := TransformSeq(a1, b[1:], aFirst)
+		return a2, append(b1, b2...)
+	}
+}
+
+// TransformCursor moves a cursor position so it stays on the same text after op
+func TransformCursor(position int, op Operation) int {
+	switch op.Type {
+	case Insert:
+		if op.Position <= position {
+			return position + op.insertLen()
+		}
+	case Delete:
+		if position <= op.Position {
+			return position
+		}
+		if position >= op.Position+op.Length {
+			return position - op.Length
+		}
+		return op.Position
+	}
+	return position
+}
+
+// Diff returns operations that turn from into to: a delete and an insert
+// covering everything between their common prefix and suffix. It is not a
+// minimal diff, but editor snapshots taken a few seconds apart usually
+// differ in one place.
+func Diff(from, to string) []Operation {
+	a, b := []rune(from), []rune(to)
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	var ops []Operation
+	if removed := len(a) - prefix - suffix; removed > 0 {
+		ops = append(ops, Operation{Type: Delete, Position: prefix, Length: removed})
+	}
+	if inserted := b[prefix : len(b)-suffix]; len(inserted) > 0 {
+		ops = append(ops, Operation{Type: Insert, Position: prefix, Text: string(inserted)})
+	}
+	return ops
+}
+
+// ApplyAll applies a sequence of operations to doc
+func ApplyAll(doc string, ops []Operation) (string, error) {
+	for _, op := range ops {
+		var err error
+		if doc, err = Apply(doc, op); err != nil {
+			return "", err
+		}
+	}
+	return doc, nil
+}
@@ -0,0 +1,140 @@
+package collab
+
+import "testing"
+
+// converge applies a then b' and b then a' and checks both sides agree.
+func converge(t *testing.T, doc string, a, b Operation) string {
+	t.Helper()
+	left, err := Apply(doc, a)
+	if err != nil {
+		t.Fatalf("apply a: %v", err)
+	}
+	for _, op := range Transform(b, a, false) {
+		if left, err = Apply(left, op); err != nil {
+			t.Fatalf("apply b': %v", err)
+		}
+	}
+	right, err := Apply(doc, b)
+	if err != nil {
+		t.Fatalf("apply b: %v", err)
+	}
+	for _, op := range Transform(a, b, true) {
+		if right, err = Apply(right, op); err != nil {
+			t.Fatalf("apply a': %v", err)
+		}
+	}
+	if left != right {
+		t.Fatalf("documents diverged: %q vs %q", left, right)
+	}
+	return left
+}
+
+func TestTransform_Converges(t *testing.T) {
+	cases := []struct {
+		name string
+		doc  string
+		a, b Operation
+		want string
+	}{
+		{"insert same position", "ac", Operation{Type: Insert, Position: 1, Text: "X"}, Operation{Type: Insert, Position: 1, Text: "Y"}, "aXYc"},
+		{"insert before insert", "abc", Operation{Type: Insert, Position: 0, Text: "X"}, Operation{Type: Insert, Position: 2, Text: "Y"}, "XabYc"},
+		{"insert inside delete", "abcdef", Operation{Type: Delete, Position: 1, Length: 4}, Operation{Type: Insert, Position: 3, Text: "X"}, "aXf"},
+		{"insert after delete", "abcdef", Operation{Type: Insert, Position: 5, Text: "X"}, Operation{Type: Delete, Position: 0, Length: 2}, "cdeXf"},
+		{"overlapping deletes", "abcdef", Operation{Type: Delete, Position: 1, Length: 3}, Operation{Type: Delete, Position: 2, Length: 3}, "af"},
+		{"identical deletes", "abcdef", Operation{Type: Delete, Position: 1, Length: 2}, Operation{Type: Delete, Position: 1, Length: 2}, "adef"},
+		{"multibyte text", "héllo", Operation{Type: Insert, Position: 2, Text: "✓"}, Operation{Type: Delete, Position: 0, Length: 1}, "é✓llo"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := converge(t, tc.doc, tc.a, tc.b); got != tc.want {
+				t.Errorf("expected %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestTransformSeq_Converges(t *testing.T) {
+	doc := "func main() {}"
+	a := []Operation{
+		{Type: Insert, Position: 13, Text: "\n\tx := 1\n"},
+		{Type: Delete, Position: 0, Length: 5},
+	}
+	b := []Operation{
+		{Type: Delete, Position: 5, Length: 4},
+		{Type: Insert, Position: 5, Text: "run"},
+	}
+
+	aPrime, bPrime := TransformSeq(a, b, true)
+	left, right := doc, doc
+	for _, op := range append(append([]Operation{}, a...), bPrime...) {
+		left = mustApply(t, left, op)
+	}
+	for _, op := range append(append([]Operation{}, b...), aPrime...) {
+		right = mustApply(t, right, op)
+	}
+	if left != right {
+		t.Fatalf("documents diverged: %q vs %q", left, right)
+	}
+	if want := "run() {\n\tx := 1\n}"; left != want {
+		t.Errorf("expected %q, got %q", want, left)
+	}
+}
+
+func mustApply(t *testing.T, doc string, op Operation) string {
+	t.Helper()
+	out, err := Apply(doc, op)
+	if err != nil {
+		t.Fatalf("apply %+v to %q: %v", op, doc, err)
+	}
+	return out
+}
+
+func TestApply_RejectsOutOfRange(t *testing.T) {
+	if _, err := Apply("abc", Operation{Type: Delete, Position: 2, Length: 5}); err == nil {
+		t.Error("expected error for delete past the end")
+	}
+	if _, err := Apply("abc", Operation{Type: Insert, Position: 4, Text: "x"}); err == nil {
+		t.Error("expected error for insert past the end")
+	}
+}
+
+func TestTransformCursor(t *testing.T) {
+	if got := TransformCursor(3, Operation{Type: Insert, Position: 1, Text: "ab"}); got != 5 {
+		t.Errorf("expected 5, got %d", got)
+	}
+	if got := TransformCursor(3, Operation{Type: Delete, Position: 1, Length: 5}); got != 1 {
+		t.Errorf("expected 1, got %d", got)
+	}
+}
+
+func TestDiff_RoundTrips(t *testing.T) {
+	cases := []struct {
+		name     string
+		from, to string
+		ops      int
+	}{
+		{"identical", "print(1)", "print(1)", 0},
+		{"append", "print(", "print(1)", 1},
+		{"delete middle", "abcdef", "abef", 1},
+		{"replace", "x = 1\ny = 2", "x = 1\ny = 3", 2},
+		{"from empty", "", "hello", 1},
+		{"to empty", "hello", "", 1},
+		{"repeated runes", "aaaa", "aaaaaa", 1},
+		{"unicode", "héllo wörld", "héllo, wörld", 1},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			ops := Diff(tc.from, tc.to)
+			if len(ops) != tc.ops {
+				t.Fatalf("got %d operations, want %d: %+v", len(ops), tc.ops, ops)
+			}
+			got, err := ApplyAll(tc.from, ops)
+			if err != nil {
+				t.Fatalf("apply: %v", err)
+			}
+			if got != tc.to {
+				t.Fatalf("got %q, want %q", got, tc.to)
+			}
+		})
+	}
+}
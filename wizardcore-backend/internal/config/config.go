@@ -7,18 +7,26 @@ import (
 )
 
 type Config struct {
-	Port               int
-	DatabaseURL        string
-	SupabaseURL        string
-	SupabaseJWTSecret  string
-	Judge0APIURL       string
-	Judge0APIKey       string
-	RedisURL           string
-	CORSAllowedOrigins []string
-	Environment        string
-	LogLevel           string
-	RateLimitRPS       float64
-	RateLimitBurst     int
+	Port                      int
+	DatabaseURL               string
+	SupabaseURL               string
+	SupabaseJWTSecret         string
+	Judge0APIURL              string
+	Judge0APIKey              string
+	RedisURL                  string
+	CORSAllowedOrigins        []string
+	Environment               string
+	LogLevel                  string
+	RateLimitRPS              float64
+	RateLimitBurst            int
+	WSReplayBufferSize        int
+	WSReplayTTLMinutes        int
+	ReplayRetentionDays       int
+	ReplayMaxSnapshots        int
+	LeaderboardRefreshMinutes int
+	SubmissionRatePerMinute   float64
+	SubmissionBurst           int
+	AntiCheatExcludeOnFlag    bool
 }
 
 func Load() (*Config, error) {
@@ -36,6 +44,42 @@ func Load() (*Config, error) {
 		return nil, fmt.Errorf("invalid RATE_LIMIT_BURST: %w", err)
 	}
 
+	replayBuffer, err := strconv.Atoi(getEnv("WS_REPLAY_BUFFER_SIZE", "200"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid WS_REPLAY_BUFFER_SIZE: %w", err)
+	}
+	replayTTL, err := strconv.Atoi(getEnv("WS_REPLAY_TTL_MINUTES", "30"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid WS_REPLAY_TTL_MINUTES: %w", err)
+	}
+
+	replayRetention, err := strconv.Atoi(getEnv("REPLAY_RETENTION_DAYS", "30"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid REPLAY_RETENTION_DAYS: %w", err)
+	}
+	replaySnapshots, err := strconv.Atoi(getEnv("REPLAY_MAX_SNAPSHOTS", "500"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid REPLAY_MAX_SNAPSHOTS: %w", err)
+	}
+
+	leaderboardRefresh, err := strconv.Atoi(getEnv("LEADERBOARD_REFRESH_MINUTES", "10"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid LEADERBOARD_REFRESH_MINUTES: %w", err)
+	}
+
+	submissionRate, err := strconv.ParseFloat(getEnv("SUBMISSION_RATE_PER_MINUTE", "10"), 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid SUBMISSION_RATE_PER_MINUTE: %w", err)
+	}
+	submissionBurst, err := strconv.Atoi(getEnv("SUBMISSION_BURST", "5"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid SUBMISSION_BURST: %w", err)
+	}
+	antiCheatExclude, err := strconv.ParseBool(getEnv("ANTICHEAT_EXCLUDE_ON_FLAG", "false"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid ANTICHEAT_EXCLUDE_ON_FLAG: %w", err)
+	}
+
 	// Get DATABASE_URL or construct from individual components
 	databaseURL := getEnv("DATABASE_URL", "")
 	if databaseURL == "" {
@@ -52,18 +96,26 @@ func Load() (*Config, error) {
 	}
 
 	cfg := &Config{
-		Port:               port,
-		DatabaseURL:        databaseURL,
-		SupabaseURL:        getEnv("SUPABASE_URL", ""),
-		SupabaseJWTSecret:  getEnv("SUPABASE_JWT_SECRET", ""),
-		Judge0APIURL:       getEnv("JUDGE0_API_URL", "http://localhost:2358"),
-		Judge0APIKey:       getEnv("JUDGE0_API_KEY", ""),
-		RedisURL:           getEnv("REDIS_URL", "localhost:6379"),
-		CORSAllowedOrigins: []string{getEnv("FRONTEND_URL", "http://localhost:3000")},
-		Environment:        getEnv("ENVIRONMENT", "development"),
-		LogLevel:           getEnv("LOG_LEVEL", "info"),
-		RateLimitRPS:       rps,
-		RateLimitBurst:     burst,
+		Port:                      port,
+		DatabaseURL:               databaseURL,
+		SupabaseURL:               getEnv("SUPABASE_URL", ""),
+		SupabaseJWTSecret:         getEnv("SUPABASE_JWT_SECRET", ""),
+		Judge0APIURL:              getEnv("JUDGE0_API_URL", "http://localhost:2358"),
+		Judge0APIKey:              getEnv("JUDGE0_API_KEY", ""),
+		RedisURL:                  getEnv("REDIS_URL", "localhost:6379"),
+		CORSAllowedOrigins:        []string{getEnv("FRONTEND_URL", "http://localhost:3000")},
+		Environment:               getEnv("ENVIRONMENT", "development"),
+		LogLevel:                  getEnv("LOG_LEVEL", "info"),
+		RateLimitRPS:              rps,
+		RateLimitBurst:            burst,
+		WSReplayBufferSize:        replayBuffer,
+		WSReplayTTLMinutes:        replayTTL,
+		ReplayRetentionDays:       replayRetention,
+		ReplayMaxSnapshots:        replaySnapshots,
+		LeaderboardRefreshMinutes: leaderboardRefresh,
+		SubmissionRatePerMinute:   submissionRate,
+		SubmissionBurst:           submissionBurst,
+		AntiCheatExcludeOnFlag:    antiCheatExclude,
 	}
 
 	if cfg.DatabaseURL == "" {
@@ -0,0 +1,105 @@
+// Package daily picks the global daily challenge and keeps its streak
+// arithmetic. Days are UTC calendar days.
+package daily
+
+import (
+	"hash/fnv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Rotation is the difficulty of the daily challenge on each weekday,
+// starting on Sunday: easy early in the week, hard towards the weekend.
+var Rotation = [7]string{
+	"INTERMEDIATE", // Sunday
+	"BEGINNER",
+	"BEGINNER",
+	"INTERMEDIATE",
+	"INTERMEDIATE",
+	"ADVANCED",
+	"ADVANCED",
+}
+
+// Bonus XP for solving the daily challenge, by difficulty
+var BonusXP = map[string]int{
+	"BEGINNER":     50,
+	"INTERMEDIATE": 100,
+	"ADVANCED":     150,
+}
+
+// DefaultBonusXP is awarded for difficulties missing from BonusXP
+const DefaultBonusXP = 50
+
+// Day returns the UTC calendar day of t at midnight
+func Day(t time.Time) time.Time {
+	y, m, d := t.UTC().Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+// Difficulties returns the difficulties to pick the challenge of a day from,
+// in order: the day's place in the rotation first, then the others, so a day
+// whose difficulty has run out of exercises still gets a challenge.
+func Difficulties(day time.Time) []string {
+	first := Rotation[Day(day).Weekday()]
+	order := []string{first}
+	for _, d := range []string{"BEGINNER", "INTERMEDIATE", "ADVANCED"} {
+		if d != first {
+			order = append(order, d)
+		}
+	}
+	return order
+}
+
+// Pick deterministically chooses the exercise of a day among candidates.
+// Each candidate is ranked by a hash of the day and its ID, so the choice
+// does not depend on the order of candidates. It returns false if there are
+// none.
+func Pick(day time.Time, candidates []uuid.UUID) (uuid.UUID, bool) {
+	if len(candidates) == 0 {
+		return uuid.Nil, false
+	}
+	date := Day(day).Format("2006-01-02")
+	var best uuid.UUID
+	var bestRank uint64
+	for i, id := range candidates {
+		h := fnv.New64a()
+		h.Write([]byte(date))
+		h.Write(id[:])
+		rank := h.Sum64()
+		if i == 0 || rank < bestRank || (rank == bestRank && id.String() < best.String()) {
+			best, bestRank = id, rank
+		}
+	}
+	return best, true
+}
+
+// NextStreak returns the streak after completing the challenge of day, given
+// the day of the previous completion and the streak it left
+func NextStreak(last *time.Time, current int, day time.Time) int {
+	day = Day(day)
+	if last == nil {
+		return 1
+	}
+	prev := Day(*last)
+	switch {
+	case prev.Equal(day):
+		return current
+	case prev.AddDate(0, 0, 1).Equal(day):
+		return current + 1
+	default:
+		return 1
+	}
+}
+
+// ActiveStreak returns the streak as of today: it is kept while today's or
+// yesterday's challenge was completed and drops to zero once a day is missed
+func ActiveStreak(last *time.Time, current int, today time.Time) int {
+	if last == nil {
+		return 0
+	}
+	if Day(*last).AddDate(0, 0, 1).Before(Day(today)) {
+		return 0
+	}
+	return current
+}
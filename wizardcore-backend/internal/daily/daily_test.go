@@ -0,0 +1,81 @@
+package daily
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestPick_DeterministicAndOrderIndependent(t *testing.T) {
+	ids := make([]uuid.UUID, 20)
+	for i := range ids {
+		ids[i] = uuid.New()
+	}
+	reversed := make([]uuid.UUID, len(ids))
+	for i, id := range ids {
+		reversed[len(ids)-1-i] = id
+	}
+
+	d := time.Date(2026, 3, 4, 0, 0, 0, 0, time.UTC).Add(17 * time.Hour)
+	first, ok := Pick(d, ids)
+	if !ok {
+		t.Fatal("Expected a pick")
+	}
+	if again, _ := Pick(time.Date(2026, 3, 4, 0, 0, 0, 0, time.UTC), reversed); again != first {
+		t.Errorf("Expected the same pick for the same day, got %s and %s", first, again)
+	}
+
+	// Different days should not all land on the same exercise
+	picks := make(map[uuid.UUID]bool)
+	for i := 0; i < 30; i++ {
+		p, _ := Pick(d.AddDate(0, 0, i), ids)
+		picks[p] = true
+	}
+	if len(picks) < 5 {
+		t.Errorf("Expected picks to spread over days, got %d distinct", len(picks))
+	}
+
+	if _, ok := Pick(d, nil); ok {
+		t.Error("Expected no pick without candidates")
+	}
+}
+
+func TestDifficulties_FollowRotation(t *testing.T) {
+	// 2026-03-06 is a Friday
+	order := Difficulties(time.Date(2026, 3, 6, 0, 0, 0, 0, time.UTC))
+	if len(order) != 3 || order[0] != "ADVANCED" {
+		t.Fatalf("Expected ADVANCED first on Friday, got %v", order)
+	}
+	seen := make(map[string]bool)
+	for _, d := range order {
+		seen[d] = true
+	}
+	if !seen["BEGINNER"] || !seen["INTERMEDIATE"] {
+		t.Errorf("Expected every difficulty as a fallback, got %v", order)
+	}
+}
+
+func TestStreaks(t *testing.T) {
+	mon, tue, thu := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC), time.Date(2026, 3, 3, 0, 0, 0, 0, time.UTC), time.Date(2026, 3, 5, 0, 0, 0, 0, time.UTC)
+
+	if got := NextStreak(nil, 0, mon); got != 1 {
+		t.Errorf("First completion: expected 1, got %d", got)
+	}
+	if got := NextStreak(&mon, 1, tue.Add(23*time.Hour)); got != 2 {
+		t.Errorf("Consecutive day: expected 2, got %d", got)
+	}
+	if got := NextStreak(&tue, 2, tue); got != 2 {
+		t.Errorf("Same day: expected 2, got %d", got)
+	}
+	if got := NextStreak(&tue, 2, thu); got != 1 {
+		t.Errorf("Missed day: expected 1, got %d", got)
+	}
+
+	if got := ActiveStreak(&tue, 2, time.Date(2026, 3, 4, 0, 0, 0, 0, time.UTC)); got != 2 {
+		t.Errorf("Streak should survive until the next day ends, got %d", got)
+	}
+	if got := ActiveStreak(&tue, 2, thu); got != 0 {
+		t.Errorf("Streak should be broken after a missed day, got %d", got)
+	}
+}
@@ -0,0 +1,5 @@
+DROP TRIGGER IF EXISTS update_pair_sessions_updated_at ON pair_sessions;
+
+DROP TABLE IF EXISTS pair_session_submissions;
+DROP TABLE IF EXISTS pair_session_operations;
+DROP TABLE IF EXISTS pair_sessions;
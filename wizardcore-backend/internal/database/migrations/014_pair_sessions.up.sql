@@ -0,0 +1,48 @@
+-- Pair-programming sessions: two learners editing one buffer
+CREATE TABLE IF NOT EXISTS pair_sessions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    exercise_id UUID REFERENCES exercises(id) ON DELETE CASCADE,
+    language_id INTEGER NOT NULL,
+    host_user_id UUID REFERENCES users(id) ON DELETE CASCADE,
+    partner_user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    invite_code VARCHAR(16) UNIQUE NOT NULL,
+    status VARCHAR(50) DEFAULT 'pending'
+    CHECK (status IN ('pending', 'active', 'submitting', 'submitted', 'closed')),
+    content TEXT NOT NULL DEFAULT '',
+    revision INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    ended_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_pair_sessions_host ON pair_sessions(host_user_id);
+CREATE INDEX IF NOT EXISTS idx_pair_sessions_partner ON pair_sessions(partner_user_id);
+CREATE INDEX IF NOT EXISTS idx_pair_sessions_status ON pair_sessions(status);
+
+-- Every applied edit, in server order. revision is the buffer revision the
+-- operation produced, so replaying operations 1..n rebuilds the buffer.
+CREATE TABLE IF NOT EXISTS pair_session_operations (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    session_id UUID REFERENCES pair_sessions(id) ON DELETE CASCADE,
+    revision INTEGER NOT NULL,
+    user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    op_type VARCHAR(10) NOT NULL CHECK (op_type IN ('insert', 'delete')),
+    position INTEGER NOT NULL,
+    text TEXT,
+    length INTEGER,
+    cursor_position INTEGER,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(session_id, revision)
+);
+
+-- Submissions credited to each member of a pair
+CREATE TABLE IF NOT EXISTS pair_session_submissions (
+    session_id UUID REFERENCES pair_sessions(id) ON DELETE CASCADE,
+    user_id UUID REFERENCES users(id) ON DELETE CASCADE,
+    submission_id UUID REFERENCES submissions(id) ON DELETE CASCADE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (session_id, submission_id)
+);
+
+CREATE TRIGGER update_pair_sessions_updated_at BEFORE UPDATE ON pair_sessions
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,8 @@
+DROP TABLE IF EXISTS duel_rating_history;
+
+DROP INDEX IF EXISTS idx_user_practice_stats_rating;
+ALTER TABLE user_practice_stats DROP COLUMN IF EXISTS peak_rating;
+ALTER TABLE user_practice_stats DROP COLUMN IF EXISTS rated_duels;
+ALTER TABLE user_practice_stats DROP COLUMN IF EXISTS rating_volatility;
+ALTER TABLE user_practice_stats DROP COLUMN IF EXISTS rating_deviation;
+ALTER TABLE user_practice_stats DROP COLUMN IF EXISTS rating;
@@ -0,0 +1,25 @@
+-- Glicko-2 duel ratings
+ALTER TABLE user_practice_stats ADD COLUMN IF NOT EXISTS rating DOUBLE PRECISION NOT NULL DEFAULT 1500;
+ALTER TABLE user_practice_stats ADD COLUMN IF NOT EXISTS rating_deviation DOUBLE PRECISION NOT NULL DEFAULT 350;
+ALTER TABLE user_practice_stats ADD COLUMN IF NOT EXISTS rating_volatility DOUBLE PRECISION NOT NULL DEFAULT 0.06;
+ALTER TABLE user_practice_stats ADD COLUMN IF NOT EXISTS rated_duels INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE user_practice_stats ADD COLUMN IF NOT EXISTS peak_rating DOUBLE PRECISION;
+
+CREATE INDEX IF NOT EXISTS idx_user_practice_stats_rating ON user_practice_stats(rating DESC) WHERE rated_duels > 0;
+
+-- One row per player per rated duel, for rating charts
+CREATE TABLE IF NOT EXISTS duel_rating_history (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID REFERENCES users(id) ON DELETE CASCADE,
+    match_id UUID REFERENCES practice_matches(id) ON DELETE CASCADE,
+    opponent_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    score DOUBLE PRECISION NOT NULL, -- 1 win, 0.5 draw, 0 loss
+    rating_before DOUBLE PRECISION NOT NULL,
+    rating_after DOUBLE PRECISION NOT NULL,
+    deviation_after DOUBLE PRECISION NOT NULL,
+    volatility_after DOUBLE PRECISION NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(user_id, match_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_duel_rating_history_user ON duel_rating_history(user_id, created_at DESC);
@@ -0,0 +1,18 @@
+-- Duel matchmaking queue
+CREATE TABLE IF NOT EXISTS matchmaking_queue (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID REFERENCES users(id) ON DELETE CASCADE,
+    language_id INTEGER, -- NULL accepts any language
+    difficulty VARCHAR(50), -- NULL accepts any difficulty
+    rating DOUBLE PRECISION NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'waiting'
+    CHECK (status IN ('waiting', 'matched', 'cancelled', 'expired')),
+    match_id UUID REFERENCES practice_matches(id) ON DELETE SET NULL,
+    enqueued_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    matched_at TIMESTAMP
+);
+
+-- A user waits in at most one queue entry at a time
+CREATE UNIQUE INDEX IF NOT EXISTS idx_matchmaking_queue_waiting_user ON matchmaking_queue(user_id) WHERE status = 'waiting';
+CREATE INDEX IF NOT EXISTS idx_matchmaking_queue_waiting ON matchmaking_queue(enqueued_at) WHERE status = 'waiting';
+CREATE INDEX IF NOT EXISTS idx_matchmaking_queue_matched_at ON matchmaking_queue(matched_at) WHERE status = 'matched';
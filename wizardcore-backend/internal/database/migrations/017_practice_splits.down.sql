@@ -0,0 +1,8 @@
+ALTER TABLE user_practice_stats DROP COLUMN IF EXISTS completion_count;
+ALTER TABLE user_practice_stats DROP COLUMN IF EXISTS completion_time_total;
+ALTER TABLE user_practice_stats DROP COLUMN IF EXISTS best_endurance_score;
+ALTER TABLE user_practice_stats DROP COLUMN IF EXISTS best_endurance_solved;
+ALTER TABLE user_practice_stats DROP COLUMN IF EXISTS endurance_runs_completed;
+
+DROP TABLE IF EXISTS practice_personal_bests;
+DROP TABLE IF EXISTS match_splits;
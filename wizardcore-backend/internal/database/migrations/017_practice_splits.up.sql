@@ -0,0 +1,38 @@
+-- Exercises served in multi-exercise practice runs (speed run, endurance)
+CREATE TABLE IF NOT EXISTS match_splits (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    match_id UUID REFERENCES practice_matches(id) ON DELETE CASCADE,
+    user_id UUID REFERENCES users(id) ON DELETE CASCADE,
+    exercise_id UUID REFERENCES exercises(id) ON DELETE CASCADE,
+    sequence INTEGER NOT NULL,
+    result VARCHAR(20) NOT NULL DEFAULT 'pending'
+    CHECK (result IN ('pending', 'solved', 'skipped', 'unfinished')),
+    score INTEGER DEFAULT 0,
+    attempts INTEGER DEFAULT 0,
+    submission_id UUID REFERENCES submissions(id) ON DELETE SET NULL,
+    served_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    completed_at TIMESTAMP,
+    split_seconds INTEGER,
+    UNIQUE(match_id, user_id, sequence)
+);
+
+CREATE INDEX IF NOT EXISTS idx_match_splits_match_user ON match_splits(match_id, user_id);
+
+-- Personal bests per practice mode
+CREATE TABLE IF NOT EXISTS practice_personal_bests (
+    user_id UUID REFERENCES users(id) ON DELETE CASCADE,
+    mode VARCHAR(50) NOT NULL,
+    best_score INTEGER NOT NULL DEFAULT 0,
+    most_solved INTEGER NOT NULL DEFAULT 0,
+    best_time_seconds INTEGER, -- fastest full completion
+    match_id UUID REFERENCES practice_matches(id) ON DELETE SET NULL,
+    achieved_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, mode)
+);
+
+-- Endurance stats and the running average of completion times
+ALTER TABLE user_practice_stats ADD COLUMN IF NOT EXISTS endurance_runs_completed INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE user_practice_stats ADD COLUMN IF NOT EXISTS best_endurance_solved INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE user_practice_stats ADD COLUMN IF NOT EXISTS best_endurance_score INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE user_practice_stats ADD COLUMN IF NOT EXISTS completion_time_total BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE user_practice_stats ADD COLUMN IF NOT EXISTS completion_count INTEGER NOT NULL DEFAULT 0;
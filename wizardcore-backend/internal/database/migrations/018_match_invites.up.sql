@@ -0,0 +1,18 @@
+-- Private duel invitations. An invite either names the invitee or is open to
+-- whoever redeems its code first.
+CREATE TABLE IF NOT EXISTS match_invites (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    match_id UUID NOT NULL REFERENCES practice_matches(id) ON DELETE CASCADE,
+    inviter_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    invitee_id UUID REFERENCES users(id) ON DELETE CASCADE, -- NULL until an open invite is accepted
+    invite_code VARCHAR(16) NOT NULL UNIQUE,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending'
+    CHECK (status IN ('pending', 'accepted', 'declined', 'cancelled', 'expired')),
+    expires_at TIMESTAMP NOT NULL,
+    responded_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_match_invites_inviter ON match_invites(inviter_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_match_invites_invitee ON match_invites(invitee_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_match_invites_pending_expiry ON match_invites(expires_at) WHERE status = 'pending';
@@ -0,0 +1,4 @@
+DROP TRIGGER IF EXISTS update_tournaments_updated_at ON tournaments;
+DROP TABLE IF EXISTS tournament_matches;
+DROP TABLE IF EXISTS tournament_participants;
+DROP TABLE IF EXISTS tournaments;
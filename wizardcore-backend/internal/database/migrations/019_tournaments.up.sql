@@ -0,0 +1,70 @@
+-- Tournaments built on practice duels
+CREATE TABLE IF NOT EXISTS tournaments (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name VARCHAR(255) NOT NULL,
+    description TEXT,
+    format VARCHAR(30) NOT NULL
+    CHECK (format IN ('single_elimination', 'double_elimination', 'swiss')),
+    status VARCHAR(20) NOT NULL DEFAULT 'registration'
+    CHECK (status IN ('registration', 'in_progress', 'completed', 'cancelled')),
+    language_id INTEGER, -- NULL allows any language
+    difficulty VARCHAR(50), -- NULL allows any difficulty
+    time_limit_minutes INTEGER NOT NULL DEFAULT 10,
+    max_participants INTEGER,
+    swiss_rounds INTEGER, -- NULL picks enough rounds for a clear winner
+    current_round INTEGER NOT NULL DEFAULT 0, -- Swiss round being played
+    registration_opens_at TIMESTAMP NOT NULL,
+    registration_closes_at TIMESTAMP NOT NULL,
+    starts_at TIMESTAMP NOT NULL,
+    winner_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    completed_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_tournaments_status_starts_at ON tournaments(status, starts_at);
+
+CREATE TABLE IF NOT EXISTS tournament_participants (
+    tournament_id UUID REFERENCES tournaments(id) ON DELETE CASCADE,
+    user_id UUID REFERENCES users(id) ON DELETE CASCADE,
+    seed INTEGER, -- assigned when the tournament starts
+    status VARCHAR(20) NOT NULL DEFAULT 'registered'
+    CHECK (status IN ('registered', 'active', 'eliminated', 'champion')),
+    registered_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (tournament_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_tournament_participants_user ON tournament_participants(user_id);
+
+-- Bracket nodes. node_index orders the nodes of a tournament; next_node and
+-- loser_next_node refer to it. A slot is filled once its feeding node is
+-- decided; a filled slot without a player is a bye.
+CREATE TABLE IF NOT EXISTS tournament_matches (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tournament_id UUID REFERENCES tournaments(id) ON DELETE CASCADE,
+    node_index INTEGER NOT NULL,
+    bracket VARCHAR(20) NOT NULL
+    CHECK (bracket IN ('winners', 'losers', 'grand_final', 'swiss')),
+    round INTEGER NOT NULL,
+    position INTEGER NOT NULL,
+    player1_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    player2_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    slot1_filled BOOLEAN NOT NULL DEFAULT false,
+    slot2_filled BOOLEAN NOT NULL DEFAULT false,
+    winner_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    decided BOOLEAN NOT NULL DEFAULT false,
+    next_node INTEGER,
+    next_slot INTEGER,
+    loser_next_node INTEGER,
+    loser_next_slot INTEGER,
+    match_id UUID REFERENCES practice_matches(id) ON DELETE SET NULL,
+    overridden BOOLEAN NOT NULL DEFAULT false,
+    decided_at TIMESTAMP,
+    UNIQUE (tournament_id, node_index)
+);
+
+CREATE INDEX IF NOT EXISTS idx_tournament_matches_match_id ON tournament_matches(match_id);
+
+CREATE TRIGGER update_tournaments_updated_at BEFORE UPDATE ON tournaments
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,4 @@
+DROP TRIGGER IF EXISTS update_practice_area_settings_updated_at ON practice_area_settings;
+DROP TABLE IF EXISTS practice_area_settings;
+DROP TRIGGER IF EXISTS update_challenge_types_updated_at ON challenge_types;
+DROP TABLE IF EXISTS challenge_types;
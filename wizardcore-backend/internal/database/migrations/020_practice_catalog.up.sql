@@ -0,0 +1,57 @@
+-- Challenge types offered on the practice page. id is the match_type the
+-- challenge creates; the modes themselves are implemented in code.
+CREATE TABLE IF NOT EXISTS challenge_types (
+    id VARCHAR(50) PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    icon VARCHAR(50) NOT NULL DEFAULT '',
+    time_limit_minutes INTEGER CHECK (time_limit_minutes > 0),
+    xp_multiplier DOUBLE PRECISION NOT NULL DEFAULT 1.0 CHECK (xp_multiplier >= 0),
+    sort_order INTEGER NOT NULL DEFAULT 0,
+    is_enabled BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO challenge_types (id, name, description, icon, time_limit_minutes, xp_multiplier, sort_order) VALUES
+    ('duel', '1v1 Duel', 'Compete against another learner in real-time', '⚔️', 10, 1.0, 1),
+    ('speed_run', 'Speed Run', 'Complete exercises as fast as possible', '⏱️', 15, 1.0, 2),
+    ('random', 'Random Challenge', 'Get a random exercise to solve', '🎲', 10, 1.0, 3),
+    ('endurance', 'Endurance', 'Solve as many exercises as you can in a time limit', '🏋️', 30, 1.0, 4)
+ON CONFLICT (id) DO NOTHING;
+
+CREATE TRIGGER update_challenge_types_updated_at BEFORE UPDATE ON challenge_types
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- Presentation of practice areas. Areas are computed from exercise
+-- languages and tags; a row here names, styles or hides one of them.
+CREATE TABLE IF NOT EXISTS practice_area_settings (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    kind VARCHAR(20) NOT NULL CHECK (kind IN ('language', 'tag')),
+    area_key VARCHAR(100) NOT NULL, -- Judge0 language ID or lower-case tag
+    name VARCHAR(100),
+    color_gradient VARCHAR(100),
+    icon VARCHAR(50),
+    sort_order INTEGER NOT NULL DEFAULT 0,
+    is_visible BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (kind, area_key)
+);
+
+INSERT INTO practice_area_settings (kind, area_key, name, color_gradient, sort_order) VALUES
+    ('language', '71', 'Python', 'from-green-400 to-cyan-400', 1),
+    ('language', '50', 'C', 'from-blue-400 to-indigo-400', 2),
+    ('language', '45', 'Assembly', 'from-blue-400 to-indigo-400', 3),
+    ('language', '63', 'JavaScript', 'from-yellow-400 to-orange-400', 4),
+    ('language', '82', 'SQL', 'from-purple-400 to-pink-400', 5),
+    ('language', '54', 'C++', 'from-sky-400 to-blue-500', 6),
+    ('language', '62', 'Java', 'from-orange-400 to-red-400', 7),
+    ('language', '60', 'Go', 'from-cyan-400 to-teal-400', 8),
+    ('language', '73', 'Rust', 'from-amber-500 to-orange-600', 9),
+    ('tag', 'reverse-engineering', 'Reverse Engineering', 'from-red-400 to-rose-400', 20),
+    ('tag', 'rootkit', 'Rootkit Development', 'from-gray-400 to-black', 21)
+ON CONFLICT (kind, area_key) DO NOTHING;
+
+CREATE TRIGGER update_practice_area_settings_updated_at BEFORE UPDATE ON practice_area_settings
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,2 @@
+ALTER TABLE match_participants DROP COLUMN IF EXISTS replay_private;
+DROP TABLE IF EXISTS match_replay_events;
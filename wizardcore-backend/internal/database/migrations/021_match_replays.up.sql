@@ -0,0 +1,24 @@
+-- Timeline of a match for replays: editor snapshots and submissions per
+-- participant. A snapshot is either a keyframe holding the full code or a
+-- delta of edit operations against the previous snapshot.
+CREATE TABLE IF NOT EXISTS match_replay_events (
+    id BIGSERIAL PRIMARY KEY,
+    match_id UUID NOT NULL REFERENCES practice_matches(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    event_type VARCHAR(20) NOT NULL CHECK (event_type IN ('snapshot', 'submission')),
+    offset_ms INTEGER NOT NULL, -- since the match started
+    code TEXT, -- keyframes only
+    operations JSONB, -- deltas only
+    language_id INTEGER,
+    exercise_id UUID REFERENCES exercises(id) ON DELETE SET NULL,
+    submission_id UUID REFERENCES submissions(id) ON DELETE SET NULL,
+    result VARCHAR(20),
+    score INTEGER,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_match_replay_events_match ON match_replay_events(match_id, offset_ms, id);
+CREATE INDEX IF NOT EXISTS idx_match_replay_events_created_at ON match_replay_events(created_at);
+
+-- A participant can keep the replay of a match to the players
+ALTER TABLE match_participants ADD COLUMN IF NOT EXISTS replay_private BOOLEAN NOT NULL DEFAULT false;
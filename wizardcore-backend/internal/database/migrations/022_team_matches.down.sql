@@ -0,0 +1,9 @@
+DELETE FROM challenge_types WHERE id = 'team_duel';
+DROP TABLE IF EXISTS match_exercise_results;
+ALTER TABLE match_participants DROP COLUMN IF EXISTS team_id;
+DROP TABLE IF EXISTS match_teams;
+DROP TABLE IF EXISTS match_exercises;
+DROP TABLE IF EXISTS team_matches;
+DROP TABLE IF EXISTS match_party_members;
+DROP TRIGGER IF EXISTS update_match_parties_updated_at ON match_parties;
+DROP TABLE IF EXISTS match_parties;
@@ -0,0 +1,70 @@
+-- Parties: players who queue for a team match together
+CREATE TABLE IF NOT EXISTS match_parties (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    leader_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    invite_code VARCHAR(16) NOT NULL UNIQUE,
+    team_size INTEGER NOT NULL CHECK (team_size BETWEEN 2 AND 5),
+    scoring VARCHAR(10) NOT NULL DEFAULT 'sum' CHECK (scoring IN ('best', 'sum')),
+    status VARCHAR(20) NOT NULL DEFAULT 'forming'
+    CHECK (status IN ('forming', 'queued', 'in_match', 'disbanded')),
+    match_id UUID REFERENCES practice_matches(id) ON DELETE SET NULL,
+    queued_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_match_parties_queue ON match_parties(team_size, scoring, queued_at) WHERE status = 'queued';
+
+CREATE TRIGGER update_match_parties_updated_at BEFORE UPDATE ON match_parties
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TABLE IF NOT EXISTS match_party_members (
+    party_id UUID NOT NULL REFERENCES match_parties(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    joined_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (party_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_match_party_members_user ON match_party_members(user_id);
+
+-- Settings of a team match and the exercises all teams share
+CREATE TABLE IF NOT EXISTS team_matches (
+    match_id UUID PRIMARY KEY REFERENCES practice_matches(id) ON DELETE CASCADE,
+    team_size INTEGER NOT NULL,
+    scoring VARCHAR(10) NOT NULL CHECK (scoring IN ('best', 'sum'))
+);
+
+CREATE TABLE IF NOT EXISTS match_exercises (
+    match_id UUID NOT NULL REFERENCES practice_matches(id) ON DELETE CASCADE,
+    position INTEGER NOT NULL,
+    exercise_id UUID NOT NULL REFERENCES exercises(id) ON DELETE CASCADE,
+    PRIMARY KEY (match_id, position)
+);
+
+CREATE TABLE IF NOT EXISTS match_teams (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    match_id UUID NOT NULL REFERENCES practice_matches(id) ON DELETE CASCADE,
+    team_number INTEGER NOT NULL,
+    party_id UUID REFERENCES match_parties(id) ON DELETE SET NULL,
+    score INTEGER NOT NULL DEFAULT 0,
+    result VARCHAR(20), -- 'win', 'loss', 'draw'
+    UNIQUE(match_id, team_number)
+);
+
+ALTER TABLE match_participants ADD COLUMN IF NOT EXISTS team_id UUID REFERENCES match_teams(id) ON DELETE SET NULL;
+
+-- Best score of each player on each exercise of a team match
+CREATE TABLE IF NOT EXISTS match_exercise_results (
+    match_id UUID NOT NULL REFERENCES practice_matches(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    exercise_id UUID NOT NULL REFERENCES exercises(id) ON DELETE CASCADE,
+    best_score INTEGER NOT NULL DEFAULT 0,
+    solved BOOLEAN NOT NULL DEFAULT false,
+    submission_id UUID REFERENCES submissions(id) ON DELETE SET NULL,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (match_id, user_id, exercise_id)
+);
+
+INSERT INTO challenge_types (id, name, description, icon, time_limit_minutes, xp_multiplier, sort_order, is_enabled) VALUES
+    ('team_duel', 'Team Duel', 'Team up with friends and race another party through a shared exercise set', '🛡️', 20, 1.0, 5, true)
+ON CONFLICT (id) DO NOTHING;
@@ -0,0 +1,4 @@
+DROP TRIGGER IF EXISTS update_daily_challenge_streaks_updated_at ON daily_challenge_streaks;
+DROP TABLE IF EXISTS daily_challenge_streaks;
+DROP TABLE IF EXISTS daily_challenge_attempts;
+DROP TABLE IF EXISTS daily_challenges;
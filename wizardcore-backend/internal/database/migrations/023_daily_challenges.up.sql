@@ -0,0 +1,40 @@
+-- One global challenge per UTC day. Past days form the archive.
+CREATE TABLE IF NOT EXISTS daily_challenges (
+    challenge_date DATE PRIMARY KEY,
+    exercise_id UUID NOT NULL REFERENCES exercises(id) ON DELETE CASCADE,
+    difficulty VARCHAR(50) NOT NULL,
+    bonus_xp INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_daily_challenges_exercise ON daily_challenges(exercise_id, challenge_date);
+
+-- A player's attempt at a daily challenge. The clock starts when the player
+-- first opens the challenge; solve time is measured to the first accepted
+-- submission made the same day.
+CREATE TABLE IF NOT EXISTS daily_challenge_attempts (
+    challenge_date DATE NOT NULL REFERENCES daily_challenges(challenge_date) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    started_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    completed_at TIMESTAMP,
+    solve_seconds INTEGER,
+    submission_id UUID REFERENCES submissions(id) ON DELETE SET NULL,
+    bonus_xp INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (challenge_date, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_daily_challenge_attempts_leaderboard
+    ON daily_challenge_attempts(challenge_date, solve_seconds, completed_at)
+    WHERE completed_at IS NOT NULL;
+
+-- Daily challenge streak, kept apart from the activity streak on users
+CREATE TABLE IF NOT EXISTS daily_challenge_streaks (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    current_streak INTEGER NOT NULL DEFAULT 0,
+    longest_streak INTEGER NOT NULL DEFAULT 0,
+    last_completed_date DATE,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TRIGGER update_daily_challenge_streaks_updated_at BEFORE UPDATE ON daily_challenge_streaks
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_leaderboard_history_scope;
+DROP INDEX IF EXISTS idx_leaderboard_entries_scope;
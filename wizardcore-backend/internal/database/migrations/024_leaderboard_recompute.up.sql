@@ -0,0 +1,10 @@
+-- The unique constraint on leaderboard_entries treats every NULL pathway as
+-- distinct, so global entries could not be upserted. Index the global scope
+-- under the nil UUID instead.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_leaderboard_entries_scope
+    ON leaderboard_entries(user_id, timeframe, (COALESCE(pathway_id, '00000000-0000-0000-0000-000000000000'::uuid)));
+
+-- Rank history is appended only when a rank or XP changes; the latest row
+-- before a point in time is the rank at that time.
+CREATE INDEX IF NOT EXISTS idx_leaderboard_history_scope
+    ON leaderboard_history(user_id, timeframe, pathway_id, recorded_at DESC);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS league_memberships;
+DROP TABLE IF EXISTS league_cohorts;
+DROP TABLE IF EXISTS league_seasons;
@@ -0,0 +1,40 @@
+-- Weekly league seasons. A season covers the same window as the weekly
+-- leaderboard and is closed by the league job once it ends.
+CREATE TABLE IF NOT EXISTS league_seasons (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    starts_at TIMESTAMP NOT NULL UNIQUE,
+    ends_at TIMESTAMP NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'closed')),
+    closed_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Groups of about 30 players of the same tier who compete in a season
+CREATE TABLE IF NOT EXISTS league_cohorts (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    season_id UUID NOT NULL REFERENCES league_seasons(id) ON DELETE CASCADE,
+    tier INTEGER NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_league_cohorts_season ON league_cohorts(season_id, tier, created_at);
+
+-- A player's place in a season. Players join when they first earn XP in the
+-- season; the result columns are filled in when it closes and form the
+-- season history. new_tier is where the player starts the next season.
+CREATE TABLE IF NOT EXISTS league_memberships (
+    season_id UUID NOT NULL REFERENCES league_seasons(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    cohort_id UUID NOT NULL REFERENCES league_cohorts(id) ON DELETE CASCADE,
+    tier INTEGER NOT NULL,
+    joined_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    final_rank INTEGER,
+    final_xp INTEGER,
+    outcome VARCHAR(20) CHECK (outcome IN ('promoted', 'relegated', 'stayed')),
+    new_tier INTEGER,
+    reward_xp INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (season_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_league_memberships_cohort ON league_memberships(cohort_id);
+CREATE INDEX IF NOT EXISTS idx_league_memberships_user ON league_memberships(user_id, joined_at DESC);
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_submissions_user_created;
+DROP TABLE IF EXISTS anticheat_exercise_baselines;
+DROP TRIGGER IF EXISTS update_anticheat_reviews_updated_at ON anticheat_reviews;
+DROP TABLE IF EXISTS anticheat_reviews;
+DROP TABLE IF EXISTS anticheat_flags;
@@ -0,0 +1,44 @@
+-- Implausible patterns spotted by the anti-cheat analyzer
+CREATE TABLE IF NOT EXISTS anticheat_flags (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    reason VARCHAR(30) NOT NULL
+    CHECK (reason IN ('fast_solve', 'burst', 'duplicate_source', 'xp_spike')),
+    submission_id UUID REFERENCES submissions(id) ON DELETE SET NULL,
+    related_user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    details TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_anticheat_flags_user ON anticheat_flags(user_id, created_at DESC);
+
+-- Review queue: one row per flagged account. A new flag reopens a cleared
+-- review; confirmed reviews stay confirmed.
+CREATE TABLE IF NOT EXISTS anticheat_reviews (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending'
+    CHECK (status IN ('pending', 'cleared', 'confirmed')),
+    exclude_from_leaderboards BOOLEAN NOT NULL DEFAULT false,
+    notes TEXT,
+    opened_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    reviewed_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    reviewed_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_anticheat_reviews_status ON anticheat_reviews(status, opened_at);
+
+CREATE TRIGGER update_anticheat_reviews_updated_at BEFORE UPDATE ON anticheat_reviews
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- Fastest unflagged first solve of each exercise, the baseline for spotting
+-- implausibly fast solves
+CREATE TABLE IF NOT EXISTS anticheat_exercise_baselines (
+    exercise_id UUID PRIMARY KEY REFERENCES exercises(id) ON DELETE CASCADE,
+    min_solve_seconds INTEGER NOT NULL,
+    samples INTEGER NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_submissions_user_created ON submissions(user_id, created_at DESC);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS xp_backfill_reviews;
+DROP TABLE IF EXISTS xp_transactions;
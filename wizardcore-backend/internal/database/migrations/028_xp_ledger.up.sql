@@ -0,0 +1,105 @@
+-- Append-only XP ledger. users.total_xp and user_pathway_enrollments.xp_earned
+-- are projections of it; each source can be awarded only once through its
+-- idempotency key.
+CREATE TABLE IF NOT EXISTS xp_transactions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    amount INTEGER NOT NULL CHECK (amount <> 0),
+    source_type VARCHAR(30) NOT NULL
+    CHECK (source_type IN ('submission', 'daily_challenge', 'league_reward', 'opening_balance')),
+    source_id UUID,
+    pathway_id UUID REFERENCES pathways(id) ON DELETE SET NULL,
+    idempotency_key VARCHAR(200) NOT NULL UNIQUE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_xp_transactions_user ON xp_transactions(user_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_xp_transactions_created ON xp_transactions(created_at);
+CREATE INDEX IF NOT EXISTS idx_xp_transactions_pathway ON xp_transactions(pathway_id, created_at)
+    WHERE pathway_id IS NOT NULL;
+
+-- Backfill the awards made before the ledger existed. Every submission used
+-- to be paid in full, so resubmitting farmed XP; an exercise is now credited
+-- once, with the best score the user reached on it.
+INSERT INTO xp_transactions (user_id, amount, source_type, source_id, pathway_id, idempotency_key, created_at)
+SELECT DISTINCT ON (s.user_id, s.exercise_id)
+    s.user_id, s.points_earned, 'submission', s.id, m.pathway_id, 'submission:' || s.id, COALESCE(s.created_at, CURRENT_TIMESTAMP)
+FROM submissions s
+JOIN exercises e ON s.exercise_id = e.id
+LEFT JOIN modules m ON e.module_id = m.id
+WHERE s.user_id IS NOT NULL AND s.points_earned > 0
+AND COALESCE(s.submission_type, 'solution') <> 'draft'
+ORDER BY s.user_id, s.exercise_id, s.points_earned DESC, s.created_at, s.id
+ON CONFLICT (idempotency_key) DO NOTHING;
+
+INSERT INTO xp_transactions (user_id, amount, source_type, source_id, idempotency_key, created_at)
+SELECT a.user_id, a.bonus_xp, 'daily_challenge', a.submission_id,
+    'daily_challenge:' || a.challenge_date || ':' || a.user_id, a.completed_at
+FROM daily_challenge_attempts a
+WHERE a.completed_at IS NOT NULL AND a.bonus_xp > 0
+ON CONFLICT (idempotency_key) DO NOTHING;
+
+INSERT INTO xp_transactions (user_id, amount, source_type, source_id, idempotency_key, created_at)
+SELECT m.user_id, m.reward_xp, 'league_reward', m.season_id,
+    'league_reward:' || m.season_id || ':' || m.user_id, COALESCE(s.closed_at, s.ends_at)
+FROM league_memberships m
+JOIN league_seasons s ON m.season_id = s.id
+WHERE m.reward_xp > 0
+ON CONFLICT (idempotency_key) DO NOTHING;
+
+-- Totals kept before the ledger counted every submission twice, so they are
+-- not carried over as opening balances. A user whose total exceeds even that
+-- double credit is recorded here for an admin to review; resolving the review
+-- posts an opening balance of up to recorded_total_xp - ledger_xp.
+CREATE TABLE IF NOT EXISTS xp_backfill_reviews (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    recorded_total_xp INTEGER NOT NULL,
+    ledger_xp INTEGER NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    resolved_at TIMESTAMP,
+    resolved_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    opening_balance INTEGER
+);
+
+INSERT INTO xp_backfill_reviews (user_id, recorded_total_xp, ledger_xp)
+SELECT u.id, COALESCE(u.total_xp, 0), COALESCE(t.xp, 0)
+FROM users u
+LEFT JOIN (
+    SELECT user_id,
+        SUM(amount)::int AS xp,
+        SUM(amount) FILTER (WHERE source_type <> 'submission')::int AS other_xp
+    FROM xp_transactions GROUP BY user_id
+) t ON t.user_id = u.id
+LEFT JOIN (
+    SELECT user_id, SUM(points_earned)::int AS xp FROM submissions
+    WHERE points_earned > 0 AND COALESCE(submission_type, 'solution') <> 'draft'
+    GROUP BY user_id
+) sub ON sub.user_id = u.id
+WHERE COALESCE(u.total_xp, 0) > COALESCE(t.other_xp, 0) + 2 * COALESCE(sub.xp, 0)
+ON CONFLICT (user_id) DO NOTHING;
+
+-- points_earned is what a submission was credited; submissions passed over
+-- by the backfill were not
+UPDATE submissions s SET points_earned = 0
+WHERE s.points_earned > 0
+AND NOT EXISTS (SELECT 1 FROM xp_transactions t WHERE t.idempotency_key = 'submission:' || s.id);
+
+-- The totals are projections of the ledger from here on
+UPDATE users u
+SET total_xp = COALESCE(t.xp, 0)
+FROM users x
+LEFT JOIN (
+    SELECT user_id, SUM(amount)::int AS xp FROM xp_transactions GROUP BY user_id
+) t ON t.user_id = x.id
+WHERE u.id = x.id AND COALESCE(u.total_xp, 0) <> COALESCE(t.xp, 0);
+
+UPDATE user_pathway_enrollments e
+SET xp_earned = COALESCE(t.xp, 0)
+FROM user_pathway_enrollments x
+LEFT JOIN (
+    SELECT user_id, pathway_id, SUM(amount)::int AS xp
+    FROM xp_transactions
+    WHERE pathway_id IS NOT NULL
+    GROUP BY user_id, pathway_id
+) t ON t.user_id = x.user_id AND t.pathway_id = x.pathway_id
+WHERE e.id = x.id AND COALESCE(e.xp_earned, 0) <> COALESCE(t.xp, 0);
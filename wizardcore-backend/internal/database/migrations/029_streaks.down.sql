@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_users_active_streak;
+ALTER TABLE users DROP COLUMN IF EXISTS streak_risk_notified_on;
+ALTER TABLE users DROP COLUMN IF EXISTS streak_last_day;
+ALTER TABLE users DROP COLUMN IF EXISTS streak_freezes;
+ALTER TABLE user_preferences DROP COLUMN IF EXISTS timezone;
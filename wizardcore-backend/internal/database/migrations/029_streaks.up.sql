@@ -0,0 +1,14 @@
+-- Timezone the user's days, and so their streaks, are counted in
+ALTER TABLE user_preferences ADD COLUMN IF NOT EXISTS timezone VARCHAR(64) NOT NULL DEFAULT 'UTC';
+
+-- Streak state next to current_streak and longest_streak. streak_last_day is
+-- the last local day that counted towards the streak, active or covered by
+-- a freeze; last_activity_date stays the last active local day.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS streak_freezes INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS streak_last_day DATE;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS streak_risk_notified_on DATE;
+
+UPDATE users SET streak_last_day = last_activity_date
+WHERE streak_last_day IS NULL AND COALESCE(current_streak, 0) > 0;
+
+CREATE INDEX IF NOT EXISTS idx_users_active_streak ON users(id) WHERE current_streak > 0;
@@ -0,0 +1,3 @@
+-- The ledger is append-only: module rewards already awarded stay credited,
+-- so the source type stays allowed
+DROP INDEX IF EXISTS idx_module_progress_completed;
@@ -0,0 +1,7 @@
+-- Completing a module awards its xp_reward through the XP ledger
+ALTER TABLE xp_transactions DROP CONSTRAINT IF EXISTS xp_transactions_source_type_check;
+ALTER TABLE xp_transactions ADD CONSTRAINT xp_transactions_source_type_check
+    CHECK (source_type IN ('submission', 'daily_challenge', 'league_reward', 'module_completion', 'opening_balance'));
+
+CREATE INDEX IF NOT EXISTS idx_module_progress_completed ON user_module_progress(user_id, pathway_id)
+    WHERE completed_at IS NOT NULL;
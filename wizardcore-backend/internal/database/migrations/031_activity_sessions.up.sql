@@ -0,0 +1,18 @@
+-- Time-on-task sessions built from client focus and heartbeat events. Each
+-- is attributed to the exercise and to its module and pathway.
+CREATE TABLE IF NOT EXISTS activity_sessions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    exercise_id UUID NOT NULL REFERENCES exercises(id) ON DELETE CASCADE,
+    module_id UUID REFERENCES modules(id) ON DELETE SET NULL,
+    pathway_id UUID REFERENCES pathways(id) ON DELETE SET NULL,
+    started_at TIMESTAMP NOT NULL,
+    last_seen_at TIMESTAMP NOT NULL,
+    active_seconds INTEGER NOT NULL DEFAULT 0,
+    focused BOOLEAN NOT NULL DEFAULT true
+);
+
+CREATE INDEX IF NOT EXISTS idx_activity_sessions_user_exercise ON activity_sessions(user_id, exercise_id, last_seen_at DESC);
+CREATE INDEX IF NOT EXISTS idx_activity_sessions_exercise ON activity_sessions(exercise_id);
+CREATE INDEX IF NOT EXISTS idx_activity_sessions_pathway ON activity_sessions(user_id, pathway_id)
+    WHERE pathway_id IS NOT NULL;
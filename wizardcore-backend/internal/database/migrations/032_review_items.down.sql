@@ -0,0 +1,4 @@
+-- The ledger is append-only: review XP already awarded stays credited, so
+-- the source type stays allowed
+DROP TABLE IF EXISTS review_logs;
+DROP TABLE IF EXISTS review_items;
@@ -0,0 +1,47 @@
+-- Spaced-repetition reviews of solved exercises. due_on is a calendar day in
+-- the user's timezone.
+CREATE TABLE IF NOT EXISTS review_items (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    exercise_id UUID NOT NULL REFERENCES exercises(id) ON DELETE CASCADE,
+    repetitions INTEGER NOT NULL DEFAULT 0,
+    interval_days INTEGER NOT NULL DEFAULT 1,
+    ease_factor DOUBLE PRECISION NOT NULL DEFAULT 2.5,
+    due_on DATE NOT NULL,
+    last_reviewed_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(user_id, exercise_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_review_items_due ON review_items(user_id, due_on);
+
+-- One row per completed review. A submission can complete one review.
+CREATE TABLE IF NOT EXISTS review_logs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    item_id UUID NOT NULL REFERENCES review_items(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    submission_id UUID NOT NULL UNIQUE REFERENCES submissions(id) ON DELETE CASCADE,
+    rating VARCHAR(10) NOT NULL CHECK (rating IN ('again', 'hard', 'good', 'easy')),
+    quality INTEGER NOT NULL,
+    correct BOOLEAN NOT NULL,
+    interval_days INTEGER NOT NULL,
+    ease_factor DOUBLE PRECISION NOT NULL,
+    due_on DATE NOT NULL,
+    xp_awarded INTEGER NOT NULL DEFAULT 0,
+    reviewed_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_review_logs_item ON review_logs(item_id, reviewed_at DESC);
+
+ALTER TABLE xp_transactions DROP CONSTRAINT IF EXISTS xp_transactions_source_type_check;
+ALTER TABLE xp_transactions ADD CONSTRAINT xp_transactions_source_type_check
+    CHECK (source_type IN ('submission', 'daily_challenge', 'league_reward', 'module_completion', 'review', 'opening_balance'));
+
+-- Exercises solved before reviews existed are first due tomorrow
+INSERT INTO review_items (user_id, exercise_id, due_on)
+SELECT DISTINCT s.user_id, s.exercise_id, CURRENT_DATE + 1
+FROM submissions s
+JOIN users u ON s.user_id = u.id
+JOIN exercises e ON s.exercise_id = e.id
+WHERE s.is_correct = true
+ON CONFLICT (user_id, exercise_id) DO NOTHING;
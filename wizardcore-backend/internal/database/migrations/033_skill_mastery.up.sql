@@ -0,0 +1,13 @@
+-- Bayesian Knowledge Tracing estimate per user and exercise tag. Run
+-- rebuild-mastery to fill it from the submissions made before it existed.
+CREATE TABLE IF NOT EXISTS skill_mastery (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    tag VARCHAR(100) NOT NULL,
+    p_mastery DOUBLE PRECISION NOT NULL CHECK (p_mastery >= 0 AND p_mastery <= 1),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    correct INTEGER NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, tag)
+);
+
+CREATE INDEX IF NOT EXISTS idx_skill_mastery_tag ON skill_mastery(tag);
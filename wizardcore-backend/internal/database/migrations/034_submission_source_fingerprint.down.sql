@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_submissions_source_fingerprint;
+ALTER TABLE submissions DROP COLUMN IF EXISTS source_fingerprint;
@@ -0,0 +1,9 @@
+-- The MD5 of a submission's source without whitespace, stored when it is
+-- written so the anti-cheat duplicate check is an index lookup
+ALTER TABLE submissions ADD COLUMN IF NOT EXISTS source_fingerprint VARCHAR(32);
+
+UPDATE submissions SET source_fingerprint = md5(regexp_replace(source_code, '\s+', '', 'g'))
+WHERE source_fingerprint IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_submissions_source_fingerprint ON submissions(exercise_id, source_fingerprint)
+    WHERE is_correct;
@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/yourusername/wizardcore-backend/internal/models"
+	"github.com/yourusername/wizardcore-backend/internal/services"
+	"go.uber.org/zap"
+)
+
+type ActivitySessionHandler struct {
+	sessionService *services.ActivitySessionService
+	userService    *services.UserService
+	logger         *zap.Logger
+}
+
+func NewActivitySessionHandler(sessionService *services.ActivitySessionService, userService *services.UserService, logger *zap.Logger) *ActivitySessionHandler {
+	return &ActivitySessionHandler{
+		sessionService: sessionService,
+		userService:    userService,
+		logger:         logger,
+	}
+}
+
+// RecordEvent takes a focus, heartbeat or blur event on an open exercise
+func (h *ActivitySessionHandler) RecordEvent(c *gin.Context) {
+	userID, ok := currentUserID(c, h.userService, h.logger)
+	if !ok {
+		return
+	}
+	exerciseID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid exercise ID"})
+		return
+	}
+	var req models.ActivityEventRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	session, err := h.sessionService.RecordEvent(userID, exerciseID, req.Event)
+	if err != nil {
+		h.logger.Error("Failed to record activity event", zap.Error(err), zap.String("user_id", userID.String()))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record activity"})
+		return
+	}
+	if session == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Exercise not found"})
+		return
+	}
+	c.JSON(http.StatusOK, session)
+}
@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/yourusername/wizardcore-backend/internal/models"
+	"github.com/yourusername/wizardcore-backend/internal/services"
+	"go.uber.org/zap"
+)
+
+type AntiCheatHandler struct {
+	antiCheatService *services.AntiCheatService
+	userService      *services.UserService
+	logger           *zap.Logger
+}
+
+func NewAntiCheatHandler(antiCheatService *services.AntiCheatService, userService *services.UserService, logger *zap.Logger) *AntiCheatHandler {
+	return &AntiCheatHandler{
+		antiCheatService: antiCheatService,
+		userService:      userService,
+		logger:           logger,
+	}
+}
+
+func (h *AntiCheatHandler) ListReviews(c *gin.Context) {
+	status := c.DefaultQuery("status", "pending")
+	switch status {
+	case "all":
+		status = ""
+	case "pending", "cleared", "confirmed":
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid status"})
+		return
+	}
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if page < 1 {
+		page = 1
+	}
+	perPage, _ := strconv.Atoi(c.DefaultQuery("per_page", "20"))
+	if perPage <= 0 || perPage > 100 {
+		perPage = 20
+	}
+	reviews, total, err := h.antiCheatService.ListReviews(status, perPage, (page-1)*perPage)
+	if err != nil {
+		h.logger.Error("Failed to list anti-cheat reviews", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch reviews"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"reviews":    reviews,
+		"pagination": models.Pagination{Total: total, Page: page, PerPage: perPage},
+	})
+}
+
+func (h *AntiCheatHandler) GetReview(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+	review, err := h.antiCheatService.GetReview(userID)
+	if err != nil {
+		h.logger.Error("Failed to get anti-cheat review", zap.Error(err), zap.String("user_id", userID.String()))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch review"})
+		return
+	}
+	if review == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Review not found"})
+		return
+	}
+	c.JSON(http.StatusOK, review)
+}
+
+func (h *AntiCheatHandler) UpdateReview(c *gin.Context) {
+	reviewerID, ok := currentUserID(c, h.userService, h.logger)
+	if !ok {
+		return
+	}
+	userID, err := uuid.Parse(c.Param("user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+	var req models.UpdateAntiCheatReviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	review, err := h.antiCheatService.UpdateReview(userID, reviewerID, &req)
+	if err != nil {
+		h.logger.Error("Failed to update anti-cheat review", zap.Error(err), zap.String("user_id", userID.String()))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update review"})
+		return
+	}
+	if review == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Review not found"})
+		return
+	}
+	c.JSON(http.StatusOK, review)
+}
@@ -0,0 +1,28 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/yourusername/wizardcore-backend/internal/middleware"
+	"github.com/yourusername/wizardcore-backend/internal/services"
+	"go.uber.org/zap"
+)
+
+// currentUserID resolves the authenticated user's internal ID. On failure it
+// writes the error response and returns false.
+func currentUserID(c *gin.Context, userService *services.UserService, logger *zap.Logger) (uuid.UUID, bool) {
+	supabaseUserID, ok := middleware.GetSupabaseUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return uuid.Nil, false
+	}
+	user, err := userService.GetUserBySupabaseUserID(supabaseUserID)
+	if err != nil {
+		logger.Error("Failed to fetch user", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch user"})
+		return uuid.Nil, false
+	}
+	return user.ID, true
+}
@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yourusername/wizardcore-backend/internal/models"
+	"github.com/yourusername/wizardcore-backend/internal/services"
+	"go.uber.org/zap"
+)
+
+type DailyChallengeHandler struct {
+	dailyService *services.DailyChallengeService
+	userService  *services.UserService
+	logger       *zap.Logger
+}
+
+func NewDailyChallengeHandler(dailyService *services.DailyChallengeService, userService *services.UserService, logger *zap.Logger) *DailyChallengeHandler {
+	return &DailyChallengeHandler{
+		dailyService: dailyService,
+		userService:  userService,
+		logger:       logger,
+	}
+}
+
+func (h *DailyChallengeHandler) GetToday(c *gin.Context) {
+	userID, ok := currentUserID(c, h.userService, h.logger)
+	if !ok {
+		return
+	}
+	challenge, err := h.dailyService.GetToday(userID)
+	if err != nil {
+		h.logger.Error("Failed to get daily challenge", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch daily challenge"})
+		return
+	}
+	c.JSON(http.StatusOK, challenge)
+}
+
+func (h *DailyChallengeHandler) GetArchive(c *gin.Context) {
+	userID, ok := currentUserID(c, h.userService, h.logger)
+	if !ok {
+		return
+	}
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if page < 1 {
+		page = 1
+	}
+	perPage, _ := strconv.Atoi(c.DefaultQuery("per_page", "30"))
+	if perPage <= 0 || perPage > 100 {
+		perPage = 30
+	}
+	challenges, total, err := h.dailyService.GetArchive(userID, perPage, (page-1)*perPage)
+	if err != nil {
+		h.logger.Error("Failed to get daily challenge archive", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch daily challenges"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"challenges": challenges,
+		"pagination": models.Pagination{Total: total, Page: page, PerPage: perPage},
+	})
+}
+
+func (h *DailyChallengeHandler) GetChallenge(c *gin.Context) {
+	userID, ok := currentUserID(c, h.userService, h.logger)
+	if !ok {
+		return
+	}
+	challenge, err := h.dailyService.GetChallenge(userID, c.Param("date"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if challenge == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Daily challenge not found"})
+		return
+	}
+	c.JSON(http.StatusOK, challenge)
+}
+
+func (h *DailyChallengeHandler) GetLeaderboard(c *gin.Context) {
+	userID, ok := currentUserID(c, h.userService, h.logger)
+	if !ok {
+		return
+	}
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if page < 1 {
+		page = 1
+	}
+	perPage, _ := strconv.Atoi(c.DefaultQuery("per_page", "50"))
+	if perPage <= 0 || perPage > 100 {
+		perPage = 50
+	}
+	entries, total, rank, err := h.dailyService.GetLeaderboard(userID, c.Param("date"), perPage, (page-1)*perPage)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"leaderboard": entries,
+		"my_rank":     rank,
+		"pagination":  models.Pagination{Total: total, Page: page, PerPage: perPage},
+	})
+}
+
+func (h *DailyChallengeHandler) GetStreak(c *gin.Context) {
+	userID, ok := currentUserID(c, h.userService, h.logger)
+	if !ok {
+		return
+	}
+	streak, err := h.dailyService.GetStreak(userID)
+	if err != nil {
+		h.logger.Error("Failed to get daily challenge streak", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch streak"})
+		return
+	}
+	c.JSON(http.StatusOK, streak)
+}
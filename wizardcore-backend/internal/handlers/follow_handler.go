@@ -0,0 +1,190 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/yourusername/wizardcore-backend/internal/models"
+	"github.com/yourusername/wizardcore-backend/internal/repositories"
+	"github.com/yourusername/wizardcore-backend/internal/services"
+	"go.uber.org/zap"
+)
+
+type FollowHandler struct {
+	followService *services.FollowService
+	userService   *services.UserService
+	logger        *zap.Logger
+}
+
+func NewFollowHandler(followService *services.FollowService, userService *services.UserService, logger *zap.Logger) *FollowHandler {
+	return &FollowHandler{
+		followService: followService,
+		userService:   userService,
+		logger:        logger,
+	}
+}
+
+// targetUserID parses the :id path parameter, where "me" is the caller
+func targetUserID(c *gin.Context, viewerID uuid.UUID) (uuid.UUID, bool) {
+	if c.Param("id") == "me" {
+		return viewerID, true
+	}
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return uuid.Nil, false
+	}
+	return userID, true
+}
+
+func (h *FollowHandler) Follow(c *gin.Context) {
+	viewerID, ok := currentUserID(c, h.userService, h.logger)
+	if !ok {
+		return
+	}
+	userID, ok := targetUserID(c, viewerID)
+	if !ok {
+		return
+	}
+	status, err := h.followService.Follow(viewerID, userID)
+	if err != nil {
+		h.logger.Warn("Failed to follow user", zap.Error(err), zap.String("user_id", viewerID.String()))
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if status == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+	c.JSON(http.StatusOK, status)
+}
+
+func (h *FollowHandler) Unfollow(c *gin.Context) {
+	viewerID, ok := currentUserID(c, h.userService, h.logger)
+	if !ok {
+		return
+	}
+	userID, ok := targetUserID(c, viewerID)
+	if !ok {
+		return
+	}
+	if err := h.followService.Unfollow(viewerID, userID); err != nil {
+		h.logger.Error("Failed to unfollow user", zap.Error(err), zap.String("user_id", viewerID.String()))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unfollow user"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Unfollowed"})
+}
+
+func (h *FollowHandler) GetStatus(c *gin.Context) {
+	viewerID, ok := currentUserID(c, h.userService, h.logger)
+	if !ok {
+		return
+	}
+	userID, ok := targetUserID(c, viewerID)
+	if !ok {
+		return
+	}
+	status, err := h.followService.GetStatus(viewerID, userID)
+	if err != nil {
+		h.logger.Error("Failed to get follow status", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch follow status"})
+		return
+	}
+	if status == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+	c.JSON(http.StatusOK, status)
+}
+
+func (h *FollowHandler) GetFollowers(c *gin.Context) {
+	h.listFollows(c, h.followService.GetFollowers)
+}
+
+func (h *FollowHandler) GetFollowing(c *gin.Context) {
+	h.listFollows(c, h.followService.GetFollowing)
+}
+
+func (h *FollowHandler) listFollows(c *gin.Context, list func(viewerID, userID uuid.UUID, limit, offset int) ([]models.FollowUser, int, error)) {
+	viewerID, ok := currentUserID(c, h.userService, h.logger)
+	if !ok {
+		return
+	}
+	userID, ok := targetUserID(c, viewerID)
+	if !ok {
+		return
+	}
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if page < 1 {
+		page = 1
+	}
+	perPage, _ := strconv.Atoi(c.DefaultQuery("per_page", "20"))
+	if perPage <= 0 || perPage > 100 {
+		perPage = 20
+	}
+	users, total, err := list(viewerID, userID, perPage, (page-1)*perPage)
+	if err != nil {
+		if errors.Is(err, repositories.ErrProfilePrivate) {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		h.logger.Error("Failed to list follows", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch users"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"users":      users,
+		"pagination": models.Pagination{Total: total, Page: page, PerPage: perPage},
+	})
+}
+
+func (h *FollowHandler) GetFriends(c *gin.Context) {
+	userID, ok := currentUserID(c, h.userService, h.logger)
+	if !ok {
+		return
+	}
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if page < 1 {
+		page = 1
+	}
+	perPage, _ := strconv.Atoi(c.DefaultQuery("per_page", "20"))
+	if perPage <= 0 || perPage > 100 {
+		perPage = 20
+	}
+	friends, total, err := h.followService.GetFriends(userID, perPage, (page-1)*perPage)
+	if err != nil {
+		h.logger.Error("Failed to list friends", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch friends"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"users":      friends,
+		"pagination": models.Pagination{Total: total, Page: page, PerPage: perPage},
+	})
+}
+
+func (h *FollowHandler) GetFeed(c *gin.Context) {
+	userID, ok := currentUserID(c, h.userService, h.logger)
+	if !ok {
+		return
+	}
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if offset < 0 {
+		offset = 0
+	}
+	activities, err := h.followService.GetFeed(userID, limit, offset)
+	if err != nil {
+		h.logger.Error("Failed to get activity feed", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch activity feed"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"activities": activities})
+}
@@ -5,27 +5,38 @@ import (
 	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"github.com/yourusername/wizardcore-backend/internal/services"
 	"go.uber.org/zap"
 )
 
 type LeaderboardHandler struct {
 	leaderboardService *services.LeaderboardService
+	userService        *services.UserService
 	logger             *zap.Logger
 }
 
-func NewLeaderboardHandler(leaderboardService *services.LeaderboardService, logger *zap.Logger) *LeaderboardHandler {
+func NewLeaderboardHandler(leaderboardService *services.LeaderboardService, userService *services.UserService, logger *zap.Logger) *LeaderboardHandler {
 	return &LeaderboardHandler{
 		leaderboardService: leaderboardService,
+		userService:        userService,
 		logger:             logger,
 	}
 }
 
 func (h *LeaderboardHandler) GetLeaderboard(c *gin.Context) {
+	userID, ok := currentUserID(c, h.userService, h.logger)
+	if !ok {
+		return
+	}
+	// "global" or "friends"
+	scope := c.DefaultQuery("scope", "global")
 	timeframe := c.DefaultQuery("timeframe", "all")
 	pathwayID := c.Query("pathway")
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	perPage, _ := strconv.Atoi(c.DefaultQuery("per_page", "10"))
+	// Entries above and below the caller, instead of a page
+	around, _ := strconv.Atoi(c.DefaultQuery("around", "0"))
 
 	if page < 1 {
 		page = 1
@@ -33,8 +44,14 @@ func (h *LeaderboardHandler) GetLeaderboard(c *gin.Context) {
 	if perPage < 1 || perPage > 100 {
 		perPage = 10
 	}
+	if around < 0 {
+		around = 0
+	}
+	if around > 50 {
+		around = 50
+	}
 
-	leaderboard, err := h.leaderboardService.GetLeaderboard(timeframe, &pathwayID, page, perPage)
+	leaderboard, err := h.leaderboardService.GetLeaderboard(userID, scope, timeframe, &pathwayID, page, perPage, around)
 	if err != nil {
 		h.logger.Error("Failed to get leaderboard", zap.Error(err))
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch leaderboard"})
@@ -42,4 +59,54 @@ func (h *LeaderboardHandler) GetLeaderboard(c *gin.Context) {
 	}
 
 	c.JSON(http.StatusOK, leaderboard)
+}
+
+func (h *LeaderboardHandler) GetRankHistory(c *gin.Context) {
+	userID, ok := currentUserID(c, h.userService, h.logger)
+	if !ok {
+		return
+	}
+	timeframe := c.DefaultQuery("timeframe", "all")
+	pathwayID := c.Query("pathway")
+	if pathwayID != "" {
+		if _, err := uuid.Parse(pathwayID); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid pathway ID"})
+			return
+		}
+	}
+	days, _ := strconv.Atoi(c.DefaultQuery("days", "30"))
+	if days < 1 || days > 365 {
+		days = 30
+	}
+
+	history, err := h.leaderboardService.GetRankHistory(userID, timeframe, &pathwayID, days)
+	if err != nil {
+		h.logger.Error("Failed to get rank history", zap.Error(err), zap.String("user_id", userID.String()))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch rank history"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"history": history})
+}
+
+func (h *LeaderboardHandler) GetRank(c *gin.Context) {
+	userID, ok := currentUserID(c, h.userService, h.logger)
+	if !ok {
+		return
+	}
+	timeframe := c.DefaultQuery("timeframe", "all")
+	pathwayID := c.Query("pathway")
+	if pathwayID != "" {
+		if _, err := uuid.Parse(pathwayID); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid pathway ID"})
+			return
+		}
+	}
+
+	rank, err := h.leaderboardService.GetRank(userID, timeframe, &pathwayID)
+	if err != nil {
+		h.logger.Error("Failed to get leaderboard rank", zap.Error(err), zap.String("user_id", userID.String()))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch rank"})
+		return
+	}
+	c.JSON(http.StatusOK, rank)
 }
\ No newline at end of file
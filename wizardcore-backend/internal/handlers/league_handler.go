@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yourusername/wizardcore-backend/internal/models"
+	"github.com/yourusername/wizardcore-backend/internal/services"
+	"go.uber.org/zap"
+)
+
+type LeagueHandler struct {
+	leagueService *services.LeagueService
+	userService   *services.UserService
+	logger        *zap.Logger
+}
+
+func NewLeagueHandler(leagueService *services.LeagueService, userService *services.UserService, logger *zap.Logger) *LeagueHandler {
+	return &LeagueHandler{
+		leagueService: leagueService,
+		userService:   userService,
+		logger:        logger,
+	}
+}
+
+func (h *LeagueHandler) GetCurrent(c *gin.Context) {
+	userID, ok := currentUserID(c, h.userService, h.logger)
+	if !ok {
+		return
+	}
+	status, err := h.leagueService.GetCurrent(userID)
+	if err != nil {
+		h.logger.Error("Failed to get league", zap.Error(err), zap.String("user_id", userID.String()))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch league"})
+		return
+	}
+	c.JSON(http.StatusOK, status)
+}
+
+func (h *LeagueHandler) GetHistory(c *gin.Context) {
+	userID, ok := currentUserID(c, h.userService, h.logger)
+	if !ok {
+		return
+	}
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if page < 1 {
+		page = 1
+	}
+	perPage, _ := strconv.Atoi(c.DefaultQuery("per_page", "20"))
+	if perPage <= 0 || perPage > 100 {
+		perPage = 20
+	}
+	results, total, err := h.leagueService.GetHistory(userID, perPage, (page-1)*perPage)
+	if err != nil {
+		h.logger.Error("Failed to get league history", zap.Error(err), zap.String("user_id", userID.String()))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch league history"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"seasons":    results,
+		"pagination": models.Pagination{Total: total, Page: page, PerPage: perPage},
+	})
+}
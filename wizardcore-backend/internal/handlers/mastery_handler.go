@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/yourusername/wizardcore-backend/internal/models"
+	"github.com/yourusername/wizardcore-backend/internal/services"
+	"go.uber.org/zap"
+)
+
+type MasteryHandler struct {
+	masteryService *services.MasteryService
+	userService    *services.UserService
+	logger         *zap.Logger
+}
+
+func NewMasteryHandler(masteryService *services.MasteryService, userService *services.UserService, logger *zap.Logger) *MasteryHandler {
+	return &MasteryHandler{
+		masteryService: masteryService,
+		userService:    userService,
+		logger:         logger,
+	}
+}
+
+// GetSkills returns the current user's mastery of each skill they have
+// practiced
+func (h *MasteryHandler) GetSkills(c *gin.Context) {
+	userID, ok := currentUserID(c, h.userService, h.logger)
+	if !ok {
+		return
+	}
+	radar, err := h.masteryService.GetSkills(userID)
+	if err != nil {
+		h.logger.Error("Failed to get skills", zap.Error(err), zap.String("user_id", userID.String()))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch skills"})
+		return
+	}
+	c.JSON(http.StatusOK, radar)
+}
+
+// GetPathwayHeatmap returns the mastery of the learners enrolled in one of
+// the current creator's pathways
+func (h *MasteryHandler) GetPathwayHeatmap(c *gin.Context) {
+	userID, ok := currentUserID(c, h.userService, h.logger)
+	if !ok {
+		return
+	}
+	pathwayID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid pathway ID"})
+		return
+	}
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if page < 1 {
+		page = 1
+	}
+	perPage, _ := strconv.Atoi(c.DefaultQuery("per_page", "50"))
+	if perPage <= 0 || perPage > 200 {
+		perPage = 50
+	}
+	heatmap, total, err := h.masteryService.GetPathwayHeatmap(pathwayID, userID, perPage, (page-1)*perPage)
+	if err != nil {
+		h.logger.Error("Failed to get mastery heatmap", zap.Error(err), zap.String("pathway_id", pathwayID.String()))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch mastery heatmap"})
+		return
+	}
+	if heatmap == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Pathway not found"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"heatmap":    heatmap,
+		"pagination": models.Pagination{Total: total, Page: page, PerPage: perPage},
+	})
+}
@@ -0,0 +1,162 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/yourusername/wizardcore-backend/internal/models"
+	"github.com/yourusername/wizardcore-backend/internal/repositories"
+	"github.com/yourusername/wizardcore-backend/internal/services"
+	"go.uber.org/zap"
+)
+
+type MatchInviteHandler struct {
+	inviteService *services.MatchInviteService
+	userService   *services.UserService
+	logger        *zap.Logger
+}
+
+func NewMatchInviteHandler(inviteService *services.MatchInviteService, userService *services.UserService, logger *zap.Logger) *MatchInviteHandler {
+	return &MatchInviteHandler{
+		inviteService: inviteService,
+		userService:   userService,
+		logger:        logger,
+	}
+}
+
+func (h *MatchInviteHandler) CreateInvite(c *gin.Context) {
+	userID, ok := currentUserID(c, h.userService, h.logger)
+	if !ok {
+		return
+	}
+
+	var req models.CreateMatchInviteRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+			return
+		}
+	}
+
+	invite, err := h.inviteService.CreateInvite(userID, req)
+	if err != nil {
+		h.logger.Warn("Failed to create match invite", zap.Error(err), zap.String("user_id", userID.String()))
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, invite)
+}
+
+func (h *MatchInviteHandler) ListInvites(c *gin.Context) {
+	userID, ok := currentUserID(c, h.userService, h.logger)
+	if !ok {
+		return
+	}
+	invites, err := h.inviteService.ListInvites(userID)
+	if err != nil {
+		h.logger.Error("Failed to list match invites", zap.Error(err), zap.String("user_id", userID.String()))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch invites"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"invites": invites})
+}
+
+func (h *MatchInviteHandler) GetInvite(c *gin.Context) {
+	userID, ok := currentUserID(c, h.userService, h.logger)
+	if !ok {
+		return
+	}
+	inviteID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid invite ID"})
+		return
+	}
+	invite, err := h.inviteService.GetInvite(inviteID, userID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Invite not found"})
+		return
+	}
+	c.JSON(http.StatusOK, invite)
+}
+
+func (h *MatchInviteHandler) AcceptInvite(c *gin.Context) {
+	userID, ok := currentUserID(c, h.userService, h.logger)
+	if !ok {
+		return
+	}
+	inviteID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid invite ID"})
+		return
+	}
+	invite, err := h.inviteService.AcceptInvite(inviteID, userID)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, invite)
+}
+
+func (h *MatchInviteHandler) AcceptByCode(c *gin.Context) {
+	userID, ok := currentUserID(c, h.userService, h.logger)
+	if !ok {
+		return
+	}
+	var req models.AcceptMatchInviteRequest
+	if err := c.ShouldBindJSON(&req); err != nil || req.InviteCode == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	invite, err := h.inviteService.AcceptByCode(req.InviteCode, userID)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, invite)
+}
+
+func (h *MatchInviteHandler) DeclineInvite(c *gin.Context) {
+	userID, ok := currentUserID(c, h.userService, h.logger)
+	if !ok {
+		return
+	}
+	inviteID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid invite ID"})
+		return
+	}
+	if err := h.inviteService.DeclineInvite(inviteID, userID); err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Invite declined"})
+}
+
+func (h *MatchInviteHandler) CancelInvite(c *gin.Context) {
+	userID, ok := currentUserID(c, h.userService, h.logger)
+	if !ok {
+		return
+	}
+	inviteID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid invite ID"})
+		return
+	}
+	if err := h.inviteService.CancelInvite(inviteID, userID); err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Invite cancelled"})
+}
+
+// respondError maps an invite answer failure to a status code
+func (h *MatchInviteHandler) respondError(c *gin.Context, err error) {
+	if errors.Is(err, repositories.ErrInviteUnavailable) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	h.logger.Warn("Failed to answer match invite", zap.Error(err))
+	c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+}
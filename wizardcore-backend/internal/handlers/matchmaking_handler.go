@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yourusername/wizardcore-backend/internal/models"
+	"github.com/yourusername/wizardcore-backend/internal/services"
+	"go.uber.org/zap"
+)
+
+type MatchmakingHandler struct {
+	matchmakingService *services.MatchmakingService
+	userService        *services.UserService
+	logger             *zap.Logger
+}
+
+func NewMatchmakingHandler(matchmakingService *services.MatchmakingService, userService *services.UserService, logger *zap.Logger) *MatchmakingHandler {
+	return &MatchmakingHandler{
+		matchmakingService: matchmakingService,
+		userService:        userService,
+		logger:             logger,
+	}
+}
+
+// JoinQueue queues the user for a duel. The response holds the match if an
+// opponent was found straight away; otherwise the wait is reported over the
+// WebSocket and the match arrives as a match_start event.
+func (h *MatchmakingHandler) JoinQueue(c *gin.Context) {
+	userID, ok := currentUserID(c, h.userService, h.logger)
+	if !ok {
+		return
+	}
+
+	var req models.JoinQueueRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+			return
+		}
+	}
+
+	status, err := h.matchmakingService.JoinQueue(userID, req)
+	if err != nil {
+		h.logger.Error("Failed to join matchmaking queue", zap.Error(err), zap.String("user_id", userID.String()))
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if status.Match != nil {
+		c.JSON(http.StatusOK, gin.H{"match": status.Match, "queue": status})
+		return
+	}
+	c.JSON(http.StatusAccepted, gin.H{"match": nil, "queue": status})
+}
+
+func (h *MatchmakingHandler) LeaveQueue(c *gin.Context) {
+	userID, ok := currentUserID(c, h.userService, h.logger)
+	if !ok {
+		return
+	}
+	if err := h.matchmakingService.LeaveQueue(userID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Left matchmaking queue"})
+}
+
+func (h *MatchmakingHandler) GetStatus(c *gin.Context) {
+	userID, ok := currentUserID(c, h.userService, h.logger)
+	if !ok {
+		return
+	}
+	status, err := h.matchmakingService.GetStatus(userID)
+	if err != nil {
+		h.logger.Error("Failed to get matchmaking status", zap.Error(err), zap.String("user_id", userID.String()))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch matchmaking status"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"queue": status})
+}
@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/yourusername/wizardcore-backend/internal/models"
+	"github.com/yourusername/wizardcore-backend/internal/services"
+	"go.uber.org/zap"
+)
+
+type PairHandler struct {
+	pairService *services.PairService
+	userService *services.UserService
+	logger      *zap.Logger
+}
+
+func NewPairHandler(pairService *services.PairService, userService *services.UserService, logger *zap.Logger) *PairHandler {
+	return &PairHandler{
+		pairService: pairService,
+		userService: userService,
+		logger:      logger,
+	}
+}
+
+func (h *PairHandler) CreateSession(c *gin.Context) {
+	userID, ok := currentUserID(c, h.userService, h.logger)
+	if !ok {
+		return
+	}
+
+	var req models.CreatePairSessionRequest
+	if err := c.ShouldBindJSON(&req); err != nil || req.ExerciseID == uuid.Nil || req.LanguageID == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	session, err := h.pairService.CreateSession(userID, req)
+	if err != nil {
+		h.logger.Error("Failed to create pair session", zap.Error(err), zap.String("user_id", userID.String()))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create pair session"})
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"session": session})
+}
+
+func (h *PairHandler) JoinSession(c *gin.Context) {
+	userID, ok := currentUserID(c, h.userService, h.logger)
+	if !ok {
+		return
+	}
+
+	var req models.JoinPairSessionRequest
+	if err := c.ShouldBindJSON(&req); err != nil || req.InviteCode == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	session, err := h.pairService.JoinSession(userID, req.InviteCode)
+	if err != nil {
+		h.logger.Warn("Failed to join pair session", zap.Error(err), zap.String("user_id", userID.String()))
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"session": session})
+}
+
+func (h *PairHandler) GetSession(c *gin.Context) {
+	userID, ok := currentUserID(c, h.userService, h.logger)
+	if !ok {
+		return
+	}
+	sessionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid session ID"})
+		return
+	}
+
+	session, err := h.pairService.GetSession(sessionID, userID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Pair session not found"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"session": session})
+}
+
+func (h *PairHandler) GetOperations(c *gin.Context) {
+	userID, ok := currentUserID(c, h.userService, h.logger)
+	if !ok {
+		return
+	}
+	sessionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid session ID"})
+		return
+	}
+	since, _ := strconv.Atoi(c.DefaultQuery("since", "0"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "200"))
+	if limit <= 0 || limit > 500 {
+		limit = 500
+	}
+
+	ops, err := h.pairService.GetOperations(sessionID, userID, since, limit)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Pair session not found"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"operations": ops})
+}
+
+func (h *PairHandler) Submit(c *gin.Context) {
+	userID, ok := currentUserID(c, h.userService, h.logger)
+	if !ok {
+		return
+	}
+	sessionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid session ID"})
+		return
+	}
+
+	submissions, err := h.pairService.SubmitSession(sessionID, userID)
+	if err != nil {
+		h.logger.Error("Failed to submit pair session", zap.Error(err), zap.String("session_id", sessionID.String()))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to submit pair session"})
+		return
+	}
+	c.JSON(http.StatusCreated, models.PairSessionSubmitResponse{
+		Submission:  &submissions[0],
+		Submissions: submissions,
+	})
+}
@@ -5,19 +5,23 @@ import (
 	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"github.com/yourusername/wizardcore-backend/internal/middleware"
+	"github.com/yourusername/wizardcore-backend/internal/models"
 	"github.com/yourusername/wizardcore-backend/internal/services"
 	"go.uber.org/zap"
 )
 
 type PracticeHandler struct {
 	practiceService *services.PracticeService
+	userService     *services.UserService
 	logger          *zap.Logger
 }
 
-func NewPracticeHandler(practiceService *services.PracticeService, logger *zap.Logger) *PracticeHandler {
+func NewPracticeHandler(practiceService *services.PracticeService, userService *services.UserService, logger *zap.Logger) *PracticeHandler {
 	return &PracticeHandler{
 		practiceService: practiceService,
+		userService:     userService,
 		logger:          logger,
 	}
 }
@@ -33,9 +37,8 @@ func (h *PracticeHandler) GetChallenges(c *gin.Context) {
 }
 
 func (h *PracticeHandler) GetAreas(c *gin.Context) {
-	userID, ok := middleware.GetSupabaseUserID(c)
+	userID, ok := currentUserID(c, h.userService, h.logger)
 	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
 		return
 	}
 	areas, err := h.practiceService.GetAreas(userID)
@@ -95,4 +98,196 @@ func (h *PracticeHandler) StartChallenge(c *gin.Context) {
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"match": match})
-}
\ No newline at end of file
+}
+
+func (h *PracticeHandler) GetRatingHistory(c *gin.Context) {
+	userID, ok := middleware.GetSupabaseUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if limit <= 0 || limit > 200 {
+		limit = 200
+	}
+	history, err := h.practiceService.GetRatingHistory(userID, limit)
+	if err != nil {
+		h.logger.Error("Failed to get rating history", zap.Error(err), zap.String("user_id", userID.String()))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch rating history"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"history": history})
+}
+
+func (h *PracticeHandler) GetRatedLeaderboard(c *gin.Context) {
+	userID, ok := middleware.GetSupabaseUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if page < 1 {
+		page = 1
+	}
+	perPage, _ := strconv.Atoi(c.DefaultQuery("per_page", "50"))
+	if perPage <= 0 || perPage > 100 {
+		perPage = 50
+	}
+	entries, total, err := h.practiceService.GetRatedLeaderboard(userID, perPage, (page-1)*perPage)
+	if err != nil {
+		h.logger.Error("Failed to get rated leaderboard", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch rated leaderboard"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"leaderboard": entries,
+		"pagination":  models.Pagination{Total: total, Page: page, PerPage: perPage},
+	})
+}
+
+func (h *PracticeHandler) GetRun(c *gin.Context) {
+	userID, ok := middleware.GetSupabaseUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+	matchID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid match ID"})
+		return
+	}
+	run, err := h.practiceService.GetRun(matchID, userID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Run not found"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"run": run})
+}
+
+func (h *PracticeHandler) SkipExercise(c *gin.Context) {
+	userID, ok := middleware.GetSupabaseUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+	matchID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid match ID"})
+		return
+	}
+	run, err := h.practiceService.SkipExercise(matchID, userID)
+	if err != nil {
+		h.logger.Warn("Failed to skip exercise", zap.Error(err), zap.String("match_id", matchID.String()))
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"run": run})
+}
+
+func (h *PracticeHandler) FinishRun(c *gin.Context) {
+	userID, ok := middleware.GetSupabaseUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+	matchID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid match ID"})
+		return
+	}
+	run, err := h.practiceService.FinishRun(matchID, userID)
+	if err != nil {
+		h.logger.Warn("Failed to finish run", zap.Error(err), zap.String("match_id", matchID.String()))
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"run": run})
+}
+
+func (h *PracticeHandler) GetPersonalBests(c *gin.Context) {
+	userID, ok := middleware.GetSupabaseUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+	bests, err := h.practiceService.GetPersonalBests(userID)
+	if err != nil {
+		h.logger.Error("Failed to get personal bests", zap.Error(err), zap.String("user_id", userID.String()))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch personal bests"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"personal_bests": bests})
+}
+
+func (h *PracticeHandler) ListChallengeTypes(c *gin.Context) {
+	types, err := h.practiceService.ListChallengeTypes()
+	if err != nil {
+		h.logger.Error("Failed to list challenge types", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch challenge types"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"challenge_types": types})
+}
+
+func (h *PracticeHandler) UpdateChallengeType(c *gin.Context) {
+	var req models.UpdateChallengeTypeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	ct, err := h.practiceService.UpdateChallengeType(c.Param("id"), &req)
+	if err != nil {
+		h.logger.Warn("Failed to update challenge type", zap.Error(err), zap.String("challenge_type", c.Param("id")))
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if ct == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Challenge type not found"})
+		return
+	}
+	c.JSON(http.StatusOK, ct)
+}
+
+func (h *PracticeHandler) ListAreaSettings(c *gin.Context) {
+	settings, err := h.practiceService.ListAreaSettings()
+	if err != nil {
+		h.logger.Error("Failed to list practice area settings", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch practice area settings"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"areas": settings})
+}
+
+func (h *PracticeHandler) SaveAreaSetting(c *gin.Context) {
+	var req models.UpsertPracticeAreaSettingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	setting, err := h.practiceService.SaveAreaSetting(&req)
+	if err != nil {
+		h.logger.Warn("Failed to save practice area setting", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, setting)
+}
+
+func (h *PracticeHandler) DeleteAreaSetting(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid area setting ID"})
+		return
+	}
+	deleted, err := h.practiceService.DeleteAreaSetting(id)
+	if err != nil {
+		h.logger.Error("Failed to delete practice area setting", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete practice area setting"})
+		return
+	}
+	if !deleted {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Area setting not found"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Area setting deleted"})
+}
@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yourusername/wizardcore-backend/internal/services"
+	"go.uber.org/zap"
+)
+
+type RecommendationHandler struct {
+	recommendationService *services.RecommendationService
+	userService           *services.UserService
+	logger                *zap.Logger
+}
+
+func NewRecommendationHandler(recommendationService *services.RecommendationService, userService *services.UserService, logger *zap.Logger) *RecommendationHandler {
+	return &RecommendationHandler{
+		recommendationService: recommendationService,
+		userService:           userService,
+		logger:                logger,
+	}
+}
+
+// GetRecommendations returns the exercises the current user should do next
+func (h *RecommendationHandler) GetRecommendations(c *gin.Context) {
+	userID, ok := currentUserID(c, h.userService, h.logger)
+	if !ok {
+		return
+	}
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if limit <= 0 || limit > 50 {
+		limit = 10
+	}
+	recommendations, err := h.recommendationService.Recommend(userID, limit)
+	if err != nil {
+		h.logger.Error("Failed to get recommendations", zap.Error(err), zap.String("user_id", userID.String()))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch recommendations"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"recommendations": recommendations})
+}
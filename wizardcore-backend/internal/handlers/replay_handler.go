@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/yourusername/wizardcore-backend/internal/models"
+	"github.com/yourusername/wizardcore-backend/internal/services"
+	"go.uber.org/zap"
+)
+
+type ReplayHandler struct {
+	replayService *services.ReplayService
+	userService   *services.UserService
+	logger        *zap.Logger
+}
+
+func NewReplayHandler(replayService *services.ReplayService, userService *services.UserService, logger *zap.Logger) *ReplayHandler {
+	return &ReplayHandler{
+		replayService: replayService,
+		userService:   userService,
+		logger:        logger,
+	}
+}
+
+func (h *ReplayHandler) GetReplay(c *gin.Context) {
+	userID, ok := currentUserID(c, h.userService, h.logger)
+	if !ok {
+		return
+	}
+	matchID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid match ID"})
+		return
+	}
+
+	replay, err := h.replayService.GetReplay(matchID, userID)
+	if err != nil {
+		h.logger.Warn("Failed to get match replay", zap.Error(err), zap.String("match_id", matchID.String()))
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if replay == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Replay not found"})
+		return
+	}
+	c.JSON(http.StatusOK, replay)
+}
+
+func (h *ReplayHandler) UpdatePrivacy(c *gin.Context) {
+	userID, ok := currentUserID(c, h.userService, h.logger)
+	if !ok {
+		return
+	}
+	matchID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid match ID"})
+		return
+	}
+	var req models.UpdateReplayPrivacyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	if err := h.replayService.SetPrivate(matchID, userID, req.IsPrivate); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"match_id": matchID, "is_private": req.IsPrivate})
+}
@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/yourusername/wizardcore-backend/internal/models"
+	"github.com/yourusername/wizardcore-backend/internal/repositories"
+	"github.com/yourusername/wizardcore-backend/internal/services"
+	"go.uber.org/zap"
+)
+
+type ReviewHandler struct {
+	reviewService *services.ReviewService
+	userService   *services.UserService
+	logger        *zap.Logger
+}
+
+func NewReviewHandler(reviewService *services.ReviewService, userService *services.UserService, logger *zap.Logger) *ReviewHandler {
+	return &ReviewHandler{
+		reviewService: reviewService,
+		userService:   userService,
+		logger:        logger,
+	}
+}
+
+// GetQueue returns the reviews the current user has due today
+func (h *ReviewHandler) GetQueue(c *gin.Context) {
+	userID, ok := currentUserID(c, h.userService, h.logger)
+	if !ok {
+		return
+	}
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if page < 1 {
+		page = 1
+	}
+	perPage, _ := strconv.Atoi(c.DefaultQuery("per_page", "20"))
+	if perPage <= 0 || perPage > 100 {
+		perPage = 20
+	}
+	queue, err := h.reviewService.GetQueue(userID, perPage, (page-1)*perPage)
+	if err != nil {
+		h.logger.Error("Failed to get review queue", zap.Error(err), zap.String("user_id", userID.String()))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch review queue"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"date":       queue.Date,
+		"items":      queue.Items,
+		"total_due":  queue.TotalDue,
+		"pagination": models.Pagination{Total: queue.TotalDue, Page: page, PerPage: perPage},
+	})
+}
+
+// CompleteReview completes a due review with a re-attempt of the exercise
+func (h *ReviewHandler) CompleteReview(c *gin.Context) {
+	userID, ok := currentUserID(c, h.userService, h.logger)
+	if !ok {
+		return
+	}
+	exerciseID, err := uuid.Parse(c.Param("exercise_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid exercise ID"})
+		return
+	}
+	var req models.CompleteReviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	result, err := h.reviewService.CompleteReview(userID, exerciseID, &req)
+	if errors.Is(err, repositories.ErrReviewNotDue) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if errors.Is(err, repositories.ErrReviewSubmissionInvalid) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		h.logger.Error("Failed to complete review", zap.Error(err), zap.String("user_id", userID.String()))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to complete review"})
+		return
+	}
+	if result == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Review not found"})
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
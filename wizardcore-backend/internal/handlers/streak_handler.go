@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yourusername/wizardcore-backend/internal/services"
+	"go.uber.org/zap"
+)
+
+type StreakHandler struct {
+	streakService *services.StreakService
+	userService   *services.UserService
+	logger        *zap.Logger
+}
+
+func NewStreakHandler(streakService *services.StreakService, userService *services.UserService, logger *zap.Logger) *StreakHandler {
+	return &StreakHandler{
+		streakService: streakService,
+		userService:   userService,
+		logger:        logger,
+	}
+}
+
+func (h *StreakHandler) GetStreak(c *gin.Context) {
+	userID, ok := currentUserID(c, h.userService, h.logger)
+	if !ok {
+		return
+	}
+	status, err := h.streakService.GetStatus(userID)
+	if err != nil {
+		h.logger.Error("Failed to get streak", zap.Error(err), zap.String("user_id", userID.String()))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch streak"})
+		return
+	}
+	if status == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+	c.JSON(http.StatusOK, status)
+}
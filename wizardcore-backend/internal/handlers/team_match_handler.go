@@ -0,0 +1,182 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/yourusername/wizardcore-backend/internal/models"
+	"github.com/yourusername/wizardcore-backend/internal/repositories"
+	"github.com/yourusername/wizardcore-backend/internal/services"
+	"go.uber.org/zap"
+)
+
+type TeamMatchHandler struct {
+	teamMatchService *services.TeamMatchService
+	userService      *services.UserService
+	logger           *zap.Logger
+}
+
+func NewTeamMatchHandler(teamMatchService *services.TeamMatchService, userService *services.UserService, logger *zap.Logger) *TeamMatchHandler {
+	return &TeamMatchHandler{
+		teamMatchService: teamMatchService,
+		userService:      userService,
+		logger:           logger,
+	}
+}
+
+func (h *TeamMatchHandler) CreateParty(c *gin.Context) {
+	userID, ok := currentUserID(c, h.userService, h.logger)
+	if !ok {
+		return
+	}
+
+	var req models.CreatePartyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	party, err := h.teamMatchService.CreateParty(userID, req)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, party)
+}
+
+func (h *TeamMatchHandler) GetCurrentParty(c *gin.Context) {
+	userID, ok := currentUserID(c, h.userService, h.logger)
+	if !ok {
+		return
+	}
+	party, err := h.teamMatchService.GetCurrentParty(userID)
+	if err != nil {
+		h.logger.Error("Failed to get current party", zap.Error(err), zap.String("user_id", userID.String()))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch party"})
+		return
+	}
+	if party == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Not in a party"})
+		return
+	}
+	c.JSON(http.StatusOK, party)
+}
+
+func (h *TeamMatchHandler) GetParty(c *gin.Context) {
+	userID, ok := currentUserID(c, h.userService, h.logger)
+	if !ok {
+		return
+	}
+	partyID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid party ID"})
+		return
+	}
+	party, err := h.teamMatchService.GetParty(partyID, userID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Party not found"})
+		return
+	}
+	c.JSON(http.StatusOK, party)
+}
+
+func (h *TeamMatchHandler) JoinParty(c *gin.Context) {
+	userID, ok := currentUserID(c, h.userService, h.logger)
+	if !ok {
+		return
+	}
+	var req models.JoinPartyRequest
+	if err := c.ShouldBindJSON(&req); err != nil || req.InviteCode == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	party, err := h.teamMatchService.JoinParty(userID, req.InviteCode)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, party)
+}
+
+func (h *TeamMatchHandler) LeaveParty(c *gin.Context) {
+	userID, ok := currentUserID(c, h.userService, h.logger)
+	if !ok {
+		return
+	}
+	partyID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid party ID"})
+		return
+	}
+	if err := h.teamMatchService.LeaveParty(partyID, userID); err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Left party"})
+}
+
+func (h *TeamMatchHandler) Queue(c *gin.Context) {
+	userID, ok := currentUserID(c, h.userService, h.logger)
+	if !ok {
+		return
+	}
+	partyID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid party ID"})
+		return
+	}
+	party, err := h.teamMatchService.Queue(partyID, userID)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, party)
+}
+
+func (h *TeamMatchHandler) Dequeue(c *gin.Context) {
+	userID, ok := currentUserID(c, h.userService, h.logger)
+	if !ok {
+		return
+	}
+	partyID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid party ID"})
+		return
+	}
+	party, err := h.teamMatchService.Dequeue(partyID, userID)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, party)
+}
+
+func (h *TeamMatchHandler) GetTeamMatch(c *gin.Context) {
+	userID, ok := currentUserID(c, h.userService, h.logger)
+	if !ok {
+		return
+	}
+	matchID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid match ID"})
+		return
+	}
+	tm, err := h.teamMatchService.GetTeamMatch(matchID, userID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Team match not found"})
+		return
+	}
+	c.JSON(http.StatusOK, tm)
+}
+
+// respondError maps a party failure to a status code
+func (h *TeamMatchHandler) respondError(c *gin.Context, err error) {
+	if errors.Is(err, repositories.ErrAlreadyInParty) || errors.Is(err, repositories.ErrPartyUnavailable) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	h.logger.Warn("Failed to update party", zap.Error(err))
+	c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+}
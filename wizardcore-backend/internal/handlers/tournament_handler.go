@@ -0,0 +1,189 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/yourusername/wizardcore-backend/internal/models"
+	"github.com/yourusername/wizardcore-backend/internal/services"
+	"go.uber.org/zap"
+)
+
+type TournamentHandler struct {
+	tournamentService *services.TournamentService
+	userService       *services.UserService
+	logger            *zap.Logger
+}
+
+func NewTournamentHandler(tournamentService *services.TournamentService, userService *services.UserService, logger *zap.Logger) *TournamentHandler {
+	return &TournamentHandler{
+		tournamentService: tournamentService,
+		userService:       userService,
+		logger:            logger,
+	}
+}
+
+func (h *TournamentHandler) ListTournaments(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if page < 1 {
+		page = 1
+	}
+	perPage, _ := strconv.Atoi(c.DefaultQuery("per_page", "20"))
+	if perPage <= 0 || perPage > 100 {
+		perPage = 20
+	}
+	var status *string
+	if s := c.Query("status"); s != "" {
+		status = &s
+	}
+
+	tournaments, total, err := h.tournamentService.ListTournaments(status, perPage, (page-1)*perPage)
+	if err != nil {
+		h.logger.Error("Failed to list tournaments", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch tournaments"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"tournaments": tournaments,
+		"pagination":  models.Pagination{Total: total, Page: page, PerPage: perPage},
+	})
+}
+
+func (h *TournamentHandler) GetTournament(c *gin.Context) {
+	userID, ok := currentUserID(c, h.userService, h.logger)
+	if !ok {
+		return
+	}
+	tournamentID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid tournament ID"})
+		return
+	}
+	tournament, err := h.tournamentService.GetTournament(tournamentID, userID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Tournament not found"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"tournament": tournament})
+}
+
+func (h *TournamentHandler) GetBracket(c *gin.Context) {
+	tournamentID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid tournament ID"})
+		return
+	}
+	bracket, err := h.tournamentService.GetBracket(tournamentID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Tournament not found"})
+		return
+	}
+	c.JSON(http.StatusOK, bracket)
+}
+
+func (h *TournamentHandler) Register(c *gin.Context) {
+	userID, ok := currentUserID(c, h.userService, h.logger)
+	if !ok {
+		return
+	}
+	tournamentID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid tournament ID"})
+		return
+	}
+	if err := h.tournamentService.Register(tournamentID, userID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Registered for tournament"})
+}
+
+func (h *TournamentHandler) Unregister(c *gin.Context) {
+	userID, ok := currentUserID(c, h.userService, h.logger)
+	if !ok {
+		return
+	}
+	tournamentID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid tournament ID"})
+		return
+	}
+	if err := h.tournamentService.Unregister(tournamentID, userID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Unregistered from tournament"})
+}
+
+func (h *TournamentHandler) CreateTournament(c *gin.Context) {
+	userID, ok := currentUserID(c, h.userService, h.logger)
+	if !ok {
+		return
+	}
+	var req models.CreateTournamentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	tournament, err := h.tournamentService.CreateTournament(userID, req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"tournament": tournament})
+}
+
+func (h *TournamentHandler) StartTournament(c *gin.Context) {
+	tournamentID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid tournament ID"})
+		return
+	}
+	tournament, err := h.tournamentService.StartTournament(tournamentID)
+	if err != nil {
+		h.logger.Warn("Failed to start tournament", zap.Error(err), zap.String("tournament_id", tournamentID.String()))
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"tournament": tournament})
+}
+
+func (h *TournamentHandler) CancelTournament(c *gin.Context) {
+	tournamentID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid tournament ID"})
+		return
+	}
+	if err := h.tournamentService.CancelTournament(tournamentID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Tournament cancelled"})
+}
+
+func (h *TournamentHandler) OverrideResult(c *gin.Context) {
+	tournamentID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid tournament ID"})
+		return
+	}
+	nodeIndex, err := strconv.Atoi(c.Param("node"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid match index"})
+		return
+	}
+	var req models.OverrideTournamentResultRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	bracket, err := h.tournamentService.OverrideResult(tournamentID, nodeIndex, req.WinnerID)
+	if err != nil {
+		h.logger.Warn("Failed to override tournament result", zap.Error(err), zap.String("tournament_id", tournamentID.String()))
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, bracket)
+}
@@ -169,6 +169,14 @@ func (h *UserHandler) UpdatePreferences(c *gin.Context) {
 	if req.SoundEffects != nil {
 		updates["sound_effects"] = *req.SoundEffects
 	}
+	if req.Timezone != nil {
+		// Only IANA names; "" and "Local" would follow the server's zone
+		if _, err := time.LoadLocation(*req.Timezone); err != nil || *req.Timezone == "" || *req.Timezone == "Local" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid timezone"})
+			return
+		}
+		updates["timezone"] = *req.Timezone
+	}
 
 	err = h.userService.UpdateUserPreferences(c.Request.Context(), user.ID, updates)
 	if err != nil {
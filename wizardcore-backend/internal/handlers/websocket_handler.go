@@ -8,7 +8,9 @@ import (
 	gorillawebsocket "github.com/gorilla/websocket"
 	"github.com/google/uuid"
 	"github.com/yourusername/wizardcore-backend/internal/middleware"
+	"github.com/yourusername/wizardcore-backend/internal/services"
 	internalws "github.com/yourusername/wizardcore-backend/internal/websocket"
+	"go.uber.org/zap"
 )
 
 var upgrader = gorillawebsocket.Upgrader{
@@ -21,22 +23,37 @@ var upgrader = gorillawebsocket.Upgrader{
 }
 
 type WebSocketHandler struct {
-	hub *internalws.Hub
+	hub         *internalws.Hub
+	userService *services.UserService
+	logger      *zap.Logger
 }
 
-func NewWebSocketHandler(hub *internalws.Hub) *WebSocketHandler {
-	return &WebSocketHandler{hub: hub}
+func NewWebSocketHandler(hub *internalws.Hub, userService *services.UserService, logger *zap.Logger) *WebSocketHandler {
+	return &WebSocketHandler{
+		hub:         hub,
+		userService: userService,
+		logger:      logger,
+	}
 }
 
-// ServeWebSocket handles WebSocket connections
+// ServeWebSocket handles WebSocket connections.
+// Events are addressed to the internal user ID so that services can push to
+// a user and a reconnecting client can resume its sequence.
 func (h *WebSocketHandler) ServeWebSocket(c *gin.Context) {
 	// Get user ID from authentication middleware
-	userID, ok := middleware.GetSupabaseUserID(c)
+	supabaseUserID, ok := middleware.GetSupabaseUserID(c)
 	if !ok {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
 		return
 	}
 
+	user, err := h.userService.GetUserBySupabaseUserID(supabaseUserID)
+	if err != nil {
+		h.logger.Error("Failed to fetch user", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch user"})
+		return
+	}
+
 	// Upgrade HTTP connection to WebSocket
 	conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
 	if err != nil {
@@ -44,7 +61,7 @@ func (h *WebSocketHandler) ServeWebSocket(c *gin.Context) {
 		return
 	}
 
-	client := internalws.NewClient(h.hub, conn, userID)
+	client := internalws.NewClient(h.hub, conn, user.ID)
 	h.hub.Register <- client
 
 	// Start goroutines for reading and writing
@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/yourusername/wizardcore-backend/internal/models"
+	"github.com/yourusername/wizardcore-backend/internal/repositories"
+	"github.com/yourusername/wizardcore-backend/internal/services"
+	"go.uber.org/zap"
+)
+
+type XPHandler struct {
+	xpService   *services.XPService
+	userService *services.UserService
+	logger      *zap.Logger
+}
+
+func NewXPHandler(xpService *services.XPService, userService *services.UserService, logger *zap.Logger) *XPHandler {
+	return &XPHandler{
+		xpService:   xpService,
+		userService: userService,
+		logger:      logger,
+	}
+}
+
+func (h *XPHandler) ListBackfillReviews(c *gin.Context) {
+	var resolved *bool
+	switch c.DefaultQuery("status", "pending") {
+	case "all":
+	case "pending":
+		resolved = new(bool)
+	case "resolved":
+		resolved = new(bool)
+		*resolved = true
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid status"})
+		return
+	}
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if page < 1 {
+		page = 1
+	}
+	perPage, _ := strconv.Atoi(c.DefaultQuery("per_page", "20"))
+	if perPage <= 0 || perPage > 100 {
+		perPage = 20
+	}
+	reviews, total, err := h.xpService.ListBackfillReviews(resolved, perPage, (page-1)*perPage)
+	if err != nil {
+		h.logger.Error("Failed to list XP backfill reviews", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch reviews"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"reviews":    reviews,
+		"pagination": models.Pagination{Total: total, Page: page, PerPage: perPage},
+	})
+}
+
+func (h *XPHandler) ResolveBackfillReview(c *gin.Context) {
+	resolverID, ok := currentUserID(c, h.userService, h.logger)
+	if !ok {
+		return
+	}
+	userID, err := uuid.Parse(c.Param("user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+	var req models.ResolveXPBackfillReviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	review, err := h.xpService.ResolveBackfillReview(userID, resolverID, &req)
+	if errors.Is(err, repositories.ErrBackfillReviewResolved) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if errors.Is(err, repositories.ErrOpeningBalanceTooLarge) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		h.logger.Error("Failed to resolve XP backfill review", zap.Error(err), zap.String("user_id", userID.String()))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to resolve review"})
+		return
+	}
+	if review == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Review not found"})
+		return
+	}
+	c.JSON(http.StatusOK, review)
+}
@@ -0,0 +1,138 @@
+// Package league runs weekly leagues. Players are grouped into cohorts
+// within tiered leagues, ranked by the XP they earn during a season, and
+// promoted or relegated when it closes. It works on plain standings so the
+// rules can be tested without a database.
+package league
+
+import (
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Tiers are the leagues from lowest to highest
+var Tiers = []string{"Bronze", "Silver", "Gold", "Sapphire", "Ruby", "Emerald", "Diamond"}
+
+// CohortSize is how many players share a cohort; a new cohort opens once
+// every cohort of a tier is full
+const CohortSize = 30
+
+// SeasonLength is how long a season runs
+const SeasonLength = 7 * 24 * time.Hour
+
+// Outcomes of a season, also used as the zone a player is in while it runs
+const (
+	Promoted  = "promoted"
+	Relegated = "relegated"
+	Stayed    = "stayed"
+)
+
+// Rules decide promotion, relegation and rewards
+type Rules struct {
+	// Players promoted from the top and relegated from the bottom of a
+	// full cohort
+	Promote  int
+	Relegate int
+	// Reward XP for the first places, multiplied by one more than the tier
+	Rewards []int
+}
+
+var DefaultRules = Rules{Promote: 7, Relegate: 5, Rewards: []int{100, 60, 40}}
+
+// Standing is a player's place in a cohort
+type Standing struct {
+	UserID uuid.UUID
+	XP     int
+	Rank   int
+}
+
+// Result is a player's outcome when a season closes
+type Result struct {
+	Standing
+	Outcome  string
+	NewTier  int
+	RewardXP int
+}
+
+// TierName returns the name of a tier, clamped to the known tiers
+func TierName(tier int) string {
+	return Tiers[clampTier(tier)]
+}
+
+func clampTier(tier int) int {
+	return max(0, min(tier, len(Tiers)-1))
+}
+
+// Rank orders standings by XP, highest first, and gives them competition
+// ranks: tied players share a rank and the next rank skips ahead. Ties are
+// listed by user ID so the order is stable.
+func Rank(standings []Standing) {
+	sort.Slice(standings, func(i, j int) bool {
+		if standings[i].XP != standings[j].XP {
+			return standings[i].XP > standings[j].XP
+		}
+		return standings[i].UserID.String() < standings[j].UserID.String()
+	})
+	for i := range standings {
+		if i > 0 && standings[i].XP == standings[i-1].XP {
+			standings[i].Rank = standings[i-1].Rank
+		} else {
+			standings[i].Rank = i + 1
+		}
+	}
+}
+
+// counts returns how many of n players move up and down. Small cohorts
+// move at most a third each way so they keep a middle.
+func (r Rules) counts(n int) (int, int) {
+	return min(r.Promote, n/3), min(r.Relegate, n/3)
+}
+
+// Zone returns the outcome a player of a tier ranked rank of n would get if
+// the season closed now. Players tied at a boundary share the better
+// outcome. Nobody leaves the top tier upwards or the bottom tier downwards,
+// and players without XP are never promoted.
+func (r Rules) Zone(tier, rank, n, xp int) string {
+	promote, relegate := r.counts(n)
+	switch {
+	case tier < len(Tiers)-1 && xp > 0 && rank <= promote:
+		return Promoted
+	case tier > 0 && rank > n-relegate:
+		return Relegated
+	default:
+		return Stayed
+	}
+}
+
+// Reward returns the XP a player earns for finishing a season of a tier at
+// rank
+func (r Rules) Reward(tier, rank, xp int) int {
+	if xp <= 0 || rank < 1 || rank > len(r.Rewards) {
+		return 0
+	}
+	return r.Rewards[rank-1] * (clampTier(tier) + 1)
+}
+
+// Close ranks a cohort of a tier and decides every player's outcome
+func (r Rules) Close(tier int, standings []Standing) []Result {
+	Rank(standings)
+	results := make([]Result, len(standings))
+	for i, s := range standings {
+		outcome := r.Zone(tier, s.Rank, len(standings), s.XP)
+		newTier := tier
+		switch outcome {
+		case Promoted:
+			newTier++
+		case Relegated:
+			newTier--
+		}
+		results[i] = Result{
+			Standing: s,
+			Outcome:  outcome,
+			NewTier:  clampTier(newTier),
+			RewardXP: r.Reward(tier, s.Rank, s.XP),
+		}
+	}
+	return results
+}
@@ -0,0 +1,86 @@
+package league
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func cohort(xps ...int) []Standing {
+	standings := make([]Standing, len(xps))
+	for i, xp := range xps {
+		standings[i] = Standing{UserID: uuid.New(), XP: xp}
+	}
+	return standings
+}
+
+func TestRank_TiesShareRank(t *testing.T) {
+	standings := cohort(10, 30, 20, 30)
+	Rank(standings)
+
+	want := []struct{ xp, rank int }{{30, 1}, {30, 1}, {20, 3}, {10, 4}}
+	for i, w := range want {
+		if standings[i].XP != w.xp || standings[i].Rank != w.rank {
+			t.Errorf("Position %d: expected %d XP at rank %d, got %d XP at rank %d",
+				i, w.xp, w.rank, standings[i].XP, standings[i].Rank)
+		}
+	}
+}
+
+func TestClose_PromotesAndRelegates(t *testing.T) {
+	xps := make([]int, CohortSize)
+	for i := range xps {
+		xps[i] = 1000 - i*10
+	}
+	results := DefaultRules.Close(2, cohort(xps...))
+
+	counts := map[string]int{}
+	for _, r := range results {
+		counts[r.Outcome]++
+		switch r.Outcome {
+		case Promoted:
+			if r.NewTier != 3 {
+				t.Errorf("Promoted player should move to tier 3, got %d", r.NewTier)
+			}
+		case Relegated:
+			if r.NewTier != 1 {
+				t.Errorf("Relegated player should move to tier 1, got %d", r.NewTier)
+			}
+		}
+	}
+	if counts[Promoted] != DefaultRules.Promote || counts[Relegated] != DefaultRules.Relegate {
+		t.Errorf("Expected %d promoted and %d relegated, got %v", DefaultRules.Promote, DefaultRules.Relegate, counts)
+	}
+	if results[0].RewardXP != 300 || results[3].RewardXP != 0 {
+		t.Errorf("Expected 300 XP for first in tier 2 and none for fourth, got %d and %d", results[0].RewardXP, results[3].RewardXP)
+	}
+}
+
+func TestClose_Edges(t *testing.T) {
+	// A tie on the promotion boundary promotes everyone tied
+	results := DefaultRules.Close(0, cohort(50, 40, 40, 10, 5, 1))
+	promoted := 0
+	for _, r := range results {
+		if r.Outcome == Promoted {
+			promoted++
+		}
+		if r.Outcome == Relegated {
+			t.Error("Nobody should be relegated from the bottom tier")
+		}
+	}
+	if promoted != 3 {
+		t.Errorf("Expected the tied pair on the boundary to both be promoted, got %d promoted", promoted)
+	}
+
+	top := len(Tiers) - 1
+	for _, r := range DefaultRules.Close(top, cohort(50, 40, 30)) {
+		if r.Outcome == Promoted || r.NewTier > top {
+			t.Errorf("Nobody should be promoted from the top tier, got %+v", r)
+		}
+	}
+
+	// Alone in a cohort: nothing moves
+	if r := DefaultRules.Close(3, cohort(100)); r[0].Outcome != Stayed {
+		t.Errorf("A lone player should stay, got %s", r[0].Outcome)
+	}
+}
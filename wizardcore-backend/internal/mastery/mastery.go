@@ -0,0 +1,73 @@
+// Package mastery estimates what a learner knows with Bayesian Knowledge
+// Tracing. Each skill, an exercise tag, has a probability of being mastered
+// that every graded submission on an exercise with the tag updates.
+package mastery
+
+// Params are the BKT parameters of a skill
+type Params struct {
+	// Probability the skill is mastered before any submission
+	Init float64
+	// Probability of learning the skill from one submission
+	Learn float64
+	// Probability of failing a submission despite mastering the skill
+	Slip float64
+	// Probability of passing a submission without mastering the skill
+	Guess float64
+}
+
+// Default are the parameters used for every skill
+var Default = Params{Init: 0.2, Learn: 0.15, Slip: 0.1, Guess: 0.2}
+
+// Levels of mastery, from the probability a skill is mastered
+const (
+	LevelNovice     = "novice"
+	LevelDeveloping = "developing"
+	LevelProficient = "proficient"
+	LevelMastered   = "mastered"
+)
+
+// MasteredAt is the probability from which a skill counts as mastered
+const MasteredAt = 0.95
+
+// Update returns the probability a skill is mastered after a submission
+// with an outcome, given the probability before it
+func Update(p Params, prior float64, correct bool) float64 {
+	var posterior float64
+	if correct {
+		posterior = prior * (1 - p.Slip) / (prior*(1-p.Slip) + (1-prior)*p.Guess)
+	} else {
+		posterior = prior * p.Slip / (prior*p.Slip + (1-prior)*(1-p.Guess))
+	}
+	return posterior + (1-posterior)*p.Learn
+}
+
+// Attempts follows a learner's submissions on one exercise to decide which
+// are evidence of its skills: every attempt up to and including the first
+// solve. Submitting an exercise again once it is solved shows nothing new.
+type Attempts struct {
+	// Whether the exercise has been solved
+	Solved bool
+}
+
+// Counts reports whether the next submission, with its outcome, counts as
+// evidence, and records it
+func (a *Attempts) Counts(correct bool) bool {
+	if a.Solved {
+		return false
+	}
+	a.Solved = correct
+	return true
+}
+
+// Level names how well a skill mastered with probability p is known
+func Level(p float64) string {
+	switch {
+	case p >= MasteredAt:
+		return LevelMastered
+	case p >= 0.7:
+		return LevelProficient
+	case p >= 0.4:
+		return LevelDeveloping
+	}
+	return LevelNovice
+}
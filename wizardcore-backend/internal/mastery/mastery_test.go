@@ -0,0 +1,61 @@
+package mastery
+
+import "testing"
+
+func TestUpdate_MovesWithOutcomes(t *testing.T) {
+	p := Default.Init
+	after := Update(Default, p, true)
+	if after <= p {
+		t.Errorf("Expected a correct submission to raise mastery from %.2f, got %.2f", p, after)
+	}
+	if failed := Update(Default, after, false); failed >= after {
+		t.Errorf("Expected a failed submission to lower mastery from %.2f, got %.2f", after, failed)
+	}
+
+	for i := 0; i < 5; i++ {
+		p = Update(Default, p, true)
+	}
+	if Level(p) != LevelMastered {
+		t.Errorf("Expected 5 correct submissions to master the skill, got %.3f (%s)", p, Level(p))
+	}
+}
+
+func TestUpdate_GuessesDoNotMaster(t *testing.T) {
+	// A learner who mostly fails and sometimes passes stays below proficient
+	p := Default.Init
+	for i := 0; i < 12; i++ {
+		p = Update(Default, p, i%3 == 0)
+	}
+	if p >= 0.7 {
+		t.Errorf("Expected mastery to stay below proficient, got %.3f", p)
+	}
+	if p <= 0 || p >= 1 {
+		t.Errorf("Expected a probability, got %.3f", p)
+	}
+}
+
+func TestLevel(t *testing.T) {
+	cases := map[float64]string{0.1: LevelNovice, 0.5: LevelDeveloping, 0.8: LevelProficient, 0.96: LevelMastered}
+	for p, want := range cases {
+		if got := Level(p); got != want {
+			t.Errorf("Level(%.2f) = %s, want %s", p, got, want)
+		}
+	}
+}
+
+func TestAttempts_CountUpToFirstSolve(t *testing.T) {
+	var a Attempts
+	outcomes := []bool{false, false, true, true, false, true}
+	want := []bool{true, true, true, false, false, false}
+	for i, correct := range outcomes {
+		if got := a.Counts(correct); got != want[i] {
+			t.Errorf("Submission %d (correct=%v): counts = %v, want %v", i, correct, got, want[i])
+		}
+	}
+
+	// An exercise solved earlier does not count again
+	solved := Attempts{Solved: true}
+	if solved.Counts(true) || solved.Counts(false) {
+		t.Error("Expected no submission on a solved exercise to count")
+	}
+}
@@ -65,6 +65,30 @@ func RateLimitMiddleware(rps float64, burst int) gin.HandlerFunc {
 			return
 		}
 
+		c.Next()
+	}
+}
+
+// UserRateLimitMiddleware returns a Gin middleware that rate‑limits requests
+// per authenticated user. It must run after AuthMiddleware.
+func UserRateLimitMiddleware(perMinute float64, burst int) gin.HandlerFunc {
+	limiter := NewRateLimiter(perMinute/60, burst)
+
+	return func(c *gin.Context) {
+		userID, ok := GetSupabaseUserID(c)
+		if !ok {
+			c.Next()
+			return
+		}
+
+		if !limiter.GetLimiter(userID.String()).Allow() {
+			c.JSON(http.StatusTooManyRequests, gin.H{
+				"error": "Too many submissions. Please slow down.",
+			})
+			c.Abort()
+			return
+		}
+
 		c.Next()
 	}
 }
\ No newline at end of file
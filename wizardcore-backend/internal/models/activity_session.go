@@ -0,0 +1,27 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ActivitySession is a stretch of time a user spent on an exercise,
+// attributed to the exercise's module and pathway
+type ActivitySession struct {
+	ID            uuid.UUID  `json:"id" db:"id"`
+	UserID        uuid.UUID  `json:"user_id" db:"user_id"`
+	ExerciseID    uuid.UUID  `json:"exercise_id" db:"exercise_id"`
+	ModuleID      *uuid.UUID `json:"module_id,omitempty" db:"module_id"`
+	PathwayID     *uuid.UUID `json:"pathway_id,omitempty" db:"pathway_id"`
+	StartedAt     time.Time  `json:"started_at" db:"started_at"`
+	LastSeenAt    time.Time  `json:"last_seen_at" db:"last_seen_at"`
+	ActiveSeconds int        `json:"active_seconds" db:"active_seconds"`
+	Focused       bool       `json:"focused" db:"focused"`
+}
+
+// ActivityEventRequest is a focus, heartbeat or blur event a client sends
+// while an exercise is open
+type ActivityEventRequest struct {
+	Event string `json:"event" binding:"required,oneof=focus heartbeat blur"`
+}
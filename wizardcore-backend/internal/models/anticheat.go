@@ -0,0 +1,40 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AntiCheatFlag is an implausible pattern spotted on an account
+type AntiCheatFlag struct {
+	ID            uuid.UUID  `json:"id" db:"id"`
+	UserID        uuid.UUID  `json:"user_id" db:"user_id"`
+	Reason        string     `json:"reason" db:"reason"` // fast_solve, burst, duplicate_source, xp_spike
+	SubmissionID  *uuid.UUID `json:"submission_id,omitempty" db:"submission_id"`
+	RelatedUserID *uuid.UUID `json:"related_user_id,omitempty" db:"related_user_id"`
+	Details       *string    `json:"details,omitempty" db:"details"`
+	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
+}
+
+// AntiCheatReview is a flagged account in the admin review queue
+type AntiCheatReview struct {
+	UserID                  uuid.UUID       `json:"user_id" db:"user_id"`
+	Username                string          `json:"username" db:"username"`
+	Email                   string          `json:"email" db:"email"`
+	Status                  string          `json:"status" db:"status"` // pending, cleared, confirmed
+	ExcludeFromLeaderboards bool            `json:"exclude_from_leaderboards" db:"exclude_from_leaderboards"`
+	Notes                   *string         `json:"notes,omitempty" db:"notes"`
+	FlagCount               int             `json:"flag_count" db:"flag_count"`
+	LastFlaggedAt           *time.Time      `json:"last_flagged_at,omitempty" db:"last_flagged_at"`
+	OpenedAt                time.Time       `json:"opened_at" db:"opened_at"`
+	ReviewedBy              *uuid.UUID      `json:"reviewed_by,omitempty" db:"reviewed_by"`
+	ReviewedAt              *time.Time      `json:"reviewed_at,omitempty" db:"reviewed_at"`
+	Flags                   []AntiCheatFlag `json:"flags,omitempty"`
+}
+
+type UpdateAntiCheatReviewRequest struct {
+	Status                  string  `json:"status" binding:"required,oneof=pending cleared confirmed"`
+	ExcludeFromLeaderboards *bool   `json:"exclude_from_leaderboards"`
+	Notes                   *string `json:"notes"`
+}
@@ -0,0 +1,58 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DailyChallenge is the exercise everyone is challenged with on a UTC day
+type DailyChallenge struct {
+	Date          string    `json:"date" db:"challenge_date"` // YYYY-MM-DD
+	ExerciseID    uuid.UUID `json:"exercise_id" db:"exercise_id"`
+	ExerciseTitle string    `json:"exercise_title" db:"exercise_title"`
+	Difficulty    string    `json:"difficulty" db:"difficulty"`
+	BonusXP       int       `json:"bonus_xp" db:"bonus_xp"`
+	Completions   int       `json:"completions" db:"completions"`
+	CreatedAt     time.Time `json:"created_at" db:"created_at"`
+	// Set for the requesting user
+	Attempt *DailyChallengeAttempt `json:"attempt,omitempty"`
+}
+
+// DailyChallengeAttempt is a player's attempt at a daily challenge
+type DailyChallengeAttempt struct {
+	Date         string     `json:"date" db:"challenge_date"`
+	UserID       uuid.UUID  `json:"user_id" db:"user_id"`
+	StartedAt    time.Time  `json:"started_at" db:"started_at"`
+	CompletedAt  *time.Time `json:"completed_at,omitempty" db:"completed_at"`
+	SolveSeconds *int       `json:"solve_seconds,omitempty" db:"solve_seconds"`
+	SubmissionID *uuid.UUID `json:"submission_id,omitempty" db:"submission_id"`
+	BonusXP      int        `json:"bonus_xp" db:"bonus_xp"`
+}
+
+// DailyChallengeStreak counts consecutive days with a completed daily
+// challenge. It is separate from the activity streak on User.
+type DailyChallengeStreak struct {
+	UserID            uuid.UUID  `json:"user_id" db:"user_id"`
+	CurrentStreak     int        `json:"current_streak" db:"current_streak"`
+	LongestStreak     int        `json:"longest_streak" db:"longest_streak"`
+	LastCompletedDate *time.Time `json:"last_completed_date,omitempty" db:"last_completed_date"`
+}
+
+// DailyLeaderboardEntry is a row of a daily challenge leaderboard, ranked by
+// solve time
+type DailyLeaderboardEntry struct {
+	Rank          int       `json:"rank"`
+	UserID        uuid.UUID `json:"user_id" db:"user_id"`
+	Username      string    `json:"username" db:"username"`
+	AvatarURL     *string   `json:"avatar_url,omitempty" db:"avatar_url"`
+	SolveSeconds  int       `json:"solve_seconds" db:"solve_seconds"`
+	CompletedAt   time.Time `json:"completed_at" db:"completed_at"`
+	IsCurrentUser bool      `json:"is_current_user"`
+}
+
+// DailyChallengeCompletion is the outcome of solving today's challenge
+type DailyChallengeCompletion struct {
+	Attempt DailyChallengeAttempt `json:"attempt"`
+	Streak  DailyChallengeStreak  `json:"streak"`
+}
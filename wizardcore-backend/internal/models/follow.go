@@ -0,0 +1,35 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// FollowUser is a user in a follower, following or friends list
+type FollowUser struct {
+	UserID     uuid.UUID `json:"user_id" db:"user_id"`
+	Username   string    `json:"username" db:"username"`
+	AvatarURL  *string   `json:"avatar_url,omitempty" db:"avatar_url"`
+	FollowedAt time.Time `json:"followed_at" db:"followed_at"`
+	// The listed user and the list's owner follow each other
+	IsFriend bool `json:"is_friend"`
+}
+
+// FollowStatus describes the relationship between the viewer and another
+// user
+type FollowStatus struct {
+	UserID         uuid.UUID `json:"user_id"`
+	Following      bool      `json:"following"`
+	FollowedBy     bool      `json:"followed_by"`
+	IsFriend       bool      `json:"is_friend"`
+	FollowerCount  int       `json:"follower_count"`
+	FollowingCount int       `json:"following_count"`
+}
+
+// FeedActivity is an activity of a followed user
+type FeedActivity struct {
+	UserActivity
+	Username  string  `json:"username" db:"username"`
+	AvatarURL *string `json:"avatar_url,omitempty" db:"avatar_url"`
+}
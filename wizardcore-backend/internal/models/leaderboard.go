@@ -28,6 +28,8 @@ type LeaderboardResponse struct {
 	Leaderboard []LeaderboardEntry `json:"leaderboard"`
 	Stats       LeaderboardStats   `json:"stats"`
 	Pagination  Pagination         `json:"pagination"`
+	// The requesting user's entry, whether or not it is on this page
+	CurrentUser *LeaderboardEntry `json:"current_user,omitempty"`
 }
 
 type LeaderboardStats struct {
@@ -43,4 +45,28 @@ type Pagination struct {
 	Total   int `json:"total"`
 	Page    int `json:"page"`
 	PerPage int `json:"per_page"`
+}
+
+// LeaderboardHistoryPoint is a user's rank on a leaderboard at the time it
+// last changed
+type LeaderboardHistoryPoint struct {
+	Rank       int       `json:"rank" db:"rank"`
+	XP         int       `json:"xp" db:"xp"`
+	RecordedAt time.Time `json:"recorded_at" db:"recorded_at"`
+}
+
+// LeaderboardScore is a user's XP on a leaderboard
+type LeaderboardScore struct {
+	UserID uuid.UUID `json:"user_id" db:"user_id"`
+	XP     int       `json:"xp" db:"xp"`
+}
+
+// LeaderboardRank is a user's standing on a leaderboard. Rank is 0 when the
+// user has no XP on it.
+type LeaderboardRank struct {
+	Timeframe     string     `json:"timeframe"`
+	PathwayID     *uuid.UUID `json:"pathway_id,omitempty"`
+	Rank          int        `json:"rank"`
+	XP            int        `json:"xp"`
+	TotalLearners int        `json:"total_learners"`
 }
\ No newline at end of file
@@ -0,0 +1,63 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// LeagueSeason is one week of league play
+type LeagueSeason struct {
+	ID        uuid.UUID  `json:"id" db:"id"`
+	StartsAt  time.Time  `json:"starts_at" db:"starts_at"`
+	EndsAt    time.Time  `json:"ends_at" db:"ends_at"`
+	Status    string     `json:"status" db:"status"` // active, closed
+	ClosedAt  *time.Time `json:"closed_at,omitempty" db:"closed_at"`
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+}
+
+// LeagueStanding is a player's place in their cohort. Zone is the outcome
+// they would get if the season closed now.
+type LeagueStanding struct {
+	Rank          int       `json:"rank"`
+	UserID        uuid.UUID `json:"user_id" db:"user_id"`
+	Username      string    `json:"username" db:"username"`
+	AvatarURL     *string   `json:"avatar_url,omitempty" db:"avatar_url"`
+	XP            int       `json:"xp" db:"xp"`
+	Zone          string    `json:"zone"` // promoted, relegated, stayed
+	IsCurrentUser bool      `json:"is_current_user"`
+}
+
+// LeagueStatus is a user's league in the current season. Users join a
+// cohort when they first earn XP in the season; until then Joined is false
+// and there are no standings.
+type LeagueStatus struct {
+	Season    LeagueSeason     `json:"season"`
+	Tier      int              `json:"tier"`
+	TierName  string           `json:"tier_name"`
+	Joined    bool             `json:"joined"`
+	CohortID  *uuid.UUID       `json:"cohort_id,omitempty"`
+	Standings []LeagueStanding `json:"standings"`
+}
+
+// LeagueCohort is a group of players of one tier in a season
+type LeagueCohort struct {
+	ID       uuid.UUID `json:"id" db:"id"`
+	SeasonID uuid.UUID `json:"season_id" db:"season_id"`
+	Tier     int       `json:"tier" db:"tier"`
+}
+
+// LeagueResult is how a user finished a closed season
+type LeagueResult struct {
+	SeasonID    uuid.UUID `json:"season_id" db:"season_id"`
+	StartsAt    time.Time `json:"starts_at" db:"starts_at"`
+	EndsAt      time.Time `json:"ends_at" db:"ends_at"`
+	Tier        int       `json:"tier" db:"tier"`
+	TierName    string    `json:"tier_name"`
+	Rank        int       `json:"rank" db:"final_rank"`
+	XP          int       `json:"xp" db:"final_xp"`
+	Outcome     string    `json:"outcome" db:"outcome"`
+	NewTier     int       `json:"new_tier" db:"new_tier"`
+	NewTierName string    `json:"new_tier_name"`
+	RewardXP    int       `json:"reward_xp" db:"reward_xp"`
+}
@@ -0,0 +1,44 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SkillMastery is the estimated probability that a user has mastered a
+// skill, an exercise tag
+type SkillMastery struct {
+	Tag       string    `json:"tag" db:"tag"`
+	Mastery   float64   `json:"mastery" db:"p_mastery"`
+	Level     string    `json:"level"`
+	Attempts  int       `json:"attempts" db:"attempts"`
+	Correct   int       `json:"correct" db:"correct"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// SkillsRadar is a user's mastery of every skill they have practiced
+type SkillsRadar struct {
+	Skills   []SkillMastery `json:"skills"`
+	Mastered int            `json:"mastered"`
+}
+
+// MasteryHeatmap is the mastery of the learners enrolled in a pathway of
+// each skill the pathway's exercises practice. Cells are nil where a
+// learner has not practiced a skill.
+type MasteryHeatmap struct {
+	PathwayID    uuid.UUID `json:"pathway_id"`
+	PathwayTitle string    `json:"pathway_title"`
+	Tags         []string  `json:"tags"`
+	// Mean mastery of each tag over all enrolled learners who practiced it
+	Averages []*float64          `json:"averages"`
+	Learners []MasteryHeatmapRow `json:"learners"`
+}
+
+// MasteryHeatmapRow is a learner's mastery of a heatmap's tags, in order
+type MasteryHeatmapRow struct {
+	UserID      uuid.UUID  `json:"user_id"`
+	DisplayName string     `json:"display_name"`
+	AvatarURL   *string    `json:"avatar_url,omitempty"`
+	Cells       []*float64 `json:"cells"`
+}
@@ -0,0 +1,41 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MatchInvite is a private duel challenge. InviteeID is nil for an open
+// invite that anyone holding the code can accept.
+type MatchInvite struct {
+	ID          uuid.UUID  `json:"id" db:"id"`
+	MatchID     uuid.UUID  `json:"match_id" db:"match_id"`
+	InviterID   uuid.UUID  `json:"inviter_id" db:"inviter_id"`
+	InviteeID   *uuid.UUID `json:"invitee_id,omitempty" db:"invitee_id"`
+	InviteCode  string     `json:"invite_code" db:"invite_code"`
+	Status      string     `json:"status" db:"status"`
+	ExpiresAt   time.Time  `json:"expires_at" db:"expires_at"`
+	RespondedAt *time.Time `json:"responded_at,omitempty" db:"responded_at"`
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+}
+
+// CreateMatchInviteRequest creates a private duel. Either an exercise is
+// chosen or one is picked matching the optional language and difficulty.
+type CreateMatchInviteRequest struct {
+	InviteeID        *uuid.UUID `json:"invitee_id,omitempty"`
+	ExerciseID       *uuid.UUID `json:"exercise_id,omitempty"`
+	LanguageID       *int       `json:"language_id,omitempty"`
+	Difficulty       *string    `json:"difficulty,omitempty"`
+	TimeLimitMinutes *int       `json:"time_limit_minutes,omitempty"`
+}
+
+type AcceptMatchInviteRequest struct {
+	InviteCode string `json:"invite_code" validate:"required"`
+}
+
+// MatchInviteDetails is an invite together with its match
+type MatchInviteDetails struct {
+	Invite *MatchInvite   `json:"invite"`
+	Match  *PracticeMatch `json:"match"`
+}
@@ -0,0 +1,55 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// QueueEntry is a user waiting for a duel opponent
+type QueueEntry struct {
+	ID         uuid.UUID  `json:"id" db:"id"`
+	UserID     uuid.UUID  `json:"user_id" db:"user_id"`
+	LanguageID *int       `json:"language_id,omitempty" db:"language_id"`
+	Difficulty *string    `json:"difficulty,omitempty" db:"difficulty"`
+	Rating     float64    `json:"rating" db:"rating"`
+	Status     string     `json:"status" db:"status"`
+	MatchID    *uuid.UUID `json:"match_id,omitempty" db:"match_id"`
+	EnqueuedAt time.Time  `json:"enqueued_at" db:"enqueued_at"`
+	MatchedAt  *time.Time `json:"matched_at,omitempty" db:"matched_at"`
+}
+
+// RatingBand describes how far apart two queued ratings may be. The band
+// starts at Base and grows by Step every StepSeconds of waiting, up to Max.
+type RatingBand struct {
+	Base        float64
+	Step        float64
+	StepSeconds int
+	Max         float64
+}
+
+// Width returns the band after waiting for wait
+func (b RatingBand) Width(wait time.Duration) float64 {
+	width := b.Base
+	if b.StepSeconds > 0 {
+		width += b.Step * float64(int(wait.Seconds())/b.StepSeconds)
+	}
+	if width > b.Max {
+		width = b.Max
+	}
+	return width
+}
+
+type JoinQueueRequest struct {
+	LanguageID *int    `json:"language_id,omitempty"`
+	Difficulty *string `json:"difficulty,omitempty"`
+}
+
+// QueueStatus is a queued user's view of their wait
+type QueueStatus struct {
+	Entry                *QueueEntry    `json:"entry,omitempty"`
+	Match                *PracticeMatch `json:"match,omitempty"`
+	WaitSeconds          int            `json:"wait_seconds"`
+	EstimatedWaitSeconds int            `json:"estimated_wait_seconds"`
+	RatingBand           float64        `json:"rating_band"`
+}
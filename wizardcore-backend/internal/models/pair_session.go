@@ -0,0 +1,64 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type PairSession struct {
+	ID            uuid.UUID  `json:"id" db:"id"`
+	ExerciseID    uuid.UUID  `json:"exercise_id" db:"exercise_id"`
+	LanguageID    int        `json:"language_id" db:"language_id"`
+	HostUserID    uuid.UUID  `json:"host_user_id" db:"host_user_id"`
+	PartnerUserID *uuid.UUID `json:"partner_user_id,omitempty" db:"partner_user_id"`
+	InviteCode    string     `json:"invite_code" db:"invite_code"`
+	Status        string     `json:"status" db:"status"`
+	Content       string     `json:"content" db:"content"`
+	Revision      int        `json:"revision" db:"revision"`
+	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at" db:"updated_at"`
+	EndedAt       *time.Time `json:"ended_at,omitempty" db:"ended_at"`
+}
+
+// Members returns the users taking part in the session
+func (s *PairSession) Members() []uuid.UUID {
+	members := []uuid.UUID{s.HostUserID}
+	if s.PartnerUserID != nil {
+		members = append(members, *s.PartnerUserID)
+	}
+	return members
+}
+
+// IsMember reports whether the user is the host or the partner
+func (s *PairSession) IsMember(userID uuid.UUID) bool {
+	return s.HostUserID == userID || (s.PartnerUserID != nil && *s.PartnerUserID == userID)
+}
+
+type PairOperation struct {
+	ID             uuid.UUID `json:"id" db:"id"`
+	SessionID      uuid.UUID `json:"session_id" db:"session_id"`
+	Revision       int       `json:"revision" db:"revision"`
+	UserID         uuid.UUID `json:"user_id" db:"user_id"`
+	Type           string    `json:"type" db:"op_type"`
+	Position       int       `json:"position" db:"position"`
+	Text           string    `json:"text,omitempty" db:"text"`
+	Length         int       `json:"length,omitempty" db:"length"`
+	CursorPosition *int      `json:"cursor_position,omitempty" db:"cursor_position"`
+	CreatedAt      time.Time `json:"created_at" db:"created_at"`
+}
+
+type CreatePairSessionRequest struct {
+	ExerciseID uuid.UUID `json:"exercise_id" validate:"required"`
+	LanguageID int       `json:"language_id" validate:"required"`
+	Content    string    `json:"content"`
+}
+
+type JoinPairSessionRequest struct {
+	InviteCode string `json:"invite_code" validate:"required"`
+}
+
+type PairSessionSubmitResponse struct {
+	Submission  *Submission  `json:"submission"`
+	Submissions []Submission `json:"submissions"`
+}
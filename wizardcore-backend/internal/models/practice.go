@@ -8,18 +8,60 @@ import (
 
 // ChallengeType represents a type of practice challenge
 type ChallengeType struct {
-	ID          string `json:"id"`
-	Name        string `json:"name"`
-	Description string `json:"description"`
-	Icon        string `json:"icon"`
+	ID               string    `json:"id" db:"id"`
+	Name             string    `json:"name" db:"name"`
+	Description      string    `json:"description" db:"description"`
+	Icon             string    `json:"icon" db:"icon"`
+	TimeLimitMinutes *int      `json:"time_limit_minutes,omitempty" db:"time_limit_minutes"`
+	XPMultiplier     float64   `json:"xp_multiplier" db:"xp_multiplier"`
+	SortOrder        int       `json:"sort_order" db:"sort_order"`
+	IsEnabled        bool      `json:"is_enabled" db:"is_enabled"`
+	UpdatedAt        time.Time `json:"updated_at" db:"updated_at"`
+}
+
+type UpdateChallengeTypeRequest struct {
+	Name             *string  `json:"name,omitempty"`
+	Description      *string  `json:"description,omitempty"`
+	Icon             *string  `json:"icon,omitempty"`
+	TimeLimitMinutes *int     `json:"time_limit_minutes,omitempty"`
+	XPMultiplier     *float64 `json:"xp_multiplier,omitempty"`
+	SortOrder        *int     `json:"sort_order,omitempty"`
+	IsEnabled        *bool    `json:"is_enabled,omitempty"`
 }
 
 // PracticeArea represents a practice area (language/topic) with completion stats
 type PracticeArea struct {
-	Name          string `json:"name"`
-	ExerciseCount int    `json:"exercise_count"`
-	CompletedCount int   `json:"completed_count"`
-	ColorGradient string `json:"color_gradient"`
+	Kind           string  `json:"kind"` // language or tag
+	Key            string  `json:"key"`
+	Name           string  `json:"name"`
+	ExerciseCount  int     `json:"exercise_count"`
+	CompletedCount int     `json:"completed_count"`
+	ColorGradient  string  `json:"color_gradient"`
+	Icon           *string `json:"icon,omitempty"`
+}
+
+// PracticeAreaSetting names, styles or hides a computed practice area
+type PracticeAreaSetting struct {
+	ID            uuid.UUID `json:"id" db:"id"`
+	Kind          string    `json:"kind" db:"kind"`
+	Key           string    `json:"key" db:"area_key"`
+	Name          *string   `json:"name,omitempty" db:"name"`
+	ColorGradient *string   `json:"color_gradient,omitempty" db:"color_gradient"`
+	Icon          *string   `json:"icon,omitempty" db:"icon"`
+	SortOrder     int       `json:"sort_order" db:"sort_order"`
+	IsVisible     bool      `json:"is_visible" db:"is_visible"`
+	CreatedAt     time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at" db:"updated_at"`
+}
+
+type UpsertPracticeAreaSettingRequest struct {
+	Kind          string  `json:"kind" validate:"required"`
+	Key           string  `json:"key" validate:"required"`
+	Name          *string `json:"name,omitempty"`
+	ColorGradient *string `json:"color_gradient,omitempty"`
+	Icon          *string `json:"icon,omitempty"`
+	SortOrder     int     `json:"sort_order"`
+	IsVisible     *bool   `json:"is_visible,omitempty"`
 }
 
 // PracticeMatch represents a practice match (duel, speed run, etc.)
@@ -62,5 +104,96 @@ type UserPracticeStats struct {
 	PracticeScore             int       `json:"practice_score" db:"practice_score"`
 	PracticeRank              *int      `json:"practice_rank,omitempty" db:"practice_rank"`
 	AvgCompletionTime         *int      `json:"avg_completion_time,omitempty" db:"avg_completion_time"`
+	EnduranceRunsCompleted    int       `json:"endurance_runs_completed" db:"endurance_runs_completed"`
+	BestEnduranceSolved       int       `json:"best_endurance_solved" db:"best_endurance_solved"`
+	BestEnduranceScore        int       `json:"best_endurance_score" db:"best_endurance_score"`
+	CompletionTimeTotal       int64     `json:"-" db:"completion_time_total"`
+	CompletionCount           int       `json:"completion_count" db:"completion_count"`
+	Rating                    float64   `json:"rating" db:"rating"`
+	RatingDeviation           float64   `json:"rating_deviation" db:"rating_deviation"`
+	RatingVolatility          float64   `json:"rating_volatility" db:"rating_volatility"`
+	RatedDuels                int       `json:"rated_duels" db:"rated_duels"`
+	PeakRating                *float64  `json:"peak_rating,omitempty" db:"peak_rating"`
 	UpdatedAt                 time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// RecordCompletion adds a completion time in seconds to the running average
+func (s *UserPracticeStats) RecordCompletion(seconds int) {
+	s.CompletionTimeTotal += int64(seconds)
+	s.CompletionCount++
+	avg := int((s.CompletionTimeTotal + int64(s.CompletionCount)/2) / int64(s.CompletionCount))
+	s.AvgCompletionTime = &avg
+}
+
+// MatchSplit is one exercise served during a multi-exercise practice run
+type MatchSplit struct {
+	ID           uuid.UUID  `json:"id" db:"id"`
+	MatchID      uuid.UUID  `json:"match_id" db:"match_id"`
+	UserID       uuid.UUID  `json:"user_id" db:"user_id"`
+	ExerciseID   uuid.UUID  `json:"exercise_id" db:"exercise_id"`
+	Sequence     int        `json:"sequence" db:"sequence"`
+	Result       string     `json:"result" db:"result"` // pending, solved, skipped, unfinished
+	Score        int        `json:"score" db:"score"`
+	Attempts     int        `json:"attempts" db:"attempts"`
+	SubmissionID *uuid.UUID `json:"submission_id,omitempty" db:"submission_id"`
+	ServedAt     time.Time  `json:"served_at" db:"served_at"`
+	CompletedAt  *time.Time `json:"completed_at,omitempty" db:"completed_at"`
+	SplitSeconds *int       `json:"split_seconds,omitempty" db:"split_seconds"`
+}
+
+// PracticeRun is the state of a multi-exercise practice run
+type PracticeRun struct {
+	Match             *PracticeMatch `json:"match"`
+	Splits            []MatchSplit   `json:"splits"`
+	CurrentExerciseID *uuid.UUID     `json:"current_exercise_id,omitempty"`
+	RemainingSeconds  int            `json:"remaining_seconds"`
+	Score             int            `json:"score"`
+	Finished          bool           `json:"finished"`
+	NewPersonalBest   bool           `json:"new_personal_best,omitempty"`
+}
+
+// PersonalBest is a user's best result in a practice mode
+type PersonalBest struct {
+	UserID          uuid.UUID  `json:"user_id" db:"user_id"`
+	Mode            string     `json:"mode" db:"mode"`
+	BestScore       int        `json:"best_score" db:"best_score"`
+	MostSolved      int        `json:"most_solved" db:"most_solved"`
+	BestTimeSeconds *int       `json:"best_time_seconds,omitempty" db:"best_time_seconds"`
+	MatchID         *uuid.UUID `json:"match_id,omitempty" db:"match_id"`
+	AchievedAt      time.Time  `json:"achieved_at" db:"achieved_at"`
+}
+
+// DuelOutcome is the result of a finished duel to rate, with ScoreA the
+// Glicko score of PlayerA: 1 for a win, 0.5 for a draw and 0 for a loss
+type DuelOutcome struct {
+	PlayerA uuid.UUID
+	PlayerB uuid.UUID
+	ScoreA  float64
+}
+
+// DuelRatingChange is one player's rating change from a rated duel
+type DuelRatingChange struct {
+	ID              uuid.UUID  `json:"id" db:"id"`
+	UserID          uuid.UUID  `json:"user_id" db:"user_id"`
+	MatchID         uuid.UUID  `json:"match_id" db:"match_id"`
+	OpponentID      *uuid.UUID `json:"opponent_id,omitempty" db:"opponent_id"`
+	Score           float64    `json:"score" db:"score"`
+	RatingBefore    float64    `json:"rating_before" db:"rating_before"`
+	RatingAfter     float64    `json:"rating_after" db:"rating_after"`
+	DeviationAfter  float64    `json:"deviation_after" db:"deviation_after"`
+	VolatilityAfter float64    `json:"volatility_after" db:"volatility_after"`
+	CreatedAt       time.Time  `json:"created_at" db:"created_at"`
+}
+
+// DuelLeaderboardEntry is a row of the rated duel leaderboard
+type DuelLeaderboardEntry struct {
+	Rank            int       `json:"rank"`
+	UserID          uuid.UUID `json:"user_id" db:"user_id"`
+	Username        string    `json:"username" db:"username"`
+	AvatarURL       *string   `json:"avatar_url,omitempty" db:"avatar_url"`
+	Rating          float64   `json:"rating" db:"rating"`
+	RatingDeviation float64   `json:"rating_deviation" db:"rating_deviation"`
+	RatedDuels      int       `json:"rated_duels" db:"rated_duels"`
+	DuelsWon        int       `json:"duels_won" db:"duels_won"`
+	IsCurrentUser   bool      `json:"is_current_user"`
 }
\ No newline at end of file
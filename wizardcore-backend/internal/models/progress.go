@@ -22,6 +22,18 @@ type Progress struct {
 	LastActivityAt     *time.Time `json:"last_activity_at,omitempty" db:"last_activity_at"`
 }
 
+// ModuleCompletion describes a module a user has just completed and what
+// completing it did to their enrollment in its pathway
+type ModuleCompletion struct {
+	ModuleID    uuid.UUID
+	ModuleTitle string
+	XPAwarded   int
+	PathwayID   *uuid.UUID
+	// The module completed the user's enrollment in its pathway
+	PathwayCompleted bool
+	PathwayTitle     string
+}
+
 // ProgressResponse is the API response for GET /api/v1/users/me/progress
 type ProgressResponse struct {
 	Pathways []PathwayProgress `json:"pathways"`
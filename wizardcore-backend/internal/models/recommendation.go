@@ -0,0 +1,18 @@
+package models
+
+import "github.com/google/uuid"
+
+// ExerciseRecommendation is an exercise suggested to a learner to do next,
+// with the reasons it was picked
+type ExerciseRecommendation struct {
+	ExerciseID   uuid.UUID `json:"exercise_id"`
+	Title        string    `json:"title"`
+	Difficulty   string    `json:"difficulty"`
+	Tags         []string  `json:"tags"`
+	ModuleTitle  *string   `json:"module_title,omitempty"`
+	PathwayTitle *string   `json:"pathway_title,omitempty"`
+	Score        float64   `json:"score"`
+	// The main reason, followed by the others
+	Reason  string   `json:"reason"`
+	Reasons []string `json:"reasons"`
+}
@@ -0,0 +1,45 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yourusername/wizardcore-backend/internal/collab"
+)
+
+// ReplayEvent is one point of a match timeline. Snapshots carry either the
+// full code (a keyframe) or the operations that turn the participant's
+// previous snapshot into this one; submissions carry the graded result.
+type ReplayEvent struct {
+	ID           int64              `json:"id" db:"id"`
+	MatchID      uuid.UUID          `json:"match_id" db:"match_id"`
+	UserID       uuid.UUID          `json:"user_id" db:"user_id"`
+	EventType    string             `json:"event_type" db:"event_type"` // snapshot, submission
+	OffsetMs     int                `json:"offset_ms" db:"offset_ms"`
+	Code         *string            `json:"code,omitempty" db:"code"`
+	Operations   []collab.Operation `json:"operations,omitempty" db:"operations"`
+	LanguageID   *int               `json:"language_id,omitempty" db:"language_id"`
+	ExerciseID   *uuid.UUID         `json:"exercise_id,omitempty" db:"exercise_id"`
+	SubmissionID *uuid.UUID         `json:"submission_id,omitempty" db:"submission_id"`
+	Result       *string            `json:"result,omitempty" db:"result"`
+	Score        *int               `json:"score,omitempty" db:"score"`
+	CreatedAt    time.Time          `json:"created_at" db:"created_at"`
+}
+
+// IsKeyframe reports whether a snapshot holds the full code
+func (e *ReplayEvent) IsKeyframe() bool {
+	return e.EventType == "snapshot" && e.Code != nil
+}
+
+// MatchReplay is the timeline of a finished match, ordered by offset
+type MatchReplay struct {
+	Match        *PracticeMatch     `json:"match"`
+	Participants []MatchParticipant `json:"participants"`
+	DurationMs   int                `json:"duration_ms"`
+	IsPrivate    bool               `json:"is_private"`
+	Events       []ReplayEvent      `json:"events"`
+}
+
+type UpdateReplayPrivacyRequest struct {
+	IsPrivate bool `json:"is_private"`
+}
@@ -0,0 +1,46 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ReviewItem schedules spaced-repetition reviews of an exercise a user has
+// solved. Due is a calendar day in the user's timezone.
+type ReviewItem struct {
+	ID             uuid.UUID  `json:"id" db:"id"`
+	UserID         uuid.UUID  `json:"user_id" db:"user_id"`
+	ExerciseID     uuid.UUID  `json:"exercise_id" db:"exercise_id"`
+	ExerciseTitle  string     `json:"exercise_title" db:"exercise_title"`
+	Difficulty     string     `json:"difficulty" db:"difficulty"`
+	ModuleTitle    *string    `json:"module_title,omitempty" db:"module_title"`
+	Repetitions    int        `json:"repetitions" db:"repetitions"`
+	IntervalDays   int        `json:"interval_days" db:"interval_days"`
+	EaseFactor     float64    `json:"ease_factor" db:"ease_factor"`
+	DueOn          string     `json:"due_on" db:"due_on"` // YYYY-MM-DD
+	LastReviewedAt *time.Time `json:"last_reviewed_at,omitempty" db:"last_reviewed_at"`
+	CreatedAt      time.Time  `json:"created_at" db:"created_at"`
+}
+
+// ReviewQueue is what a user has due for review on a day
+type ReviewQueue struct {
+	Date     string       `json:"date"` // YYYY-MM-DD
+	Items    []ReviewItem `json:"items"`
+	TotalDue int          `json:"total_due"`
+}
+
+// CompleteReviewRequest completes a due review with a graded re-attempt of
+// the exercise and the user's rating of how hard it was
+type CompleteReviewRequest struct {
+	SubmissionID uuid.UUID `json:"submission_id" binding:"required"`
+	Rating       string    `json:"rating" binding:"required,oneof=again hard good easy"`
+}
+
+// ReviewResult is the outcome of a completed review
+type ReviewResult struct {
+	Item      ReviewItem `json:"item"`
+	Correct   bool       `json:"correct"`
+	Quality   int        `json:"quality"`
+	XPAwarded int        `json:"xp_awarded"`
+}
@@ -0,0 +1,34 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UserStreak is a user's stored streak state. Dates are calendar days in
+// the user's timezone.
+type UserStreak struct {
+	UserID           uuid.UUID  `json:"user_id" db:"user_id"`
+	Timezone         string     `json:"timezone" db:"timezone"`
+	CurrentStreak    int        `json:"current_streak" db:"current_streak"`
+	LongestStreak    int        `json:"longest_streak" db:"longest_streak"`
+	Freezes          int        `json:"freezes" db:"streak_freezes"`
+	LastDay          *time.Time `json:"last_day,omitempty" db:"streak_last_day"`
+	LastActivityDate *time.Time `json:"last_activity_date,omitempty" db:"last_activity_date"`
+	RiskNotifiedOn   *time.Time `json:"risk_notified_on,omitempty" db:"streak_risk_notified_on"`
+}
+
+// StreakStatus is a user's streak as of now
+type StreakStatus struct {
+	CurrentStreak    int     `json:"current_streak"`
+	LongestStreak    int     `json:"longest_streak"`
+	FreezesAvailable int     `json:"freezes_available"`
+	MaxFreezes       int     `json:"max_freezes"`
+	LastActiveDate   *string `json:"last_active_date,omitempty"`
+	Timezone         string  `json:"timezone"`
+	ActiveToday      bool    `json:"active_today"`
+	AtRisk           bool    `json:"at_risk"`
+	// Days of streak left until the next freeze is earned
+	NextFreezeIn int `json:"next_freeze_in"`
+}
@@ -6,6 +6,10 @@ import (
 	"github.com/google/uuid"
 )
 
+// SubmissionTypeJointCopy is the type of the copies of a joint submission
+// credited to the author's partners
+const SubmissionTypeJointCopy = "joint_copy"
+
 type Submission struct {
 	ID               uuid.UUID  `json:"id" db:"id"`
 	UserID           uuid.UUID  `json:"user_id" db:"user_id"`
@@ -0,0 +1,90 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MatchParty is a group of players who queue for a team match together
+type MatchParty struct {
+	ID         uuid.UUID     `json:"id" db:"id"`
+	LeaderID   uuid.UUID     `json:"leader_id" db:"leader_id"`
+	InviteCode string        `json:"invite_code" db:"invite_code"`
+	TeamSize   int           `json:"team_size" db:"team_size"`
+	Scoring    string        `json:"scoring" db:"scoring"` // best, sum
+	Status     string        `json:"status" db:"status"`   // forming, queued, in_match, disbanded
+	MatchID    *uuid.UUID    `json:"match_id,omitempty" db:"match_id"`
+	QueuedAt   *time.Time    `json:"queued_at,omitempty" db:"queued_at"`
+	CreatedAt  time.Time     `json:"created_at" db:"created_at"`
+	UpdatedAt  time.Time     `json:"updated_at" db:"updated_at"`
+	Members    []PartyMember `json:"members"`
+}
+
+// IsMember reports whether the user belongs to the party
+func (p *MatchParty) IsMember(userID uuid.UUID) bool {
+	for _, m := range p.Members {
+		if m.UserID == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// MemberIDs returns the user IDs of the party members
+func (p *MatchParty) MemberIDs() []uuid.UUID {
+	ids := make([]uuid.UUID, 0, len(p.Members))
+	for _, m := range p.Members {
+		ids = append(ids, m.UserID)
+	}
+	return ids
+}
+
+type PartyMember struct {
+	UserID      uuid.UUID `json:"user_id" db:"user_id"`
+	DisplayName *string   `json:"display_name,omitempty" db:"display_name"`
+	JoinedAt    time.Time `json:"joined_at" db:"joined_at"`
+}
+
+// MatchTeam is one side of a team match
+type MatchTeam struct {
+	ID      uuid.UUID          `json:"id" db:"id"`
+	MatchID uuid.UUID          `json:"match_id" db:"match_id"`
+	Number  int                `json:"number" db:"team_number"`
+	PartyID *uuid.UUID         `json:"party_id,omitempty" db:"party_id"`
+	Score   int                `json:"score" db:"score"`
+	Result  *string            `json:"result,omitempty" db:"result"`
+	Members []MatchParticipant `json:"members"`
+}
+
+// TeamMatch is a match between teams on a shared set of exercises. Each
+// member scores the best result on every exercise of the set; the team
+// scores the best or the sum of its members' scores.
+type TeamMatch struct {
+	Match       *PracticeMatch `json:"match"`
+	TeamSize    int            `json:"team_size"`
+	Scoring     string         `json:"scoring"`
+	ExerciseIDs []uuid.UUID    `json:"exercise_ids"`
+	Teams       []MatchTeam    `json:"teams"`
+}
+
+// TeamOf returns the team the user plays for
+func (m *TeamMatch) TeamOf(userID uuid.UUID) *MatchTeam {
+	for i := range m.Teams {
+		for _, p := range m.Teams[i].Members {
+			if p.UserID == userID {
+				return &m.Teams[i]
+			}
+		}
+	}
+	return nil
+}
+
+type CreatePartyRequest struct {
+	TeamSize int    `json:"team_size" validate:"required"`
+	Scoring  string `json:"scoring,omitempty"`
+}
+
+type JoinPartyRequest struct {
+	InviteCode string `json:"invite_code" validate:"required"`
+}
@@ -0,0 +1,106 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Tournament struct {
+	ID                   uuid.UUID  `json:"id" db:"id"`
+	Name                 string     `json:"name" db:"name"`
+	Description          *string    `json:"description,omitempty" db:"description"`
+	Format               string     `json:"format" db:"format"`
+	Status               string     `json:"status" db:"status"`
+	LanguageID           *int       `json:"language_id,omitempty" db:"language_id"`
+	Difficulty           *string    `json:"difficulty,omitempty" db:"difficulty"`
+	TimeLimitMinutes     int        `json:"time_limit_minutes" db:"time_limit_minutes"`
+	MaxParticipants      *int       `json:"max_participants,omitempty" db:"max_participants"`
+	SwissRounds          *int       `json:"swiss_rounds,omitempty" db:"swiss_rounds"`
+	CurrentRound         int        `json:"current_round" db:"current_round"`
+	RegistrationOpensAt  time.Time  `json:"registration_opens_at" db:"registration_opens_at"`
+	RegistrationClosesAt time.Time  `json:"registration_closes_at" db:"registration_closes_at"`
+	StartsAt             time.Time  `json:"starts_at" db:"starts_at"`
+	WinnerID             *uuid.UUID `json:"winner_id,omitempty" db:"winner_id"`
+	CreatedBy            *uuid.UUID `json:"created_by,omitempty" db:"created_by"`
+	CreatedAt            time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt            time.Time  `json:"updated_at" db:"updated_at"`
+	CompletedAt          *time.Time `json:"completed_at,omitempty" db:"completed_at"`
+	ParticipantCount     int        `json:"participant_count" db:"participant_count"`
+	IsRegistered         bool       `json:"is_registered"`
+}
+
+type TournamentParticipant struct {
+	TournamentID uuid.UUID `json:"tournament_id" db:"tournament_id"`
+	UserID       uuid.UUID `json:"user_id" db:"user_id"`
+	DisplayName  *string   `json:"display_name,omitempty" db:"display_name"`
+	Seed         *int      `json:"seed,omitempty" db:"seed"`
+	Status       string    `json:"status" db:"status"`
+	RegisteredAt time.Time `json:"registered_at" db:"registered_at"`
+}
+
+// TournamentMatch is a node of a bracket. Status is derived: pending while
+// a player is still unknown, ready once both are, active while the duel is
+// on, and completed or bye once decided.
+type TournamentMatch struct {
+	ID            uuid.UUID  `json:"id" db:"id"`
+	TournamentID  uuid.UUID  `json:"tournament_id" db:"tournament_id"`
+	NodeIndex     int        `json:"node_index" db:"node_index"`
+	Bracket       string     `json:"bracket" db:"bracket"`
+	Round         int        `json:"round" db:"round"`
+	Position      int        `json:"position" db:"position"`
+	Player1ID     *uuid.UUID `json:"player1_id,omitempty" db:"player1_id"`
+	Player2ID     *uuid.UUID `json:"player2_id,omitempty" db:"player2_id"`
+	Slot1Filled   bool       `json:"-" db:"slot1_filled"`
+	Slot2Filled   bool       `json:"-" db:"slot2_filled"`
+	WinnerID      *uuid.UUID `json:"winner_id,omitempty" db:"winner_id"`
+	Decided       bool       `json:"decided" db:"decided"`
+	NextNode      *int       `json:"next_node,omitempty" db:"next_node"`
+	NextSlot      *int       `json:"next_slot,omitempty" db:"next_slot"`
+	LoserNextNode *int       `json:"loser_next_node,omitempty" db:"loser_next_node"`
+	LoserNextSlot *int       `json:"loser_next_slot,omitempty" db:"loser_next_slot"`
+	MatchID       *uuid.UUID `json:"match_id,omitempty" db:"match_id"`
+	Overridden    bool       `json:"overridden" db:"overridden"`
+	DecidedAt     *time.Time `json:"decided_at,omitempty" db:"decided_at"`
+	Status        string     `json:"status"`
+}
+
+// TournamentStanding is a participant's Swiss score
+type TournamentStanding struct {
+	Rank     int       `json:"rank"`
+	UserID   uuid.UUID `json:"user_id"`
+	Seed     int       `json:"seed"`
+	Points   float64   `json:"points"`
+	Buchholz float64   `json:"buchholz"`
+	Wins     int       `json:"wins"`
+	Draws    int       `json:"draws"`
+	Losses   int       `json:"losses"`
+}
+
+// TournamentBracket is everything the frontend needs to draw a tournament
+type TournamentBracket struct {
+	Tournament   *Tournament             `json:"tournament"`
+	Participants []TournamentParticipant `json:"participants"`
+	Matches      []TournamentMatch       `json:"matches"`
+	Standings    []TournamentStanding    `json:"standings,omitempty"`
+}
+
+type CreateTournamentRequest struct {
+	Name                 string    `json:"name" validate:"required"`
+	Description          *string   `json:"description,omitempty"`
+	Format               string    `json:"format" validate:"required"`
+	LanguageID           *int      `json:"language_id,omitempty"`
+	Difficulty           *string   `json:"difficulty,omitempty"`
+	TimeLimitMinutes     *int      `json:"time_limit_minutes,omitempty"`
+	MaxParticipants      *int      `json:"max_participants,omitempty"`
+	SwissRounds          *int      `json:"swiss_rounds,omitempty"`
+	RegistrationOpensAt  time.Time `json:"registration_opens_at"`
+	RegistrationClosesAt time.Time `json:"registration_closes_at" validate:"required"`
+	StartsAt             time.Time `json:"starts_at" validate:"required"`
+}
+
+// OverrideTournamentResultRequest sets the winner of a bracket match. A nil
+// winner records a draw, which only Swiss allows.
+type OverrideTournamentResultRequest struct {
+	WinnerID *uuid.UUID `json:"winner_id"`
+}
@@ -38,6 +38,7 @@ type UserPreferences struct {
 	AutoSave           bool      `json:"auto_save" db:"auto_save"`
 	SoundEffects       bool      `json:"sound_effects" db:"sound_effects"`
 	TwoFactorEnabled   bool      `json:"two_factor_enabled" db:"two_factor_enabled"`
+	Timezone           string    `json:"timezone" db:"timezone"`
 	CreatedAt          time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt          time.Time `json:"updated_at" db:"updated_at"`
 }
@@ -89,4 +90,5 @@ type UpdatePreferencesRequest struct {
 	ShowProgress       *bool   `json:"show_progress"`
 	AutoSave           *bool   `json:"auto_save"`
 	SoundEffects       *bool   `json:"sound_effects"`
+	Timezone           *string `json:"timezone"`
 }
\ No newline at end of file
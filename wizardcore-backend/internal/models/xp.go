@@ -0,0 +1,61 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Sources of XP
+const (
+	XPSourceSubmission     = "submission"
+	XPSourceDailyChallenge = "daily_challenge"
+	XPSourceLeagueReward   = "league_reward"
+	// The xp_reward of a completed module
+	XPSourceModuleCompletion = "module_completion"
+	// An accepted spaced-repetition review
+	XPSourceReview = "review"
+	// XP a user held before the ledger existed that no source explains,
+	// posted when an admin resolves their XPBackfillReview
+	XPSourceOpeningBalance = "opening_balance"
+)
+
+// XPTransaction is an entry of the append-only XP ledger
+type XPTransaction struct {
+	ID             uuid.UUID  `json:"id" db:"id"`
+	UserID         uuid.UUID  `json:"user_id" db:"user_id"`
+	Amount         int        `json:"amount" db:"amount"`
+	SourceType     string     `json:"source_type" db:"source_type"`
+	SourceID       *uuid.UUID `json:"source_id,omitempty" db:"source_id"`
+	PathwayID      *uuid.UUID `json:"pathway_id,omitempty" db:"pathway_id"`
+	IdempotencyKey string     `json:"-" db:"idempotency_key"`
+	CreatedAt      time.Time  `json:"created_at" db:"created_at"`
+}
+
+// XPReconciliation counts the totals a reconciliation found out of step
+// with the ledger
+type XPReconciliation struct {
+	UsersCorrected       int `json:"users_corrected"`
+	EnrollmentsCorrected int `json:"enrollments_corrected"`
+}
+
+// XPBackfillReview is a user whose total XP before the ledger existed was
+// more than the ledger could account for, queued for an admin to decide on
+// an opening balance
+type XPBackfillReview struct {
+	UserID          uuid.UUID  `json:"user_id" db:"user_id"`
+	Username        string     `json:"username" db:"username"`
+	Email           string     `json:"email" db:"email"`
+	RecordedTotalXP int        `json:"recorded_total_xp" db:"recorded_total_xp"`
+	LedgerXP        int        `json:"ledger_xp" db:"ledger_xp"`
+	CreatedAt       time.Time  `json:"created_at" db:"created_at"`
+	ResolvedAt      *time.Time `json:"resolved_at,omitempty" db:"resolved_at"`
+	ResolvedBy      *uuid.UUID `json:"resolved_by,omitempty" db:"resolved_by"`
+	OpeningBalance  *int       `json:"opening_balance,omitempty" db:"opening_balance"`
+}
+
+// ResolveXPBackfillReviewRequest sets the opening balance of a reviewed
+// user; 0 posts none
+type ResolveXPBackfillReviewRequest struct {
+	OpeningBalance *int `json:"opening_balance" binding:"required,min=0"`
+}
@@ -0,0 +1,65 @@
+// Package ontask builds time-on-task sessions from the focus, heartbeat and
+// blur events clients send while an exercise is open. Time between two
+// events counts while the exercise had focus, and a gap longer than IdleGap
+// ends the session without counting.
+package ontask
+
+import "time"
+
+// Events a client sends about an open exercise
+const (
+	// The exercise gained focus
+	EventFocus = "focus"
+	// The exercise still has focus; clients send one every HeartbeatInterval
+	EventHeartbeat = "heartbeat"
+	// The exercise lost focus
+	EventBlur = "blur"
+)
+
+const (
+	// HeartbeatInterval is how often clients are expected to send heartbeats
+	HeartbeatInterval = 30 * time.Second
+	// IdleGap is the longest gap between events of one session. It allows for
+	// a few missed heartbeats.
+	IdleGap = 2 * time.Minute
+)
+
+// Session is a stretch of events on one exercise without an idle gap
+type Session struct {
+	StartedAt     time.Time
+	LastSeenAt    time.Time
+	ActiveSeconds int
+	// The last event left the exercise in focus
+	Focused bool
+}
+
+// Start opens a session with its first event
+func Start(event string, at time.Time) Session {
+	return Session{StartedAt: at, LastSeenAt: at, Focused: event != EventBlur}
+}
+
+// Continues reports whether an event at a time belongs to the session
+// rather than opening a new one
+func Continues(s Session, at time.Time) bool {
+	return !at.Before(s.LastSeenAt) && at.Sub(s.LastSeenAt) <= IdleGap
+}
+
+// Apply records an event the session continues and returns the seconds of
+// activity it adds: the time since the last event, if the exercise had focus.
+func Apply(s *Session, event string, at time.Time) int {
+	credited := 0
+	if s.Focused {
+		credited = int(at.Sub(s.LastSeenAt).Seconds())
+	}
+	s.LastSeenAt = at
+	s.ActiveSeconds += credited
+	s.Focused = event != EventBlur
+	return credited
+}
+
+// Minutes returns how many whole minutes of activity adding credited
+// seconds to active completes, so seconds added event by event sum to the
+// same minutes as the session's total
+func Minutes(active, credited int) int {
+	return (active+credited)/60 - active/60
+}
@@ -0,0 +1,54 @@
+package ontask
+
+import (
+	"testing"
+	"time"
+)
+
+func TestApply_CountsFocusedTimeOnly(t *testing.T) {
+	start := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	s := Start(EventFocus, start)
+
+	if got := Apply(&s, EventHeartbeat, start.Add(30*time.Second)); got != 30 {
+		t.Errorf("Expected a heartbeat to add 30 seconds, got %d", got)
+	}
+	if got := Apply(&s, EventBlur, start.Add(45*time.Second)); got != 15 {
+		t.Errorf("Expected the blur to add the 15 seconds before it, got %d", got)
+	}
+	// Time away from the exercise does not count
+	if got := Apply(&s, EventFocus, start.Add(100*time.Second)); got != 0 {
+		t.Errorf("Expected refocusing to add nothing, got %d", got)
+	}
+	Apply(&s, EventHeartbeat, start.Add(130*time.Second))
+	if s.ActiveSeconds != 75 {
+		t.Errorf("Expected 75 active seconds, got %d", s.ActiveSeconds)
+	}
+}
+
+func TestContinues_EndsAfterIdleGap(t *testing.T) {
+	start := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	s := Start(EventFocus, start)
+	if !Continues(s, start.Add(IdleGap)) {
+		t.Error("Expected an event within the idle gap to continue the session")
+	}
+	if Continues(s, start.Add(IdleGap+time.Second)) {
+		t.Error("Expected an event after the idle gap to open a new session")
+	}
+	if Continues(s, start.Add(-time.Second)) {
+		t.Error("Expected an event before the session to open a new one")
+	}
+}
+
+func TestMinutes_SumsToSessionTotal(t *testing.T) {
+	active, minutes := 0, 0
+	for i := 0; i < 7; i++ {
+		minutes += Minutes(active, 25)
+		active += 25
+	}
+	if minutes != active/60 {
+		t.Errorf("Expected %d minutes, got %d", active/60, minutes)
+	}
+	if got := Minutes(50, 20); got != 1 {
+		t.Errorf("Expected crossing a minute to count it, got %d", got)
+	}
+}
@@ -0,0 +1,124 @@
+// Package rating implements the Glicko-2 rating system.
+//
+// See Mark Glickman, "Example of the Glicko-2 system" (2013). Every duel is
+// treated as its own rating period, so ratings move after each game.
+package rating
+
+import "math"
+
+const (
+	// DefaultRating is the rating of a new player
+	DefaultRating = 1500.0
+	// DefaultDeviation is the rating deviation of a new player
+	DefaultDeviation = 350.0
+	// DefaultVolatility is the volatility of a new player
+	DefaultVolatility = 0.06
+
+	// tau constrains how fast volatility changes
+	tau = 0.5
+	// scale converts between the Glicko and Glicko-2 scales
+	scale = 173.7178
+	// convergence tolerance of the volatility iteration
+	epsilon = 0.000001
+	// the deviation never grows past that of a new player
+	maxDeviation = DefaultDeviation
+)
+
+// Rating is a player's Glicko-2 state on the Glicko scale
+type Rating struct {
+	Rating     float64 `json:"rating"`
+	Deviation  float64 `json:"rating_deviation"`
+	Volatility float64 `json:"rating_volatility"`
+}
+
+// New returns the rating of a player with no games
+func New() Rating {
+	return Rating{Rating: DefaultRating, Deviation: DefaultDeviation, Volatility: DefaultVolatility}
+}
+
+// Result is one game against an opponent. Score is 1 for a win, 0.5 for a
+// draw and 0 for a loss.
+type Result struct {
+	Opponent Rating
+	Score    float64
+}
+
+func g(phi float64) float64 {
+	return 1 / math.Sqrt(1+3*phi*phi/(math.Pi*math.Pi))
+}
+
+func expected(mu, muJ, phiJ float64) float64 {
+	return 1 / (1 + math.Exp(-g(phiJ)*(mu-muJ)))
+}
+
+// Update returns the player's rating after a rating period with the given
+// results. With no results only the deviation grows.
+func Update(player Rating, results []Result) Rating {
+	mu := (player.Rating - DefaultRating) / scale
+	phi := player.Deviation / scale
+	sigma := player.Volatility
+
+	if len(results) == 0 {
+		phiStar := math.Min(math.Sqrt(phi*phi+sigma*sigma), maxDeviation/scale)
+		return Rating{Rating: player.Rating, Deviation: phiStar * scale, Volatility: sigma}
+	}
+
+	var vInv, deltaSum float64
+	for _, r := range results {
+		muJ := (r.Opponent.Rating - DefaultRating) / scale
+		phiJ := r.Opponent.Deviation / scale
+		gJ := g(phiJ)
+		e := expected(mu, muJ, phiJ)
+		vInv += gJ * gJ * e * (1 - e)
+		deltaSum += gJ * (r.Score - e)
+	}
+	v := 1 / vInv
+	delta := v * deltaSum
+
+	newSigma := volatility(phi, sigma, v, delta)
+	phiStar := math.Sqrt(phi*phi + newSigma*newSigma)
+	newPhi := 1 / math.Sqrt(1/(phiStar*phiStar)+1/v)
+	newMu := mu + newPhi*newPhi*deltaSum
+
+	return Rating{
+		Rating:     newMu*scale + DefaultRating,
+		Deviation:  math.Min(newPhi*scale, maxDeviation),
+		Volatility: newSigma,
+	}
+}
+
+// volatility solves for the new volatility with the Illinois algorithm
+func volatility(phi, sigma, v, delta float64) float64 {
+	a := math.Log(sigma * sigma)
+	f := func(x float64) float64 {
+		ex := math.Exp(x)
+		num := ex * (delta*delta - phi*phi - v - ex)
+		den := 2 * (phi*phi + v + ex) * (phi*phi + v + ex)
+		return num/den - (x-a)/(tau*tau)
+	}
+
+	A := a
+	var B float64
+	if delta*delta > phi*phi+v {
+		B = math.Log(delta*delta - phi*phi - v)
+	} else {
+		k := 1.0
+		for f(a-k*tau) < 0 {
+			k++
+		}
+		B = a - k*tau
+	}
+
+	fA, fB := f(A), f(B)
+	for math.Abs(B-A) > epsilon {
+		C := A + (A-B)*fA/(fB-fA)
+		fC := f(C)
+		if fC*fB <= 0 {
+			A, fA = B, fB
+		} else {
+			fA /= 2
+		}
+		B, fB = C, fC
+	}
+	return math.Exp(A / 2)
+}
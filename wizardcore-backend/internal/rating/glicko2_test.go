@@ -0,0 +1,37 @@
+package rating
+
+import (
+	"math"
+	"testing"
+)
+
+func TestUpdate_GlickmanExample(t *testing.T) {
+	player := Rating{Rating: 1500, Deviation: 200, Volatility: 0.06}
+	results := []Result{
+		{Opponent: Rating{Rating: 1400, Deviation: 30, Volatility: 0.06}, Score: 1},
+		{Opponent: Rating{Rating: 1550, Deviation: 100, Volatility: 0.06}, Score: 0},
+		{Opponent: Rating{Rating: 1700, Deviation: 300, Volatility: 0.06}, Score: 0},
+	}
+
+	got := Update(player, results)
+	if math.Abs(got.Rating-1464.06) > 0.01 {
+		t.Errorf("Expected rating 1464.06, got %.4f", got.Rating)
+	}
+	if math.Abs(got.Deviation-151.52) > 0.01 {
+		t.Errorf("Expected deviation 151.52, got %.4f", got.Deviation)
+	}
+	if math.Abs(got.Volatility-0.05999) > 0.00001 {
+		t.Errorf("Expected volatility 0.05999, got %.6f", got.Volatility)
+	}
+}
+
+func TestUpdate_NoGamesGrowsDeviation(t *testing.T) {
+	player := Rating{Rating: 1600, Deviation: 50, Volatility: 0.06}
+	got := Update(player, nil)
+	if got.Rating != 1600 || got.Deviation <= 50 {
+		t.Errorf("Expected unchanged rating and larger deviation, got %+v", got)
+	}
+	if capped := Update(New(), nil); capped.Deviation > DefaultDeviation {
+		t.Errorf("Expected deviation capped at %v, got %v", DefaultDeviation, capped.Deviation)
+	}
+}
@@ -0,0 +1,156 @@
+// Package recommend scores the exercises a learner has not solved yet to
+// pick what they should do next. Each score comes with the reasons behind
+// it, so recommendations can explain themselves.
+package recommend
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/google/uuid"
+)
+
+// Difficulties in the order learners progress through them
+var difficulties = []string{"BEGINNER", "INTERMEDIATE", "ADVANCED"}
+
+const (
+	// A learner moves up a difficulty after solving this many at their level
+	LevelUpAfter = 5
+	// A tag is struggled with below this success rate, once it has been
+	// submitted on at least MinTagAttempts times
+	StruggleRate   = 0.5
+	MinTagAttempts = 3
+)
+
+// TagStat counts a learner's graded submissions on exercises with a tag
+type TagStat struct {
+	Attempts int
+	Correct  int
+}
+
+// Rate returns the share of the submissions that were accepted
+func (t TagStat) Rate() float64 {
+	if t.Attempts == 0 {
+		return 0
+	}
+	return float64(t.Correct) / float64(t.Attempts)
+}
+
+// Profile is what is known about a learner
+type Profile struct {
+	Tags map[string]TagStat
+	// Exercises solved per difficulty
+	Solved map[string]int
+}
+
+// Candidate is an exercise the learner has not solved
+type Candidate struct {
+	ExerciseID   uuid.UUID
+	Title        string
+	Difficulty   string
+	Tags         []string
+	ModuleTitle  string
+	PathwayTitle string
+	// The exercise is in a pathway the learner is enrolled in
+	Enrolled bool
+	// The exercise is in a module the learner started and has not finished
+	ModuleStarted bool
+	// The exercise is the first one of its module the learner has not solved
+	NextInModule bool
+	// The learner submitted on the exercise without solving it
+	Attempted bool
+}
+
+// Recommendation is a scored candidate
+type Recommendation struct {
+	Candidate
+	Score   float64
+	Reasons []string
+}
+
+// Level returns the difficulty the learner is working at: the lowest one
+// they have not yet solved LevelUpAfter exercises of
+func Level(p Profile) int {
+	for i, d := range difficulties {
+		if p.Solved[d] < LevelUpAfter {
+			return i
+		}
+	}
+	return len(difficulties) - 1
+}
+
+func difficultyIndex(d string) int {
+	for i, known := range difficulties {
+		if known == d {
+			return i
+		}
+	}
+	return -1
+}
+
+// Score rates how good a next exercise a candidate is for the learner
+func Score(p Profile, c Candidate) Recommendation {
+	r := Recommendation{Candidate: c}
+	add := func(score float64, reason string) {
+		r.Score += score
+		if reason != "" {
+			r.Reasons = append(r.Reasons, reason)
+		}
+	}
+
+	if c.ModuleStarted {
+		if c.NextInModule {
+			add(4, fmt.Sprintf("Next up in %s", c.ModuleTitle))
+		} else {
+			add(2, fmt.Sprintf("Part of %s, which you started", c.ModuleTitle))
+		}
+	} else if c.Enrolled {
+		add(2, fmt.Sprintf("Continues your %s pathway", c.PathwayTitle))
+		if c.NextInModule {
+			add(1, "")
+		}
+	}
+
+	// The tag the learner struggles with most
+	weakest, weakestRate := "", StruggleRate
+	for _, tag := range c.Tags {
+		stat := p.Tags[tag]
+		if stat.Attempts >= MinTagAttempts && stat.Rate() < weakestRate {
+			weakest, weakestRate = tag, stat.Rate()
+		}
+	}
+	if weakest != "" {
+		add(3*(1-weakestRate), fmt.Sprintf("Because you struggled with %s", weakest))
+	}
+
+	level := Level(p)
+	switch d := difficultyIndex(c.Difficulty); {
+	case d < 0:
+	case d == level:
+		add(2, "Matches your level")
+	case d == level+1:
+		add(1, "A step up in difficulty")
+	case d > level+1:
+		add(-3, "")
+	}
+
+	if c.Attempted {
+		add(1, "You have tried this one but not solved it yet")
+	}
+	return r
+}
+
+// Rank scores candidates and returns the best n, best first
+func Rank(p Profile, candidates []Candidate, n int) []Recommendation {
+	scored := make([]Recommendation, len(candidates))
+	for i, c := range candidates {
+		scored[i] = Score(p, c)
+	}
+	sort.SliceStable(scored, func(i, j int) bool {
+		return scored[i].Score > scored[j].Score
+	})
+	if n >= 0 && len(scored) > n {
+		scored = scored[:n]
+	}
+	return scored
+}
@@ -0,0 +1,57 @@
+package recommend
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestLevel_MovesUpAfterEnoughSolves(t *testing.T) {
+	if got := Level(Profile{}); got != 0 {
+		t.Errorf("Expected a new learner at level 0, got %d", got)
+	}
+	p := Profile{Solved: map[string]int{"BEGINNER": LevelUpAfter, "INTERMEDIATE": 2}}
+	if got := Level(p); got != 1 {
+		t.Errorf("Expected level 1, got %d", got)
+	}
+	p.Solved["INTERMEDIATE"] = LevelUpAfter
+	p.Solved["ADVANCED"] = 50
+	if got := Level(p); got != 2 {
+		t.Errorf("Expected the top level to be kept, got %d", got)
+	}
+}
+
+func TestScore_ExplainsStruggledTag(t *testing.T) {
+	p := Profile{Tags: map[string]TagStat{
+		"pointers":  {Attempts: 6, Correct: 1},
+		"recursion": {Attempts: 2, Correct: 0}, // too few attempts to judge
+		"strings":   {Attempts: 10, Correct: 9},
+	}}
+	r := Score(p, Candidate{Difficulty: "BEGINNER", Tags: []string{"strings", "pointers", "recursion"}})
+	if len(r.Reasons) == 0 || !strings.Contains(r.Reasons[0], "pointers") {
+		t.Errorf("Expected the pointers struggle to be explained, got %v", r.Reasons)
+	}
+}
+
+func TestRank_PrefersUnfinishedModuleAtLevel(t *testing.T) {
+	p := Profile{}
+	next := Candidate{ExerciseID: uuid.New(), Difficulty: "BEGINNER", ModuleStarted: true, NextInModule: true, ModuleTitle: "Pointers 101"}
+	enrolled := Candidate{ExerciseID: uuid.New(), Difficulty: "BEGINNER", Enrolled: true, PathwayTitle: "C Basics"}
+	tooHard := Candidate{ExerciseID: uuid.New(), Difficulty: "ADVANCED", Enrolled: true}
+	other := Candidate{ExerciseID: uuid.New(), Difficulty: "INTERMEDIATE"}
+
+	ranked := Rank(p, []Candidate{tooHard, other, enrolled, next}, 3)
+	if len(ranked) != 3 {
+		t.Fatalf("Expected 3 recommendations, got %d", len(ranked))
+	}
+	want := []uuid.UUID{next.ExerciseID, enrolled.ExerciseID, other.ExerciseID}
+	for i, id := range want {
+		if ranked[i].ExerciseID != id {
+			t.Errorf("Expected recommendation %d to be %s, got %s (%v)", i, id, ranked[i].ExerciseID, ranked[i].Reasons)
+		}
+	}
+	if ranked[0].Reasons[0] != "Next up in Pointers 101" {
+		t.Errorf("Expected the module to be explained first, got %v", ranked[0].Reasons)
+	}
+}
@@ -129,6 +129,86 @@ func (r *ActivityRepository) GetUserActivities(ctx context.Context, userID uuid.
 	return activities, nil
 }
 
+// GetFollowingFeed retrieves the activities of the users someone follows,
+// newest first. Users whose profile is not public appear only if they
+// follow back.
+func (r *ActivityRepository) GetFollowingFeed(ctx context.Context, userID uuid.UUID, limit, offset int) ([]models.FeedActivity, error) {
+	query := `
+		SELECT
+			a.id, a.user_id, a.activity_type, a.title, a.description, a.icon, a.color, a.metadata, a.created_at,
+			COALESCE(u.display_name, ''), u.avatar_url
+		FROM user_follows f
+		JOIN user_activities a ON a.user_id = f.followee_id
+		JOIN users u ON u.id = f.followee_id
+		LEFT JOIN user_preferences up ON up.user_id = f.followee_id
+		WHERE f.follower_id = $1
+		AND (
+			COALESCE(up.public_profile, true)
+			OR EXISTS (SELECT 1 FROM user_follows back WHERE back.follower_id = f.followee_id AND back.followee_id = $1)
+		)
+		ORDER BY a.created_at DESC, a.id
+		LIMIT $2 OFFSET $3
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query activity feed: %w", err)
+	}
+	defer rows.Close()
+
+	activities := []models.FeedActivity{}
+	for rows.Next() {
+		var activity models.FeedActivity
+		var description, icon, color sql.NullString
+		var metadataJSON []byte
+
+		err := rows.Scan(
+			&activity.ID,
+			&activity.UserID,
+			&activity.ActivityType,
+			&activity.Title,
+			&description,
+			&icon,
+			&color,
+			&metadataJSON,
+			&activity.CreatedAt,
+			&activity.Username,
+			&activity.AvatarURL,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan feed activity: %w", err)
+		}
+
+		// Handle nullable fields
+		if description.Valid {
+			activity.Description = &description.String
+		}
+		if icon.Valid {
+			activity.Icon = &icon.String
+		}
+		if color.Valid {
+			activity.Color = &color.String
+		}
+
+		// Parse metadata JSON
+		if len(metadataJSON) > 0 {
+			var metadata map[string]interface{}
+			if err := json.Unmarshal(metadataJSON, &metadata); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal metadata: %w", err)
+			}
+			activity.Metadata = metadata
+		}
+
+		activities = append(activities, activity)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating feed activities: %w", err)
+	}
+
+	return activities, nil
+}
+
 // CountUserActivities returns the total number of activities for a user
 func (r *ActivityRepository) CountUserActivities(ctx context.Context, userID uuid.UUID) (int, error) {
 	query := `SELECT COUNT(*) FROM user_activities WHERE user_id = $1`
@@ -144,12 +224,16 @@ func (r *ActivityRepository) CountUserActivities(ctx context.Context, userID uui
 
 // CreateExerciseSubmissionActivity creates an activity record for exercise submission
 func (r *ActivityRepository) CreateExerciseSubmissionActivity(ctx context.Context, userID uuid.UUID, exerciseID uuid.UUID, exerciseTitle string, xpEarned int, timeSpentMinutes int) error {
+	description := fmt.Sprintf("Earned %d XP", xpEarned)
+	if timeSpentMinutes > 0 {
+		description = fmt.Sprintf("Earned %d XP in %d minutes", xpEarned, timeSpentMinutes)
+	}
 	activity := &models.UserActivity{
 		ID:           uuid.New(),
 		UserID:       userID,
 		ActivityType: "practice",
 		Title:        fmt.Sprintf("Completed: %s", exerciseTitle),
-		Description:  stringPtr(fmt.Sprintf("Earned %d XP in %d minutes", xpEarned, timeSpentMinutes)),
+		Description:  stringPtr(description),
 		Icon:         stringPtr("code"),
 		Color:        stringPtr("text-blue-400"),
 		Metadata: map[string]interface{}{
@@ -205,6 +289,26 @@ func (r *ActivityRepository) CreatePathwayEnrollmentActivity(ctx context.Context
 	return r.CreateActivity(ctx, activity)
 }
 
+// CreatePathwayCompletionActivity creates an activity record for pathway completion
+func (r *ActivityRepository) CreatePathwayCompletionActivity(ctx context.Context, userID uuid.UUID, pathwayID uuid.UUID, pathwayTitle string) error {
+	activity := &models.UserActivity{
+		ID:           uuid.New(),
+		UserID:       userID,
+		ActivityType: "completion",
+		Title:        fmt.Sprintf("Pathway Completed: %s", pathwayTitle),
+		Description:  stringPtr("Finished every module of the pathway"),
+		Icon:         stringPtr("trophy"),
+		Color:        stringPtr("text-yellow-400"),
+		Metadata: map[string]interface{}{
+			"pathway_id":    pathwayID.String(),
+			"activity_date": time.Now().Format(time.RFC3339),
+		},
+		CreatedAt: time.Now(),
+	}
+
+	return r.CreateActivity(ctx, activity)
+}
+
 // CreateAchievementActivity creates an activity record for achievement unlock
 func (r *ActivityRepository) CreateAchievementActivity(ctx context.Context, userID uuid.UUID, achievementID uuid.UUID, achievementTitle string, xpEarned int) error {
 	activity := &models.UserActivity{
@@ -0,0 +1,167 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yourusername/wizardcore-backend/internal/models"
+	"github.com/yourusername/wizardcore-backend/internal/ontask"
+)
+
+// ActivitySessionRepository builds time-on-task sessions from client
+// events and passes the time they add on to daily activity and module
+// progress
+type ActivitySessionRepository struct {
+	db *sql.DB
+}
+
+func NewActivitySessionRepository(db *sql.DB) *ActivitySessionRepository {
+	return &ActivitySessionRepository{db: db}
+}
+
+// RecordEvent applies a client event on an exercise to the user's session
+// on it, opening a new session after an idle gap. Whole minutes of activity
+// it completes are added to the user's day and to their progress on the
+// exercise's module. It returns the session, or nil if there is no such
+// exercise.
+func (r *ActivitySessionRepository) RecordEvent(userID, exerciseID uuid.UUID, event string, at time.Time) (*models.ActivitySession, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// Events of one user on one exercise are applied one at a time
+	if _, err := tx.Exec(`SELECT pg_advisory_xact_lock(hashtext($1))`, fmt.Sprintf("activity_session:%s:%s", userID, exerciseID)); err != nil {
+		return nil, fmt.Errorf("failed to lock activity session: %w", err)
+	}
+
+	session := models.ActivitySession{UserID: userID, ExerciseID: exerciseID}
+	var moduleID, pathwayID uuid.NullUUID
+	err = tx.QueryRow(`
+		SELECT e.module_id, m.pathway_id
+		FROM exercises e
+		LEFT JOIN modules m ON e.module_id = m.id
+		WHERE e.id = $1
+	`, exerciseID).Scan(&moduleID, &pathwayID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find exercise module: %w", err)
+	}
+	if moduleID.Valid {
+		session.ModuleID = &moduleID.UUID
+	}
+	if pathwayID.Valid {
+		session.PathwayID = &pathwayID.UUID
+	}
+
+	err = tx.QueryRow(`
+		SELECT id, started_at, last_seen_at, active_seconds, focused
+		FROM activity_sessions
+		WHERE user_id = $1 AND exercise_id = $2
+		ORDER BY last_seen_at DESC
+		LIMIT 1
+	`, userID, exerciseID).Scan(&session.ID, &session.StartedAt, &session.LastSeenAt, &session.ActiveSeconds, &session.Focused)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, fmt.Errorf("failed to get activity session: %w", err)
+	}
+	state := ontask.Session{
+		StartedAt:     session.StartedAt,
+		LastSeenAt:    session.LastSeenAt,
+		ActiveSeconds: session.ActiveSeconds,
+		Focused:       session.Focused,
+	}
+
+	if err == sql.ErrNoRows || !ontask.Continues(state, at) {
+		state = ontask.Start(event, at)
+		session.ID = uuid.New()
+		session.StartedAt = state.StartedAt
+		session.LastSeenAt = state.LastSeenAt
+		session.ActiveSeconds = 0
+		session.Focused = state.Focused
+		if _, err := tx.Exec(`
+			INSERT INTO activity_sessions (id, user_id, exercise_id, module_id, pathway_id, started_at, last_seen_at, active_seconds, focused)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, 0, $8)
+		`, session.ID, userID, exerciseID, session.ModuleID, session.PathwayID, session.StartedAt, session.LastSeenAt, session.Focused); err != nil {
+			return nil, fmt.Errorf("failed to start activity session: %w", err)
+		}
+		if err := tx.Commit(); err != nil {
+			return nil, err
+		}
+		return &session, nil
+	}
+
+	before := state.ActiveSeconds
+	credited := ontask.Apply(&state, event, at)
+	session.LastSeenAt = state.LastSeenAt
+	session.ActiveSeconds = state.ActiveSeconds
+	session.Focused = state.Focused
+	if _, err := tx.Exec(`
+		UPDATE activity_sessions SET last_seen_at = $2, active_seconds = $3, focused = $4
+		WHERE id = $1
+	`, session.ID, session.LastSeenAt, session.ActiveSeconds, session.Focused); err != nil {
+		return nil, fmt.Errorf("failed to update activity session: %w", err)
+	}
+
+	if minutes := ontask.Minutes(before, credited); minutes > 0 {
+		if err := addDailyMinutes(tx, userID, at, minutes); err != nil {
+			return nil, err
+		}
+		if session.ModuleID != nil {
+			if _, err := tx.Exec(`
+				INSERT INTO user_module_progress (user_id, module_id, pathway_id, started_at, time_spent_minutes, last_activity_at)
+				VALUES ($1, $2, $3, $4, $5, $4)
+				ON CONFLICT (user_id, module_id) DO UPDATE SET
+					time_spent_minutes = COALESCE(user_module_progress.time_spent_minutes, 0) + EXCLUDED.time_spent_minutes,
+					last_activity_at = EXCLUDED.last_activity_at
+			`, userID, session.ModuleID, session.PathwayID, at, minutes); err != nil {
+				return nil, fmt.Errorf("failed to record module time: %w", err)
+			}
+		}
+		if session.PathwayID != nil {
+			if _, err := tx.Exec(`
+				UPDATE user_pathway_enrollments SET last_activity_at = $3
+				WHERE user_id = $1 AND pathway_id = $2
+			`, userID, session.PathwayID, at); err != nil {
+				return nil, fmt.Errorf("failed to record pathway activity: %w", err)
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// RefreshCompletionStats recounts the users who solved an exercise and
+// their average time on it before their first accepted submission
+func (r *ActivitySessionRepository) RefreshCompletionStats(exerciseID uuid.UUID) error {
+	_, err := r.db.Exec(`
+		WITH solvers AS (
+			SELECT user_id, MIN(created_at) AS solved_at
+			FROM submissions
+			WHERE exercise_id = $1 AND is_correct = true
+			GROUP BY user_id
+		), solve_times AS (
+			SELECT SUM(a.active_seconds) AS seconds
+			FROM solvers s
+			JOIN activity_sessions a ON a.user_id = s.user_id AND a.exercise_id = $1 AND a.started_at <= s.solved_at
+			GROUP BY s.user_id
+			HAVING SUM(a.active_seconds) > 0
+		)
+		UPDATE exercises
+		SET total_completions = (SELECT COUNT(*) FROM solvers),
+		    average_completion_time = COALESCE((SELECT AVG(seconds)::int FROM solve_times), average_completion_time),
+		    updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+	`, exerciseID)
+	if err != nil {
+		return fmt.Errorf("failed to refresh completion stats: %w", err)
+	}
+	return nil
+}
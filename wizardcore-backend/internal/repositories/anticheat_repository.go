@@ -0,0 +1,317 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yourusername/wizardcore-backend/internal/models"
+)
+
+type AntiCheatRepository struct {
+	db *sql.DB
+}
+
+func NewAntiCheatRepository(db *sql.DB) *AntiCheatRepository {
+	return &AntiCheatRepository{db: db}
+}
+
+// AddFlag records a flag and puts the account in the review queue. A flag
+// of the same reason within dedupeWindow is not recorded again. A cleared
+// review is reopened; exclude is applied to reviews it opens or reopens.
+// It reports whether the flag was recorded.
+func (r *AntiCheatRepository) AddFlag(flag *models.AntiCheatFlag, dedupeWindow time.Duration, exclude bool) (bool, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	if flag.ID == uuid.Nil {
+		flag.ID = uuid.New()
+	}
+	if flag.CreatedAt.IsZero() {
+		flag.CreatedAt = time.Now()
+	}
+	result, err := tx.Exec(`
+		INSERT INTO anticheat_flags (id, user_id, reason, submission_id, related_user_id, details, created_at)
+		SELECT $1, $2, $3, $4, $5, $6, $7
+		WHERE NOT EXISTS (
+			SELECT 1 FROM anticheat_flags
+			WHERE user_id = $2 AND reason = $3 AND created_at > $8
+		)
+	`, flag.ID, flag.UserID, flag.Reason, flag.SubmissionID, flag.RelatedUserID, flag.Details, flag.CreatedAt, flag.CreatedAt.Add(-dedupeWindow))
+	if err != nil {
+		return false, fmt.Errorf("failed to record anti-cheat flag: %w", err)
+	}
+	if n, err := result.RowsAffected(); err != nil || n == 0 {
+		return false, err
+	}
+
+	if _, err := tx.Exec(`
+		INSERT INTO anticheat_reviews (user_id, status, exclude_from_leaderboards, opened_at)
+		VALUES ($1, 'pending', $2, $3)
+		ON CONFLICT (user_id) DO UPDATE SET
+			status = 'pending',
+			exclude_from_leaderboards = $2,
+			opened_at = $3,
+			reviewed_by = NULL,
+			reviewed_at = NULL
+		WHERE anticheat_reviews.status = 'cleared'
+	`, flag.UserID, exclude, flag.CreatedAt); err != nil {
+		return false, fmt.Errorf("failed to open anti-cheat review: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// GetBaseline returns the fastest recorded solve of an exercise and how many
+// solves it covers
+func (r *AntiCheatRepository) GetBaseline(exerciseID uuid.UUID) (int, int, error) {
+	var minSeconds, samples int
+	err := r.db.QueryRow(`
+		SELECT min_solve_seconds, samples FROM anticheat_exercise_baselines WHERE exercise_id = $1
+	`, exerciseID).Scan(&minSeconds, &samples)
+	if err == sql.ErrNoRows {
+		return 0, 0, nil
+	}
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to get solve baseline: %w", err)
+	}
+	return minSeconds, samples, nil
+}
+
+// RecordSolveTime adds a solve to an exercise's baseline
+func (r *AntiCheatRepository) RecordSolveTime(exerciseID uuid.UUID, seconds int) error {
+	_, err := r.db.Exec(`
+		INSERT INTO anticheat_exercise_baselines (exercise_id, min_solve_seconds, samples)
+		VALUES ($1, $2, 1)
+		ON CONFLICT (exercise_id) DO UPDATE SET
+			min_solve_seconds = LEAST(anticheat_exercise_baselines.min_solve_seconds, EXCLUDED.min_solve_seconds),
+			samples = anticheat_exercise_baselines.samples + 1,
+			updated_at = CURRENT_TIMESTAMP
+	`, exerciseID, seconds)
+	if err != nil {
+		return fmt.Errorf("failed to record solve time: %w", err)
+	}
+	return nil
+}
+
+// GetSolveSeconds returns how long an accepted submission took to write,
+// measured from the user's last submission to any other exercise. It
+// reports false when there is nothing to measure from, or when the user
+// had already solved the exercise and this is a re-solve.
+func (r *AntiCheatRepository) GetSolveSeconds(submission *models.Submission) (int, bool, error) {
+	var since sql.NullTime
+	var solvedBefore bool
+	err := r.db.QueryRow(`
+		SELECT
+			(SELECT MAX(created_at) FROM submissions
+			 WHERE user_id = $1 AND exercise_id <> $2 AND created_at < $4),
+			EXISTS (SELECT 1 FROM submissions
+			 WHERE user_id = $1 AND exercise_id = $2 AND is_correct AND id <> $3 AND created_at <= $4)
+	`, submission.UserID, submission.ExerciseID, submission.ID, submission.CreatedAt).Scan(&since, &solvedBefore)
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to measure solve time: %w", err)
+	}
+	if !since.Valid || solvedBefore {
+		return 0, false, nil
+	}
+	return int(submission.CreatedAt.Sub(since.Time).Seconds()), true, nil
+}
+
+// CountSubmissionsSince counts a user's graded submissions since a time
+func (r *AntiCheatRepository) CountSubmissionsSince(userID uuid.UUID, since time.Time) (int, error) {
+	var count int
+	err := r.db.QueryRow(`
+		SELECT COUNT(*) FROM submissions
+		WHERE user_id = $1 AND created_at >= $2
+		AND COALESCE(submission_type, 'solution') <> 'draft'
+	`, userID, since).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count recent submissions: %w", err)
+	}
+	return count, nil
+}
+
+// GetXPWindows returns the submission XP a user earned since recentSince,
+// and between baselineSince and recentSince
+func (r *AntiCheatRepository) GetXPWindows(userID uuid.UUID, recentSince, baselineSince time.Time) (int, int, error) {
+	var recent, baseline int
+	err := r.db.QueryRow(`
+		SELECT
+			COALESCE(SUM(points_earned) FILTER (WHERE created_at >= $2), 0)::int,
+			COALESCE(SUM(points_earned) FILTER (WHERE created_at < $2), 0)::int
+		FROM submissions
+		WHERE user_id = $1 AND created_at >= $3
+		AND COALESCE(submission_type, 'solution') <> 'draft'
+	`, userID, recentSince, baselineSince).Scan(&recent, &baseline)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to sum recent XP: %w", err)
+	}
+	return recent, baseline, nil
+}
+
+// FindDuplicateSource returns other users with an accepted submission to the
+// exercise with the given source fingerprint. Pair submissions, which
+// partners share, are not compared.
+func (r *AntiCheatRepository) FindDuplicateSource(exerciseID, userID uuid.UUID, fingerprint string) ([]uuid.UUID, error) {
+	rows, err := r.db.Query(`
+		SELECT DISTINCT user_id
+		FROM submissions
+		WHERE exercise_id = $1 AND source_fingerprint = $3 AND is_correct
+		AND user_id <> $2
+		AND COALESCE(submission_type, 'solution') NOT IN ('pair', 'joint_copy')
+		LIMIT 10
+	`, exerciseID, userID, fingerprint)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compare sources: %w", err)
+	}
+	defer rows.Close()
+	var users []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan duplicate source: %w", err)
+		}
+		users = append(users, id)
+	}
+	return users, rows.Err()
+}
+
+const antiCheatReviewSelect = `
+	SELECT
+		ar.user_id,
+		COALESCE(u.display_name, ''),
+		u.email,
+		ar.status,
+		ar.exclude_from_leaderboards,
+		ar.notes,
+		(SELECT COUNT(*) FROM anticheat_flags f WHERE f.user_id = ar.user_id),
+		(SELECT MAX(f.created_at) FROM anticheat_flags f WHERE f.user_id = ar.user_id),
+		ar.opened_at,
+		ar.reviewed_by,
+		ar.reviewed_at
+	FROM anticheat_reviews ar
+	JOIN users u ON ar.user_id = u.id
+`
+
+func scanAntiCheatReview(row interface{ Scan(...interface{}) error }) (*models.AntiCheatReview, error) {
+	var review models.AntiCheatReview
+	var lastFlaggedAt, reviewedAt sql.NullTime
+	var reviewedBy uuid.NullUUID
+	if err := row.Scan(
+		&review.UserID, &review.Username, &review.Email, &review.Status, &review.ExcludeFromLeaderboards,
+		&review.Notes, &review.FlagCount, &lastFlaggedAt, &review.OpenedAt, &reviewedBy, &reviewedAt,
+	); err != nil {
+		return nil, err
+	}
+	if lastFlaggedAt.Valid {
+		review.LastFlaggedAt = &lastFlaggedAt.Time
+	}
+	if reviewedBy.Valid {
+		review.ReviewedBy = &reviewedBy.UUID
+	}
+	if reviewedAt.Valid {
+		review.ReviewedAt = &reviewedAt.Time
+	}
+	return &review, nil
+}
+
+// ListReviews returns the reviews with a status, oldest opened first, or all
+// reviews when status is empty
+func (r *AntiCheatRepository) ListReviews(status string, limit, offset int) ([]models.AntiCheatReview, int, error) {
+	var total int
+	if err := r.db.QueryRow(`
+		SELECT COUNT(*) FROM anticheat_reviews WHERE ($1 = '' OR status = $1)
+	`, status).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count anti-cheat reviews: %w", err)
+	}
+	rows, err := r.db.Query(antiCheatReviewSelect+`
+		WHERE ($1 = '' OR ar.status = $1)
+		ORDER BY ar.opened_at, ar.user_id
+		LIMIT $2 OFFSET $3
+	`, status, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list anti-cheat reviews: %w", err)
+	}
+	defer rows.Close()
+	reviews := []models.AntiCheatReview{}
+	for rows.Next() {
+		review, err := scanAntiCheatReview(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan anti-cheat review: %w", err)
+		}
+		reviews = append(reviews, *review)
+	}
+	return reviews, total, rows.Err()
+}
+
+// GetReview returns the review of an account, or nil if it was never
+// flagged
+func (r *AntiCheatRepository) GetReview(userID uuid.UUID) (*models.AntiCheatReview, error) {
+	review, err := scanAntiCheatReview(r.db.QueryRow(antiCheatReviewSelect+` WHERE ar.user_id = $1`, userID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get anti-cheat review: %w", err)
+	}
+	return review, nil
+}
+
+// ListFlags returns an account's flags, newest first
+func (r *AntiCheatRepository) ListFlags(userID uuid.UUID, limit int) ([]models.AntiCheatFlag, error) {
+	rows, err := r.db.Query(`
+		SELECT id, user_id, reason, submission_id, related_user_id, details, created_at
+		FROM anticheat_flags
+		WHERE user_id = $1
+		ORDER BY created_at DESC
+		LIMIT $2
+	`, userID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list anti-cheat flags: %w", err)
+	}
+	defer rows.Close()
+	flags := []models.AntiCheatFlag{}
+	for rows.Next() {
+		var f models.AntiCheatFlag
+		var submissionID, relatedUserID uuid.NullUUID
+		if err := rows.Scan(&f.ID, &f.UserID, &f.Reason, &submissionID, &relatedUserID, &f.Details, &f.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan anti-cheat flag: %w", err)
+		}
+		if submissionID.Valid {
+			f.SubmissionID = &submissionID.UUID
+		}
+		if relatedUserID.Valid {
+			f.RelatedUserID = &relatedUserID.UUID
+		}
+		flags = append(flags, f)
+	}
+	return flags, rows.Err()
+}
+
+// UpdateReview records a reviewer's decision. exclude and notes are left
+// unchanged when nil. It reports false if the account has no review.
+func (r *AntiCheatRepository) UpdateReview(userID, reviewerID uuid.UUID, status string, exclude *bool, notes *string) (bool, error) {
+	result, err := r.db.Exec(`
+		UPDATE anticheat_reviews SET
+			status = $3,
+			exclude_from_leaderboards = COALESCE($4, exclude_from_leaderboards),
+			notes = COALESCE($5, notes),
+			reviewed_by = $2,
+			reviewed_at = CURRENT_TIMESTAMP
+		WHERE user_id = $1
+	`, userID, reviewerID, status, exclude, notes)
+	if err != nil {
+		return false, fmt.Errorf("failed to update anti-cheat review: %w", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to update anti-cheat review: %w", err)
+	}
+	return n > 0, nil
+}
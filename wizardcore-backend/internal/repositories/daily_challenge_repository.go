@@ -0,0 +1,401 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yourusername/wizardcore-backend/internal/daily"
+	"github.com/yourusername/wizardcore-backend/internal/models"
+)
+
+type DailyChallengeRepository struct {
+	db     *sql.DB
+	xpRepo *XPRepository
+}
+
+func NewDailyChallengeRepository(db *sql.DB, xpRepo *XPRepository) *DailyChallengeRepository {
+	return &DailyChallengeRepository{db: db, xpRepo: xpRepo}
+}
+
+const dateLayout = "2006-01-02"
+
+const dailyChallengeColumns = `
+	dc.challenge_date, dc.exercise_id, e.title, dc.difficulty, dc.bonus_xp,
+	(SELECT COUNT(*) FROM daily_challenge_attempts a
+	 WHERE a.challenge_date = dc.challenge_date AND a.completed_at IS NOT NULL),
+	dc.created_at
+`
+
+func scanDailyChallenge(row interface{ Scan(...interface{}) error }, extra ...interface{}) (*models.DailyChallenge, error) {
+	var c models.DailyChallenge
+	var date time.Time
+	dest := []interface{}{
+		&date,
+		&c.ExerciseID,
+		&c.ExerciseTitle,
+		&c.Difficulty,
+		&c.BonusXP,
+		&c.Completions,
+		&c.CreatedAt,
+	}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+	c.Date = date.Format(dateLayout)
+	return &c, nil
+}
+
+const dailyAttemptColumns = `
+	challenge_date, user_id, started_at, completed_at, solve_seconds, submission_id, bonus_xp
+`
+
+func scanDailyAttempt(row interface{ Scan(...interface{}) error }) (*models.DailyChallengeAttempt, error) {
+	var a models.DailyChallengeAttempt
+	var date time.Time
+	var submissionID uuid.NullUUID
+	err := row.Scan(
+		&date,
+		&a.UserID,
+		&a.StartedAt,
+		&a.CompletedAt,
+		&a.SolveSeconds,
+		&submissionID,
+		&a.BonusXP,
+	)
+	if err != nil {
+		return nil, err
+	}
+	a.Date = date.Format(dateLayout)
+	if submissionID.Valid {
+		a.SubmissionID = &submissionID.UUID
+	}
+	return &a, nil
+}
+
+// FindChallenge returns the challenge of a day
+func (r *DailyChallengeRepository) FindChallenge(day time.Time) (*models.DailyChallenge, error) {
+	c, err := scanDailyChallenge(r.db.QueryRow(`
+		SELECT `+dailyChallengeColumns+`
+		FROM daily_challenges dc
+		JOIN exercises e ON e.id = dc.exercise_id
+		WHERE dc.challenge_date = $1
+	`, day.Format(dateLayout)))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find daily challenge: %w", err)
+	}
+	return c, nil
+}
+
+// CandidateExerciseIDs returns the published exercises of a difficulty that
+// have not been a daily challenge since the given day, or ever if since is nil
+func (r *DailyChallengeRepository) CandidateExerciseIDs(difficulty string, since *time.Time) ([]uuid.UUID, error) {
+	var sinceDate interface{}
+	if since != nil {
+		sinceDate = since.Format(dateLayout)
+	}
+	rows, err := r.db.Query(`
+		SELECT e.id FROM exercises e
+		WHERE e.difficulty = $1
+		  AND COALESCE(e.status, 'published') = 'published'
+		  AND NOT EXISTS (
+			SELECT 1 FROM daily_challenges dc
+			WHERE dc.exercise_id = e.id AND ($2::date IS NULL OR dc.challenge_date >= $2::date)
+		  )
+	`, difficulty, sinceDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list daily challenge candidates: %w", err)
+	}
+	defer rows.Close()
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan daily challenge candidate: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// CreateChallenge stores the challenge of a day unless one already exists
+func (r *DailyChallengeRepository) CreateChallenge(day time.Time, exerciseID uuid.UUID, difficulty string, bonusXP int) error {
+	_, err := r.db.Exec(`
+		INSERT INTO daily_challenges (challenge_date, exercise_id, difficulty, bonus_xp)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (challenge_date) DO NOTHING
+	`, day.Format(dateLayout), exerciseID, difficulty, bonusXP)
+	if err != nil {
+		return fmt.Errorf("failed to create daily challenge: %w", err)
+	}
+	return nil
+}
+
+// ListChallenges returns the challenges up to and including a day, newest
+// first, each with the user's attempt if they made one
+func (r *DailyChallengeRepository) ListChallenges(userID uuid.UUID, through time.Time, limit, offset int) ([]models.DailyChallenge, int, error) {
+	var total int
+	err := r.db.QueryRow(`
+		SELECT COUNT(*) FROM daily_challenges WHERE challenge_date <= $1
+	`, through.Format(dateLayout)).Scan(&total)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count daily challenges: %w", err)
+	}
+
+	rows, err := r.db.Query(`
+		SELECT `+dailyChallengeColumns+`,
+			a.started_at, a.completed_at, a.solve_seconds, a.submission_id, COALESCE(a.bonus_xp, 0)
+		FROM daily_challenges dc
+		JOIN exercises e ON e.id = dc.exercise_id
+		LEFT JOIN daily_challenge_attempts a ON a.challenge_date = dc.challenge_date AND a.user_id = $1
+		WHERE dc.challenge_date <= $2
+		ORDER BY dc.challenge_date DESC
+		LIMIT $3 OFFSET $4
+	`, userID, through.Format(dateLayout), limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list daily challenges: %w", err)
+	}
+	defer rows.Close()
+
+	challenges := []models.DailyChallenge{}
+	for rows.Next() {
+		var startedAt, completedAt sql.NullTime
+		var solveSeconds sql.NullInt64
+		var submissionID uuid.NullUUID
+		var bonusXP int
+		c, err := scanDailyChallenge(rows, &startedAt, &completedAt, &solveSeconds, &submissionID, &bonusXP)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan daily challenge: %w", err)
+		}
+		if startedAt.Valid {
+			a := &models.DailyChallengeAttempt{Date: c.Date, UserID: userID, StartedAt: startedAt.Time, BonusXP: bonusXP}
+			if completedAt.Valid {
+				a.CompletedAt = &completedAt.Time
+			}
+			if solveSeconds.Valid {
+				s := int(solveSeconds.Int64)
+				a.SolveSeconds = &s
+			}
+			if submissionID.Valid {
+				a.SubmissionID = &submissionID.UUID
+			}
+			c.Attempt = a
+		}
+		challenges = append(challenges, *c)
+	}
+	return challenges, total, rows.Err()
+}
+
+// GetAttempt returns a user's attempt at the challenge of a day
+func (r *DailyChallengeRepository) GetAttempt(day time.Time, userID uuid.UUID) (*models.DailyChallengeAttempt, error) {
+	a, err := scanDailyAttempt(r.db.QueryRow(`
+		SELECT `+dailyAttemptColumns+`
+		FROM daily_challenge_attempts
+		WHERE challenge_date = $1 AND user_id = $2
+	`, day.Format(dateLayout), userID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get daily challenge attempt: %w", err)
+	}
+	return a, nil
+}
+
+// StartAttempt starts the clock on a user's attempt at the challenge of a
+// day. Starting again keeps the first start.
+func (r *DailyChallengeRepository) StartAttempt(day time.Time, userID uuid.UUID, now time.Time) (*models.DailyChallengeAttempt, error) {
+	_, err := r.db.Exec(`
+		INSERT INTO daily_challenge_attempts (challenge_date, user_id, started_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (challenge_date, user_id) DO NOTHING
+	`, day.Format(dateLayout), userID, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start daily challenge attempt: %w", err)
+	}
+	return r.GetAttempt(day, userID)
+}
+
+// CompleteAttempt records the first accepted submission of a user on the
+// challenge of a day, extends their daily challenge streak and credits the
+// bonus XP. An attempt that was never started is timed from the start of
+// the day. It returns nil if the user had already completed the challenge.
+func (r *DailyChallengeRepository) CompleteAttempt(day time.Time, userID uuid.UUID, submissionID uuid.UUID, completedAt time.Time, bonusXP int) (*models.DailyChallengeCompletion, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	date := day.Format(dateLayout)
+	if _, err := tx.Exec(`
+		INSERT INTO daily_challenge_attempts (challenge_date, user_id, started_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (challenge_date, user_id) DO NOTHING
+	`, date, userID, daily.Day(day)); err != nil {
+		return nil, fmt.Errorf("failed to create daily challenge attempt: %w", err)
+	}
+	attempt, err := scanDailyAttempt(tx.QueryRow(`
+		SELECT `+dailyAttemptColumns+`
+		FROM daily_challenge_attempts
+		WHERE challenge_date = $1 AND user_id = $2
+		FOR UPDATE
+	`, date, userID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock daily challenge attempt: %w", err)
+	}
+	if attempt.CompletedAt != nil {
+		return nil, nil
+	}
+
+	solveSeconds := int(completedAt.Sub(attempt.StartedAt).Seconds())
+	if solveSeconds < 0 {
+		solveSeconds = 0
+	}
+	attempt.CompletedAt = &completedAt
+	attempt.SolveSeconds = &solveSeconds
+	attempt.SubmissionID = &submissionID
+	attempt.BonusXP = bonusXP
+	if _, err := tx.Exec(`
+		UPDATE daily_challenge_attempts
+		SET completed_at = $3, solve_seconds = $4, submission_id = $5, bonus_xp = $6
+		WHERE challenge_date = $1 AND user_id = $2
+	`, date, userID, completedAt, solveSeconds, submissionID, bonusXP); err != nil {
+		return nil, fmt.Errorf("failed to complete daily challenge attempt: %w", err)
+	}
+
+	if _, err := tx.Exec(`
+		INSERT INTO daily_challenge_streaks (user_id) VALUES ($1)
+		ON CONFLICT (user_id) DO NOTHING
+	`, userID); err != nil {
+		return nil, fmt.Errorf("failed to create daily challenge streak: %w", err)
+	}
+	streak := models.DailyChallengeStreak{UserID: userID}
+	err = tx.QueryRow(`
+		SELECT current_streak, longest_streak, last_completed_date
+		FROM daily_challenge_streaks WHERE user_id = $1
+		FOR UPDATE
+	`, userID).Scan(&streak.CurrentStreak, &streak.LongestStreak, &streak.LastCompletedDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock daily challenge streak: %w", err)
+	}
+	streak.CurrentStreak = daily.NextStreak(streak.LastCompletedDate, streak.CurrentStreak, day)
+	if streak.CurrentStreak > streak.LongestStreak {
+		streak.LongestStreak = streak.CurrentStreak
+	}
+	last := daily.Day(day)
+	if streak.LastCompletedDate == nil || last.After(*streak.LastCompletedDate) {
+		streak.LastCompletedDate = &last
+	}
+	if _, err := tx.Exec(`
+		UPDATE daily_challenge_streaks
+		SET current_streak = $2, longest_streak = $3, last_completed_date = $4
+		WHERE user_id = $1
+	`, userID, streak.CurrentStreak, streak.LongestStreak, streak.LastCompletedDate.Format(dateLayout)); err != nil {
+		return nil, fmt.Errorf("failed to update daily challenge streak: %w", err)
+	}
+
+	var awards []models.XPTransaction
+	if bonusXP > 0 {
+		t := &models.XPTransaction{
+			UserID:         userID,
+			Amount:         bonusXP,
+			SourceType:     models.XPSourceDailyChallenge,
+			SourceID:       &submissionID,
+			IdempotencyKey: "daily_challenge:" + date + ":" + userID.String(),
+			CreatedAt:      completedAt,
+		}
+		awarded, err := awardXP(tx, t)
+		if err != nil {
+			return nil, fmt.Errorf("failed to credit daily challenge XP: %w", err)
+		}
+		if awarded {
+			awards = append(awards, *t)
+		}
+	}
+
+	if err := r.xpRepo.commit(tx, awards); err != nil {
+		return nil, err
+	}
+	return &models.DailyChallengeCompletion{Attempt: *attempt, Streak: streak}, nil
+}
+
+// GetStreak returns a user's stored daily challenge streak
+func (r *DailyChallengeRepository) GetStreak(userID uuid.UUID) (*models.DailyChallengeStreak, error) {
+	streak := &models.DailyChallengeStreak{UserID: userID}
+	err := r.db.QueryRow(`
+		SELECT current_streak, longest_streak, last_completed_date
+		FROM daily_challenge_streaks WHERE user_id = $1
+	`, userID).Scan(&streak.CurrentStreak, &streak.LongestStreak, &streak.LastCompletedDate)
+	if err == sql.ErrNoRows {
+		return streak, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get daily challenge streak: %w", err)
+	}
+	return streak, nil
+}
+
+// GetLeaderboard returns the players who solved the challenge of a day,
+// fastest first
+func (r *DailyChallengeRepository) GetLeaderboard(day time.Time, limit, offset int) ([]models.DailyLeaderboardEntry, int, error) {
+	date := day.Format(dateLayout)
+	var total int
+	err := r.db.QueryRow(`
+		SELECT COUNT(*) FROM daily_challenge_attempts
+		WHERE challenge_date = $1 AND completed_at IS NOT NULL
+	`, date).Scan(&total)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count daily leaderboard: %w", err)
+	}
+
+	rows, err := r.db.Query(`
+		SELECT a.user_id, u.display_name, u.avatar_url, a.solve_seconds, a.completed_at
+		FROM daily_challenge_attempts a
+		JOIN users u ON u.id = a.user_id
+		WHERE a.challenge_date = $1 AND a.completed_at IS NOT NULL
+		ORDER BY a.solve_seconds, a.completed_at, a.user_id
+		LIMIT $2 OFFSET $3
+	`, date, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query daily leaderboard: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []models.DailyLeaderboardEntry{}
+	for rows.Next() {
+		var entry models.DailyLeaderboardEntry
+		if err := rows.Scan(&entry.UserID, &entry.Username, &entry.AvatarURL, &entry.SolveSeconds, &entry.CompletedAt); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan daily leaderboard entry: %w", err)
+		}
+		entry.Rank = offset + len(entries) + 1
+		entries = append(entries, entry)
+	}
+	return entries, total, rows.Err()
+}
+
+// GetRank returns the position of a user on the leaderboard of a day, or 0
+// if they have not solved the challenge
+func (r *DailyChallengeRepository) GetRank(day time.Time, userID uuid.UUID) (int, error) {
+	var rank int
+	err := r.db.QueryRow(`
+		SELECT 1 + (
+			SELECT COUNT(*) FROM daily_challenge_attempts other
+			WHERE other.challenge_date = mine.challenge_date AND other.completed_at IS NOT NULL
+			  AND (other.solve_seconds, other.completed_at, other.user_id) < (mine.solve_seconds, mine.completed_at, mine.user_id)
+		)
+		FROM daily_challenge_attempts mine
+		WHERE mine.challenge_date = $1 AND mine.user_id = $2 AND mine.completed_at IS NOT NULL
+	`, day.Format(dateLayout), userID).Scan(&rank)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to get daily leaderboard rank: %w", err)
+	}
+	return rank, nil
+}
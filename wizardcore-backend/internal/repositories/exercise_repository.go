@@ -250,8 +250,90 @@ func (r *ExerciseRepository) UpdateStats(exerciseID uuid.UUID, concurrentSolvers
 	return nil
 }
 
+// IncrementSubmissions counts a graded submission on an exercise
+func (r *ExerciseRepository) IncrementSubmissions(exerciseID uuid.UUID) error {
+	query := `
+		UPDATE exercises
+		SET total_submissions = COALESCE(total_submissions, 0) + 1,
+		    updated_at = $2
+		WHERE id = $1
+	`
+	_, err := r.db.Exec(query, exerciseID, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to update exercise stats: %w", err)
+	}
+	return nil
+}
+
+// IsPublished reports whether an exercise exists and is published
+func (r *ExerciseRepository) IsPublished(exerciseID uuid.UUID) (bool, error) {
+	var published bool
+	err := r.db.QueryRow(`
+		SELECT EXISTS (
+			SELECT 1 FROM exercises
+			WHERE id = $1 AND COALESCE(status, 'published') = 'published'
+		)
+	`, exerciseID).Scan(&published)
+	if err != nil {
+		return false, fmt.Errorf("failed to check exercise status: %w", err)
+	}
+	return published, nil
+}
+
+// FindRandomExerciseID returns the ID of a random published exercise in the
+// given language and difficulty; nil filters match anything. It returns nil
+// if no exercise matches.
+func (r *ExerciseRepository) FindRandomExerciseID(languageID *int, difficulty *string) (*uuid.UUID, error) {
+	query := `
+		SELECT id FROM exercises
+		WHERE ($1::int IS NULL OR language_id = $1)
+		  AND ($2::varchar IS NULL OR difficulty = $2)
+		  AND COALESCE(status, 'published') = 'published'
+		ORDER BY RANDOM()
+		LIMIT 1
+	`
+	var id uuid.UUID
+	err := r.db.QueryRow(query, languageID, difficulty).Scan(&id)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to pick exercise: %w", err)
+	}
+	return &id, nil
+}
+
+// FindRandomExerciseIDs returns the IDs of up to n distinct random published
+// exercises
+func (r *ExerciseRepository) FindRandomExerciseIDs(n int) ([]uuid.UUID, error) {
+	rows, err := r.db.Query(`
+		SELECT id FROM exercises
+		WHERE COALESCE(status, 'published') = 'published'
+		ORDER BY RANDOM()
+		LIMIT $1
+	`, n)
+	if err != nil {
+		return nil, fmt.Errorf("failed to pick exercises: %w", err)
+	}
+	defer rows.Close()
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan exercise id: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
 // GetRandomExercise returns a random exercise from the database
 func (r *ExerciseRepository) GetRandomExercise() (*models.Exercise, error) {
+	return r.GetRandomExerciseExcluding(nil)
+}
+
+// GetRandomExerciseExcluding returns a random exercise other than the given ones
+func (r *ExerciseRepository) GetRandomExerciseExcluding(exclude []uuid.UUID) (*models.Exercise, error) {
 	query := `
 		SELECT id, module_id, title, difficulty, points, time_limit_minutes,
 		       sort_order, objectives, content, examples, description,
@@ -259,15 +341,20 @@ func (r *ExerciseRepository) GetRandomExercise() (*models.Exercise, error) {
 		       tags, concurrent_solvers, total_submissions, total_completions,
 		       average_completion_time, created_at, updated_at
 		FROM exercises
+		WHERE NOT (id = ANY($1::uuid[]))
 		ORDER BY RANDOM()
 		LIMIT 1
 	`
+	excludeIDs := make(pq.StringArray, len(exclude))
+	for i, id := range exclude {
+		excludeIDs[i] = id.String()
+	}
 	var e models.Exercise
 	var timeLimit, avgCompletionTime sql.NullInt64
 	var content, description, starterCode, solutionCode sql.NullString
 	var examplesBytes []byte
 	var objectives, constraints, hints, tags pq.StringArray
-	err := r.db.QueryRow(query).Scan(
+	err := r.db.QueryRow(query, excludeIDs).Scan(
 		&e.ID,
 		&e.ModuleID,
 		&e.Title,
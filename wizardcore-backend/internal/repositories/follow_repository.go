@@ -0,0 +1,174 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/yourusername/wizardcore-backend/internal/models"
+)
+
+// ErrProfilePrivate is returned when the viewer may not see a user's
+// followers or following because their profile is not public
+var ErrProfilePrivate = errors.New("profile is private")
+
+// Users whose profile is not public are shown only to themselves and to
+// their friends
+const followVisibleTo = `(
+	u.id = $%[1]d
+	OR COALESCE((SELECT up.public_profile FROM user_preferences up WHERE up.user_id = u.id), true)
+	OR (
+		EXISTS (SELECT 1 FROM user_follows a WHERE a.follower_id = u.id AND a.followee_id = $%[1]d)
+		AND EXISTS (SELECT 1 FROM user_follows b WHERE b.follower_id = $%[1]d AND b.followee_id = u.id)
+	)
+)`
+
+type FollowRepository struct {
+	db *sql.DB
+}
+
+func NewFollowRepository(db *sql.DB) *FollowRepository {
+	return &FollowRepository{db: db}
+}
+
+// Follow makes followerID follow followeeID. It reports whether the follow
+// is new.
+func (r *FollowRepository) Follow(followerID, followeeID uuid.UUID) (bool, error) {
+	result, err := r.db.Exec(`
+		INSERT INTO user_follows (follower_id, followee_id)
+		VALUES ($1, $2)
+		ON CONFLICT DO NOTHING
+	`, followerID, followeeID)
+	if err != nil {
+		return false, fmt.Errorf("failed to follow user: %w", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to follow user: %w", err)
+	}
+	return n > 0, nil
+}
+
+func (r *FollowRepository) Unfollow(followerID, followeeID uuid.UUID) error {
+	_, err := r.db.Exec(`DELETE FROM user_follows WHERE follower_id = $1 AND followee_id = $2`, followerID, followeeID)
+	if err != nil {
+		return fmt.Errorf("failed to unfollow user: %w", err)
+	}
+	return nil
+}
+
+// GetStatus returns how the viewer and a user are related, with the user's
+// follower counts
+func (r *FollowRepository) GetStatus(viewerID, userID uuid.UUID) (*models.FollowStatus, error) {
+	status := &models.FollowStatus{UserID: userID}
+	err := r.db.QueryRow(`
+		SELECT
+			EXISTS (SELECT 1 FROM user_follows WHERE follower_id = $1 AND followee_id = $2),
+			EXISTS (SELECT 1 FROM user_follows WHERE follower_id = $2 AND followee_id = $1),
+			(SELECT COUNT(*) FROM user_follows WHERE followee_id = $2),
+			(SELECT COUNT(*) FROM user_follows WHERE follower_id = $2)
+	`, viewerID, userID).Scan(&status.Following, &status.FollowedBy, &status.FollowerCount, &status.FollowingCount)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get follow status: %w", err)
+	}
+	status.IsFriend = status.Following && status.FollowedBy
+	return status, nil
+}
+
+// CanView reports whether the viewer may see a user's follow lists: their
+// own, a public profile's or a friend's
+func (r *FollowRepository) CanView(viewerID, userID uuid.UUID) (bool, error) {
+	var visible bool
+	err := r.db.QueryRow(`SELECT `+fmt.Sprintf(followVisibleTo, 2)+` FROM users u WHERE u.id = $1`, userID, viewerID).Scan(&visible)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to check profile visibility: %w", err)
+	}
+	return visible, nil
+}
+
+// ListFollowers returns the users following userID, newest first, leaving
+// out private profiles the viewer may not see unless the list is their own
+func (r *FollowRepository) ListFollowers(userID, viewerID uuid.UUID, limit, offset int) ([]models.FollowUser, int, error) {
+	return r.list(`f.followee_id = $1`, `f.follower_id`, userID, viewerID, limit, offset)
+}
+
+// ListFollowing returns the users userID follows, newest first, leaving out
+// private profiles the viewer may not see unless the list is their own
+func (r *FollowRepository) ListFollowing(userID, viewerID uuid.UUID, limit, offset int) ([]models.FollowUser, int, error) {
+	return r.list(`f.follower_id = $1`, `f.followee_id`, userID, viewerID, limit, offset)
+}
+
+// ListFriends returns the users who follow userID and whom userID follows
+// back, by when userID followed them
+func (r *FollowRepository) ListFriends(userID uuid.UUID, limit, offset int) ([]models.FollowUser, int, error) {
+	return r.list(`f.follower_id = $1 AND EXISTS (
+		SELECT 1 FROM user_follows back WHERE back.follower_id = f.followee_id AND back.followee_id = $1
+	)`, `f.followee_id`, userID, userID, limit, offset)
+}
+
+// list pages through the follows matching where, listing the user in the
+// other column
+func (r *FollowRepository) list(where, other string, userID, viewerID uuid.UUID, limit, offset int) ([]models.FollowUser, int, error) {
+	from := `
+		FROM user_follows f
+		JOIN users u ON u.id = ` + other + `
+		WHERE ` + where + `
+		AND ($1 = $2 OR ` + fmt.Sprintf(followVisibleTo, 2) + `)
+	`
+	var total int
+	if err := r.db.QueryRow(`SELECT COUNT(*)`+from, userID, viewerID).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count follows: %w", err)
+	}
+	rows, err := r.db.Query(`
+		SELECT
+			u.id,
+			COALESCE(u.display_name, ''),
+			u.avatar_url,
+			f.created_at,
+			EXISTS (SELECT 1 FROM user_follows a WHERE a.follower_id = $1 AND a.followee_id = u.id)
+			AND EXISTS (SELECT 1 FROM user_follows b WHERE b.follower_id = u.id AND b.followee_id = $1)
+		`+from+`
+		ORDER BY f.created_at DESC, u.id
+		LIMIT $3 OFFSET $4
+	`, userID, viewerID, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list follows: %w", err)
+	}
+	defer rows.Close()
+	users := []models.FollowUser{}
+	for rows.Next() {
+		var u models.FollowUser
+		if err := rows.Scan(&u.UserID, &u.Username, &u.AvatarURL, &u.FollowedAt, &u.IsFriend); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan follow: %w", err)
+		}
+		users = append(users, u)
+	}
+	return users, total, rows.Err()
+}
+
+// ListFriendIDs returns the IDs of all of a user's friends
+func (r *FollowRepository) ListFriendIDs(userID uuid.UUID) ([]uuid.UUID, error) {
+	rows, err := r.db.Query(`
+		SELECT f.followee_id
+		FROM user_follows f
+		JOIN user_follows back ON back.follower_id = f.followee_id AND back.followee_id = f.follower_id
+		WHERE f.follower_id = $1
+	`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list friends: %w", err)
+	}
+	defer rows.Close()
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan friend: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
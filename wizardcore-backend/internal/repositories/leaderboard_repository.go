@@ -2,8 +2,11 @@ package repositories
 
 import (
 	"database/sql"
+	"fmt"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 	"github.com/yourusername/wizardcore-backend/internal/models"
 )
 
@@ -34,8 +37,8 @@ func (r *LeaderboardRepository) GetLeaderboard(timeframe string, pathwayID *uuid
 		FROM leaderboard_entries le
 		JOIN users u ON le.user_id = u.id
 		WHERE le.timeframe = $1
-		AND ($2::uuid IS NULL OR le.pathway_id = $2)
-		ORDER BY le.rank
+		AND le.pathway_id IS NOT DISTINCT FROM $2::uuid
+		ORDER BY le.rank, le.user_id
 		LIMIT $3 OFFSET $4
 	`
 	var rows *sql.Rows
@@ -82,7 +85,7 @@ func (r *LeaderboardRepository) GetLeaderboard(timeframe string, pathwayID *uuid
 			rank := int(previousRank.Int64)
 			le.PreviousRank = &rank
 		}
-		// TODO: set country code, trend, is_current_user (requires additional joins)
+		// TODO: set country code (requires additional joins)
 		entries = append(entries, le)
 	}
 	return entries, nil
@@ -91,17 +94,21 @@ func (r *LeaderboardRepository) GetLeaderboard(timeframe string, pathwayID *uuid
 // GetLeaderboardStats returns statistics about the leaderboard
 func (r *LeaderboardRepository) GetLeaderboardStats(timeframe string, pathwayID *uuid.UUID) (*models.LeaderboardStats, error) {
 	query := `
-		SELECT 
-			COUNT(DISTINCT le.user_id) as total_learners,
-			MAX(le.xp) as top_xp,
-			u.display_name as top_username
+		SELECT
+			COUNT(*) as total_learners,
+			COALESCE(MAX(le.xp), 0) as top_xp,
+			(
+				SELECT u.display_name
+				FROM leaderboard_entries top
+				JOIN users u ON top.user_id = u.id
+				WHERE top.timeframe = $1
+				AND top.pathway_id IS NOT DISTINCT FROM $2::uuid
+				ORDER BY top.rank, top.user_id
+				LIMIT 1
+			) as top_username
 		FROM leaderboard_entries le
-		JOIN users u ON le.user_id = u.id
 		WHERE le.timeframe = $1
-		AND ($2::uuid IS NULL OR le.pathway_id = $2)
-		GROUP BY u.display_name
-		ORDER BY le.xp DESC
-		LIMIT 1
+		AND le.pathway_id IS NOT DISTINCT FROM $2::uuid
 	`
 	var stats models.LeaderboardStats
 	var topUsername sql.NullString
@@ -122,18 +129,186 @@ func (r *LeaderboardRepository) GetLeaderboardStats(timeframe string, pathwayID
 	if topUsername.Valid {
 		stats.TopUsername = topUsername.String
 	}
-	// TODO: compute current user rank and change
+	// The service fills in the current user's rank and change
 	stats.CurrentUserRank = 0
 	stats.CurrentUserChange = 0
 	stats.CountryCount = 0 // would need country data
 	return &stats, nil
 }
 
-// UpdateLeaderboard recalculates leaderboard entries for a given timeframe
-func (r *LeaderboardRepository) UpdateLeaderboard(timeframe string) error {
-	// This is a complex operation that should be run as a cron job
-	// For now, we'll just log that it's called
-	return nil
+// ListPathwayIDs returns the pathways that have their own leaderboards
+func (r *LeaderboardRepository) ListPathwayIDs() ([]uuid.UUID, error) {
+	rows, err := r.db.Query(`SELECT id FROM pathways ORDER BY sort_order, id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pathways: %w", err)
+	}
+	defer rows.Close()
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan pathway id: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// How long leaderboard_history keeps a rank after a newer one replaced it.
+// Rank history is served for up to 365 days.
+const leaderboardHistoryRetention = 366 * 24 * time.Hour
+
+// UpdateLeaderboard recalculates the leaderboard of a timeframe, globally
+// when pathwayID is nil or for one pathway, from the XP ledger. XP counts
+// from since, or over all time when since is nil; opening balances, which
+// have no date, only count over all time.
+//
+// The ranking is replaced in a single transaction, so readers keep seeing
+// the previous ranking until it commits, and an advisory lock keeps two
+// recalculations of the same leaderboard from interleaving. Entries whose
+// rank or XP changed are appended to leaderboard_history, and previous_rank
+// is the rank the user held historyWindow before now. History older than
+// leaderboardHistoryRetention is pruned, except for the rank each user held
+// at its start. Accounts excluded by an open anti-cheat review are left out.
+func (r *LeaderboardRepository) UpdateLeaderboard(timeframe string, pathwayID *uuid.UUID, since *time.Time, now time.Time, historyWindow time.Duration) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	scope := "global"
+	if pathwayID != nil {
+		scope = pathwayID.String()
+	}
+	if _, err := tx.Exec(`SELECT pg_advisory_xact_lock(hashtext($1))`, "leaderboard:"+timeframe+":"+scope); err != nil {
+		return fmt.Errorf("failed to lock leaderboard: %w", err)
+	}
+
+	if _, err := tx.Exec(`
+		CREATE TEMP TABLE leaderboard_ranking (
+			user_id UUID PRIMARY KEY,
+			xp INTEGER NOT NULL,
+			rank INTEGER NOT NULL
+		) ON COMMIT DROP
+	`); err != nil {
+		return fmt.Errorf("failed to create leaderboard ranking: %w", err)
+	}
+	if _, err := tx.Exec(`
+		INSERT INTO leaderboard_ranking (user_id, xp, rank)
+		SELECT x.user_id, x.xp, RANK() OVER (ORDER BY x.xp DESC)
+		FROM (
+			SELECT t.user_id, SUM(t.amount)::int AS xp
+			FROM xp_transactions t
+			WHERE ($1::timestamp IS NULL OR (t.created_at >= $1 AND t.source_type <> 'opening_balance'))
+			AND ($2::uuid IS NULL OR t.pathway_id = $2)
+			GROUP BY t.user_id
+			HAVING SUM(t.amount) > 0
+		) x
+		WHERE NOT EXISTS (`+excludedFromLeaderboards+`)
+	`, since, pathwayID); err != nil {
+		return fmt.Errorf("failed to rank leaderboard: %w", err)
+	}
+
+	if _, err := tx.Exec(`
+		INSERT INTO leaderboard_history (user_id, timeframe, pathway_id, rank, xp, recorded_at)
+		SELECT r.user_id, $1, $2::uuid, r.rank, r.xp, $3
+		FROM leaderboard_ranking r
+		LEFT JOIN leaderboard_entries le ON le.user_id = r.user_id
+			AND le.timeframe = $1
+			AND le.pathway_id IS NOT DISTINCT FROM $2::uuid
+		WHERE le.id IS NULL OR le.rank <> r.rank OR le.xp <> r.xp
+	`, timeframe, pathwayID, now); err != nil {
+		return fmt.Errorf("failed to record leaderboard history: %w", err)
+	}
+	if _, err := tx.Exec(`
+		DELETE FROM leaderboard_history h
+		WHERE h.timeframe = $1
+		AND h.pathway_id IS NOT DISTINCT FROM $2::uuid
+		AND h.recorded_at < $3
+		AND EXISTS (
+			SELECT 1 FROM leaderboard_history n
+			WHERE n.user_id = h.user_id
+			AND n.timeframe = h.timeframe
+			AND n.pathway_id IS NOT DISTINCT FROM h.pathway_id
+			AND n.recorded_at > h.recorded_at
+			AND n.recorded_at <= $3
+		)
+	`, timeframe, pathwayID, now.Add(-leaderboardHistoryRetention)); err != nil {
+		return fmt.Errorf("failed to prune leaderboard history: %w", err)
+	}
+
+	if _, err := tx.Exec(`
+		INSERT INTO leaderboard_entries (
+			user_id, timeframe, pathway_id, rank, previous_rank, xp, streak_days, badge_count, updated_at
+		)
+		SELECT
+			r.user_id, $1, $2::uuid, r.rank,
+			(
+				SELECT h.rank FROM leaderboard_history h
+				WHERE h.user_id = r.user_id
+				AND h.timeframe = $1
+				AND h.pathway_id IS NOT DISTINCT FROM $2::uuid
+				AND h.recorded_at <= $4
+				ORDER BY h.recorded_at DESC
+				LIMIT 1
+			),
+			r.xp,
+			COALESCE(u.current_streak, 0),
+			(SELECT COUNT(*) FROM user_achievements ua WHERE ua.user_id = r.user_id AND ua.earned_at IS NOT NULL),
+			$3
+		FROM leaderboard_ranking r
+		JOIN users u ON r.user_id = u.id
+		ON CONFLICT (user_id, timeframe, (COALESCE(pathway_id, '00000000-0000-0000-0000-000000000000'::uuid)))
+		DO UPDATE SET
+			rank = EXCLUDED.rank,
+			previous_rank = EXCLUDED.previous_rank,
+			xp = EXCLUDED.xp,
+			streak_days = EXCLUDED.streak_days,
+			badge_count = EXCLUDED.badge_count,
+			updated_at = EXCLUDED.updated_at
+	`, timeframe, pathwayID, now, now.Add(-historyWindow)); err != nil {
+		return fmt.Errorf("failed to store leaderboard entries: %w", err)
+	}
+
+	// Users without XP in the window leave the board
+	if _, err := tx.Exec(`
+		DELETE FROM leaderboard_entries le
+		WHERE le.timeframe = $1
+		AND le.pathway_id IS NOT DISTINCT FROM $2::uuid
+		AND NOT EXISTS (SELECT 1 FROM leaderboard_ranking r WHERE r.user_id = le.user_id)
+	`, timeframe, pathwayID); err != nil {
+		return fmt.Errorf("failed to prune leaderboard entries: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// GetRankHistory returns a user's recorded ranks on a leaderboard since a
+// point in time, oldest first
+func (r *LeaderboardRepository) GetRankHistory(userID uuid.UUID, timeframe string, pathwayID *uuid.UUID, since time.Time) ([]models.LeaderboardHistoryPoint, error) {
+	rows, err := r.db.Query(`
+		SELECT rank, xp, recorded_at
+		FROM leaderboard_history
+		WHERE user_id = $1
+		AND timeframe = $2
+		AND pathway_id IS NOT DISTINCT FROM $3::uuid
+		AND recorded_at >= $4
+		ORDER BY recorded_at
+	`, userID, timeframe, pathwayID, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rank history: %w", err)
+	}
+	defer rows.Close()
+	points := []models.LeaderboardHistoryPoint{}
+	for rows.Next() {
+		var p models.LeaderboardHistoryPoint
+		if err := rows.Scan(&p.Rank, &p.XP, &p.RecordedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan rank history: %w", err)
+		}
+		points = append(points, p)
+	}
+	return points, rows.Err()
 }
 
 // GetTotalCount returns total number of entries for pagination
@@ -142,7 +317,7 @@ func (r *LeaderboardRepository) GetTotalCount(timeframe string, pathwayID *uuid.
 		SELECT COUNT(*)
 		FROM leaderboard_entries
 		WHERE timeframe = $1
-		AND ($2::uuid IS NULL OR pathway_id = $2)
+		AND pathway_id IS NOT DISTINCT FROM $2::uuid
 	`
 	var count int
 	var pathwayParam interface{}
@@ -153,4 +328,145 @@ func (r *LeaderboardRepository) GetTotalCount(timeframe string, pathwayID *uuid.
 	}
 	err := r.db.QueryRow(query, timeframe, pathwayParam).Scan(&count)
 	return count, err
-}
\ No newline at end of file
+}
+
+// ListScores returns the XP of everyone on a leaderboard as of its last
+// recalculation
+func (r *LeaderboardRepository) ListScores(timeframe string, pathwayID *uuid.UUID) ([]models.LeaderboardScore, error) {
+	rows, err := r.db.Query(`
+		SELECT user_id, xp
+		FROM leaderboard_entries
+		WHERE timeframe = $1
+		AND pathway_id IS NOT DISTINCT FROM $2::uuid
+	`, timeframe, pathwayID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list leaderboard scores: %w", err)
+	}
+	defer rows.Close()
+	var scores []models.LeaderboardScore
+	for rows.Next() {
+		var score models.LeaderboardScore
+		if err := rows.Scan(&score.UserID, &score.XP); err != nil {
+			return nil, fmt.Errorf("failed to scan leaderboard score: %w", err)
+		}
+		scores = append(scores, score)
+	}
+	return scores, rows.Err()
+}
+
+// excludedFromLeaderboards selects the open anti-cheat review of x.user_id
+// that keeps the account off the leaderboards
+const excludedFromLeaderboards = `
+	SELECT 1 FROM anticheat_reviews ar
+	WHERE ar.user_id = x.user_id
+	AND ar.exclude_from_leaderboards AND ar.status <> 'cleared'
+`
+
+// IsExcluded reports whether an open anti-cheat review keeps a user off the
+// leaderboards
+func (r *LeaderboardRepository) IsExcluded(userID uuid.UUID) (bool, error) {
+	var excluded bool
+	err := r.db.QueryRow(`
+		SELECT EXISTS (`+excludedFromLeaderboards+`)
+		FROM (SELECT $1::uuid AS user_id) x
+	`, userID).Scan(&excluded)
+	if err != nil {
+		return false, fmt.Errorf("failed to check leaderboard exclusion: %w", err)
+	}
+	return excluded, nil
+}
+
+// FindExercisePathwayID returns the pathway an exercise belongs to through
+// its module, or nil if it is not part of one
+func (r *LeaderboardRepository) FindExercisePathwayID(exerciseID uuid.UUID) (*uuid.UUID, error) {
+	var pathwayID uuid.NullUUID
+	err := r.db.QueryRow(`
+		SELECT m.pathway_id
+		FROM exercises e
+		LEFT JOIN modules m ON e.module_id = m.id
+		WHERE e.id = $1
+	`, exerciseID).Scan(&pathwayID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find exercise pathway: %w", err)
+	}
+	if !pathwayID.Valid {
+		return nil, nil
+	}
+	return &pathwayID.UUID, nil
+}
+
+// GetUserEntries returns leaderboard entries for the given users, keyed by
+// user. Users the last recalculation did not rank are included with their
+// profile details and a zero rank and XP.
+func (r *LeaderboardRepository) GetUserEntries(timeframe string, pathwayID *uuid.UUID, userIDs []uuid.UUID) (map[uuid.UUID]models.LeaderboardEntry, error) {
+	entries := make(map[uuid.UUID]models.LeaderboardEntry, len(userIDs))
+	if len(userIDs) == 0 {
+		return entries, nil
+	}
+	rows, err := r.db.Query(`
+		SELECT
+			u.id,
+			COALESCE(u.display_name, ''),
+			u.avatar_url,
+			le.id,
+			le.rank,
+			le.previous_rank,
+			le.xp,
+			COALESCE(u.current_streak, 0),
+			(SELECT COUNT(*) FROM user_achievements ua WHERE ua.user_id = u.id AND ua.earned_at IS NOT NULL),
+			le.updated_at
+		FROM users u
+		LEFT JOIN leaderboard_entries le ON le.user_id = u.id
+			AND le.timeframe = $1
+			AND le.pathway_id IS NOT DISTINCT FROM $2::uuid
+		WHERE u.id = ANY($3::uuid[])
+	`, timeframe, pathwayID, pq.Array(userIDs))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get leaderboard entries: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var le models.LeaderboardEntry
+		var id uuid.NullUUID
+		var rank, previousRank, xp sql.NullInt64
+		var updatedAt sql.NullTime
+		if err := rows.Scan(&le.UserID, &le.Username, &le.AvatarURL, &id, &rank, &previousRank, &xp, &le.StreakDays, &le.BadgeCount, &updatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan leaderboard entry: %w", err)
+		}
+		le.ID = id.UUID
+		le.Timeframe = timeframe
+		le.PathwayID = pathwayID
+		le.Rank = int(rank.Int64)
+		le.XP = int(xp.Int64)
+		if previousRank.Valid {
+			previous := int(previousRank.Int64)
+			le.PreviousRank = &previous
+		}
+		le.UpdatedAt = updatedAt.Time
+		entries[le.UserID] = le
+	}
+	return entries, rows.Err()
+}
+
+// GetPosition returns the zero-based position of a ranked user in the order
+// GetLeaderboard pages through
+func (r *LeaderboardRepository) GetPosition(timeframe string, pathwayID *uuid.UUID, userID uuid.UUID) (int, error) {
+	var position int
+	err := r.db.QueryRow(`
+		SELECT COUNT(*)
+		FROM leaderboard_entries le
+		JOIN leaderboard_entries me ON me.user_id = $3
+			AND me.timeframe = le.timeframe
+			AND me.pathway_id IS NOT DISTINCT FROM le.pathway_id
+		WHERE le.timeframe = $1
+		AND le.pathway_id IS NOT DISTINCT FROM $2::uuid
+		AND (le.rank, le.user_id) < (me.rank, me.user_id)
+	`, timeframe, pathwayID, userID).Scan(&position)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get leaderboard position: %w", err)
+	}
+	return position, nil
+}
@@ -0,0 +1,306 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yourusername/wizardcore-backend/internal/league"
+	"github.com/yourusername/wizardcore-backend/internal/models"
+)
+
+type LeagueRepository struct {
+	db     *sql.DB
+	xpRepo *XPRepository
+}
+
+func NewLeagueRepository(db *sql.DB, xpRepo *XPRepository) *LeagueRepository {
+	return &LeagueRepository{db: db, xpRepo: xpRepo}
+}
+
+const leagueSeasonColumns = `id, starts_at, ends_at, status, closed_at, created_at`
+
+func scanLeagueSeason(row interface{ Scan(...interface{}) error }) (*models.LeagueSeason, error) {
+	var s models.LeagueSeason
+	var closedAt sql.NullTime
+	if err := row.Scan(&s.ID, &s.StartsAt, &s.EndsAt, &s.Status, &closedAt, &s.CreatedAt); err != nil {
+		return nil, err
+	}
+	if closedAt.Valid {
+		s.ClosedAt = &closedAt.Time
+	}
+	return &s, nil
+}
+
+// EnsureSeason returns the season starting at startsAt, creating it if needed
+func (r *LeagueRepository) EnsureSeason(startsAt, endsAt time.Time) (*models.LeagueSeason, error) {
+	if _, err := r.db.Exec(`
+		INSERT INTO league_seasons (starts_at, ends_at)
+		VALUES ($1, $2)
+		ON CONFLICT (starts_at) DO NOTHING
+	`, startsAt, endsAt); err != nil {
+		return nil, fmt.Errorf("failed to create league season: %w", err)
+	}
+	season, err := scanLeagueSeason(r.db.QueryRow(`SELECT `+leagueSeasonColumns+` FROM league_seasons WHERE starts_at = $1`, startsAt))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get league season: %w", err)
+	}
+	return season, nil
+}
+
+// ListDueSeasons returns the active seasons that have ended, oldest first
+func (r *LeagueRepository) ListDueSeasons(now time.Time) ([]models.LeagueSeason, error) {
+	rows, err := r.db.Query(`
+		SELECT `+leagueSeasonColumns+`
+		FROM league_seasons
+		WHERE status = 'active' AND ends_at <= $1
+		ORDER BY starts_at
+	`, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list due league seasons: %w", err)
+	}
+	defer rows.Close()
+	var seasons []models.LeagueSeason
+	for rows.Next() {
+		season, err := scanLeagueSeason(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan league season: %w", err)
+		}
+		seasons = append(seasons, *season)
+	}
+	return seasons, rows.Err()
+}
+
+// CurrentTier returns the tier a user starts their next season in: the one
+// their last closed season placed them in, or the bottom tier
+func (r *LeagueRepository) CurrentTier(userID uuid.UUID) (int, error) {
+	var tier int
+	err := r.db.QueryRow(`
+		SELECT m.new_tier
+		FROM league_memberships m
+		JOIN league_seasons s ON m.season_id = s.id
+		WHERE m.user_id = $1 AND m.new_tier IS NOT NULL
+		ORDER BY s.starts_at DESC
+		LIMIT 1
+	`, userID).Scan(&tier)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to get league tier: %w", err)
+	}
+	return tier, nil
+}
+
+// GetMembership returns the cohort and tier of a user in a season, or nil
+// if they have not joined it
+func (r *LeagueRepository) GetMembership(seasonID, userID uuid.UUID) (*models.LeagueCohort, error) {
+	var cohort models.LeagueCohort
+	err := r.db.QueryRow(`
+		SELECT cohort_id, season_id, tier
+		FROM league_memberships
+		WHERE season_id = $1 AND user_id = $2
+	`, seasonID, userID).Scan(&cohort.ID, &cohort.SeasonID, &cohort.Tier)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get league membership: %w", err)
+	}
+	return &cohort, nil
+}
+
+// Join places a user in a season at a tier: in the oldest cohort of that
+// tier with room, or in a new one. Joins to the same season and tier are
+// serialized so cohorts do not overfill. Joining twice keeps the first
+// place.
+func (r *LeagueRepository) Join(seasonID, userID uuid.UUID, tier, cohortSize int) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`SELECT pg_advisory_xact_lock(hashtext($1))`, fmt.Sprintf("league:%s:%d", seasonID, tier)); err != nil {
+		return fmt.Errorf("failed to lock league tier: %w", err)
+	}
+	var joined bool
+	if err := tx.QueryRow(`
+		SELECT EXISTS (SELECT 1 FROM league_memberships WHERE season_id = $1 AND user_id = $2)
+	`, seasonID, userID).Scan(&joined); err != nil {
+		return fmt.Errorf("failed to check league membership: %w", err)
+	}
+	if joined {
+		return nil
+	}
+
+	var cohortID uuid.UUID
+	err = tx.QueryRow(`
+		SELECT c.id
+		FROM league_cohorts c
+		WHERE c.season_id = $1 AND c.tier = $2
+		AND (SELECT COUNT(*) FROM league_memberships m WHERE m.cohort_id = c.id) < $3
+		ORDER BY c.created_at, c.id
+		LIMIT 1
+	`, seasonID, tier, cohortSize).Scan(&cohortID)
+	if err == sql.ErrNoRows {
+		err = tx.QueryRow(`
+			INSERT INTO league_cohorts (season_id, tier) VALUES ($1, $2) RETURNING id
+		`, seasonID, tier).Scan(&cohortID)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to find league cohort: %w", err)
+	}
+
+	if _, err := tx.Exec(`
+		INSERT INTO league_memberships (season_id, user_id, cohort_id, tier)
+		VALUES ($1, $2, $3, $4)
+	`, seasonID, userID, cohortID, tier); err != nil {
+		return fmt.Errorf("failed to join league: %w", err)
+	}
+	return tx.Commit()
+}
+
+// ListCohorts returns the cohorts of a season
+func (r *LeagueRepository) ListCohorts(seasonID uuid.UUID) ([]models.LeagueCohort, error) {
+	rows, err := r.db.Query(`
+		SELECT id, season_id, tier FROM league_cohorts WHERE season_id = $1 ORDER BY tier, created_at
+	`, seasonID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list league cohorts: %w", err)
+	}
+	defer rows.Close()
+	var cohorts []models.LeagueCohort
+	for rows.Next() {
+		var c models.LeagueCohort
+		if err := rows.Scan(&c.ID, &c.SeasonID, &c.Tier); err != nil {
+			return nil, fmt.Errorf("failed to scan league cohort: %w", err)
+		}
+		cohorts = append(cohorts, c)
+	}
+	return cohorts, rows.Err()
+}
+
+// GetCohortStandings returns the members of a cohort with the XP they
+// earned from submissions in the season window, unranked. The window is
+// counted the same way as the weekly leaderboard.
+func (r *LeagueRepository) GetCohortStandings(cohortID uuid.UUID, startsAt, endsAt time.Time) ([]models.LeagueStanding, error) {
+	rows, err := r.db.Query(`
+		SELECT
+			m.user_id,
+			COALESCE(u.display_name, ''),
+			u.avatar_url,
+			COALESCE((
+				SELECT SUM(s.points_earned)
+				FROM submissions s
+				WHERE s.user_id = m.user_id
+				AND COALESCE(s.submission_type, 'solution') <> 'draft'
+				AND s.created_at >= $2 AND s.created_at < $3
+			), 0)::int
+		FROM league_memberships m
+		JOIN users u ON m.user_id = u.id
+		WHERE m.cohort_id = $1
+	`, cohortID, startsAt, endsAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get league standings: %w", err)
+	}
+	defer rows.Close()
+	standings := []models.LeagueStanding{}
+	for rows.Next() {
+		var s models.LeagueStanding
+		if err := rows.Scan(&s.UserID, &s.Username, &s.AvatarURL, &s.XP); err != nil {
+			return nil, fmt.Errorf("failed to scan league standing: %w", err)
+		}
+		standings = append(standings, s)
+	}
+	return standings, rows.Err()
+}
+
+// CloseSeason stores the results of a season, credits the reward XP and
+// marks the season closed, all at once. It reports false without changing
+// anything if the season was already closed.
+func (r *LeagueRepository) CloseSeason(seasonID uuid.UUID, results []league.Result, closedAt time.Time) (bool, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	var status string
+	if err := tx.QueryRow(`SELECT status FROM league_seasons WHERE id = $1 FOR UPDATE`, seasonID).Scan(&status); err != nil {
+		return false, fmt.Errorf("failed to lock league season: %w", err)
+	}
+	if status != "active" {
+		return false, nil
+	}
+
+	var awards []models.XPTransaction
+	for _, result := range results {
+		if _, err := tx.Exec(`
+			UPDATE league_memberships
+			SET final_rank = $3, final_xp = $4, outcome = $5, new_tier = $6, reward_xp = $7
+			WHERE season_id = $1 AND user_id = $2
+		`, seasonID, result.UserID, result.Rank, result.XP, result.Outcome, result.NewTier, result.RewardXP); err != nil {
+			return false, fmt.Errorf("failed to store league result: %w", err)
+		}
+		if result.RewardXP > 0 {
+			t := &models.XPTransaction{
+				UserID:         result.UserID,
+				Amount:         result.RewardXP,
+				SourceType:     models.XPSourceLeagueReward,
+				SourceID:       &seasonID,
+				IdempotencyKey: "league_reward:" + seasonID.String() + ":" + result.UserID.String(),
+				CreatedAt:      closedAt,
+			}
+			awarded, err := awardXP(tx, t)
+			if err != nil {
+				return false, fmt.Errorf("failed to credit league reward: %w", err)
+			}
+			if awarded {
+				awards = append(awards, *t)
+			}
+		}
+	}
+
+	if _, err := tx.Exec(`
+		UPDATE league_seasons SET status = 'closed', closed_at = $2 WHERE id = $1
+	`, seasonID, closedAt); err != nil {
+		return false, fmt.Errorf("failed to close league season: %w", err)
+	}
+	if err := r.xpRepo.commit(tx, awards); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// ListHistory returns a user's results in closed seasons, newest first
+func (r *LeagueRepository) ListHistory(userID uuid.UUID, limit, offset int) ([]models.LeagueResult, int, error) {
+	var total int
+	if err := r.db.QueryRow(`
+		SELECT COUNT(*) FROM league_memberships WHERE user_id = $1 AND outcome IS NOT NULL
+	`, userID).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count league history: %w", err)
+	}
+	rows, err := r.db.Query(`
+		SELECT s.id, s.starts_at, s.ends_at, m.tier, m.final_rank, m.final_xp, m.outcome, m.new_tier, m.reward_xp
+		FROM league_memberships m
+		JOIN league_seasons s ON m.season_id = s.id
+		WHERE m.user_id = $1 AND m.outcome IS NOT NULL
+		ORDER BY s.starts_at DESC
+		LIMIT $2 OFFSET $3
+	`, userID, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list league history: %w", err)
+	}
+	defer rows.Close()
+	results := []models.LeagueResult{}
+	for rows.Next() {
+		var res models.LeagueResult
+		if err := rows.Scan(&res.SeasonID, &res.StartsAt, &res.EndsAt, &res.Tier, &res.Rank, &res.XP, &res.Outcome, &res.NewTier, &res.RewardXP); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan league result: %w", err)
+		}
+		results = append(results, res)
+	}
+	return results, total, rows.Err()
+}
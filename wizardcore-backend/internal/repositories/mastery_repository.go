@@ -0,0 +1,353 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/yourusername/wizardcore-backend/internal/mastery"
+	"github.com/yourusername/wizardcore-backend/internal/models"
+)
+
+// Graded submissions that can count as evidence of a skill. Copies of a
+// joint submission credited to partners are left out: the author's
+// submission already counts for the work.
+const masteryEvidence = `
+	COALESCE(s.submission_type, 'solution') NOT IN ('draft', 'joint_copy')
+	AND s.status NOT IN ('pending', 'processing', 'judge0_error')
+`
+
+// MasteryRepository stores each user's mastery of the skills exercises are
+// tagged with
+type MasteryRepository struct {
+	db *sql.DB
+}
+
+func NewMasteryRepository(db *sql.DB) *MasteryRepository {
+	return &MasteryRepository{db: db}
+}
+
+// RecordOutcome updates the author's mastery of each tag of an exercise
+// with the outcome of a graded submission on it. Only attempts up to and
+// including the first solve of the exercise count (see mastery.Attempts),
+// and copies of joint submissions do not count at all.
+func (r *MasteryRepository) RecordOutcome(submission *models.Submission, at time.Time) error {
+	if submission.SubmissionType == "draft" || submission.SubmissionType == models.SubmissionTypeJointCopy {
+		return nil
+	}
+	userID, exerciseID, correct := submission.UserID, submission.ExerciseID, submission.IsCorrect
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var solved bool
+	err = tx.QueryRow(`
+		SELECT EXISTS (
+			SELECT 1 FROM submissions s
+			WHERE s.user_id = $1 AND s.exercise_id = $2 AND s.is_correct
+			AND (s.created_at, s.id) < ($3, $4)
+			AND `+masteryEvidence+`
+		)
+	`, userID, exerciseID, submission.CreatedAt, submission.ID).Scan(&solved)
+	if err != nil {
+		return fmt.Errorf("failed to check earlier solves: %w", err)
+	}
+	attempts := mastery.Attempts{Solved: solved}
+	if !attempts.Counts(correct) {
+		return nil
+	}
+
+	var tags pq.StringArray
+	err = tx.QueryRow(`SELECT tags FROM exercises WHERE id = $1`, exerciseID).Scan(&tags)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get exercise tags: %w", err)
+	}
+
+	// Lock the rows in the same order everywhere
+	sorted := uniqueTags(tags)
+	for _, tag := range sorted {
+		if _, err := tx.Exec(`
+			INSERT INTO skill_mastery (user_id, tag, p_mastery, updated_at)
+			VALUES ($1, $2, $3, $4)
+			ON CONFLICT (user_id, tag) DO NOTHING
+		`, userID, tag, mastery.Default.Init, at); err != nil {
+			return fmt.Errorf("failed to start skill mastery: %w", err)
+		}
+		var prior float64
+		if err := tx.QueryRow(`
+			SELECT p_mastery FROM skill_mastery WHERE user_id = $1 AND tag = $2 FOR UPDATE
+		`, userID, tag).Scan(&prior); err != nil {
+			return fmt.Errorf("failed to lock skill mastery: %w", err)
+		}
+		correctCount := 0
+		if correct {
+			correctCount = 1
+		}
+		if _, err := tx.Exec(`
+			UPDATE skill_mastery
+			SET p_mastery = $3, attempts = attempts + 1, correct = correct + $4, updated_at = $5
+			WHERE user_id = $1 AND tag = $2
+		`, userID, tag, mastery.Update(mastery.Default, prior, correct), correctCount, at); err != nil {
+			return fmt.Errorf("failed to update skill mastery: %w", err)
+		}
+	}
+	return tx.Commit()
+}
+
+// uniqueTags returns the distinct non-empty tags, sorted
+func uniqueTags(tags []string) []string {
+	seen := make(map[string]bool, len(tags))
+	var unique []string
+	for _, tag := range tags {
+		if tag != "" && !seen[tag] {
+			seen[tag] = true
+			unique = append(unique, tag)
+		}
+	}
+	sort.Strings(unique)
+	return unique
+}
+
+// ListSkills returns a user's mastery of every skill they have practiced,
+// by tag
+func (r *MasteryRepository) ListSkills(userID uuid.UUID) ([]models.SkillMastery, error) {
+	rows, err := r.db.Query(`
+		SELECT tag, p_mastery, attempts, correct, updated_at
+		FROM skill_mastery
+		WHERE user_id = $1
+		ORDER BY tag
+	`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list skills: %w", err)
+	}
+	defer rows.Close()
+	skills := []models.SkillMastery{}
+	for rows.Next() {
+		var s models.SkillMastery
+		if err := rows.Scan(&s.Tag, &s.Mastery, &s.Attempts, &s.Correct, &s.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan skill: %w", err)
+		}
+		s.Level = mastery.Level(s.Mastery)
+		skills = append(skills, s)
+	}
+	return skills, rows.Err()
+}
+
+// GetPathwayHeatmap returns a page of the mastery heatmap of a pathway
+// created by creatorID, and the number of learners enrolled in it. It
+// returns nil if the creator has no such pathway.
+func (r *MasteryRepository) GetPathwayHeatmap(pathwayID, creatorID uuid.UUID, limit, offset int) (*models.MasteryHeatmap, int, error) {
+	heatmap := &models.MasteryHeatmap{PathwayID: pathwayID}
+	err := r.db.QueryRow(`
+		SELECT title FROM pathways WHERE id = $1 AND created_by = $2
+	`, pathwayID, creatorID).Scan(&heatmap.PathwayTitle)
+	if err == sql.ErrNoRows {
+		return nil, 0, nil
+	}
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get pathway: %w", err)
+	}
+
+	var tags pq.StringArray
+	if err := r.db.QueryRow(`
+		SELECT COALESCE(array_agg(DISTINCT t.tag ORDER BY t.tag), '{}')
+		FROM exercises e
+		JOIN modules m ON e.module_id = m.id
+		CROSS JOIN LATERAL unnest(e.tags) AS t(tag)
+		WHERE m.pathway_id = $1 AND t.tag <> ''
+	`, pathwayID).Scan(&tags); err != nil {
+		return nil, 0, fmt.Errorf("failed to get pathway tags: %w", err)
+	}
+	heatmap.Tags = tags
+	column := make(map[string]int, len(tags))
+	for i, tag := range tags {
+		column[tag] = i
+	}
+
+	heatmap.Averages = make([]*float64, len(tags))
+	averages, err := r.db.Query(`
+		SELECT sm.tag, AVG(sm.p_mastery)
+		FROM skill_mastery sm
+		JOIN user_pathway_enrollments upe ON upe.user_id = sm.user_id AND upe.pathway_id = $1
+		WHERE sm.tag = ANY($2)
+		GROUP BY sm.tag
+	`, pathwayID, tags)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to average pathway mastery: %w", err)
+	}
+	defer averages.Close()
+	for averages.Next() {
+		var tag string
+		var avg float64
+		if err := averages.Scan(&tag, &avg); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan mastery average: %w", err)
+		}
+		heatmap.Averages[column[tag]] = &avg
+	}
+	if err := averages.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	var total int
+	if err := r.db.QueryRow(`
+		SELECT COUNT(*) FROM user_pathway_enrollments WHERE pathway_id = $1
+	`, pathwayID).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count enrolled learners: %w", err)
+	}
+
+	learners, err := r.db.Query(`
+		SELECT u.id, COALESCE(u.display_name, ''), u.avatar_url
+		FROM user_pathway_enrollments upe
+		JOIN users u ON upe.user_id = u.id
+		WHERE upe.pathway_id = $1
+		ORDER BY u.display_name, u.id
+		LIMIT $2 OFFSET $3
+	`, pathwayID, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list enrolled learners: %w", err)
+	}
+	defer learners.Close()
+	heatmap.Learners = []models.MasteryHeatmapRow{}
+	row := make(map[uuid.UUID]int)
+	var userIDs pq.StringArray
+	for learners.Next() {
+		learner := models.MasteryHeatmapRow{Cells: make([]*float64, len(tags))}
+		var avatarURL sql.NullString
+		if err := learners.Scan(&learner.UserID, &learner.DisplayName, &avatarURL); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan enrolled learner: %w", err)
+		}
+		if avatarURL.Valid {
+			learner.AvatarURL = &avatarURL.String
+		}
+		row[learner.UserID] = len(heatmap.Learners)
+		userIDs = append(userIDs, learner.UserID.String())
+		heatmap.Learners = append(heatmap.Learners, learner)
+	}
+	if err := learners.Err(); err != nil {
+		return nil, 0, err
+	}
+	if len(userIDs) == 0 || len(tags) == 0 {
+		return heatmap, total, nil
+	}
+
+	cells, err := r.db.Query(`
+		SELECT user_id, tag, p_mastery FROM skill_mastery
+		WHERE user_id = ANY($1::uuid[]) AND tag = ANY($2)
+	`, userIDs, tags)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get learner mastery: %w", err)
+	}
+	defer cells.Close()
+	for cells.Next() {
+		var userID uuid.UUID
+		var tag string
+		var p float64
+		if err := cells.Scan(&userID, &tag, &p); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan learner mastery: %w", err)
+		}
+		heatmap.Learners[row[userID]].Cells[column[tag]] = &p
+	}
+	return heatmap, total, cells.Err()
+}
+
+// Rebuild recomputes every user's mastery by replaying their graded
+// submissions in order, counting the same attempts as RecordOutcome, and
+// returns the number of skills it stored. Outcomes recorded while it runs
+// wait for it.
+func (r *MasteryRepository) Rebuild() (int, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`LOCK TABLE skill_mastery IN EXCLUSIVE MODE`); err != nil {
+		return 0, fmt.Errorf("failed to lock skill mastery: %w", err)
+	}
+	if _, err := tx.Exec(`DELETE FROM skill_mastery`); err != nil {
+		return 0, fmt.Errorf("failed to clear skill mastery: %w", err)
+	}
+
+	type key struct {
+		userID uuid.UUID
+		tag    string
+	}
+	type exerciseKey struct {
+		userID     uuid.UUID
+		exerciseID uuid.UUID
+	}
+	skills := make(map[key]*models.SkillMastery)
+	attempts := make(map[exerciseKey]*mastery.Attempts)
+	var order []key
+	rows, err := tx.Query(`
+		SELECT s.user_id, s.exercise_id, s.is_correct, COALESCE(s.created_at, CURRENT_TIMESTAMP), e.tags
+		FROM submissions s
+		JOIN exercises e ON s.exercise_id = e.id
+		WHERE s.user_id IS NOT NULL AND ` + masteryEvidence + `
+		ORDER BY s.created_at, s.id
+	`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list submissions: %w", err)
+	}
+	for rows.Next() {
+		var userID, exerciseID uuid.UUID
+		var correct bool
+		var at time.Time
+		var tags pq.StringArray
+		if err := rows.Scan(&userID, &exerciseID, &correct, &at, &tags); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan submission: %w", err)
+		}
+		ek := exerciseKey{userID, exerciseID}
+		a, ok := attempts[ek]
+		if !ok {
+			a = &mastery.Attempts{}
+			attempts[ek] = a
+		}
+		if !a.Counts(correct) {
+			continue
+		}
+		for _, tag := range uniqueTags(tags) {
+			k := key{userID, tag}
+			s, ok := skills[k]
+			if !ok {
+				s = &models.SkillMastery{Tag: tag, Mastery: mastery.Default.Init}
+				skills[k] = s
+				order = append(order, k)
+			}
+			s.Mastery = mastery.Update(mastery.Default, s.Mastery, correct)
+			s.Attempts++
+			if correct {
+				s.Correct++
+			}
+			s.UpdatedAt = at
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	for _, k := range order {
+		s := skills[k]
+		if _, err := tx.Exec(`
+			INSERT INTO skill_mastery (user_id, tag, p_mastery, attempts, correct, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6)
+		`, k.userID, k.tag, s.Mastery, s.Attempts, s.Correct, s.UpdatedAt); err != nil {
+			return 0, fmt.Errorf("failed to store skill mastery: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return len(order), nil
+}
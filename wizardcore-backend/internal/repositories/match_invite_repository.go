@@ -0,0 +1,277 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yourusername/wizardcore-backend/internal/models"
+)
+
+// ErrInviteUnavailable is returned when an invite can no longer be answered
+// because it was already answered, withdrawn or has expired.
+var ErrInviteUnavailable = errors.New("invite is no longer available")
+
+type MatchInviteRepository struct {
+	db *sql.DB
+}
+
+func NewMatchInviteRepository(db *sql.DB) *MatchInviteRepository {
+	return &MatchInviteRepository{db: db}
+}
+
+const matchInviteColumns = `
+	id, match_id, inviter_id, invitee_id, invite_code, status, expires_at, responded_at, created_at
+`
+
+func scanMatchInvite(row interface{ Scan(...interface{}) error }) (*models.MatchInvite, error) {
+	var inv models.MatchInvite
+	var inviteeID uuid.NullUUID
+	err := row.Scan(
+		&inv.ID,
+		&inv.MatchID,
+		&inv.InviterID,
+		&inviteeID,
+		&inv.InviteCode,
+		&inv.Status,
+		&inv.ExpiresAt,
+		&inv.RespondedAt,
+		&inv.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if inviteeID.Valid {
+		inv.InviteeID = &inviteeID.UUID
+	}
+	return &inv, nil
+}
+
+// Create stores a pending private match, its inviter as the first
+// participant, and the invite
+func (r *MatchInviteRepository) Create(invite *models.MatchInvite, match *models.PracticeMatch) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		INSERT INTO practice_matches (id, match_type, status, exercise_id, time_limit_minutes, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`, match.ID, match.MatchType, match.Status, match.ExerciseID, match.TimeLimitMinutes, match.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create private match: %w", err)
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO match_participants (id, match_id, user_id, score, result, xp_earned, joined_at)
+		VALUES ($1, $2, $3, 0, '', 0, $4)
+	`, uuid.New(), match.ID, invite.InviterID, match.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to add inviter to match: %w", err)
+	}
+
+	err = tx.QueryRow(`
+		INSERT INTO match_invites (id, match_id, inviter_id, invitee_id, invite_code, status, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING created_at
+	`, invite.ID, invite.MatchID, invite.InviterID, invite.InviteeID, invite.InviteCode, invite.Status, invite.ExpiresAt, match.CreatedAt).Scan(&invite.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create invite: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+func (r *MatchInviteRepository) FindByID(id uuid.UUID) (*models.MatchInvite, error) {
+	query := `SELECT ` + matchInviteColumns + ` FROM match_invites WHERE id = $1`
+	invite, err := scanMatchInvite(r.db.QueryRow(query, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find invite: %w", err)
+	}
+	return invite, nil
+}
+
+func (r *MatchInviteRepository) FindByCode(code string) (*models.MatchInvite, error) {
+	query := `SELECT ` + matchInviteColumns + ` FROM match_invites WHERE invite_code = $1`
+	invite, err := scanMatchInvite(r.db.QueryRow(query, code))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find invite: %w", err)
+	}
+	return invite, nil
+}
+
+// ListPendingForUser returns the user's unexpired pending invites, both sent
+// and received, newest first
+func (r *MatchInviteRepository) ListPendingForUser(userID uuid.UUID) ([]models.MatchInvite, error) {
+	query := `SELECT ` + matchInviteColumns + `
+		FROM match_invites
+		WHERE (inviter_id = $1 OR invitee_id = $1)
+		  AND status = 'pending'
+		  AND expires_at > NOW()
+		ORDER BY created_at DESC`
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query invites: %w", err)
+	}
+	defer rows.Close()
+
+	var invites []models.MatchInvite
+	for rows.Next() {
+		invite, err := scanMatchInvite(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan invite: %w", err)
+		}
+		invites = append(invites, *invite)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+	return invites, nil
+}
+
+// Accept adds the user to the invite's match and starts it. The invite row
+// is locked so two users redeeming an open code cannot both join. It returns
+// ErrInviteUnavailable if the invite is no longer pending or has expired.
+func (r *MatchInviteRepository) Accept(inviteID, userID uuid.UUID) (*models.MatchInvite, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	invite, err := scanMatchInvite(tx.QueryRow(`SELECT `+matchInviteColumns+`
+		FROM match_invites
+		WHERE id = $1
+		FOR UPDATE`, inviteID))
+	if err == sql.ErrNoRows {
+		return nil, ErrInviteUnavailable
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock invite: %w", err)
+	}
+	now := time.Now()
+	if invite.Status != "pending" || !now.Before(invite.ExpiresAt) {
+		return nil, ErrInviteUnavailable
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO match_participants (id, match_id, user_id, score, result, xp_earned, joined_at)
+		VALUES ($1, $2, $3, 0, '', 0, $4)
+	`, uuid.New(), invite.MatchID, userID, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add invitee to match: %w", err)
+	}
+
+	_, err = tx.Exec(`
+		UPDATE practice_matches SET status = 'active', started_at = $2
+		WHERE id = $1
+	`, invite.MatchID, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start private match: %w", err)
+	}
+
+	_, err = tx.Exec(`
+		UPDATE match_invites SET status = 'accepted', invitee_id = $2, responded_at = $3
+		WHERE id = $1
+	`, invite.ID, userID, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to accept invite: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	invite.Status = "accepted"
+	invite.InviteeID = &userID
+	invite.RespondedAt = &now
+	return invite, nil
+}
+
+// Close moves a pending invite to status (declined or cancelled) and cancels
+// its match. It returns ErrInviteUnavailable if the invite is not pending.
+func (r *MatchInviteRepository) Close(inviteID uuid.UUID, status string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var matchID uuid.UUID
+	err = tx.QueryRow(`
+		UPDATE match_invites SET status = $2, responded_at = NOW()
+		WHERE id = $1 AND status = 'pending'
+		RETURNING match_id
+	`, inviteID, status).Scan(&matchID)
+	if err == sql.ErrNoRows {
+		return ErrInviteUnavailable
+	}
+	if err != nil {
+		return fmt.Errorf("failed to close invite: %w", err)
+	}
+
+	_, err = tx.Exec(`
+		UPDATE practice_matches SET status = 'cancelled', ended_at = NOW()
+		WHERE id = $1
+	`, matchID)
+	if err != nil {
+		return fmt.Errorf("failed to cancel private match: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// ExpirePending expires pending invites past their expiry, cancels their
+// matches and returns the expired invites
+func (r *MatchInviteRepository) ExpirePending(now time.Time) ([]models.MatchInvite, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.Query(`
+		UPDATE match_invites SET status = 'expired'
+		WHERE status = 'pending' AND expires_at <= $1
+		RETURNING `+matchInviteColumns, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to expire invites: %w", err)
+	}
+	var invites []models.MatchInvite
+	for rows.Next() {
+		invite, err := scanMatchInvite(rows)
+		if err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan expired invite: %w", err)
+		}
+		invites = append(invites, *invite)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	for _, invite := range invites {
+		_, err = tx.Exec(`
+			UPDATE practice_matches SET status = 'cancelled', ended_at = $2
+			WHERE id = $1 AND status = 'pending'
+		`, invite.MatchID, now)
+		if err != nil {
+			return nil, fmt.Errorf("failed to cancel expired match: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return invites, nil
+}
@@ -2,10 +2,12 @@ package repositories
 
 import (
 	"database/sql"
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/yourusername/wizardcore-backend/internal/models"
+	"github.com/yourusername/wizardcore-backend/internal/rating"
 )
 
 type MatchRepository struct {
@@ -159,14 +161,41 @@ func (r *MatchRepository) UpdateParticipant(participant *models.MatchParticipant
 	return err
 }
 
+// FinishParticipant stores the final result of a participant who has not
+// finished yet. It reports false, storing nothing, if they already have, so
+// concurrent callers cannot both finish the same participant.
+func (r *MatchRepository) FinishParticipant(participant *models.MatchParticipant) (bool, error) {
+	result, err := r.db.Exec(`
+		UPDATE match_participants
+		SET score = $2, result = $3, xp_earned = $4, finished_at = $5
+		WHERE id = $1 AND finished_at IS NULL
+	`, participant.ID, participant.Score, participant.Result, participant.XPEarned, participant.FinishedAt)
+	if err != nil {
+		return false, fmt.Errorf("failed to finish participant: %w", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to finish participant: %w", err)
+	}
+	return n > 0, nil
+}
+
 // GetUserPracticeStats retrieves practice stats for a user
 func (r *MatchRepository) GetUserPracticeStats(userID uuid.UUID) (*models.UserPracticeStats, error) {
 	query := `
-		SELECT duels_total, duels_won, duels_lost, duels_draw, speed_runs_completed, best_speed_run_time, random_challenges_completed, total_practice_xp, practice_score, practice_rank, avg_completion_time
-		FROM user_practice_stats
+		SELECT duels_total, duels_won, duels_lost, duels_draw, speed_runs_completed, best_speed_run_time, random_challenges_completed, total_practice_xp, practice_score,
+			CASE WHEN s.rated_duels > 0 THEN (
+				SELECT COUNT(*) + 1 FROM user_practice_stats o
+				WHERE o.rated_duels > 0 AND o.rating > s.rating
+			) END AS practice_rank,
+			avg_completion_time, endurance_runs_completed, best_endurance_solved, best_endurance_score,
+			completion_time_total, completion_count,
+			rating, rating_deviation, rating_volatility, rated_duels, peak_rating
+		FROM user_practice_stats s
 		WHERE user_id = $1
 	`
-	var stats models.UserPracticeStats
+	stats := models.UserPracticeStats{UserID: userID}
+	var practiceRank sql.NullInt64
 	err := r.db.QueryRow(query, userID).Scan(
 		&stats.DuelsTotal,
 		&stats.DuelsWon,
@@ -177,24 +206,45 @@ func (r *MatchRepository) GetUserPracticeStats(userID uuid.UUID) (*models.UserPr
 		&stats.RandomChallengesCompleted,
 		&stats.TotalPracticeXP,
 		&stats.PracticeScore,
-		&stats.PracticeRank,
+		&practiceRank,
 		&stats.AvgCompletionTime,
+		&stats.EnduranceRunsCompleted,
+		&stats.BestEnduranceSolved,
+		&stats.BestEnduranceScore,
+		&stats.CompletionTimeTotal,
+		&stats.CompletionCount,
+		&stats.Rating,
+		&stats.RatingDeviation,
+		&stats.RatingVolatility,
+		&stats.RatedDuels,
+		&stats.PeakRating,
 	)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			// Return zero stats
-			return &models.UserPracticeStats{}, nil
+			// Return zero stats with an unrated duel rating
+			return &models.UserPracticeStats{
+				UserID:           userID,
+				Rating:           rating.DefaultRating,
+				RatingDeviation:  rating.DefaultDeviation,
+				RatingVolatility: rating.DefaultVolatility,
+			}, nil
 		}
 		return nil, err
 	}
+	if practiceRank.Valid {
+		rank := int(practiceRank.Int64)
+		stats.PracticeRank = &rank
+	}
 	return &stats, nil
 }
 
-// UpdateUserPracticeStats updates or inserts practice stats for a user
+// UpdateUserPracticeStats updates or inserts practice stats for a user.
+// Duel ratings are owned by CompleteMatch and left untouched.
 func (r *MatchRepository) UpdateUserPracticeStats(stats *models.UserPracticeStats) error {
 	query := `
-		INSERT INTO user_practice_stats (user_id, duels_total, duels_won, duels_lost, duels_draw, speed_runs_completed, best_speed_run_time, random_challenges_completed, total_practice_xp, practice_score, practice_rank, avg_completion_time, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		INSERT INTO user_practice_stats (user_id, duels_total, duels_won, duels_lost, duels_draw, speed_runs_completed, best_speed_run_time, random_challenges_completed, total_practice_xp, practice_score, practice_rank, avg_completion_time,
+			endurance_runs_completed, best_endurance_solved, best_endurance_score, completion_time_total, completion_count, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18)
 		ON CONFLICT (user_id) DO UPDATE SET
 			duels_total = EXCLUDED.duels_total,
 			duels_won = EXCLUDED.duels_won,
@@ -207,6 +257,11 @@ func (r *MatchRepository) UpdateUserPracticeStats(stats *models.UserPracticeStat
 			practice_score = EXCLUDED.practice_score,
 			practice_rank = EXCLUDED.practice_rank,
 			avg_completion_time = EXCLUDED.avg_completion_time,
+			endurance_runs_completed = EXCLUDED.endurance_runs_completed,
+			best_endurance_solved = EXCLUDED.best_endurance_solved,
+			best_endurance_score = EXCLUDED.best_endurance_score,
+			completion_time_total = EXCLUDED.completion_time_total,
+			completion_count = EXCLUDED.completion_count,
 			updated_at = EXCLUDED.updated_at
 	`
 	_, err := r.db.Exec(query,
@@ -222,41 +277,398 @@ func (r *MatchRepository) UpdateUserPracticeStats(stats *models.UserPracticeStat
 		stats.PracticeScore,
 		stats.PracticeRank,
 		stats.AvgCompletionTime,
+		stats.EnduranceRunsCompleted,
+		stats.BestEnduranceSolved,
+		stats.BestEnduranceScore,
+		stats.CompletionTimeTotal,
+		stats.CompletionCount,
 		time.Now(),
 	)
 	return err
 }
 
-// FindPendingDuelWithOneParticipant finds a pending duel match that has exactly one participant
-func (r *MatchRepository) FindPendingDuelWithOneParticipant() (*models.PracticeMatch, error) {
+// CreateSplit records an exercise served in a practice run
+func (r *MatchRepository) CreateSplit(split *models.MatchSplit) error {
 	query := `
-		SELECT pm.id, pm.match_type, pm.status, pm.exercise_id, pm.time_limit_minutes, pm.started_at, pm.ended_at, pm.created_at
-		FROM practice_matches pm
-		JOIN match_participants mp ON pm.id = mp.match_id
-		WHERE pm.match_type = 'duel'
-		  AND pm.status = 'pending'
-		GROUP BY pm.id
-		HAVING COUNT(mp.user_id) = 1
-		LIMIT 1
+		INSERT INTO match_splits (id, match_id, user_id, exercise_id, sequence, result, served_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
 	`
-	var match models.PracticeMatch
-	err := r.db.QueryRow(query).Scan(
-		&match.ID,
-		&match.MatchType,
-		&match.Status,
-		&match.ExerciseID,
-		&match.TimeLimitMinutes,
-		&match.StartedAt,
-		&match.EndedAt,
-		&match.CreatedAt,
-	)
+	if split.ID == uuid.Nil {
+		split.ID = uuid.New()
+	}
+	if split.Result == "" {
+		split.Result = "pending"
+	}
+	_, err := r.db.Exec(query, split.ID, split.MatchID, split.UserID, split.ExerciseID, split.Sequence, split.Result, split.ServedAt)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return nil, nil
+		return fmt.Errorf("failed to create split: %w", err)
+	}
+	return nil
+}
+
+// UpdateSplit stores the outcome of a split
+func (r *MatchRepository) UpdateSplit(split *models.MatchSplit) error {
+	query := `
+		UPDATE match_splits
+		SET result = $2, score = $3, attempts = $4, submission_id = $5, completed_at = $6, split_seconds = $7
+		WHERE id = $1
+	`
+	_, err := r.db.Exec(query, split.ID, split.Result, split.Score, split.Attempts, split.SubmissionID, split.CompletedAt, split.SplitSeconds)
+	if err != nil {
+		return fmt.Errorf("failed to update split: %w", err)
+	}
+	return nil
+}
+
+// GetSplits returns a user's splits in a match in the order they were served
+func (r *MatchRepository) GetSplits(matchID, userID uuid.UUID) ([]models.MatchSplit, error) {
+	query := `
+		SELECT id, match_id, user_id, exercise_id, sequence, result, score, attempts,
+			submission_id, served_at, completed_at, split_seconds
+		FROM match_splits
+		WHERE match_id = $1 AND user_id = $2
+		ORDER BY sequence ASC
+	`
+	rows, err := r.db.Query(query, matchID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query splits: %w", err)
+	}
+	defer rows.Close()
+
+	var splits []models.MatchSplit
+	for rows.Next() {
+		var split models.MatchSplit
+		var submissionID uuid.NullUUID
+		var splitSeconds sql.NullInt64
+		err := rows.Scan(
+			&split.ID,
+			&split.MatchID,
+			&split.UserID,
+			&split.ExerciseID,
+			&split.Sequence,
+			&split.Result,
+			&split.Score,
+			&split.Attempts,
+			&submissionID,
+			&split.ServedAt,
+			&split.CompletedAt,
+			&splitSeconds,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan split: %w", err)
 		}
-		return nil, err
+		if submissionID.Valid {
+			split.SubmissionID = &submissionID.UUID
+		}
+		if splitSeconds.Valid {
+			seconds := int(splitSeconds.Int64)
+			split.SplitSeconds = &seconds
+		}
+		splits = append(splits, split)
 	}
-	return &match, nil
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+	return splits, nil
+}
+
+// GetPersonalBests returns a user's personal bests for every practice mode
+func (r *MatchRepository) GetPersonalBests(userID uuid.UUID) ([]models.PersonalBest, error) {
+	query := `
+		SELECT user_id, mode, best_score, most_solved, best_time_seconds, match_id, achieved_at
+		FROM practice_personal_bests
+		WHERE user_id = $1
+		ORDER BY mode
+	`
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query personal bests: %w", err)
+	}
+	defer rows.Close()
+
+	var bests []models.PersonalBest
+	for rows.Next() {
+		var pb models.PersonalBest
+		var matchID uuid.NullUUID
+		var bestTime sql.NullInt64
+		if err := rows.Scan(&pb.UserID, &pb.Mode, &pb.BestScore, &pb.MostSolved, &bestTime, &matchID, &pb.AchievedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan personal best: %w", err)
+		}
+		if bestTime.Valid {
+			seconds := int(bestTime.Int64)
+			pb.BestTimeSeconds = &seconds
+		}
+		if matchID.Valid {
+			pb.MatchID = &matchID.UUID
+		}
+		bests = append(bests, pb)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+	return bests, nil
+}
+
+// SavePersonalBest merges a run into the user's personal best for its mode
+// and reports whether any of the records improved
+func (r *MatchRepository) SavePersonalBest(run *models.PersonalBest) (bool, error) {
+	query := `
+		INSERT INTO practice_personal_bests AS pb (user_id, mode, best_score, most_solved, best_time_seconds, match_id, achieved_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (user_id, mode) DO UPDATE SET
+			best_score = GREATEST(pb.best_score, EXCLUDED.best_score),
+			most_solved = GREATEST(pb.most_solved, EXCLUDED.most_solved),
+			best_time_seconds = LEAST(pb.best_time_seconds, EXCLUDED.best_time_seconds),
+			match_id = EXCLUDED.match_id,
+			achieved_at = EXCLUDED.achieved_at
+		WHERE EXCLUDED.best_score > pb.best_score
+		   OR EXCLUDED.most_solved > pb.most_solved
+		   OR (EXCLUDED.best_time_seconds IS NOT NULL
+		       AND (pb.best_time_seconds IS NULL OR EXCLUDED.best_time_seconds < pb.best_time_seconds))
+	`
+	result, err := r.db.Exec(query, run.UserID, run.Mode, run.BestScore, run.MostSolved, run.BestTimeSeconds, run.MatchID, run.AchievedAt)
+	if err != nil {
+		return false, fmt.Errorf("failed to save personal best: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return rows > 0, nil
+}
+
+// CompleteMatch ends a match that is still running. The match row is locked
+// so that only one caller completes it, and the ratings of a duel's players
+// are read and updated under the same lock. It returns false if the match
+// had already ended, and the rating changes of a rated duel.
+func (r *MatchRepository) CompleteMatch(matchID uuid.UUID, endedAt time.Time, duel *models.DuelOutcome) (bool, []models.DuelRatingChange, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return false, nil, err
+	}
+	defer tx.Rollback()
+
+	var status string
+	err = tx.QueryRow(`SELECT status FROM practice_matches WHERE id = $1 FOR UPDATE`, matchID).Scan(&status)
+	if err == sql.ErrNoRows {
+		return false, nil, nil
+	}
+	if err != nil {
+		return false, nil, fmt.Errorf("failed to lock match: %w", err)
+	}
+	if status == "completed" || status == "cancelled" {
+		return false, nil, nil
+	}
+	if _, err := tx.Exec(`
+		UPDATE practice_matches SET status = 'completed', ended_at = $2 WHERE id = $1
+	`, matchID, endedAt); err != nil {
+		return false, nil, fmt.Errorf("failed to complete match: %w", err)
+	}
+
+	var changes []models.DuelRatingChange
+	if duel != nil {
+		changes, err = rateDuel(tx, matchID, duel, endedAt)
+		if err != nil {
+			return false, nil, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return false, nil, err
+	}
+	return true, changes, nil
+}
+
+// rateDuel updates the Glicko-2 ratings of both players of a duel within tx
+// and records the changes
+func rateDuel(tx *sql.Tx, matchID uuid.UUID, duel *models.DuelOutcome, at time.Time) ([]models.DuelRatingChange, error) {
+	// Lock the players' ratings in the same order everywhere
+	players := []uuid.UUID{duel.PlayerA, duel.PlayerB}
+	if players[1].String() < players[0].String() {
+		players[0], players[1] = players[1], players[0]
+	}
+	current := make(map[uuid.UUID]rating.Rating, 2)
+	for _, userID := range players {
+		if _, err := tx.Exec(`
+			INSERT INTO user_practice_stats (user_id) VALUES ($1) ON CONFLICT (user_id) DO NOTHING
+		`, userID); err != nil {
+			return nil, fmt.Errorf("failed to create practice stats: %w", err)
+		}
+		var rt rating.Rating
+		if err := tx.QueryRow(`
+			SELECT rating, rating_deviation, rating_volatility
+			FROM user_practice_stats WHERE user_id = $1 FOR UPDATE
+		`, userID).Scan(&rt.Rating, &rt.Deviation, &rt.Volatility); err != nil {
+			return nil, fmt.Errorf("failed to lock rating: %w", err)
+		}
+		current[userID] = rt
+	}
+
+	a, b := current[duel.PlayerA], current[duel.PlayerB]
+	newA := rating.Update(a, []rating.Result{{Opponent: b, Score: duel.ScoreA}})
+	newB := rating.Update(b, []rating.Result{{Opponent: a, Score: 1 - duel.ScoreA}})
+	changes := []models.DuelRatingChange{
+		ratingChange(matchID, duel.PlayerA, duel.PlayerB, duel.ScoreA, a, newA, at),
+		ratingChange(matchID, duel.PlayerB, duel.PlayerA, 1-duel.ScoreA, b, newB, at),
+	}
+
+	historyQuery := `
+		INSERT INTO duel_rating_history (
+			id, user_id, match_id, opponent_id, score, rating_before, rating_after,
+			deviation_after, volatility_after, created_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	`
+	statsQuery := `
+		UPDATE user_practice_stats SET
+			rating = $2,
+			rating_deviation = $3,
+			rating_volatility = $4,
+			rated_duels = rated_duels + 1,
+			peak_rating = GREATEST(COALESCE(peak_rating, $2), $2),
+			updated_at = $5
+		WHERE user_id = $1
+	`
+	for i := range changes {
+		change := &changes[i]
+		if _, err := tx.Exec(historyQuery,
+			change.ID,
+			change.UserID,
+			change.MatchID,
+			change.OpponentID,
+			change.Score,
+			change.RatingBefore,
+			change.RatingAfter,
+			change.DeviationAfter,
+			change.VolatilityAfter,
+			change.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to store rating history: %w", err)
+		}
+		if _, err := tx.Exec(statsQuery,
+			change.UserID,
+			change.RatingAfter,
+			change.DeviationAfter,
+			change.VolatilityAfter,
+			at,
+		); err != nil {
+			return nil, fmt.Errorf("failed to update rating: %w", err)
+		}
+	}
+	return changes, nil
+}
+
+func ratingChange(matchID, userID, opponentID uuid.UUID, score float64, before, after rating.Rating, at time.Time) models.DuelRatingChange {
+	return models.DuelRatingChange{
+		ID:              uuid.New(),
+		UserID:          userID,
+		MatchID:         matchID,
+		OpponentID:      &opponentID,
+		Score:           score,
+		RatingBefore:    before.Rating,
+		RatingAfter:     after.Rating,
+		DeviationAfter:  after.Deviation,
+		VolatilityAfter: after.Volatility,
+		CreatedAt:       at,
+	}
+}
+
+// GetRatingHistory returns a user's most recent rating changes, oldest first
+func (r *MatchRepository) GetRatingHistory(userID uuid.UUID, limit int) ([]models.DuelRatingChange, error) {
+	query := `
+		SELECT id, user_id, match_id, opponent_id, score, rating_before, rating_after,
+			deviation_after, volatility_after, created_at
+		FROM (
+			SELECT * FROM duel_rating_history
+			WHERE user_id = $1
+			ORDER BY created_at DESC
+			LIMIT $2
+		) recent
+		ORDER BY created_at ASC
+	`
+	rows, err := r.db.Query(query, userID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query rating history: %w", err)
+	}
+	defer rows.Close()
+
+	var history []models.DuelRatingChange
+	for rows.Next() {
+		var change models.DuelRatingChange
+		var opponentID uuid.NullUUID
+		err := rows.Scan(
+			&change.ID,
+			&change.UserID,
+			&change.MatchID,
+			&opponentID,
+			&change.Score,
+			&change.RatingBefore,
+			&change.RatingAfter,
+			&change.DeviationAfter,
+			&change.VolatilityAfter,
+			&change.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan rating history: %w", err)
+		}
+		if opponentID.Valid {
+			change.OpponentID = &opponentID.UUID
+		}
+		history = append(history, change)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+	return history, nil
+}
+
+// GetRatedLeaderboard returns rated duel players ordered by rating. Players
+// whose deviation is above maxDeviation are still provisional and left out.
+func (r *MatchRepository) GetRatedLeaderboard(maxDeviation float64, limit, offset int) ([]models.DuelLeaderboardEntry, int, error) {
+	var total int
+	err := r.db.QueryRow(`
+		SELECT COUNT(*) FROM user_practice_stats
+		WHERE rated_duels > 0 AND rating_deviation <= $1
+	`, maxDeviation).Scan(&total)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count rated players: %w", err)
+	}
+
+	query := `
+		SELECT s.user_id, u.display_name, u.avatar_url, s.rating, s.rating_deviation,
+			s.rated_duels, s.duels_won
+		FROM user_practice_stats s
+		JOIN users u ON s.user_id = u.id
+		WHERE s.rated_duels > 0 AND s.rating_deviation <= $1
+		ORDER BY s.rating DESC, s.rated_duels DESC
+		LIMIT $2 OFFSET $3
+	`
+	rows, err := r.db.Query(query, maxDeviation, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query rated leaderboard: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []models.DuelLeaderboardEntry
+	for rows.Next() {
+		var entry models.DuelLeaderboardEntry
+		err := rows.Scan(
+			&entry.UserID,
+			&entry.Username,
+			&entry.AvatarURL,
+			&entry.Rating,
+			&entry.RatingDeviation,
+			&entry.RatedDuels,
+			&entry.DuelsWon,
+		)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan rated leaderboard entry: %w", err)
+		}
+		entry.Rank = offset + len(entries) + 1
+		entries = append(entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("rows error: %w", err)
+	}
+	return entries, total, nil
 }
 
 // GetRecentMatches returns recent matches for a user
@@ -294,4 +706,73 @@ func (r *MatchRepository) GetRecentMatches(userID uuid.UUID, limit int) ([]model
 		matches = append(matches, match)
 	}
 	return matches, nil
-}
\ No newline at end of file
+}
+// ListStalePendingMatches returns matches still waiting for players that
+// were created before cutoff
+func (r *MatchRepository) ListStalePendingMatches(cutoff time.Time) ([]models.PracticeMatch, error) {
+	return r.listMatches(`
+		SELECT id, match_type, status, exercise_id, time_limit_minutes, started_at, ended_at, created_at
+		FROM practice_matches
+		WHERE status = 'pending' AND created_at < $1
+		ORDER BY created_at
+	`, cutoff)
+}
+
+// ListOverdueMatches returns active matches whose time limit ran out before
+// cutoff
+func (r *MatchRepository) ListOverdueMatches(cutoff time.Time) ([]models.PracticeMatch, error) {
+	return r.listMatches(`
+		SELECT id, match_type, status, exercise_id, time_limit_minutes, started_at, ended_at, created_at
+		FROM practice_matches
+		WHERE status = 'active'
+		  AND started_at IS NOT NULL
+		  AND time_limit_minutes IS NOT NULL
+		  AND started_at + make_interval(mins => time_limit_minutes) < $1
+		ORDER BY started_at
+	`, cutoff)
+}
+
+func (r *MatchRepository) listMatches(query string, args ...interface{}) ([]models.PracticeMatch, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list matches: %w", err)
+	}
+	defer rows.Close()
+
+	var matches []models.PracticeMatch
+	for rows.Next() {
+		var match models.PracticeMatch
+		err := rows.Scan(
+			&match.ID,
+			&match.MatchType,
+			&match.Status,
+			&match.ExerciseID,
+			&match.TimeLimitMinutes,
+			&match.StartedAt,
+			&match.EndedAt,
+			&match.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan match: %w", err)
+		}
+		matches = append(matches, match)
+	}
+	return matches, rows.Err()
+}
+
+// CancelPendingMatch cancels a match that has not started. It reports
+// whether the match was still pending.
+func (r *MatchRepository) CancelPendingMatch(matchID uuid.UUID, now time.Time) (bool, error) {
+	res, err := r.db.Exec(`
+		UPDATE practice_matches SET status = 'cancelled', ended_at = $2
+		WHERE id = $1 AND status = 'pending'
+	`, matchID, now)
+	if err != nil {
+		return false, fmt.Errorf("failed to cancel match: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
@@ -0,0 +1,301 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yourusername/wizardcore-backend/internal/models"
+)
+
+type MatchmakingRepository struct {
+	db *sql.DB
+}
+
+func NewMatchmakingRepository(db *sql.DB) *MatchmakingRepository {
+	return &MatchmakingRepository{db: db}
+}
+
+const queueEntryColumns = `
+	id, user_id, language_id, difficulty, rating, status, match_id, enqueued_at, matched_at
+`
+
+func scanQueueEntry(row interface{ Scan(...interface{}) error }) (*models.QueueEntry, error) {
+	var e models.QueueEntry
+	var languageID sql.NullInt64
+	var difficulty sql.NullString
+	var matchID uuid.NullUUID
+	err := row.Scan(
+		&e.ID,
+		&e.UserID,
+		&languageID,
+		&difficulty,
+		&e.Rating,
+		&e.Status,
+		&matchID,
+		&e.EnqueuedAt,
+		&e.MatchedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if languageID.Valid {
+		id := int(languageID.Int64)
+		e.LanguageID = &id
+	}
+	if difficulty.Valid {
+		e.Difficulty = &difficulty.String
+	}
+	if matchID.Valid {
+		e.MatchID = &matchID.UUID
+	}
+	return &e, nil
+}
+
+// Enqueue adds a waiting entry. It fails if the user is already waiting.
+func (r *MatchmakingRepository) Enqueue(entry *models.QueueEntry) error {
+	query := `
+		INSERT INTO matchmaking_queue (id, user_id, language_id, difficulty, rating, status, enqueued_at)
+		VALUES ($1, $2, $3, $4, $5, 'waiting', $6)
+	`
+	if entry.ID == uuid.Nil {
+		entry.ID = uuid.New()
+	}
+	entry.Status = "waiting"
+	entry.EnqueuedAt = time.Now()
+	_, err := r.db.Exec(query, entry.ID, entry.UserID, entry.LanguageID, entry.Difficulty, entry.Rating, entry.EnqueuedAt)
+	if err != nil {
+		return fmt.Errorf("failed to join matchmaking queue: %w", err)
+	}
+	return nil
+}
+
+// FindWaitingByUser returns the user's waiting entry, if any
+func (r *MatchmakingRepository) FindWaitingByUser(userID uuid.UUID) (*models.QueueEntry, error) {
+	query := `SELECT ` + queueEntryColumns + ` FROM matchmaking_queue WHERE user_id = $1 AND status = 'waiting'`
+	entry, err := scanQueueEntry(r.db.QueryRow(query, userID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find queue entry: %w", err)
+	}
+	return entry, nil
+}
+
+// FindLatestByUser returns the user's most recent entry in any state
+func (r *MatchmakingRepository) FindLatestByUser(userID uuid.UUID) (*models.QueueEntry, error) {
+	query := `SELECT ` + queueEntryColumns + ` FROM matchmaking_queue WHERE user_id = $1 ORDER BY enqueued_at DESC LIMIT 1`
+	entry, err := scanQueueEntry(r.db.QueryRow(query, userID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find queue entry: %w", err)
+	}
+	return entry, nil
+}
+
+// Cancel takes the user out of the queue. It returns false if the user was
+// not waiting, for example because they were matched in the meantime.
+func (r *MatchmakingRepository) Cancel(userID uuid.UUID) (bool, error) {
+	result, err := r.db.Exec(`
+		UPDATE matchmaking_queue SET status = 'cancelled'
+		WHERE user_id = $1 AND status = 'waiting'
+	`, userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to leave matchmaking queue: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return rows > 0, nil
+}
+
+// ListWaiting returns waiting entries, longest waiting first
+func (r *MatchmakingRepository) ListWaiting(limit int) ([]models.QueueEntry, error) {
+	query := `SELECT ` + queueEntryColumns + `
+		FROM matchmaking_queue
+		WHERE status = 'waiting'
+		ORDER BY enqueued_at ASC
+		LIMIT $1`
+	rows, err := r.db.Query(query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query matchmaking queue: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []models.QueueEntry
+	for rows.Next() {
+		entry, err := scanQueueEntry(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan queue entry: %w", err)
+		}
+		entries = append(entries, *entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+	return entries, nil
+}
+
+// ExpireWaitingBefore expires entries queued before cutoff and returns the
+// affected users
+func (r *MatchmakingRepository) ExpireWaitingBefore(cutoff time.Time) ([]uuid.UUID, error) {
+	rows, err := r.db.Query(`
+		UPDATE matchmaking_queue SET status = 'expired'
+		WHERE status = 'waiting' AND enqueued_at < $1
+		RETURNING user_id
+	`, cutoff)
+	if err != nil {
+		return nil, fmt.Errorf("failed to expire queue entries: %w", err)
+	}
+	defer rows.Close()
+
+	var userIDs []uuid.UUID
+	for rows.Next() {
+		var userID uuid.UUID
+		if err := rows.Scan(&userID); err != nil {
+			return nil, fmt.Errorf("failed to scan expired entry: %w", err)
+		}
+		userIDs = append(userIDs, userID)
+	}
+	return userIDs, rows.Err()
+}
+
+// AverageWaitSeconds returns the mean time entries with the given
+// preferences waited before being matched since the given time, and how many
+// matches that is based on
+func (r *MatchmakingRepository) AverageWaitSeconds(languageID *int, difficulty *string, since time.Time) (float64, int, error) {
+	query := `
+		SELECT COALESCE(AVG(EXTRACT(EPOCH FROM (matched_at - enqueued_at))), 0), COUNT(*)
+		FROM matchmaking_queue
+		WHERE status = 'matched'
+		  AND matched_at >= $1
+		  AND language_id IS NOT DISTINCT FROM $2
+		  AND difficulty IS NOT DISTINCT FROM $3
+	`
+	var avg float64
+	var count int
+	if err := r.db.QueryRow(query, since, languageID, difficulty).Scan(&avg, &count); err != nil {
+		return 0, 0, fmt.Errorf("failed to compute average wait: %w", err)
+	}
+	return avg, count, nil
+}
+
+// PairEntry tries to match a waiting entry with a compatible opponent and
+// start a duel for both. Everything happens in one transaction: both queue
+// rows are locked, so a user can never be paired twice. Rows locked by a
+// concurrent pairing are skipped rather than waited on. It returns nil if no
+// opponent or no suitable exercise is available.
+func (r *MatchmakingRepository) PairEntry(entryID uuid.UUID, band models.RatingBand, timeLimitMinutes int) (*models.PracticeMatch, []uuid.UUID, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, nil, err
+	}
+	defer tx.Rollback()
+
+	entry, err := scanQueueEntry(tx.QueryRow(`SELECT `+queueEntryColumns+`
+		FROM matchmaking_queue
+		WHERE id = $1 AND status = 'waiting'
+		FOR UPDATE SKIP LOCKED`, entryID))
+	if err == sql.ErrNoRows {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to lock queue entry: %w", err)
+	}
+
+	// The band of whoever has waited longer decides, so a long wait widens
+	// the search for both sides.
+	myBand := band.Width(time.Since(entry.EnqueuedAt))
+	opponent, err := scanQueueEntry(tx.QueryRow(`SELECT `+queueEntryColumns+`
+		FROM matchmaking_queue q
+		WHERE q.status = 'waiting'
+		  AND q.id <> $1
+		  AND q.user_id <> $2
+		  AND (q.language_id IS NULL OR $3::int IS NULL OR q.language_id = $3)
+		  AND (q.difficulty IS NULL OR $4::varchar IS NULL OR q.difficulty = $4)
+		  AND ABS(q.rating - $5) <= GREATEST($6, LEAST($10, $7 + $8 * FLOOR(EXTRACT(EPOCH FROM (NOW() - q.enqueued_at)) / $9)))
+		ORDER BY ABS(q.rating - $5), q.enqueued_at
+		LIMIT 1
+		FOR UPDATE SKIP LOCKED`,
+		entry.ID, entry.UserID, entry.LanguageID, entry.Difficulty, entry.Rating,
+		myBand, band.Base, band.Step, band.StepSeconds, band.Max,
+	))
+	if err == sql.ErrNoRows {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to find opponent: %w", err)
+	}
+
+	languageID := entry.LanguageID
+	if languageID == nil {
+		languageID = opponent.LanguageID
+	}
+	difficulty := entry.Difficulty
+	if difficulty == nil {
+		difficulty = opponent.Difficulty
+	}
+	var exerciseID uuid.UUID
+	err = tx.QueryRow(`
+		SELECT id FROM exercises
+		WHERE ($1::int IS NULL OR language_id = $1)
+		  AND ($2::varchar IS NULL OR difficulty = $2)
+		  AND COALESCE(status, 'published') = 'published'
+		ORDER BY RANDOM()
+		LIMIT 1
+	`, languageID, difficulty).Scan(&exerciseID)
+	if err == sql.ErrNoRows {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to pick exercise: %w", err)
+	}
+
+	now := time.Now()
+	match := &models.PracticeMatch{
+		ID:               uuid.New(),
+		MatchType:        "duel",
+		Status:           "active",
+		ExerciseID:       exerciseID,
+		TimeLimitMinutes: &timeLimitMinutes,
+		StartedAt:        &now,
+		CreatedAt:        &now,
+	}
+	_, err = tx.Exec(`
+		INSERT INTO practice_matches (id, match_type, status, exercise_id, time_limit_minutes, started_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`, match.ID, match.MatchType, match.Status, match.ExerciseID, match.TimeLimitMinutes, match.StartedAt, match.CreatedAt)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create duel: %w", err)
+	}
+
+	userIDs := []uuid.UUID{entry.UserID, opponent.UserID}
+	for _, userID := range userIDs {
+		_, err = tx.Exec(`
+			INSERT INTO match_participants (id, match_id, user_id, score, result, xp_earned, joined_at)
+			VALUES ($1, $2, $3, 0, '', 0, $4)
+		`, uuid.New(), match.ID, userID, now)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to add duel participant: %w", err)
+		}
+	}
+
+	_, err = tx.Exec(`
+		UPDATE matchmaking_queue
+		SET status = 'matched', match_id = $3, matched_at = $4
+		WHERE id IN ($1, $2)
+	`, entry.ID, opponent.ID, match.ID, now)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to update queue entries: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, nil, err
+	}
+	return match, userIDs, nil
+}
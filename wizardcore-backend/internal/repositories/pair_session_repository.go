@@ -0,0 +1,285 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yourusername/wizardcore-backend/internal/models"
+)
+
+// ErrRevisionConflict is returned when a pair session buffer was changed
+// by someone else between reading it and writing new operations.
+var ErrRevisionConflict = errors.New("pair session revision conflict")
+
+type PairSessionRepository struct {
+	db *sql.DB
+}
+
+func NewPairSessionRepository(db *sql.DB) *PairSessionRepository {
+	return &PairSessionRepository{db: db}
+}
+
+const pairSessionColumns = `
+	id, exercise_id, language_id, host_user_id, partner_user_id, invite_code,
+	status, content, revision, created_at, updated_at, ended_at
+`
+
+func scanPairSession(row interface{ Scan(...interface{}) error }) (*models.PairSession, error) {
+	var s models.PairSession
+	var partnerID uuid.NullUUID
+	err := row.Scan(
+		&s.ID,
+		&s.ExerciseID,
+		&s.LanguageID,
+		&s.HostUserID,
+		&partnerID,
+		&s.InviteCode,
+		&s.Status,
+		&s.Content,
+		&s.Revision,
+		&s.CreatedAt,
+		&s.UpdatedAt,
+		&s.EndedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if partnerID.Valid {
+		s.PartnerUserID = &partnerID.UUID
+	}
+	return &s, nil
+}
+
+func (r *PairSessionRepository) Create(session *models.PairSession) error {
+	query := `
+		INSERT INTO pair_sessions (
+			id, exercise_id, language_id, host_user_id, invite_code,
+			status, content, revision, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING created_at, updated_at
+	`
+	if session.ID == uuid.Nil {
+		session.ID = uuid.New()
+	}
+	now := time.Now()
+	err := r.db.QueryRow(
+		query,
+		session.ID,
+		session.ExerciseID,
+		session.LanguageID,
+		session.HostUserID,
+		session.InviteCode,
+		session.Status,
+		session.Content,
+		session.Revision,
+		now,
+		now,
+	).Scan(&session.CreatedAt, &session.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create pair session: %w", err)
+	}
+	return nil
+}
+
+func (r *PairSessionRepository) FindByID(id uuid.UUID) (*models.PairSession, error) {
+	query := `SELECT ` + pairSessionColumns + ` FROM pair_sessions WHERE id = $1`
+	session, err := scanPairSession(r.db.QueryRow(query, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find pair session: %w", err)
+	}
+	return session, nil
+}
+
+func (r *PairSessionRepository) FindByInviteCode(code string) (*models.PairSession, error) {
+	query := `SELECT ` + pairSessionColumns + ` FROM pair_sessions WHERE invite_code = $1`
+	session, err := scanPairSession(r.db.QueryRow(query, code))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find pair session: %w", err)
+	}
+	return session, nil
+}
+
+// SetPartner claims the free seat of a pending session and activates it.
+// It returns false if the seat was already taken.
+func (r *PairSessionRepository) SetPartner(sessionID, partnerID uuid.UUID) (bool, error) {
+	query := `
+		UPDATE pair_sessions
+		SET partner_user_id = $2, status = 'active'
+		WHERE id = $1 AND status = 'pending' AND partner_user_id IS NULL
+	`
+	result, err := r.db.Exec(query, sessionID, partnerID)
+	if err != nil {
+		return false, fmt.Errorf("failed to join pair session: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return rows > 0, nil
+}
+
+// ClaimForSubmission marks an active session as submitting so that only
+// one submission of its buffer is graded at a time. It returns false if the
+// session was not active.
+func (r *PairSessionRepository) ClaimForSubmission(sessionID uuid.UUID) (bool, error) {
+	result, err := r.db.Exec(`
+		UPDATE pair_sessions SET status = 'submitting' WHERE id = $1 AND status = 'active'
+	`, sessionID)
+	if err != nil {
+		return false, fmt.Errorf("failed to claim pair session: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return rows > 0, nil
+}
+
+func (r *PairSessionRepository) UpdateStatus(sessionID uuid.UUID, status string) error {
+	query := `
+		UPDATE pair_sessions
+		SET status = $2,
+		    ended_at = CASE WHEN $2 IN ('submitted', 'closed') THEN CURRENT_TIMESTAMP ELSE ended_at END
+		WHERE id = $1
+	`
+	_, err := r.db.Exec(query, sessionID, status)
+	if err != nil {
+		return fmt.Errorf("failed to update pair session status: %w", err)
+	}
+	return nil
+}
+
+// AppendOperations stores operations applied on top of baseRevision together
+// with the resulting buffer. Operations are numbered baseRevision+1 onwards.
+// ErrRevisionConflict is returned if the stored revision has moved on.
+func (r *PairSessionRepository) AppendOperations(sessionID uuid.UUID, baseRevision int, content string, ops []models.PairOperation) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	newRevision := baseRevision + len(ops)
+	result, err := tx.Exec(`
+		UPDATE pair_sessions
+		SET content = $3, revision = $4
+		WHERE id = $1 AND revision = $2 AND status = 'active'
+	`, sessionID, baseRevision, content, newRevision)
+	if err != nil {
+		return fmt.Errorf("failed to update pair session buffer: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return ErrRevisionConflict
+	}
+
+	query := `
+		INSERT INTO pair_session_operations (
+			id, session_id, revision, user_id, op_type, position, text, length,
+			cursor_position, created_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	`
+	now := time.Now()
+	for i := range ops {
+		op := &ops[i]
+		if op.ID == uuid.Nil {
+			op.ID = uuid.New()
+		}
+		op.SessionID = sessionID
+		op.Revision = baseRevision + i + 1
+		op.CreatedAt = now
+		_, err := tx.Exec(
+			query,
+			op.ID,
+			op.SessionID,
+			op.Revision,
+			op.UserID,
+			op.Type,
+			op.Position,
+			op.Text,
+			op.Length,
+			op.CursorPosition,
+			op.CreatedAt,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to store pair operation: %w", err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// FindOperationsSince returns the operations with a revision greater than
+// since, oldest first.
+func (r *PairSessionRepository) FindOperationsSince(sessionID uuid.UUID, since, limit int) ([]models.PairOperation, error) {
+	query := `
+		SELECT id, session_id, revision, user_id, op_type, position,
+			COALESCE(text, ''), COALESCE(length, 0), cursor_position, created_at
+		FROM pair_session_operations
+		WHERE session_id = $1 AND revision > $2
+		ORDER BY revision ASC
+		LIMIT $3
+	`
+	rows, err := r.db.Query(query, sessionID, since, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query pair operations: %w", err)
+	}
+	defer rows.Close()
+
+	var ops []models.PairOperation
+	for rows.Next() {
+		var op models.PairOperation
+		var userID uuid.NullUUID
+		var cursor sql.NullInt64
+		err := rows.Scan(
+			&op.ID,
+			&op.SessionID,
+			&op.Revision,
+			&userID,
+			&op.Type,
+			&op.Position,
+			&op.Text,
+			&op.Length,
+			&cursor,
+			&op.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan pair operation: %w", err)
+		}
+		op.UserID = userID.UUID
+		if cursor.Valid {
+			pos := int(cursor.Int64)
+			op.CursorPosition = &pos
+		}
+		ops = append(ops, op)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+	return ops, nil
+}
+
+func (r *PairSessionRepository) RecordSubmission(sessionID, userID, submissionID uuid.UUID) error {
+	query := `
+		INSERT INTO pair_session_submissions (session_id, user_id, submission_id)
+		VALUES ($1, $2, $3)
+		ON CONFLICT DO NOTHING
+	`
+	_, err := r.db.Exec(query, sessionID, userID, submissionID)
+	if err != nil {
+		return fmt.Errorf("failed to record pair submission: %w", err)
+	}
+	return nil
+}
@@ -280,13 +280,30 @@ func (r *PathwayRepository) FindEnrollment(userID, pathwayID uuid.UUID) (*models
 	return &e, nil
 }
 
+// CreateEnrollment enrolls a user in a pathway. The enrollment's XP is
+// taken from the ledger, so XP earned in the pathway before enrolling counts,
+// and so do modules completed before enrolling.
 func (r *PathwayRepository) CreateEnrollment(enrollment *models.UserPathwayEnrollment) error {
 	query := `
+		WITH modules_done AS (
+			SELECT COUNT(*)::int AS total, COUNT(ump.completed_at)::int AS completed
+			FROM modules m
+			LEFT JOIN user_module_progress ump ON ump.module_id = m.id AND ump.user_id = $2
+			WHERE m.pathway_id = $3
+		)
 		INSERT INTO user_pathway_enrollments (
 			id, user_id, pathway_id, progress_percentage, completed_modules,
 			xp_earned, streak_days, last_activity_at, enrolled_at, completed_at
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
-		RETURNING id, enrolled_at
+		)
+		SELECT
+			$1, $2, $3,
+			GREATEST($4, CASE WHEN d.total > 0 THEN d.completed * 100 / d.total ELSE 0 END),
+			GREATEST($5, d.completed),
+			(SELECT COALESCE(SUM(amount), 0) FROM xp_transactions WHERE user_id = $2 AND pathway_id = $3),
+			$6, $7, $8,
+			COALESCE($9, CASE WHEN d.total > 0 AND d.completed = d.total THEN $8::timestamp END)
+		FROM modules_done d
+		RETURNING id, enrolled_at, xp_earned, progress_percentage, completed_modules, completed_at
 	`
 	if enrollment.ID == uuid.Nil {
 		enrollment.ID = uuid.New()
@@ -302,40 +319,39 @@ func (r *PathwayRepository) CreateEnrollment(enrollment *models.UserPathwayEnrol
 		enrollment.PathwayID,
 		enrollment.ProgressPercentage,
 		enrollment.CompletedModules,
-		enrollment.XPEarned,
 		enrollment.StreakDays,
 		enrollment.LastActivityAt,
 		enrollment.EnrolledAt,
 		enrollment.CompletedAt,
-	).Scan(&enrollment.ID, &enrollment.EnrolledAt)
+	).Scan(&enrollment.ID, &enrollment.EnrolledAt, &enrollment.XPEarned, &enrollment.ProgressPercentage, &enrollment.CompletedModules, &enrollment.CompletedAt)
 	if err != nil {
 		return fmt.Errorf("failed to create enrollment: %w", err)
 	}
 	return nil
 }
 
+// UpdateEnrollment stores an enrollment's progress. Its XP is left to the
+// ledger.
 func (r *PathwayRepository) UpdateEnrollment(enrollment *models.UserPathwayEnrollment) error {
 	query := `
 		UPDATE user_pathway_enrollments
 		SET progress_percentage = $2,
 		    completed_modules = $3,
-		    xp_earned = $4,
-		    streak_days = $5,
-		    last_activity_at = $6,
-		    completed_at = $7
+		    streak_days = $4,
+		    last_activity_at = $5,
+		    completed_at = $6
 		WHERE id = $1
-		RETURNING id
+		RETURNING xp_earned
 	`
 	err := r.db.QueryRow(
 		query,
 		enrollment.ID,
 		enrollment.ProgressPercentage,
 		enrollment.CompletedModules,
-		enrollment.XPEarned,
 		enrollment.StreakDays,
 		enrollment.LastActivityAt,
 		enrollment.CompletedAt,
-	).Scan(&enrollment.ID)
+	).Scan(&enrollment.XPEarned)
 	if err != nil {
 		return fmt.Errorf("failed to update enrollment: %w", err)
 	}
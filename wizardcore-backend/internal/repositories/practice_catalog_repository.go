@@ -0,0 +1,253 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/yourusername/wizardcore-backend/internal/models"
+)
+
+// Gradient for practice areas an admin has not styled yet
+const defaultAreaGradient = "from-slate-400 to-slate-600"
+
+// PracticeCatalogRepository stores the challenge types and practice area
+// presentation shown on the practice page
+type PracticeCatalogRepository struct {
+	db *sql.DB
+}
+
+func NewPracticeCatalogRepository(db *sql.DB) *PracticeCatalogRepository {
+	return &PracticeCatalogRepository{db: db}
+}
+
+const challengeTypeColumns = `
+	id, name, description, icon, time_limit_minutes, xp_multiplier, sort_order, is_enabled, updated_at
+`
+
+func scanChallengeType(row interface{ Scan(...interface{}) error }) (*models.ChallengeType, error) {
+	var ct models.ChallengeType
+	err := row.Scan(
+		&ct.ID,
+		&ct.Name,
+		&ct.Description,
+		&ct.Icon,
+		&ct.TimeLimitMinutes,
+		&ct.XPMultiplier,
+		&ct.SortOrder,
+		&ct.IsEnabled,
+		&ct.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &ct, nil
+}
+
+// ListChallengeTypes returns the challenge types in display order
+func (r *PracticeCatalogRepository) ListChallengeTypes(includeDisabled bool) ([]models.ChallengeType, error) {
+	rows, err := r.db.Query(`
+		SELECT `+challengeTypeColumns+`
+		FROM challenge_types
+		WHERE is_enabled OR $1
+		ORDER BY sort_order, id
+	`, includeDisabled)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list challenge types: %w", err)
+	}
+	defer rows.Close()
+
+	types := []models.ChallengeType{}
+	for rows.Next() {
+		ct, err := scanChallengeType(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan challenge type: %w", err)
+		}
+		types = append(types, *ct)
+	}
+	return types, rows.Err()
+}
+
+// FindChallengeType returns a challenge type by ID
+func (r *PracticeCatalogRepository) FindChallengeType(id string) (*models.ChallengeType, error) {
+	ct, err := scanChallengeType(r.db.QueryRow(`
+		SELECT `+challengeTypeColumns+`
+		FROM challenge_types
+		WHERE id = $1
+	`, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find challenge type: %w", err)
+	}
+	return ct, nil
+}
+
+// UpdateChallengeType saves the configurable fields of a challenge type
+func (r *PracticeCatalogRepository) UpdateChallengeType(ct *models.ChallengeType) error {
+	err := r.db.QueryRow(`
+		UPDATE challenge_types
+		SET name = $2, description = $3, icon = $4, time_limit_minutes = $5,
+			xp_multiplier = $6, sort_order = $7, is_enabled = $8
+		WHERE id = $1
+		RETURNING updated_at
+	`, ct.ID, ct.Name, ct.Description, ct.Icon, ct.TimeLimitMinutes,
+		ct.XPMultiplier, ct.SortOrder, ct.IsEnabled).Scan(&ct.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to update challenge type: %w", err)
+	}
+	return nil
+}
+
+// ComputeAreas groups published exercises into practice areas, one per
+// language and one per tag, with the number of them the user has solved.
+// Area settings supply names, colors and icons and can hide an area.
+func (r *PracticeCatalogRepository) ComputeAreas(userID uuid.UUID) ([]models.PracticeArea, error) {
+	rows, err := r.db.Query(`
+		WITH published AS (
+			SELECT id, language_id, tags
+			FROM exercises
+			WHERE COALESCE(status, 'published') = 'published'
+		),
+		areas AS (
+			SELECT 'language' AS kind, language_id::text AS area_key, id
+			FROM published
+			WHERE language_id IS NOT NULL
+			UNION
+			SELECT 'tag', LOWER(tag), id
+			FROM published, UNNEST(tags) AS tag
+		),
+		solved AS (
+			SELECT DISTINCT exercise_id
+			FROM submissions
+			WHERE user_id = $1 AND status = 'accepted'
+		)
+		SELECT a.kind, a.area_key,
+			COUNT(*) AS exercise_count,
+			COUNT(s.exercise_id) AS completed_count,
+			ps.name, ps.color_gradient, ps.icon
+		FROM areas a
+		LEFT JOIN solved s ON s.exercise_id = a.id
+		LEFT JOIN practice_area_settings ps ON ps.kind = a.kind AND ps.area_key = a.area_key
+		WHERE COALESCE(ps.is_visible, true)
+		GROUP BY a.kind, a.area_key, ps.name, ps.color_gradient, ps.icon, ps.sort_order
+		ORDER BY COALESCE(ps.sort_order, 1000), exercise_count DESC, a.area_key
+	`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute practice areas: %w", err)
+	}
+	defer rows.Close()
+
+	areas := []models.PracticeArea{}
+	for rows.Next() {
+		var area models.PracticeArea
+		var name, gradient sql.NullString
+		if err := rows.Scan(
+			&area.Kind,
+			&area.Key,
+			&area.ExerciseCount,
+			&area.CompletedCount,
+			&name,
+			&gradient,
+			&area.Icon,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan practice area: %w", err)
+		}
+		switch {
+		case name.Valid:
+			area.Name = name.String
+		case area.Kind == "language":
+			area.Name = "Language " + area.Key
+		default:
+			area.Name = area.Key
+		}
+		area.ColorGradient = defaultAreaGradient
+		if gradient.Valid {
+			area.ColorGradient = gradient.String
+		}
+		areas = append(areas, area)
+	}
+	return areas, rows.Err()
+}
+
+const areaSettingColumns = `
+	id, kind, area_key, name, color_gradient, icon, sort_order, is_visible, created_at, updated_at
+`
+
+func scanAreaSetting(row interface{ Scan(...interface{}) error }) (*models.PracticeAreaSetting, error) {
+	var s models.PracticeAreaSetting
+	err := row.Scan(
+		&s.ID,
+		&s.Kind,
+		&s.Key,
+		&s.Name,
+		&s.ColorGradient,
+		&s.Icon,
+		&s.SortOrder,
+		&s.IsVisible,
+		&s.CreatedAt,
+		&s.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// ListAreaSettings returns every practice area setting
+func (r *PracticeCatalogRepository) ListAreaSettings() ([]models.PracticeAreaSetting, error) {
+	rows, err := r.db.Query(`
+		SELECT ` + areaSettingColumns + `
+		FROM practice_area_settings
+		ORDER BY sort_order, kind, area_key
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list practice area settings: %w", err)
+	}
+	defer rows.Close()
+
+	settings := []models.PracticeAreaSetting{}
+	for rows.Next() {
+		s, err := scanAreaSetting(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan practice area setting: %w", err)
+		}
+		settings = append(settings, *s)
+	}
+	return settings, rows.Err()
+}
+
+// UpsertAreaSetting creates or replaces the setting for an area
+func (r *PracticeCatalogRepository) UpsertAreaSetting(s *models.PracticeAreaSetting) error {
+	saved, err := scanAreaSetting(r.db.QueryRow(`
+		INSERT INTO practice_area_settings (kind, area_key, name, color_gradient, icon, sort_order, is_visible)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (kind, area_key) DO UPDATE
+		SET name = EXCLUDED.name,
+			color_gradient = EXCLUDED.color_gradient,
+			icon = EXCLUDED.icon,
+			sort_order = EXCLUDED.sort_order,
+			is_visible = EXCLUDED.is_visible
+		RETURNING `+areaSettingColumns,
+		s.Kind, s.Key, s.Name, s.ColorGradient, s.Icon, s.SortOrder, s.IsVisible))
+	if err != nil {
+		return fmt.Errorf("failed to save practice area setting: %w", err)
+	}
+	*s = *saved
+	return nil
+}
+
+// DeleteAreaSetting removes a setting, returning the area to its defaults.
+// It reports whether the setting existed.
+func (r *PracticeCatalogRepository) DeleteAreaSetting(id uuid.UUID) (bool, error) {
+	res, err := r.db.Exec(`DELETE FROM practice_area_settings WHERE id = $1`, id)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete practice area setting: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
@@ -24,7 +24,7 @@ func (r *PreferencesRepository) GetUserPreferences(ctx context.Context, userID u
 		SELECT 
 			user_id, theme, language, email_notifications, push_notifications,
 			public_profile, show_progress, auto_save, sound_effects, two_factor_enabled,
-			created_at, updated_at
+			timezone, created_at, updated_at
 		FROM user_preferences
 		WHERE user_id = $1
 	`
@@ -41,6 +41,7 @@ func (r *PreferencesRepository) GetUserPreferences(ctx context.Context, userID u
 		&preferences.AutoSave,
 		&preferences.SoundEffects,
 		&preferences.TwoFactorEnabled,
+		&preferences.Timezone,
 		&preferences.CreatedAt,
 		&preferences.UpdatedAt,
 	)
@@ -104,8 +105,8 @@ func (r *PreferencesRepository) createDefaultPreferences(ctx context.Context, us
 		INSERT INTO user_preferences (
 			user_id, theme, language, email_notifications, push_notifications,
 			public_profile, show_progress, auto_save, sound_effects, two_factor_enabled,
-			created_at, updated_at
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+			timezone, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
 		RETURNING created_at, updated_at
 	`
 
@@ -121,6 +122,7 @@ func (r *PreferencesRepository) createDefaultPreferences(ctx context.Context, us
 		AutoSave:           true,
 		SoundEffects:       true,
 		TwoFactorEnabled:   false,
+		Timezone:           "UTC",
 		CreatedAt:          now,
 		UpdatedAt:          now,
 	}
@@ -136,6 +138,7 @@ func (r *PreferencesRepository) createDefaultPreferences(ctx context.Context, us
 		defaultPrefs.AutoSave,
 		defaultPrefs.SoundEffects,
 		defaultPrefs.TwoFactorEnabled,
+		defaultPrefs.Timezone,
 		defaultPrefs.CreatedAt,
 		defaultPrefs.UpdatedAt,
 	).Scan(&defaultPrefs.CreatedAt, &defaultPrefs.UpdatedAt)
@@ -161,6 +164,7 @@ func (r *PreferencesRepository) createPreferencesWithUpdates(ctx context.Context
 		AutoSave:           true,
 		SoundEffects:       true,
 		TwoFactorEnabled:   false,
+		Timezone:           "UTC",
 		CreatedAt:          time.Now(),
 		UpdatedAt:          time.Now(),
 	}
@@ -193,13 +197,16 @@ func (r *PreferencesRepository) createPreferencesWithUpdates(ctx context.Context
 	if twoFactorEnabled, ok := updates["two_factor_enabled"].(bool); ok {
 		defaultPrefs.TwoFactorEnabled = twoFactorEnabled
 	}
+	if timezone, ok := updates["timezone"].(string); ok {
+		defaultPrefs.Timezone = timezone
+	}
 
 	query := `
 		INSERT INTO user_preferences (
 			user_id, theme, language, email_notifications, push_notifications,
 			public_profile, show_progress, auto_save, sound_effects, two_factor_enabled,
-			created_at, updated_at
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+			timezone, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
 	`
 
 	_, err := r.db.ExecContext(ctx, query,
@@ -213,6 +220,7 @@ func (r *PreferencesRepository) createPreferencesWithUpdates(ctx context.Context
 		defaultPrefs.AutoSave,
 		defaultPrefs.SoundEffects,
 		defaultPrefs.TwoFactorEnabled,
+		defaultPrefs.Timezone,
 		defaultPrefs.CreatedAt,
 		defaultPrefs.UpdatedAt,
 	)
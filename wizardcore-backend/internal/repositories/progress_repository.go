@@ -2,18 +2,21 @@ package repositories
 
 import (
 	"database/sql"
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/yourusername/wizardcore-backend/internal/models"
+	"github.com/yourusername/wizardcore-backend/internal/streak"
 )
 
 type ProgressRepository struct {
-	db *sql.DB
+	db     *sql.DB
+	xpRepo *XPRepository
 }
 
-func NewProgressRepository(db *sql.DB) *ProgressRepository {
-	return &ProgressRepository{db: db}
+func NewProgressRepository(db *sql.DB, xpRepo *XPRepository) *ProgressRepository {
+	return &ProgressRepository{db: db, xpRepo: xpRepo}
 }
 
 // GetUserPathwayProgress returns progress for all pathways a user is enrolled in
@@ -258,20 +261,75 @@ func (r *ProgressRepository) GetWeeklyHours(userID uuid.UUID) (*models.WeeklyHou
 	}, nil
 }
 
-// RecordDailyActivity records or updates daily activity for a user
-func (r *ProgressRepository) RecordDailyActivity(userID uuid.UUID, date time.Time, exercisesCompleted, xpEarned, timeSpentMinutes, submissionsCount int, streakMaintained bool) error {
+// RecordDailyActivity adds activity at a moment to the user's day, counted
+// in their timezone, and extends their streak with it. It returns the
+// streak afterwards and what the activity did to it.
+func (r *ProgressRepository) RecordDailyActivity(userID uuid.UUID, at time.Time, exercisesCompleted, xpEarned, timeSpentMinutes, submissionsCount int) (streak.State, streak.Change, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return streak.State{}, streak.Change{}, err
+	}
+	defer tx.Rollback()
+
+	today, err := localDay(tx, userID, at)
+	if err != nil {
+		return streak.State{}, streak.Change{}, err
+	}
+
 	query := `
 		INSERT INTO user_daily_activity (user_id, activity_date, exercises_completed, xp_earned, time_spent_minutes, submissions_count, streak_maintained)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		VALUES ($1, $2, $3, $4, $5, $6, true)
 		ON CONFLICT (user_id, activity_date) DO UPDATE SET
-			exercises_completed = EXCLUDED.exercises_completed,
-			xp_earned = EXCLUDED.xp_earned,
-			time_spent_minutes = EXCLUDED.time_spent_minutes,
-			submissions_count = EXCLUDED.submissions_count,
-			streak_maintained = EXCLUDED.streak_maintained
+			exercises_completed = user_daily_activity.exercises_completed + EXCLUDED.exercises_completed,
+			xp_earned = user_daily_activity.xp_earned + EXCLUDED.xp_earned,
+			time_spent_minutes = user_daily_activity.time_spent_minutes + EXCLUDED.time_spent_minutes,
+			submissions_count = user_daily_activity.submissions_count + EXCLUDED.submissions_count,
+			streak_maintained = true
 	`
-	_, err := r.db.Exec(query, userID, date, exercisesCompleted, xpEarned, timeSpentMinutes, submissionsCount, streakMaintained)
-	return err
+	if _, err := tx.Exec(query, userID, today.Format(dateLayout), exercisesCompleted, xpEarned, timeSpentMinutes, submissionsCount); err != nil {
+		return streak.State{}, streak.Change{}, fmt.Errorf("failed to record daily activity: %w", err)
+	}
+
+	state, change, err := recordStreak(tx, userID, today)
+	if err != nil {
+		return streak.State{}, streak.Change{}, err
+	}
+	if err := tx.Commit(); err != nil {
+		return streak.State{}, streak.Change{}, err
+	}
+	return state, change, nil
+}
+
+// localDay returns the calendar day of a moment in the user's timezone. q
+// is the database or a transaction.
+func localDay(q interface {
+	QueryRow(string, ...interface{}) *sql.Row
+}, userID uuid.UUID, at time.Time) (time.Time, error) {
+	var timezone string
+	if err := q.QueryRow(`
+		SELECT COALESCE((SELECT timezone FROM user_preferences WHERE user_id = $1), 'UTC')
+	`, userID).Scan(&timezone); err != nil {
+		return time.Time{}, fmt.Errorf("failed to get timezone: %w", err)
+	}
+	return streak.Day(at, streak.Location(timezone)), nil
+}
+
+// addDailyMinutes adds time on task to the user's day within tx. Unlike
+// RecordDailyActivity it does not count towards the streak.
+func addDailyMinutes(tx *sql.Tx, userID uuid.UUID, at time.Time, minutes int) error {
+	today, err := localDay(tx, userID, at)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`
+		INSERT INTO user_daily_activity (user_id, activity_date, time_spent_minutes)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (user_id, activity_date) DO UPDATE SET
+			time_spent_minutes = user_daily_activity.time_spent_minutes + EXCLUDED.time_spent_minutes
+	`, userID, today.Format(dateLayout), minutes); err != nil {
+		return fmt.Errorf("failed to record time on task: %w", err)
+	}
+	return nil
 }
 
 // AddMilestone adds a new milestone for a user
@@ -283,3 +341,204 @@ func (r *ProgressRepository) AddMilestone(milestone *models.Milestone) error {
 	_, err := r.db.Exec(query, milestone.ID, milestone.UserID, milestone.Title, milestone.Description, milestone.MilestoneType, milestone.XPAwarded, milestone.AchievedAt)
 	return err
 }
+
+// RecordExerciseSolved brings the user's progress on the module of a solved
+// exercise up to date from their accepted submissions, counting only the
+// module's published exercises. When that completes the module it awards
+// the module's XP, adds a milestone and updates the user's enrollment in
+// the pathway, completing it with the last module.
+// It returns the completed module, or nil if no module was completed.
+func (r *ProgressRepository) RecordExerciseSolved(userID, exerciseID uuid.UUID, at time.Time) (*models.ModuleCompletion, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var moduleID uuid.UUID
+	var pathwayID uuid.NullUUID
+	var moduleTitle, pathwayTitle string
+	var xpReward int
+	err = tx.QueryRow(`
+		SELECT m.id, m.title, COALESCE(m.xp_reward, 0), m.pathway_id, COALESCE(p.title, '')
+		FROM exercises e
+		JOIN modules m ON e.module_id = m.id
+		LEFT JOIN pathways p ON m.pathway_id = p.id
+		WHERE e.id = $1
+	`, exerciseID).Scan(&moduleID, &moduleTitle, &xpReward, &pathwayID, &pathwayTitle)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find exercise module: %w", err)
+	}
+
+	if _, err := tx.Exec(`
+		INSERT INTO user_module_progress (user_id, module_id, pathway_id, started_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (user_id, module_id) DO NOTHING
+	`, userID, moduleID, pathwayID, at); err != nil {
+		return nil, fmt.Errorf("failed to start module progress: %w", err)
+	}
+	var completedAt sql.NullTime
+	if err := tx.QueryRow(`
+		SELECT completed_at FROM user_module_progress
+		WHERE user_id = $1 AND module_id = $2
+		FOR UPDATE
+	`, userID, moduleID).Scan(&completedAt); err != nil {
+		return nil, fmt.Errorf("failed to lock module progress: %w", err)
+	}
+
+	var total, solved, xpEarned int
+	if err := tx.QueryRow(`
+		SELECT
+			COUNT(*),
+			COUNT(*) FILTER (WHERE EXISTS (
+				SELECT 1 FROM submissions s
+				WHERE s.exercise_id = e.id AND s.user_id = $2 AND s.is_correct = true
+			)),
+			COALESCE((
+				SELECT SUM(s.points_earned)
+				FROM submissions s
+				JOIN exercises x ON s.exercise_id = x.id
+				WHERE x.module_id = $1 AND s.user_id = $2
+			), 0)
+		FROM exercises e
+		WHERE e.module_id = $1 AND COALESCE(e.status, 'published') = 'published'
+	`, moduleID, userID).Scan(&total, &solved, &xpEarned); err != nil {
+		return nil, fmt.Errorf("failed to count module exercises: %w", err)
+	}
+	percentage := 0
+	if total > 0 {
+		percentage = solved * 100 / total
+	}
+	completed := !completedAt.Valid && total > 0 && solved == total
+	if _, err := tx.Exec(`
+		UPDATE user_module_progress
+		SET completed_exercises = $3,
+		    total_exercises = $4,
+		    progress_percentage = $5,
+		    xp_earned = $6,
+		    last_activity_at = $7,
+		    completed_at = CASE WHEN $8 THEN $7 ELSE completed_at END
+		WHERE user_id = $1 AND module_id = $2
+	`, userID, moduleID, solved, total, percentage, xpEarned, at, completed); err != nil {
+		return nil, fmt.Errorf("failed to update module progress: %w", err)
+	}
+	if !completed {
+		if err := tx.Commit(); err != nil {
+			return nil, err
+		}
+		return nil, nil
+	}
+
+	completion := &models.ModuleCompletion{
+		ModuleID:    moduleID,
+		ModuleTitle: moduleTitle,
+		XPAwarded:   xpReward,
+	}
+	if pathwayID.Valid {
+		completion.PathwayID = &pathwayID.UUID
+	}
+	var awards []models.XPTransaction
+	if xpReward > 0 {
+		sourceID := moduleID
+		t := &models.XPTransaction{
+			UserID:         userID,
+			Amount:         xpReward,
+			SourceType:     models.XPSourceModuleCompletion,
+			SourceID:       &sourceID,
+			PathwayID:      completion.PathwayID,
+			IdempotencyKey: fmt.Sprintf("module_completion:%s:%s", moduleID, userID),
+			CreatedAt:      at,
+		}
+		awarded, err := awardXP(tx, t)
+		if err != nil {
+			return nil, err
+		}
+		if awarded {
+			awards = append(awards, *t)
+		}
+	}
+	if err := addMilestone(tx, userID, "Completed "+moduleTitle, "module", xpReward, at); err != nil {
+		return nil, err
+	}
+
+	if pathwayID.Valid {
+		pathwayCompleted, err := updateEnrollmentProgress(tx, userID, pathwayID.UUID, at)
+		if err != nil {
+			return nil, err
+		}
+		if pathwayCompleted {
+			completion.PathwayCompleted = true
+			completion.PathwayTitle = pathwayTitle
+			if err := addMilestone(tx, userID, "Completed "+pathwayTitle, "pathway", 0, at); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if err := r.xpRepo.commit(tx, awards); err != nil {
+		return nil, err
+	}
+	return completion, nil
+}
+
+// updateEnrollmentProgress recounts the completed modules of the user's
+// enrollment in a pathway within tx, out of the modules with published
+// exercises. It reports whether that completed the
+// enrollment; a user who is not enrolled is left alone.
+func updateEnrollmentProgress(tx *sql.Tx, userID, pathwayID uuid.UUID, at time.Time) (bool, error) {
+	var completedAt sql.NullTime
+	err := tx.QueryRow(`
+		SELECT completed_at FROM user_pathway_enrollments
+		WHERE user_id = $1 AND pathway_id = $2
+		FOR UPDATE
+	`, userID, pathwayID).Scan(&completedAt)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to lock enrollment: %w", err)
+	}
+
+	var total, completedModules int
+	if err := tx.QueryRow(`
+		SELECT COUNT(*), COUNT(ump.completed_at)
+		FROM modules m
+		LEFT JOIN user_module_progress ump ON ump.module_id = m.id AND ump.user_id = $2
+		WHERE m.pathway_id = $1
+		AND EXISTS (
+			SELECT 1 FROM exercises e
+			WHERE e.module_id = m.id AND COALESCE(e.status, 'published') = 'published'
+		)
+	`, pathwayID, userID).Scan(&total, &completedModules); err != nil {
+		return false, fmt.Errorf("failed to count completed modules: %w", err)
+	}
+	percentage := 0
+	if total > 0 {
+		percentage = completedModules * 100 / total
+	}
+	completed := !completedAt.Valid && total > 0 && completedModules == total
+	if _, err := tx.Exec(`
+		UPDATE user_pathway_enrollments
+		SET completed_modules = $3,
+		    progress_percentage = $4,
+		    last_activity_at = $5,
+		    completed_at = CASE WHEN $6 THEN $5 ELSE completed_at END
+		WHERE user_id = $1 AND pathway_id = $2
+	`, userID, pathwayID, completedModules, percentage, at, completed); err != nil {
+		return false, fmt.Errorf("failed to update enrollment progress: %w", err)
+	}
+	return completed, nil
+}
+
+func addMilestone(tx *sql.Tx, userID uuid.UUID, title, milestoneType string, xpAwarded int, at time.Time) error {
+	if _, err := tx.Exec(`
+		INSERT INTO user_milestones (id, user_id, title, milestone_type, xp_awarded, achieved_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`, uuid.New(), userID, title, milestoneType, xpAwarded, at); err != nil {
+		return fmt.Errorf("failed to add milestone: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/yourusername/wizardcore-backend/internal/recommend"
+)
+
+// RecommendationRepository loads what the recommender knows about a
+// learner and the exercises it can recommend to them
+type RecommendationRepository struct {
+	db *sql.DB
+}
+
+func NewRecommendationRepository(db *sql.DB) *RecommendationRepository {
+	return &RecommendationRepository{db: db}
+}
+
+// GetProfile returns a learner's success rate per tag and the exercises
+// they solved per difficulty
+func (r *RecommendationRepository) GetProfile(userID uuid.UUID) (recommend.Profile, error) {
+	p := recommend.Profile{Tags: map[string]recommend.TagStat{}, Solved: map[string]int{}}
+
+	rows, err := r.db.Query(`
+		SELECT t.tag, COUNT(*), COUNT(*) FILTER (WHERE s.is_correct = true)
+		FROM submissions s
+		JOIN exercises e ON s.exercise_id = e.id
+		CROSS JOIN LATERAL unnest(e.tags) AS t(tag)
+		WHERE s.user_id = $1
+		AND COALESCE(s.submission_type, 'solution') <> 'draft'
+		AND s.status NOT IN ('pending', 'processing', 'judge0_error')
+		GROUP BY t.tag
+	`, userID)
+	if err != nil {
+		return p, fmt.Errorf("failed to get tag success rates: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var tag string
+		var stat recommend.TagStat
+		if err := rows.Scan(&tag, &stat.Attempts, &stat.Correct); err != nil {
+			return p, fmt.Errorf("failed to scan tag success rate: %w", err)
+		}
+		p.Tags[tag] = stat
+	}
+	if err := rows.Err(); err != nil {
+		return p, err
+	}
+
+	solved, err := r.db.Query(`
+		SELECT e.difficulty, COUNT(DISTINCT e.id)
+		FROM submissions s
+		JOIN exercises e ON s.exercise_id = e.id
+		WHERE s.user_id = $1 AND s.is_correct = true
+		GROUP BY e.difficulty
+	`, userID)
+	if err != nil {
+		return p, fmt.Errorf("failed to count solved exercises: %w", err)
+	}
+	defer solved.Close()
+	for solved.Next() {
+		var difficulty string
+		var count int
+		if err := solved.Scan(&difficulty, &count); err != nil {
+			return p, fmt.Errorf("failed to scan solved exercises: %w", err)
+		}
+		p.Solved[difficulty] = count
+	}
+	return p, solved.Err()
+}
+
+// ListCandidates returns the published exercises a learner has not solved,
+// with how they relate to the learner's enrollments and modules
+func (r *RecommendationRepository) ListCandidates(userID uuid.UUID) ([]recommend.Candidate, error) {
+	rows, err := r.db.Query(`
+		WITH unsolved AS (
+			SELECT e.*
+			FROM exercises e
+			WHERE COALESCE(e.status, 'published') = 'published'
+			AND NOT EXISTS (
+				SELECT 1 FROM submissions s
+				WHERE s.exercise_id = e.id AND s.user_id = $1 AND s.is_correct = true
+			)
+		)
+		SELECT
+			e.id, e.title, e.difficulty, e.tags,
+			COALESCE(m.title, ''), COALESCE(p.title, ''),
+			upe.id IS NOT NULL AND upe.completed_at IS NULL,
+			ump.id IS NOT NULL AND ump.completed_at IS NULL,
+			e.module_id IS NOT NULL AND e.sort_order = MIN(e.sort_order) OVER (PARTITION BY e.module_id),
+			EXISTS (
+				SELECT 1 FROM submissions s
+				WHERE s.exercise_id = e.id AND s.user_id = $1
+				AND COALESCE(s.submission_type, 'solution') <> 'draft'
+			)
+		FROM unsolved e
+		LEFT JOIN modules m ON e.module_id = m.id
+		LEFT JOIN pathways p ON m.pathway_id = p.id
+		LEFT JOIN user_pathway_enrollments upe ON upe.pathway_id = m.pathway_id AND upe.user_id = $1
+		LEFT JOIN user_module_progress ump ON ump.module_id = e.module_id AND ump.user_id = $1
+	`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list candidate exercises: %w", err)
+	}
+	defer rows.Close()
+	var candidates []recommend.Candidate
+	for rows.Next() {
+		var c recommend.Candidate
+		var tags pq.StringArray
+		if err := rows.Scan(
+			&c.ExerciseID, &c.Title, &c.Difficulty, &tags,
+			&c.ModuleTitle, &c.PathwayTitle,
+			&c.Enrolled, &c.ModuleStarted, &c.NextInModule, &c.Attempted,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan candidate exercise: %w", err)
+		}
+		c.Tags = tags
+		candidates = append(candidates, c)
+	}
+	return candidates, rows.Err()
+}
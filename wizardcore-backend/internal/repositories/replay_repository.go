@@ -0,0 +1,160 @@
+package repositories
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yourusername/wizardcore-backend/internal/models"
+)
+
+type ReplayRepository struct {
+	db *sql.DB
+}
+
+func NewReplayRepository(db *sql.DB) *ReplayRepository {
+	return &ReplayRepository{db: db}
+}
+
+const replayEventColumns = `
+	id, match_id, user_id, event_type, offset_ms, code, operations, language_id,
+	exercise_id, submission_id, result, score, created_at
+`
+
+func scanReplayEvent(row interface{ Scan(...interface{}) error }) (*models.ReplayEvent, error) {
+	var e models.ReplayEvent
+	var operations []byte
+	var exerciseID, submissionID uuid.NullUUID
+	err := row.Scan(
+		&e.ID,
+		&e.MatchID,
+		&e.UserID,
+		&e.EventType,
+		&e.OffsetMs,
+		&e.Code,
+		&operations,
+		&e.LanguageID,
+		&exerciseID,
+		&submissionID,
+		&e.Result,
+		&e.Score,
+		&e.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if len(operations) > 0 {
+		if err := json.Unmarshal(operations, &e.Operations); err != nil {
+			return nil, fmt.Errorf("failed to decode replay operations: %w", err)
+		}
+	}
+	if exerciseID.Valid {
+		e.ExerciseID = &exerciseID.UUID
+	}
+	if submissionID.Valid {
+		e.SubmissionID = &submissionID.UUID
+	}
+	return &e, nil
+}
+
+// AddEvent appends an event to a match timeline
+func (r *ReplayRepository) AddEvent(e *models.ReplayEvent) error {
+	var operations interface{}
+	if len(e.Operations) > 0 {
+		data, err := json.Marshal(e.Operations)
+		if err != nil {
+			return fmt.Errorf("failed to encode replay operations: %w", err)
+		}
+		operations = data
+	}
+	err := r.db.QueryRow(`
+		INSERT INTO match_replay_events (match_id, user_id, event_type, offset_ms, code, operations,
+			language_id, exercise_id, submission_id, result, score)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING id, created_at
+	`, e.MatchID, e.UserID, e.EventType, e.OffsetMs, e.Code, operations,
+		e.LanguageID, e.ExerciseID, e.SubmissionID, e.Result, e.Score).Scan(&e.ID, &e.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to add replay event: %w", err)
+	}
+	return nil
+}
+
+// GetEvents returns the timeline of a match ordered by offset
+func (r *ReplayRepository) GetEvents(matchID uuid.UUID) ([]models.ReplayEvent, error) {
+	rows, err := r.db.Query(`
+		SELECT `+replayEventColumns+`
+		FROM match_replay_events
+		WHERE match_id = $1
+		ORDER BY offset_ms, id
+	`, matchID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get replay events: %w", err)
+	}
+	defer rows.Close()
+
+	events := []models.ReplayEvent{}
+	for rows.Next() {
+		e, err := scanReplayEvent(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan replay event: %w", err)
+		}
+		events = append(events, *e)
+	}
+	return events, rows.Err()
+}
+
+// CountSnapshots returns how many snapshots of a participant are stored
+func (r *ReplayRepository) CountSnapshots(matchID, userID uuid.UUID) (int, error) {
+	var count int
+	err := r.db.QueryRow(`
+		SELECT COUNT(*) FROM match_replay_events
+		WHERE match_id = $1 AND user_id = $2 AND event_type = 'snapshot'
+	`, matchID, userID).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count replay snapshots: %w", err)
+	}
+	return count, nil
+}
+
+// IsPrivate reports whether any participant keeps the match replay private
+func (r *ReplayRepository) IsPrivate(matchID uuid.UUID) (bool, error) {
+	var private bool
+	err := r.db.QueryRow(`
+		SELECT EXISTS (
+			SELECT 1 FROM match_participants WHERE match_id = $1 AND replay_private
+		)
+	`, matchID).Scan(&private)
+	if err != nil {
+		return false, fmt.Errorf("failed to check replay privacy: %w", err)
+	}
+	return private, nil
+}
+
+// SetPrivate sets a participant's replay privacy. It reports whether the
+// user takes part in the match.
+func (r *ReplayRepository) SetPrivate(matchID, userID uuid.UUID, private bool) (bool, error) {
+	res, err := r.db.Exec(`
+		UPDATE match_participants SET replay_private = $3
+		WHERE match_id = $1 AND user_id = $2
+	`, matchID, userID, private)
+	if err != nil {
+		return false, fmt.Errorf("failed to update replay privacy: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+// DeleteBefore removes replay events recorded before cutoff
+func (r *ReplayRepository) DeleteBefore(cutoff time.Time) (int64, error) {
+	res, err := r.db.Exec(`DELETE FROM match_replay_events WHERE created_at < $1`, cutoff)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete old replay events: %w", err)
+	}
+	return res.RowsAffected()
+}
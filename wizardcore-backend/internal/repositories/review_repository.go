@@ -0,0 +1,232 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yourusername/wizardcore-backend/internal/models"
+	"github.com/yourusername/wizardcore-backend/internal/review"
+)
+
+var (
+	// ErrReviewNotDue is returned when a review is completed before its due day
+	ErrReviewNotDue = errors.New("review is not due yet")
+	// ErrReviewSubmissionInvalid is returned when the submission completing a
+	// review is not a graded re-attempt of the exercise by the user since
+	// the last review
+	ErrReviewSubmissionInvalid = errors.New("submission is not a new attempt at the exercise")
+)
+
+// ReviewRepository stores the spaced-repetition schedules of solved
+// exercises and the reviews completed on them
+type ReviewRepository struct {
+	db     *sql.DB
+	xpRepo *XPRepository
+}
+
+func NewReviewRepository(db *sql.DB, xpRepo *XPRepository) *ReviewRepository {
+	return &ReviewRepository{db: db, xpRepo: xpRepo}
+}
+
+const reviewItemSelect = `
+	SELECT
+		r.id, r.user_id, r.exercise_id, e.title, e.difficulty, m.title,
+		r.repetitions, r.interval_days, r.ease_factor, r.due_on,
+		r.last_reviewed_at, r.created_at
+	FROM review_items r
+	JOIN exercises e ON r.exercise_id = e.id
+	LEFT JOIN modules m ON e.module_id = m.id
+`
+
+func scanReviewItem(row interface{ Scan(...interface{}) error }) (*models.ReviewItem, error) {
+	var item models.ReviewItem
+	var moduleTitle sql.NullString
+	var dueOn time.Time
+	var lastReviewed sql.NullTime
+	err := row.Scan(
+		&item.ID, &item.UserID, &item.ExerciseID, &item.ExerciseTitle, &item.Difficulty, &moduleTitle,
+		&item.Repetitions, &item.IntervalDays, &item.EaseFactor, &dueOn,
+		&lastReviewed, &item.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if moduleTitle.Valid {
+		item.ModuleTitle = &moduleTitle.String
+	}
+	item.DueOn = dueOn.Format(dateLayout)
+	if lastReviewed.Valid {
+		item.LastReviewedAt = &lastReviewed.Time
+	}
+	return &item, nil
+}
+
+// EnsureItem schedules reviews of an exercise the user solved at a moment.
+// An exercise already scheduled keeps its schedule.
+func (r *ReviewRepository) EnsureItem(userID, exerciseID uuid.UUID, solvedAt time.Time) error {
+	today, err := localDay(r.db, userID, solvedAt)
+	if err != nil {
+		return err
+	}
+	item := review.New(today)
+	_, err = r.db.Exec(`
+		INSERT INTO review_items (user_id, exercise_id, repetitions, interval_days, ease_factor, due_on)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (user_id, exercise_id) DO NOTHING
+	`, userID, exerciseID, item.Repetitions, item.IntervalDays, item.Ease, item.Due.Format(dateLayout))
+	if err != nil {
+		return fmt.Errorf("failed to schedule review: %w", err)
+	}
+	return nil
+}
+
+// ListDue returns a page of the reviews the user has due as of a moment,
+// most overdue first, with the day it is for them and the total due
+func (r *ReviewRepository) ListDue(userID uuid.UUID, at time.Time, limit, offset int) ([]models.ReviewItem, time.Time, int, error) {
+	today, err := localDay(r.db, userID, at)
+	if err != nil {
+		return nil, time.Time{}, 0, err
+	}
+	var total int
+	if err := r.db.QueryRow(`
+		SELECT COUNT(*) FROM review_items WHERE user_id = $1 AND due_on <= $2
+	`, userID, today.Format(dateLayout)).Scan(&total); err != nil {
+		return nil, time.Time{}, 0, fmt.Errorf("failed to count due reviews: %w", err)
+	}
+
+	rows, err := r.db.Query(reviewItemSelect+`
+		WHERE r.user_id = $1 AND r.due_on <= $2
+		ORDER BY r.due_on, r.ease_factor, r.id
+		LIMIT $3 OFFSET $4
+	`, userID, today.Format(dateLayout), limit, offset)
+	if err != nil {
+		return nil, time.Time{}, 0, fmt.Errorf("failed to list due reviews: %w", err)
+	}
+	defer rows.Close()
+	items := []models.ReviewItem{}
+	for rows.Next() {
+		item, err := scanReviewItem(rows)
+		if err != nil {
+			return nil, time.Time{}, 0, fmt.Errorf("failed to scan review: %w", err)
+		}
+		items = append(items, *item)
+	}
+	return items, today, total, rows.Err()
+}
+
+// CompleteReview completes the user's due review of an exercise with a
+// graded re-attempt and their rating, schedules the next review and awards
+// XP if the re-attempt was accepted. It returns nil if the user has no
+// review of the exercise.
+func (r *ReviewRepository) CompleteReview(userID, exerciseID, submissionID uuid.UUID, rating string, at time.Time) (*models.ReviewResult, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	item, err := scanReviewItem(tx.QueryRow(reviewItemSelect+`
+		WHERE r.user_id = $1 AND r.exercise_id = $2
+		FOR UPDATE OF r
+	`, userID, exerciseID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock review: %w", err)
+	}
+	today, err := localDay(tx, userID, at)
+	if err != nil {
+		return nil, err
+	}
+	if item.DueOn > today.Format(dateLayout) {
+		return nil, ErrReviewNotDue
+	}
+
+	var correct bool
+	err = tx.QueryRow(`
+		SELECT is_correct FROM submissions
+		WHERE id = $1 AND user_id = $2 AND exercise_id = $3
+		AND COALESCE(submission_type, 'solution') <> 'draft'
+		AND status NOT IN ('pending', 'processing', 'judge0_error')
+		AND created_at > $4
+		AND NOT EXISTS (SELECT 1 FROM review_logs WHERE submission_id = $1)
+	`, submissionID, userID, exerciseID, lastReview(item)).Scan(&correct)
+	if err == sql.ErrNoRows {
+		return nil, ErrReviewSubmissionInvalid
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get review submission: %w", err)
+	}
+
+	schedule := review.Item{Repetitions: item.Repetitions, IntervalDays: item.IntervalDays, Ease: item.EaseFactor}
+	quality := review.Quality(correct, rating)
+	review.Schedule(&schedule, quality, today)
+	item.Repetitions = schedule.Repetitions
+	item.IntervalDays = schedule.IntervalDays
+	item.EaseFactor = schedule.Ease
+	item.DueOn = schedule.Due.Format(dateLayout)
+	item.LastReviewedAt = &at
+	if _, err := tx.Exec(`
+		UPDATE review_items
+		SET repetitions = $2, interval_days = $3, ease_factor = $4, due_on = $5, last_reviewed_at = $6
+		WHERE id = $1
+	`, item.ID, item.Repetitions, item.IntervalDays, item.EaseFactor, item.DueOn, at); err != nil {
+		return nil, fmt.Errorf("failed to reschedule review: %w", err)
+	}
+
+	result := &models.ReviewResult{Correct: correct, Quality: quality}
+	var awards []models.XPTransaction
+	if correct {
+		var pathwayID uuid.NullUUID
+		if err := tx.QueryRow(`
+			SELECT m.pathway_id FROM exercises e LEFT JOIN modules m ON e.module_id = m.id WHERE e.id = $1
+		`, exerciseID).Scan(&pathwayID); err != nil {
+			return nil, fmt.Errorf("failed to find exercise pathway: %w", err)
+		}
+		t := &models.XPTransaction{
+			UserID:         userID,
+			Amount:         review.XP,
+			SourceType:     models.XPSourceReview,
+			SourceID:       &submissionID,
+			IdempotencyKey: "review:" + submissionID.String(),
+			CreatedAt:      at,
+		}
+		if pathwayID.Valid {
+			t.PathwayID = &pathwayID.UUID
+		}
+		awarded, err := awardXP(tx, t)
+		if err != nil {
+			return nil, err
+		}
+		if awarded {
+			result.XPAwarded = review.XP
+			awards = append(awards, *t)
+		}
+	}
+
+	if _, err := tx.Exec(`
+		INSERT INTO review_logs (item_id, user_id, submission_id, rating, quality, correct, interval_days, ease_factor, due_on, xp_awarded, reviewed_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	`, item.ID, userID, submissionID, rating, quality, correct, item.IntervalDays, item.EaseFactor, item.DueOn, result.XPAwarded, at); err != nil {
+		return nil, fmt.Errorf("failed to log review: %w", err)
+	}
+
+	if err := r.xpRepo.commit(tx, awards); err != nil {
+		return nil, err
+	}
+	result.Item = *item
+	return result, nil
+}
+
+// lastReview returns when an item was last reviewed, or scheduled if it
+// has not been reviewed yet
+func lastReview(item *models.ReviewItem) time.Time {
+	if item.LastReviewedAt != nil {
+		return *item.LastReviewedAt
+	}
+	return item.CreatedAt
+}
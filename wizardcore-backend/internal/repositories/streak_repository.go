@@ -0,0 +1,172 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yourusername/wizardcore-backend/internal/models"
+	"github.com/yourusername/wizardcore-backend/internal/streak"
+)
+
+// StreakRepository stores activity streaks on the users table. Activity
+// extends them through ProgressRepository.RecordDailyActivity; the streak
+// job settles the days nobody was active.
+type StreakRepository struct {
+	db *sql.DB
+}
+
+func NewStreakRepository(db *sql.DB) *StreakRepository {
+	return &StreakRepository{db: db}
+}
+
+const userStreakSelect = `
+	SELECT
+		u.id,
+		COALESCE(p.timezone, 'UTC'),
+		COALESCE(u.current_streak, 0),
+		COALESCE(u.longest_streak, 0),
+		u.streak_freezes,
+		u.streak_last_day,
+		u.last_activity_date,
+		u.streak_risk_notified_on
+	FROM users u
+	LEFT JOIN user_preferences p ON p.user_id = u.id
+`
+
+func scanUserStreak(row interface{ Scan(...interface{}) error }) (*models.UserStreak, error) {
+	var s models.UserStreak
+	var lastDay, lastActivity, riskNotified sql.NullTime
+	if err := row.Scan(&s.UserID, &s.Timezone, &s.CurrentStreak, &s.LongestStreak, &s.Freezes, &lastDay, &lastActivity, &riskNotified); err != nil {
+		return nil, err
+	}
+	if lastDay.Valid {
+		s.LastDay = &lastDay.Time
+	}
+	if lastActivity.Valid {
+		s.LastActivityDate = &lastActivity.Time
+	}
+	if riskNotified.Valid {
+		s.RiskNotifiedOn = &riskNotified.Time
+	}
+	return &s, nil
+}
+
+// StreakState returns the arithmetic state of a stored streak
+func StreakState(s *models.UserStreak) streak.State {
+	return streak.State{Current: s.CurrentStreak, Longest: s.LongestStreak, Freezes: s.Freezes, LastDay: s.LastDay}
+}
+
+// lockStreak loads a user's streak within tx and locks it until tx ends
+func lockStreak(tx *sql.Tx, userID uuid.UUID) (*models.UserStreak, error) {
+	s, err := scanUserStreak(tx.QueryRow(userStreakSelect+` WHERE u.id = $1 FOR UPDATE OF u`, userID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock streak: %w", err)
+	}
+	return s, nil
+}
+
+func saveStreak(tx *sql.Tx, userID uuid.UUID, s streak.State) error {
+	var lastDay *string
+	if s.LastDay != nil {
+		day := s.LastDay.Format(dateLayout)
+		lastDay = &day
+	}
+	if _, err := tx.Exec(`
+		UPDATE users
+		SET current_streak = $2, longest_streak = $3, streak_freezes = $4, streak_last_day = $5
+		WHERE id = $1
+	`, userID, s.Current, s.Longest, s.Freezes, lastDay); err != nil {
+		return fmt.Errorf("failed to save streak: %w", err)
+	}
+	return nil
+}
+
+// recordStreak counts activity on a local day towards a user's streak
+// within tx
+func recordStreak(tx *sql.Tx, userID uuid.UUID, today time.Time) (streak.State, streak.Change, error) {
+	stored, err := lockStreak(tx, userID)
+	if err != nil {
+		return streak.State{}, streak.Change{}, err
+	}
+	state := StreakState(stored)
+	change := streak.Record(&state, today)
+	if err := saveStreak(tx, userID, state); err != nil {
+		return streak.State{}, streak.Change{}, err
+	}
+	if _, err := tx.Exec(`
+		UPDATE users SET last_activity_date = $2 WHERE id = $1
+	`, userID, today.Format(dateLayout)); err != nil {
+		return streak.State{}, streak.Change{}, fmt.Errorf("failed to save last activity date: %w", err)
+	}
+	return state, change, nil
+}
+
+// GetStreak returns a user's stored streak, or nil if there is no such user
+func (r *StreakRepository) GetStreak(userID uuid.UUID) (*models.UserStreak, error) {
+	s, err := scanUserStreak(r.db.QueryRow(userStreakSelect+` WHERE u.id = $1`, userID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get streak: %w", err)
+	}
+	return s, nil
+}
+
+// ListActiveStreaks returns the users with a running streak
+func (r *StreakRepository) ListActiveStreaks() ([]models.UserStreak, error) {
+	rows, err := r.db.Query(userStreakSelect + ` WHERE u.current_streak > 0`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list streaks: %w", err)
+	}
+	defer rows.Close()
+	var streaks []models.UserStreak
+	for rows.Next() {
+		s, err := scanUserStreak(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan streak: %w", err)
+		}
+		streaks = append(streaks, *s)
+	}
+	return streaks, rows.Err()
+}
+
+// Settle brings a user's streak up to their local today, spending freezes
+// on missed days or resetting it
+func (r *StreakRepository) Settle(userID uuid.UUID, today time.Time) (streak.State, streak.Change, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return streak.State{}, streak.Change{}, err
+	}
+	defer tx.Rollback()
+
+	stored, err := lockStreak(tx, userID)
+	if err != nil {
+		return streak.State{}, streak.Change{}, err
+	}
+	state := StreakState(stored)
+	change := streak.Settle(&state, today)
+	if change == (streak.Change{}) {
+		return state, change, nil
+	}
+	if err := saveStreak(tx, userID, state); err != nil {
+		return streak.State{}, streak.Change{}, err
+	}
+	if err := tx.Commit(); err != nil {
+		return streak.State{}, streak.Change{}, err
+	}
+	return state, change, nil
+}
+
+// MarkRiskNotified records that a user was warned about their streak on a
+// local day
+func (r *StreakRepository) MarkRiskNotified(userID uuid.UUID, day time.Time) error {
+	if _, err := r.db.Exec(`
+		UPDATE users SET streak_risk_notified_on = $2 WHERE id = $1
+	`, userID, day.Format(dateLayout)); err != nil {
+		return fmt.Errorf("failed to mark streak warning: %w", err)
+	}
+	return nil
+}
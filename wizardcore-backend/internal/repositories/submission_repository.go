@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/yourusername/wizardcore-backend/internal/anticheat"
 	"github.com/yourusername/wizardcore-backend/internal/models"
 )
 
@@ -24,8 +25,8 @@ func (r *SubmissionRepository) Create(submission *models.Submission) error {
 			judge0_token, status, stdout, stderr, compile_output,
 			execution_time, memory_used, test_cases_passed, test_cases_total,
 			points_earned, is_correct, submission_type, ip_address, user_agent,
-			created_at, updated_at
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21)
+			created_at, updated_at, source_fingerprint
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22)
 		RETURNING created_at, updated_at
 	`
 	if submission.ID == uuid.Nil {
@@ -55,6 +56,7 @@ func (r *SubmissionRepository) Create(submission *models.Submission) error {
 		submission.UserAgent,
 		now,
 		now,
+		anticheat.SourceFingerprint(submission.SourceCode),
 	).Scan(&submission.CreatedAt, &submission.UpdatedAt)
 	if err != nil {
 		return fmt.Errorf("failed to create submission: %w", err)
@@ -266,4 +268,4 @@ func (r *SubmissionRepository) Delete(id uuid.UUID) error {
 		return fmt.Errorf("submission not found")
 	}
 	return nil
-}
\ No newline at end of file
+}
@@ -0,0 +1,564 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yourusername/wizardcore-backend/internal/models"
+)
+
+var (
+	// ErrAlreadyInParty is returned when a user who is in an active party
+	// tries to create or join another one
+	ErrAlreadyInParty = errors.New("already in a party")
+	// ErrPartyUnavailable is returned when a party can no longer be joined
+	// or changed, because it is full, queued, playing or disbanded
+	ErrPartyUnavailable = errors.New("party is not available")
+)
+
+type TeamMatchRepository struct {
+	db *sql.DB
+}
+
+func NewTeamMatchRepository(db *sql.DB) *TeamMatchRepository {
+	return &TeamMatchRepository{db: db}
+}
+
+// queryer is satisfied by both *sql.DB and *sql.Tx
+type queryer interface {
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+const partyColumns = `
+	id, leader_id, invite_code, team_size, scoring, status, match_id, queued_at, created_at, updated_at
+`
+
+func scanParty(row interface{ Scan(...interface{}) error }) (*models.MatchParty, error) {
+	var p models.MatchParty
+	var matchID uuid.NullUUID
+	err := row.Scan(
+		&p.ID,
+		&p.LeaderID,
+		&p.InviteCode,
+		&p.TeamSize,
+		&p.Scoring,
+		&p.Status,
+		&matchID,
+		&p.QueuedAt,
+		&p.CreatedAt,
+		&p.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if matchID.Valid {
+		p.MatchID = &matchID.UUID
+	}
+	return &p, nil
+}
+
+// loadParty reads a party and its members, optionally locking the party row
+func loadParty(q queryer, lock bool, where string, args ...interface{}) (*models.MatchParty, error) {
+	query := `SELECT ` + partyColumns + ` FROM match_parties WHERE ` + where
+	if lock {
+		query += ` FOR UPDATE`
+	}
+	party, err := scanParty(q.QueryRow(query, args...))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load party: %w", err)
+	}
+
+	rows, err := q.Query(`
+		SELECT m.user_id, u.display_name, m.joined_at
+		FROM match_party_members m
+		JOIN users u ON u.id = m.user_id
+		WHERE m.party_id = $1
+		ORDER BY m.joined_at, m.user_id
+	`, party.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load party members: %w", err)
+	}
+	defer rows.Close()
+	party.Members = []models.PartyMember{}
+	for rows.Next() {
+		var m models.PartyMember
+		if err := rows.Scan(&m.UserID, &m.DisplayName, &m.JoinedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan party member: %w", err)
+		}
+		party.Members = append(party.Members, m)
+	}
+	return party, rows.Err()
+}
+
+// inActiveParty reports whether the user is in a party that has not been
+// disbanded
+func inActiveParty(q queryer, userID uuid.UUID) (bool, error) {
+	var exists bool
+	err := q.QueryRow(`
+		SELECT EXISTS (
+			SELECT 1 FROM match_party_members m
+			JOIN match_parties p ON p.id = m.party_id
+			WHERE m.user_id = $1 AND p.status <> 'disbanded'
+		)
+	`, userID).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to check party membership: %w", err)
+	}
+	return exists, nil
+}
+
+// CreateParty stores a party with its leader as the first member
+func (r *TeamMatchRepository) CreateParty(party *models.MatchParty) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	busy, err := inActiveParty(tx, party.LeaderID)
+	if err != nil {
+		return err
+	}
+	if busy {
+		return ErrAlreadyInParty
+	}
+	err = tx.QueryRow(`
+		INSERT INTO match_parties (id, leader_id, invite_code, team_size, scoring, status)
+		VALUES ($1, $2, $3, $4, $5, 'forming')
+		RETURNING status, created_at, updated_at
+	`, party.ID, party.LeaderID, party.InviteCode, party.TeamSize, party.Scoring).Scan(&party.Status, &party.CreatedAt, &party.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create party: %w", err)
+	}
+	if _, err := tx.Exec(`
+		INSERT INTO match_party_members (party_id, user_id) VALUES ($1, $2)
+	`, party.ID, party.LeaderID); err != nil {
+		return fmt.Errorf("failed to add party leader: %w", err)
+	}
+	return tx.Commit()
+}
+
+// FindParty returns a party with its members
+func (r *TeamMatchRepository) FindParty(partyID uuid.UUID) (*models.MatchParty, error) {
+	return loadParty(r.db, false, `id = $1`, partyID)
+}
+
+// FindPartyByCode returns the party with an invite code
+func (r *TeamMatchRepository) FindPartyByCode(code string) (*models.MatchParty, error) {
+	return loadParty(r.db, false, `invite_code = $1`, code)
+}
+
+// FindActiveParty returns the party the user is in, if it was not disbanded
+func (r *TeamMatchRepository) FindActiveParty(userID uuid.UUID) (*models.MatchParty, error) {
+	return loadParty(r.db, false, `status <> 'disbanded' AND id IN (
+		SELECT party_id FROM match_party_members WHERE user_id = $1
+	)`, userID)
+}
+
+// JoinParty adds a user to a forming party that has room
+func (r *TeamMatchRepository) JoinParty(partyID, userID uuid.UUID) (*models.MatchParty, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	party, err := loadParty(tx, true, `id = $1`, partyID)
+	if err != nil {
+		return nil, err
+	}
+	if party == nil || party.Status != "forming" || len(party.Members) >= party.TeamSize {
+		return nil, ErrPartyUnavailable
+	}
+	busy, err := inActiveParty(tx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if busy {
+		return nil, ErrAlreadyInParty
+	}
+	var joinedAt time.Time
+	err = tx.QueryRow(`
+		INSERT INTO match_party_members (party_id, user_id) VALUES ($1, $2)
+		RETURNING joined_at
+	`, partyID, userID).Scan(&joinedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to join party: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	party.Members = append(party.Members, models.PartyMember{UserID: userID, JoinedAt: joinedAt})
+	return party, nil
+}
+
+// LeaveParty removes a member. A queued party goes back to forming; if the
+// leader leaves, the longest-standing member takes over, and a party left
+// empty is disbanded. Players cannot leave during a match.
+func (r *TeamMatchRepository) LeaveParty(partyID, userID uuid.UUID) (*models.MatchParty, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	party, err := loadParty(tx, true, `id = $1`, partyID)
+	if err != nil {
+		return nil, err
+	}
+	if party == nil || !party.IsMember(userID) || party.Status == "in_match" || party.Status == "disbanded" {
+		return nil, ErrPartyUnavailable
+	}
+	if _, err := tx.Exec(`
+		DELETE FROM match_party_members WHERE party_id = $1 AND user_id = $2
+	`, partyID, userID); err != nil {
+		return nil, fmt.Errorf("failed to leave party: %w", err)
+	}
+
+	remaining := party.Members[:0]
+	for _, m := range party.Members {
+		if m.UserID != userID {
+			remaining = append(remaining, m)
+		}
+	}
+	party.Members = remaining
+	party.Status = "forming"
+	if len(remaining) == 0 {
+		party.Status = "disbanded"
+	} else if party.LeaderID == userID {
+		party.LeaderID = remaining[0].UserID
+	}
+	if _, err := tx.Exec(`
+		UPDATE match_parties SET status = $2, leader_id = $3, queued_at = NULL
+		WHERE id = $1
+	`, partyID, party.Status, party.LeaderID); err != nil {
+		return nil, fmt.Errorf("failed to update party: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	party.QueuedAt = nil
+	return party, nil
+}
+
+// SetQueued moves a party between forming and queued. Only a full party can
+// queue. It returns ErrPartyUnavailable if the party is in neither state.
+func (r *TeamMatchRepository) SetQueued(partyID uuid.UUID, queued bool) error {
+	var res sql.Result
+	var err error
+	if queued {
+		res, err = r.db.Exec(`
+			UPDATE match_parties SET status = 'queued', queued_at = NOW()
+			WHERE id = $1 AND status = 'forming'
+			  AND team_size = (SELECT COUNT(*) FROM match_party_members WHERE party_id = $1)
+		`, partyID)
+	} else {
+		res, err = r.db.Exec(`
+			UPDATE match_parties SET status = 'forming', queued_at = NULL
+			WHERE id = $1 AND status = 'queued'
+		`, partyID)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update party queue: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrPartyUnavailable
+	}
+	return nil
+}
+
+// PairParty matches a queued party with the longest-waiting queued party of
+// the same size and scoring. If one is found, it starts a team match on the
+// exercises and returns it; otherwise it returns nil.
+func (r *TeamMatchRepository) PairParty(partyID uuid.UUID, exerciseIDs []uuid.UUID, timeLimitMinutes int) (*models.TeamMatch, error) {
+	if len(exerciseIDs) == 0 {
+		return nil, fmt.Errorf("a team match needs at least one exercise")
+	}
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	party, err := loadParty(tx, true, `id = $1`, partyID)
+	if err != nil {
+		return nil, err
+	}
+	if party == nil || party.Status != "queued" {
+		return nil, nil
+	}
+	var opponentID uuid.UUID
+	err = tx.QueryRow(`
+		SELECT id FROM match_parties
+		WHERE status = 'queued' AND team_size = $1 AND scoring = $2 AND id <> $3
+		ORDER BY queued_at
+		LIMIT 1
+		FOR UPDATE SKIP LOCKED
+	`, party.TeamSize, party.Scoring, party.ID).Scan(&opponentID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find opposing party: %w", err)
+	}
+	opponent, err := loadParty(tx, false, `id = $1`, opponentID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	match := &models.PracticeMatch{
+		ID:               uuid.New(),
+		MatchType:        "team_duel",
+		Status:           "active",
+		ExerciseID:       exerciseIDs[0],
+		TimeLimitMinutes: &timeLimitMinutes,
+		StartedAt:        &now,
+		CreatedAt:        &now,
+	}
+	_, err = tx.Exec(`
+		INSERT INTO practice_matches (id, match_type, status, exercise_id, time_limit_minutes, started_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`, match.ID, match.MatchType, match.Status, match.ExerciseID, match.TimeLimitMinutes, match.StartedAt, match.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create team match: %w", err)
+	}
+	if _, err := tx.Exec(`
+		INSERT INTO team_matches (match_id, team_size, scoring) VALUES ($1, $2, $3)
+	`, match.ID, party.TeamSize, party.Scoring); err != nil {
+		return nil, fmt.Errorf("failed to create team match settings: %w", err)
+	}
+	for i, exerciseID := range exerciseIDs {
+		if _, err := tx.Exec(`
+			INSERT INTO match_exercises (match_id, position, exercise_id) VALUES ($1, $2, $3)
+		`, match.ID, i+1, exerciseID); err != nil {
+			return nil, fmt.Errorf("failed to add match exercise: %w", err)
+		}
+	}
+
+	tm := &models.TeamMatch{
+		Match:       match,
+		TeamSize:    party.TeamSize,
+		Scoring:     party.Scoring,
+		ExerciseIDs: exerciseIDs,
+	}
+	// The party that waited longer is team 1
+	for number, p := range []*models.MatchParty{opponent, party} {
+		partyID := p.ID
+		team := models.MatchTeam{ID: uuid.New(), MatchID: match.ID, Number: number + 1, PartyID: &partyID}
+		if _, err := tx.Exec(`
+			INSERT INTO match_teams (id, match_id, team_number, party_id) VALUES ($1, $2, $3, $4)
+		`, team.ID, match.ID, team.Number, partyID); err != nil {
+			return nil, fmt.Errorf("failed to create team: %w", err)
+		}
+		for _, m := range p.Members {
+			participant := models.MatchParticipant{ID: uuid.New(), MatchID: match.ID, UserID: m.UserID, JoinedAt: &now}
+			if _, err := tx.Exec(`
+				INSERT INTO match_participants (id, match_id, user_id, score, result, xp_earned, joined_at, team_id)
+				VALUES ($1, $2, $3, 0, '', 0, $4, $5)
+			`, participant.ID, match.ID, m.UserID, now, team.ID); err != nil {
+				return nil, fmt.Errorf("failed to add team member: %w", err)
+			}
+			team.Members = append(team.Members, participant)
+		}
+		if _, err := tx.Exec(`
+			UPDATE match_parties SET status = 'in_match', match_id = $2, queued_at = NULL
+			WHERE id = $1
+		`, partyID, match.ID); err != nil {
+			return nil, fmt.Errorf("failed to update party: %w", err)
+		}
+		tm.Teams = append(tm.Teams, team)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return tm, nil
+}
+
+// GetTeamMatch returns a team match with its exercises, teams and members,
+// or nil if the match is not a team match
+func (r *TeamMatchRepository) GetTeamMatch(matchID uuid.UUID) (*models.TeamMatch, error) {
+	tm := &models.TeamMatch{}
+	err := r.db.QueryRow(`
+		SELECT team_size, scoring FROM team_matches WHERE match_id = $1
+	`, matchID).Scan(&tm.TeamSize, &tm.Scoring)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load team match: %w", err)
+	}
+
+	rows, err := r.db.Query(`
+		SELECT exercise_id FROM match_exercises WHERE match_id = $1 ORDER BY position
+	`, matchID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load match exercises: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan match exercise: %w", err)
+		}
+		tm.ExerciseIDs = append(tm.ExerciseIDs, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	teamRows, err := r.db.Query(`
+		SELECT id, match_id, team_number, party_id, score, result
+		FROM match_teams WHERE match_id = $1 ORDER BY team_number
+	`, matchID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load teams: %w", err)
+	}
+	defer teamRows.Close()
+	byID := make(map[uuid.UUID]int)
+	for teamRows.Next() {
+		var team models.MatchTeam
+		var partyID uuid.NullUUID
+		if err := teamRows.Scan(&team.ID, &team.MatchID, &team.Number, &partyID, &team.Score, &team.Result); err != nil {
+			return nil, fmt.Errorf("failed to scan team: %w", err)
+		}
+		if partyID.Valid {
+			team.PartyID = &partyID.UUID
+		}
+		team.Members = []models.MatchParticipant{}
+		byID[team.ID] = len(tm.Teams)
+		tm.Teams = append(tm.Teams, team)
+	}
+	if err := teamRows.Err(); err != nil {
+		return nil, err
+	}
+
+	memberRows, err := r.db.Query(`
+		SELECT id, match_id, user_id, submission_id, score, rank, result, xp_earned, joined_at, finished_at, team_id
+		FROM match_participants
+		WHERE match_id = $1 AND team_id IS NOT NULL
+		ORDER BY joined_at, user_id
+	`, matchID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load team members: %w", err)
+	}
+	defer memberRows.Close()
+	for memberRows.Next() {
+		var p models.MatchParticipant
+		var teamID uuid.UUID
+		if err := memberRows.Scan(&p.ID, &p.MatchID, &p.UserID, &p.SubmissionID, &p.Score, &p.Rank,
+			&p.Result, &p.XPEarned, &p.JoinedAt, &p.FinishedAt, &teamID); err != nil {
+			return nil, fmt.Errorf("failed to scan team member: %w", err)
+		}
+		if i, ok := byID[teamID]; ok {
+			tm.Teams[i].Members = append(tm.Teams[i].Members, p)
+		}
+	}
+	return tm, memberRows.Err()
+}
+
+// FindTeamOfUser returns the ID of the team a user plays for in a match
+func (r *TeamMatchRepository) FindTeamOfUser(matchID, userID uuid.UUID) (*uuid.UUID, error) {
+	var teamID uuid.NullUUID
+	err := r.db.QueryRow(`
+		SELECT team_id FROM match_participants WHERE match_id = $1 AND user_id = $2
+	`, matchID, userID).Scan(&teamID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find team: %w", err)
+	}
+	if !teamID.Valid {
+		return nil, nil
+	}
+	return &teamID.UUID, nil
+}
+
+// IsTeamMember reports whether a user plays for a team
+func (r *TeamMatchRepository) IsTeamMember(teamID, userID uuid.UUID) (bool, error) {
+	var exists bool
+	err := r.db.QueryRow(`
+		SELECT EXISTS (SELECT 1 FROM match_participants WHERE team_id = $1 AND user_id = $2)
+	`, teamID, userID).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to check team membership: %w", err)
+	}
+	return exists, nil
+}
+
+// IsPartyMember reports whether a user is in a party
+func (r *TeamMatchRepository) IsPartyMember(partyID, userID uuid.UUID) (bool, error) {
+	var exists bool
+	err := r.db.QueryRow(`
+		SELECT EXISTS (SELECT 1 FROM match_party_members WHERE party_id = $1 AND user_id = $2)
+	`, partyID, userID).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to check party membership: %w", err)
+	}
+	return exists, nil
+}
+
+// RecordExerciseResult keeps a player's best score on an exercise of a team
+// match and returns their total score and number of solved exercises
+func (r *TeamMatchRepository) RecordExerciseResult(matchID, userID, exerciseID uuid.UUID, score int, solved bool, submissionID *uuid.UUID) (total, solvedCount int, err error) {
+	_, err = r.db.Exec(`
+		INSERT INTO match_exercise_results (match_id, user_id, exercise_id, best_score, solved, submission_id)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (match_id, user_id, exercise_id) DO UPDATE
+		SET best_score = GREATEST(match_exercise_results.best_score, EXCLUDED.best_score),
+			solved = match_exercise_results.solved OR EXCLUDED.solved,
+			submission_id = CASE WHEN EXCLUDED.best_score > match_exercise_results.best_score
+				THEN EXCLUDED.submission_id ELSE match_exercise_results.submission_id END,
+			updated_at = NOW()
+	`, matchID, userID, exerciseID, score, solved, submissionID)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to record exercise result: %w", err)
+	}
+	err = r.db.QueryRow(`
+		SELECT COALESCE(SUM(best_score), 0), COUNT(*) FILTER (WHERE solved)
+		FROM match_exercise_results
+		WHERE match_id = $1 AND user_id = $2
+	`, matchID, userID).Scan(&total, &solvedCount)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to total exercise results: %w", err)
+	}
+	return total, solvedCount, nil
+}
+
+// SaveTeamResults stores the final team scores and results and frees the
+// parties to queue again
+func (r *TeamMatchRepository) SaveTeamResults(matchID uuid.UUID, teams []models.MatchTeam) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, team := range teams {
+		if _, err := tx.Exec(`
+			UPDATE match_teams SET score = $2, result = $3 WHERE id = $1
+		`, team.ID, team.Score, team.Result); err != nil {
+			return fmt.Errorf("failed to save team result: %w", err)
+		}
+	}
+	if _, err := tx.Exec(`
+		UPDATE match_parties SET status = 'forming'
+		WHERE match_id = $1 AND status = 'in_match'
+	`, matchID); err != nil {
+		return fmt.Errorf("failed to release parties: %w", err)
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,516 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/yourusername/wizardcore-backend/internal/models"
+)
+
+type TournamentRepository struct {
+	db *sql.DB
+}
+
+func NewTournamentRepository(db *sql.DB) *TournamentRepository {
+	return &TournamentRepository{db: db}
+}
+
+const tournamentColumns = `
+	t.id, t.name, t.description, t.format, t.status, t.language_id, t.difficulty,
+	t.time_limit_minutes, t.max_participants, t.swiss_rounds, t.current_round,
+	t.registration_opens_at, t.registration_closes_at, t.starts_at, t.winner_id,
+	t.created_by, t.created_at, t.updated_at, t.completed_at,
+	(SELECT COUNT(*) FROM tournament_participants tp WHERE tp.tournament_id = t.id)
+`
+
+func scanTournament(row interface{ Scan(...interface{}) error }) (*models.Tournament, error) {
+	var t models.Tournament
+	var languageID, maxParticipants, swissRounds sql.NullInt64
+	var difficulty sql.NullString
+	var winnerID, createdBy uuid.NullUUID
+	err := row.Scan(
+		&t.ID,
+		&t.Name,
+		&t.Description,
+		&t.Format,
+		&t.Status,
+		&languageID,
+		&difficulty,
+		&t.TimeLimitMinutes,
+		&maxParticipants,
+		&swissRounds,
+		&t.CurrentRound,
+		&t.RegistrationOpensAt,
+		&t.RegistrationClosesAt,
+		&t.StartsAt,
+		&winnerID,
+		&createdBy,
+		&t.CreatedAt,
+		&t.UpdatedAt,
+		&t.CompletedAt,
+		&t.ParticipantCount,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if languageID.Valid {
+		id := int(languageID.Int64)
+		t.LanguageID = &id
+	}
+	if difficulty.Valid {
+		t.Difficulty = &difficulty.String
+	}
+	if maxParticipants.Valid {
+		n := int(maxParticipants.Int64)
+		t.MaxParticipants = &n
+	}
+	if swissRounds.Valid {
+		n := int(swissRounds.Int64)
+		t.SwissRounds = &n
+	}
+	if winnerID.Valid {
+		t.WinnerID = &winnerID.UUID
+	}
+	if createdBy.Valid {
+		t.CreatedBy = &createdBy.UUID
+	}
+	return &t, nil
+}
+
+func (r *TournamentRepository) Create(t *models.Tournament) error {
+	query := `
+		INSERT INTO tournaments (
+			id, name, description, format, status, language_id, difficulty,
+			time_limit_minutes, max_participants, swiss_rounds,
+			registration_opens_at, registration_closes_at, starts_at, created_by
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+		RETURNING created_at, updated_at
+	`
+	if t.ID == uuid.Nil {
+		t.ID = uuid.New()
+	}
+	err := r.db.QueryRow(
+		query,
+		t.ID,
+		t.Name,
+		t.Description,
+		t.Format,
+		t.Status,
+		t.LanguageID,
+		t.Difficulty,
+		t.TimeLimitMinutes,
+		t.MaxParticipants,
+		t.SwissRounds,
+		t.RegistrationOpensAt,
+		t.RegistrationClosesAt,
+		t.StartsAt,
+		t.CreatedBy,
+	).Scan(&t.CreatedAt, &t.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create tournament: %w", err)
+	}
+	return nil
+}
+
+func (r *TournamentRepository) FindByID(id uuid.UUID) (*models.Tournament, error) {
+	query := `SELECT ` + tournamentColumns + ` FROM tournaments t WHERE t.id = $1`
+	t, err := scanTournament(r.db.QueryRow(query, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find tournament: %w", err)
+	}
+	return t, nil
+}
+
+// List returns tournaments, optionally with the given status, soonest first
+func (r *TournamentRepository) List(status *string, limit, offset int) ([]models.Tournament, int, error) {
+	var total int
+	if err := r.db.QueryRow(`
+		SELECT COUNT(*) FROM tournaments WHERE $1::varchar IS NULL OR status = $1
+	`, status).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count tournaments: %w", err)
+	}
+
+	query := `SELECT ` + tournamentColumns + `
+		FROM tournaments t
+		WHERE $1::varchar IS NULL OR t.status = $1
+		ORDER BY t.starts_at DESC
+		LIMIT $2 OFFSET $3`
+	tournaments, err := r.queryTournaments(query, status, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	return tournaments, total, nil
+}
+
+// ListDueToStart returns tournaments still in registration whose start time
+// has passed
+func (r *TournamentRepository) ListDueToStart(now time.Time) ([]models.Tournament, error) {
+	query := `SELECT ` + tournamentColumns + `
+		FROM tournaments t
+		WHERE t.status = 'registration' AND t.starts_at <= $1
+		ORDER BY t.starts_at`
+	return r.queryTournaments(query, now)
+}
+
+func (r *TournamentRepository) queryTournaments(query string, args ...interface{}) ([]models.Tournament, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tournaments: %w", err)
+	}
+	defer rows.Close()
+
+	var tournaments []models.Tournament
+	for rows.Next() {
+		t, err := scanTournament(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan tournament: %w", err)
+		}
+		tournaments = append(tournaments, *t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+	return tournaments, nil
+}
+
+// UpdateProgress stores the status, round and winner of a tournament
+func (r *TournamentRepository) UpdateProgress(t *models.Tournament) error {
+	_, err := r.db.Exec(`
+		UPDATE tournaments
+		SET status = $2, current_round = $3, swiss_rounds = $4, winner_id = $5, completed_at = $6
+		WHERE id = $1
+	`, t.ID, t.Status, t.CurrentRound, t.SwissRounds, t.WinnerID, t.CompletedAt)
+	if err != nil {
+		return fmt.Errorf("failed to update tournament: %w", err)
+	}
+	return nil
+}
+
+// Register adds the user to the tournament unless it is full. It returns
+// false if the tournament is full or the user was already registered.
+func (r *TournamentRepository) Register(tournamentID, userID uuid.UUID) (bool, error) {
+	result, err := r.db.Exec(`
+		INSERT INTO tournament_participants (tournament_id, user_id, status, registered_at)
+		SELECT t.id, $2, 'registered', NOW()
+		FROM tournaments t
+		WHERE t.id = $1
+		  AND (t.max_participants IS NULL
+		       OR (SELECT COUNT(*) FROM tournament_participants tp WHERE tp.tournament_id = t.id) < t.max_participants)
+		ON CONFLICT (tournament_id, user_id) DO NOTHING
+	`, tournamentID, userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to register for tournament: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return rows > 0, nil
+}
+
+func (r *TournamentRepository) Unregister(tournamentID, userID uuid.UUID) (bool, error) {
+	result, err := r.db.Exec(`
+		DELETE FROM tournament_participants WHERE tournament_id = $1 AND user_id = $2
+	`, tournamentID, userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to unregister from tournament: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return rows > 0, nil
+}
+
+func (r *TournamentRepository) IsRegistered(tournamentID, userID uuid.UUID) (bool, error) {
+	var exists bool
+	err := r.db.QueryRow(`
+		SELECT EXISTS(SELECT 1 FROM tournament_participants WHERE tournament_id = $1 AND user_id = $2)
+	`, tournamentID, userID).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to check registration: %w", err)
+	}
+	return exists, nil
+}
+
+// GetParticipants returns the participants in seed order, then in order of
+// registration
+func (r *TournamentRepository) GetParticipants(tournamentID uuid.UUID) ([]models.TournamentParticipant, error) {
+	rows, err := r.db.Query(`
+		SELECT tp.tournament_id, tp.user_id, u.display_name, tp.seed, tp.status, tp.registered_at
+		FROM tournament_participants tp
+		JOIN users u ON u.id = tp.user_id
+		WHERE tp.tournament_id = $1
+		ORDER BY tp.seed ASC NULLS LAST, tp.registered_at ASC
+	`, tournamentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query participants: %w", err)
+	}
+	defer rows.Close()
+
+	var participants []models.TournamentParticipant
+	for rows.Next() {
+		var p models.TournamentParticipant
+		var seed sql.NullInt64
+		if err := rows.Scan(&p.TournamentID, &p.UserID, &p.DisplayName, &seed, &p.Status, &p.RegisteredAt); err != nil {
+			return nil, fmt.Errorf("failed to scan participant: %w", err)
+		}
+		if seed.Valid {
+			n := int(seed.Int64)
+			p.Seed = &n
+		}
+		participants = append(participants, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+	return participants, nil
+}
+
+// GetSeedingRatings returns the duel rating and practice score of every
+// participant, used to seed the tournament
+func (r *TournamentRepository) GetSeedingRatings(tournamentID uuid.UUID) (map[uuid.UUID][2]float64, error) {
+	rows, err := r.db.Query(`
+		SELECT tp.user_id, COALESCE(s.rating, 1500), COALESCE(s.practice_score, 0)
+		FROM tournament_participants tp
+		LEFT JOIN user_practice_stats s ON s.user_id = tp.user_id
+		WHERE tp.tournament_id = $1
+	`, tournamentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query seeding ratings: %w", err)
+	}
+	defer rows.Close()
+
+	ratings := make(map[uuid.UUID][2]float64)
+	for rows.Next() {
+		var userID uuid.UUID
+		var rating, score float64
+		if err := rows.Scan(&userID, &rating, &score); err != nil {
+			return nil, fmt.Errorf("failed to scan seeding rating: %w", err)
+		}
+		ratings[userID] = [2]float64{rating, score}
+	}
+	return ratings, rows.Err()
+}
+
+// SetSeeds stores the seeding, best seed first, and marks the seeded
+// participants active
+func (r *TournamentRepository) SetSeeds(tournamentID uuid.UUID, seeded []uuid.UUID) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for i, userID := range seeded {
+		_, err := tx.Exec(`
+			UPDATE tournament_participants SET seed = $3, status = 'active'
+			WHERE tournament_id = $1 AND user_id = $2
+		`, tournamentID, userID, i+1)
+		if err != nil {
+			return fmt.Errorf("failed to seed participant: %w", err)
+		}
+	}
+	return tx.Commit()
+}
+
+func (r *TournamentRepository) SetParticipantStatus(tournamentID uuid.UUID, userIDs []uuid.UUID, status string) error {
+	if len(userIDs) == 0 {
+		return nil
+	}
+	ids := make(pq.StringArray, len(userIDs))
+	for i, id := range userIDs {
+		ids[i] = id.String()
+	}
+	_, err := r.db.Exec(`
+		UPDATE tournament_participants SET status = $3
+		WHERE tournament_id = $1 AND user_id = ANY($2::uuid[])
+	`, tournamentID, ids, status)
+	if err != nil {
+		return fmt.Errorf("failed to update participant status: %w", err)
+	}
+	return nil
+}
+
+const tournamentMatchColumns = `
+	id, tournament_id, node_index, bracket, round, position, player1_id, player2_id,
+	slot1_filled, slot2_filled, winner_id, decided, next_node, next_slot,
+	loser_next_node, loser_next_slot, match_id, overridden, decided_at
+`
+
+func scanTournamentMatch(row interface{ Scan(...interface{}) error }) (*models.TournamentMatch, error) {
+	var m models.TournamentMatch
+	var player1, player2, winner, matchID uuid.NullUUID
+	var nextNode, nextSlot, loserNextNode, loserNextSlot sql.NullInt64
+	err := row.Scan(
+		&m.ID,
+		&m.TournamentID,
+		&m.NodeIndex,
+		&m.Bracket,
+		&m.Round,
+		&m.Position,
+		&player1,
+		&player2,
+		&m.Slot1Filled,
+		&m.Slot2Filled,
+		&winner,
+		&m.Decided,
+		&nextNode,
+		&nextSlot,
+		&loserNextNode,
+		&loserNextSlot,
+		&matchID,
+		&m.Overridden,
+		&m.DecidedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	m.Player1ID = nullUUIDPtr(player1)
+	m.Player2ID = nullUUIDPtr(player2)
+	m.WinnerID = nullUUIDPtr(winner)
+	m.MatchID = nullUUIDPtr(matchID)
+	m.NextNode = nullIntPtr(nextNode)
+	m.NextSlot = nullIntPtr(nextSlot)
+	m.LoserNextNode = nullIntPtr(loserNextNode)
+	m.LoserNextSlot = nullIntPtr(loserNextSlot)
+	return &m, nil
+}
+
+func nullUUIDPtr(id uuid.NullUUID) *uuid.UUID {
+	if !id.Valid {
+		return nil
+	}
+	return &id.UUID
+}
+
+func nullIntPtr(n sql.NullInt64) *int {
+	if !n.Valid {
+		return nil
+	}
+	v := int(n.Int64)
+	return &v
+}
+
+// GetMatches returns the bracket nodes of a tournament in node order
+func (r *TournamentRepository) GetMatches(tournamentID uuid.UUID) ([]models.TournamentMatch, error) {
+	rows, err := r.db.Query(`SELECT `+tournamentMatchColumns+`
+		FROM tournament_matches
+		WHERE tournament_id = $1
+		ORDER BY node_index`, tournamentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tournament matches: %w", err)
+	}
+	defer rows.Close()
+
+	var matches []models.TournamentMatch
+	for rows.Next() {
+		m, err := scanTournamentMatch(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan tournament match: %w", err)
+		}
+		matches = append(matches, *m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+	return matches, nil
+}
+
+// FindMatchByPracticeMatch returns the bracket node a practice match was
+// played for, if any
+func (r *TournamentRepository) FindMatchByPracticeMatch(matchID uuid.UUID) (*models.TournamentMatch, error) {
+	m, err := scanTournamentMatch(r.db.QueryRow(`SELECT `+tournamentMatchColumns+`
+		FROM tournament_matches WHERE match_id = $1`, matchID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find tournament match: %w", err)
+	}
+	return m, nil
+}
+
+// SaveMatches inserts or updates bracket nodes by node index
+func (r *TournamentRepository) SaveMatches(matches []models.TournamentMatch) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for i := range matches {
+		m := &matches[i]
+		if m.ID == uuid.Nil {
+			m.ID = uuid.New()
+		}
+		err := tx.QueryRow(`
+			INSERT INTO tournament_matches (
+				id, tournament_id, node_index, bracket, round, position, player1_id, player2_id,
+				slot1_filled, slot2_filled, winner_id, decided, next_node, next_slot,
+				loser_next_node, loser_next_slot, match_id, overridden, decided_at
+			) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19)
+			ON CONFLICT (tournament_id, node_index) DO UPDATE SET
+				player1_id = EXCLUDED.player1_id,
+				player2_id = EXCLUDED.player2_id,
+				slot1_filled = EXCLUDED.slot1_filled,
+				slot2_filled = EXCLUDED.slot2_filled,
+				winner_id = EXCLUDED.winner_id,
+				decided = EXCLUDED.decided,
+				match_id = EXCLUDED.match_id,
+				overridden = EXCLUDED.overridden,
+				decided_at = EXCLUDED.decided_at
+			RETURNING id
+		`,
+			m.ID, m.TournamentID, m.NodeIndex, m.Bracket, m.Round, m.Position, m.Player1ID, m.Player2ID,
+			m.Slot1Filled, m.Slot2Filled, m.WinnerID, m.Decided, m.NextNode, m.NextSlot,
+			m.LoserNextNode, m.LoserNextSlot, m.MatchID, m.Overridden, m.DecidedAt,
+		).Scan(&m.ID)
+		if err != nil {
+			return fmt.Errorf("failed to save tournament match: %w", err)
+		}
+	}
+	return tx.Commit()
+}
+
+// StartMatch creates the practice duel for a ready bracket node and links it
+func (r *TournamentRepository) StartMatch(node *models.TournamentMatch, match *models.PracticeMatch) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		INSERT INTO practice_matches (id, match_type, status, exercise_id, time_limit_minutes, started_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`, match.ID, match.MatchType, match.Status, match.ExerciseID, match.TimeLimitMinutes, match.StartedAt, match.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create tournament duel: %w", err)
+	}
+	for _, userID := range []*uuid.UUID{node.Player1ID, node.Player2ID} {
+		_, err = tx.Exec(`
+			INSERT INTO match_participants (id, match_id, user_id, score, result, xp_earned, joined_at)
+			VALUES ($1, $2, $3, 0, '', 0, $4)
+		`, uuid.New(), match.ID, userID, match.StartedAt)
+		if err != nil {
+			return fmt.Errorf("failed to add duel participant: %w", err)
+		}
+	}
+	_, err = tx.Exec(`UPDATE tournament_matches SET match_id = $2 WHERE id = $1`, node.ID, match.ID)
+	if err != nil {
+		return fmt.Errorf("failed to link tournament duel: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	node.MatchID = &match.ID
+	return nil
+}
@@ -137,6 +137,8 @@ func (r *UserRepository) FindBySupabaseUserID(supabaseUserID uuid.UUID) (*models
 	return user, nil
 }
 
+// Update stores a user's profile. XP, practice score, rank and streaks are
+// left untouched: they are kept by the XP ledger and the streak repository.
 func (r *UserRepository) Update(user *models.User) error {
 	query := `
 		UPDATE users
@@ -149,13 +151,7 @@ func (r *UserRepository) Update(user *models.User) error {
 			website = $7,
 			github_username = $8,
 			twitter_username = $9,
-			total_xp = $10,
-			practice_score = $11,
-			global_rank = $12,
-			current_streak = $13,
-			longest_streak = $14,
-			last_activity_date = $15,
-			updated_at = $16
+			updated_at = $10
 		WHERE id = $1
 		RETURNING updated_at
 	`
@@ -171,12 +167,6 @@ func (r *UserRepository) Update(user *models.User) error {
 		user.Website,
 		user.GithubUsername,
 		user.TwitterUsername,
-		user.TotalXP,
-		user.PracticeScore,
-		user.GlobalRank,
-		user.CurrentStreak,
-		user.LongestStreak,
-		user.LastActivityDate,
 		now,
 	).Scan(&user.UpdatedAt)
 	if err != nil {
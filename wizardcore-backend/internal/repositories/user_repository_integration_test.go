@@ -167,8 +167,12 @@ func TestUserRepository_Update(t *testing.T) {
 	if fetched.Bio == nil || *fetched.Bio != "A bio" {
 		t.Errorf("Expected Bio 'A bio', got %v", fetched.Bio)
 	}
-	if fetched.TotalXP != 100 {
-		t.Errorf("Expected TotalXP 100, got %d", fetched.TotalXP)
+	// XP and streaks belong to the ledger and the streak repository
+	if fetched.TotalXP != 0 {
+		t.Errorf("Expected TotalXP to stay 0, got %d", fetched.TotalXP)
+	}
+	if fetched.CurrentStreak != 0 {
+		t.Errorf("Expected CurrentStreak to stay 0, got %d", fetched.CurrentStreak)
 	}
 }
 
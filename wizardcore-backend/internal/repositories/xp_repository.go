@@ -0,0 +1,400 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yourusername/wizardcore-backend/internal/models"
+)
+
+var (
+	// ErrBackfillReviewResolved is returned when a backfill review that was
+	// already resolved is resolved again
+	ErrBackfillReviewResolved = errors.New("backfill review is already resolved")
+	// ErrOpeningBalanceTooLarge is returned when an opening balance exceeds
+	// the XP the ledger could not account for
+	ErrOpeningBalanceTooLarge = errors.New("opening balance exceeds the unexplained XP")
+)
+
+// XPRepository writes the XP ledger. Awards go through awardXP, which keeps
+// users.total_xp and user_pathway_enrollments.xp_earned in step with the
+// ledger; Reconcile rebuilds them from it. Repositories that award XP commit
+// through commit, so award listeners hear of every credit once it is stored.
+type XPRepository struct {
+	db        *sql.DB
+	listeners []XPListener
+}
+
+// XPListener is called with a transaction the ledger credited, after it
+// was committed
+type XPListener func(t models.XPTransaction)
+
+func NewXPRepository(db *sql.DB) *XPRepository {
+	return &XPRepository{db: db}
+}
+
+// OnAward registers a listener for credited XP. Listeners must be
+// registered before awards are made.
+func (r *XPRepository) OnAward(listener XPListener) {
+	r.listeners = append(r.listeners, listener)
+}
+
+// commit commits tx and then tells the listeners about the awards it made
+func (r *XPRepository) commit(tx *sql.Tx, awards []models.XPTransaction) error {
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	for _, t := range awards {
+		for _, listener := range r.listeners {
+			listener(t)
+		}
+	}
+	return nil
+}
+
+// awardXP appends a transaction to the ledger within tx and adds it to the
+// user's totals. It reports false without changing anything if the
+// idempotency key was already awarded.
+func awardXP(tx *sql.Tx, t *models.XPTransaction) (bool, error) {
+	if t.ID == uuid.Nil {
+		t.ID = uuid.New()
+	}
+	if t.CreatedAt.IsZero() {
+		t.CreatedAt = time.Now()
+	}
+	result, err := tx.Exec(`
+		INSERT INTO xp_transactions (id, user_id, amount, source_type, source_id, pathway_id, idempotency_key, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (idempotency_key) DO NOTHING
+	`, t.ID, t.UserID, t.Amount, t.SourceType, t.SourceID, t.PathwayID, t.IdempotencyKey, t.CreatedAt)
+	if err != nil {
+		return false, fmt.Errorf("failed to record XP transaction: %w", err)
+	}
+	if n, err := result.RowsAffected(); err != nil || n == 0 {
+		return false, err
+	}
+
+	if _, err := tx.Exec(`
+		UPDATE users SET total_xp = COALESCE(total_xp, 0) + $2 WHERE id = $1
+	`, t.UserID, t.Amount); err != nil {
+		return false, fmt.Errorf("failed to credit XP: %w", err)
+	}
+	if t.PathwayID != nil {
+		if _, err := tx.Exec(`
+			UPDATE user_pathway_enrollments SET xp_earned = COALESCE(xp_earned, 0) + $3
+			WHERE user_id = $1 AND pathway_id = $2
+		`, t.UserID, t.PathwayID, t.Amount); err != nil {
+			return false, fmt.Errorf("failed to credit pathway XP: %w", err)
+		}
+	}
+	return true, nil
+}
+
+// Award appends a transaction to the ledger and credits it. It reports
+// false if the idempotency key was already awarded.
+func (r *XPRepository) Award(t *models.XPTransaction) (bool, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+	awarded, err := awardXP(tx, t)
+	if err != nil || !awarded {
+		return false, err
+	}
+	if err := r.commit(tx, []models.XPTransaction{*t}); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// AwardSubmission credits a graded submission with the part of its score
+// the author has not yet earned on the exercise through other submissions,
+// attributed to the pathway of the exercise. The credited points are stored
+// on the submission and set in submission.PointsEarned. Submissions of the
+// same user and exercise are credited one at a time, so concurrent
+// submissions cannot both be paid the same points, and a submission is
+// credited at most once.
+func (r *XPRepository) AwardSubmission(submission *models.Submission, score int) (bool, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	lockKey := "submission_points:" + submission.UserID.String() + ":" + submission.ExerciseID.String()
+	if _, err := tx.Exec(`SELECT pg_advisory_xact_lock(hashtext($1))`, lockKey); err != nil {
+		return false, fmt.Errorf("failed to lock submission points: %w", err)
+	}
+	var credited int
+	err = tx.QueryRow(`
+		SELECT COALESCE(SUM(points_earned), 0)::int
+		FROM submissions
+		WHERE user_id = $1 AND exercise_id = $2 AND id <> $3
+		AND COALESCE(submission_type, 'solution') <> 'draft'
+	`, submission.UserID, submission.ExerciseID, submission.ID).Scan(&credited)
+	if err != nil {
+		return false, fmt.Errorf("failed to sum points earned: %w", err)
+	}
+	points := 0
+	if score > credited {
+		points = score - credited
+	}
+	if _, err := tx.Exec(`
+		UPDATE submissions SET points_earned = $2 WHERE id = $1
+	`, submission.ID, points); err != nil {
+		return false, fmt.Errorf("failed to store points earned: %w", err)
+	}
+	if points == 0 {
+		if err := tx.Commit(); err != nil {
+			return false, err
+		}
+		submission.PointsEarned = 0
+		return false, nil
+	}
+
+	var pathwayID uuid.NullUUID
+	err = tx.QueryRow(`
+		SELECT m.pathway_id
+		FROM exercises e
+		LEFT JOIN modules m ON e.module_id = m.id
+		WHERE e.id = $1
+	`, submission.ExerciseID).Scan(&pathwayID)
+	if err != nil && err != sql.ErrNoRows {
+		return false, fmt.Errorf("failed to find exercise pathway: %w", err)
+	}
+	submissionID := submission.ID
+	t := &models.XPTransaction{
+		UserID:         submission.UserID,
+		Amount:         points,
+		SourceType:     models.XPSourceSubmission,
+		SourceID:       &submissionID,
+		IdempotencyKey: "submission:" + submission.ID.String(),
+		CreatedAt:      submission.CreatedAt,
+	}
+	if pathwayID.Valid {
+		t.PathwayID = &pathwayID.UUID
+	}
+	awarded, err := awardXP(tx, t)
+	if err != nil {
+		return false, err
+	}
+	var awards []models.XPTransaction
+	if awarded {
+		awards = append(awards, *t)
+	}
+	if err := r.commit(tx, awards); err != nil {
+		return false, err
+	}
+	submission.PointsEarned = points
+	return awarded, nil
+}
+
+// Reconcile sets every user's total XP and every enrollment's XP to what
+// the ledger adds up to. With dryRun it only counts the totals that are
+// out of step. Awards wait while it runs.
+func (r *XPRepository) Reconcile(dryRun bool) (*models.XPReconciliation, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// Hold awards back until the totals are rebuilt, so none is counted
+	// against a stale sum
+	if _, err := tx.Exec(`LOCK TABLE xp_transactions IN SHARE MODE`); err != nil {
+		return nil, fmt.Errorf("failed to lock XP ledger: %w", err)
+	}
+
+	var result models.XPReconciliation
+	users, err := tx.Exec(`
+		UPDATE users u
+		SET total_xp = COALESCE(t.xp, 0)
+		FROM users x
+		LEFT JOIN (
+			SELECT user_id, SUM(amount)::int AS xp FROM xp_transactions GROUP BY user_id
+		) t ON t.user_id = x.id
+		WHERE u.id = x.id AND COALESCE(u.total_xp, 0) <> COALESCE(t.xp, 0)
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reconcile user XP: %w", err)
+	}
+	n, err := users.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+	result.UsersCorrected = int(n)
+
+	enrollments, err := tx.Exec(`
+		UPDATE user_pathway_enrollments e
+		SET xp_earned = COALESCE(t.xp, 0)
+		FROM user_pathway_enrollments x
+		LEFT JOIN (
+			SELECT user_id, pathway_id, SUM(amount)::int AS xp
+			FROM xp_transactions
+			WHERE pathway_id IS NOT NULL
+			GROUP BY user_id, pathway_id
+		) t ON t.user_id = x.user_id AND t.pathway_id = x.pathway_id
+		WHERE e.id = x.id AND COALESCE(e.xp_earned, 0) <> COALESCE(t.xp, 0)
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reconcile enrollment XP: %w", err)
+	}
+	n, err = enrollments.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+	result.EnrollmentsCorrected = int(n)
+
+	if dryRun {
+		return &result, nil
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+const xpBackfillReviewSelect = `
+	SELECT
+		r.user_id,
+		COALESCE(u.display_name, ''),
+		u.email,
+		r.recorded_total_xp,
+		r.ledger_xp,
+		r.created_at,
+		r.resolved_at,
+		r.resolved_by,
+		r.opening_balance
+	FROM xp_backfill_reviews r
+	JOIN users u ON u.id = r.user_id
+`
+
+func scanXPBackfillReview(row interface{ Scan(...interface{}) error }) (*models.XPBackfillReview, error) {
+	var review models.XPBackfillReview
+	var resolvedAt sql.NullTime
+	var resolvedBy uuid.NullUUID
+	var openingBalance sql.NullInt64
+	if err := row.Scan(
+		&review.UserID, &review.Username, &review.Email, &review.RecordedTotalXP, &review.LedgerXP,
+		&review.CreatedAt, &resolvedAt, &resolvedBy, &openingBalance,
+	); err != nil {
+		return nil, err
+	}
+	if resolvedAt.Valid {
+		review.ResolvedAt = &resolvedAt.Time
+	}
+	if resolvedBy.Valid {
+		review.ResolvedBy = &resolvedBy.UUID
+	}
+	if openingBalance.Valid {
+		balance := int(openingBalance.Int64)
+		review.OpeningBalance = &balance
+	}
+	return &review, nil
+}
+
+// ListBackfillReviews returns the users the ledger backfill could not
+// account for, pending or resolved, or both if resolved is nil
+func (r *XPRepository) ListBackfillReviews(resolved *bool, limit, offset int) ([]models.XPBackfillReview, int, error) {
+	var total int
+	if err := r.db.QueryRow(`
+		SELECT COUNT(*) FROM xp_backfill_reviews
+		WHERE $1::boolean IS NULL OR (resolved_at IS NOT NULL) = $1
+	`, resolved).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count XP backfill reviews: %w", err)
+	}
+	rows, err := r.db.Query(xpBackfillReviewSelect+`
+		WHERE $1::boolean IS NULL OR (r.resolved_at IS NOT NULL) = $1
+		ORDER BY r.recorded_total_xp - r.ledger_xp DESC, r.user_id
+		LIMIT $2 OFFSET $3
+	`, resolved, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list XP backfill reviews: %w", err)
+	}
+	defer rows.Close()
+	reviews := []models.XPBackfillReview{}
+	for rows.Next() {
+		review, err := scanXPBackfillReview(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan XP backfill review: %w", err)
+		}
+		reviews = append(reviews, *review)
+	}
+	return reviews, total, rows.Err()
+}
+
+// GetBackfillReview returns the backfill review of a user, or nil if the
+// backfill accounted for all of their XP
+func (r *XPRepository) GetBackfillReview(userID uuid.UUID) (*models.XPBackfillReview, error) {
+	review, err := scanXPBackfillReview(r.db.QueryRow(xpBackfillReviewSelect+` WHERE r.user_id = $1`, userID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get XP backfill review: %w", err)
+	}
+	return review, nil
+}
+
+// ResolveBackfillReview closes a user's backfill review, crediting them
+// with an opening balance of at most the XP the ledger could not account
+// for. It reports false if the user has no backfill review.
+func (r *XPRepository) ResolveBackfillReview(userID, resolverID uuid.UUID, openingBalance int) (bool, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	var recorded, ledger int
+	var resolvedAt sql.NullTime
+	err = tx.QueryRow(`
+		SELECT recorded_total_xp, ledger_xp, resolved_at FROM xp_backfill_reviews
+		WHERE user_id = $1
+		FOR UPDATE
+	`, userID).Scan(&recorded, &ledger, &resolvedAt)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to lock XP backfill review: %w", err)
+	}
+	if resolvedAt.Valid {
+		return false, ErrBackfillReviewResolved
+	}
+	if openingBalance > recorded-ledger {
+		return false, ErrOpeningBalanceTooLarge
+	}
+
+	var awards []models.XPTransaction
+	if openingBalance > 0 {
+		t := models.XPTransaction{
+			UserID:         userID,
+			Amount:         openingBalance,
+			SourceType:     models.XPSourceOpeningBalance,
+			IdempotencyKey: "opening_balance:" + userID.String(),
+		}
+		awarded, err := awardXP(tx, &t)
+		if err != nil {
+			return false, err
+		}
+		if awarded {
+			awards = append(awards, t)
+		}
+	}
+	if _, err := tx.Exec(`
+		UPDATE xp_backfill_reviews
+		SET resolved_at = CURRENT_TIMESTAMP, resolved_by = $2, opening_balance = $3
+		WHERE user_id = $1
+	`, userID, resolverID, openingBalance); err != nil {
+		return false, fmt.Errorf("failed to resolve XP backfill review: %w", err)
+	}
+	if err := r.commit(tx, awards); err != nil {
+		return false, err
+	}
+	return true, nil
+}
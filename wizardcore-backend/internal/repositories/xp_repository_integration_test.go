@@ -0,0 +1,241 @@
+package repositories
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/yourusername/wizardcore-backend/internal/models"
+	"github.com/yourusername/wizardcore-backend/internal/testutils"
+)
+
+func createXPTestUser(t *testing.T, db *sql.DB) uuid.UUID {
+	t.Helper()
+	user := &models.User{
+		SupabaseUserID: uuid.New(),
+		Email:          uuid.NewString() + "@example.com",
+	}
+	if err := NewUserRepository(db).Create(user); err != nil {
+		t.Fatalf("Create user failed: %v", err)
+	}
+	return user.ID
+}
+
+// createXPTestExercise creates an exercise in a pathway the user is
+// enrolled in
+func createXPTestExercise(t *testing.T, db *sql.DB, userID uuid.UUID) (exerciseID, pathwayID uuid.UUID) {
+	t.Helper()
+	pathwayID, moduleID, exerciseID := uuid.New(), uuid.New(), uuid.New()
+	if _, err := db.Exec(`
+		INSERT INTO pathways (id, title, level, duration_weeks) VALUES ($1, 'Pathway', 'Beginner', 1)
+	`, pathwayID); err != nil {
+		t.Fatalf("Create pathway failed: %v", err)
+	}
+	if _, err := db.Exec(`
+		INSERT INTO modules (id, pathway_id, title, sort_order) VALUES ($1, $2, 'Module', 1)
+	`, moduleID, pathwayID); err != nil {
+		t.Fatalf("Create module failed: %v", err)
+	}
+	if _, err := db.Exec(`
+		INSERT INTO exercises (id, module_id, title, difficulty, sort_order, language_id)
+		VALUES ($1, $2, 'Exercise', 'BEGINNER', 1, 71)
+	`, exerciseID, moduleID); err != nil {
+		t.Fatalf("Create exercise failed: %v", err)
+	}
+	if _, err := db.Exec(`
+		INSERT INTO user_pathway_enrollments (user_id, pathway_id) VALUES ($1, $2)
+	`, userID, pathwayID); err != nil {
+		t.Fatalf("Create enrollment failed: %v", err)
+	}
+	return exerciseID, pathwayID
+}
+
+func createXPTestSubmission(t *testing.T, db *sql.DB, userID, exerciseID uuid.UUID) *models.Submission {
+	t.Helper()
+	submission := &models.Submission{
+		UserID:         userID,
+		ExerciseID:     exerciseID,
+		SourceCode:     "print(1)",
+		LanguageID:     71,
+		Status:         "accepted",
+		SubmissionType: "solution",
+	}
+	if err := NewSubmissionRepository(db).Create(submission); err != nil {
+		t.Fatalf("Create submission failed: %v", err)
+	}
+	return submission
+}
+
+func xpTotals(t *testing.T, db *sql.DB, userID, pathwayID uuid.UUID) (total, pathway int) {
+	t.Helper()
+	if err := db.QueryRow(`
+		SELECT COALESCE(u.total_xp, 0), COALESCE(e.xp_earned, 0)
+		FROM users u
+		JOIN user_pathway_enrollments e ON e.user_id = u.id AND e.pathway_id = $2
+		WHERE u.id = $1
+	`, userID, pathwayID).Scan(&total, &pathway); err != nil {
+		t.Fatalf("Fetch XP totals failed: %v", err)
+	}
+	return total, pathway
+}
+
+func TestXPRepository_AwardIsIdempotent(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	repo := NewXPRepository(db)
+	userID := createXPTestUser(t, db)
+	_, pathwayID := createXPTestExercise(t, db, userID)
+
+	var heard int
+	repo.OnAward(func(models.XPTransaction) { heard++ })
+
+	for i := 0; i < 2; i++ {
+		awarded, err := repo.Award(&models.XPTransaction{
+			UserID:         userID,
+			Amount:         25,
+			SourceType:     models.XPSourceDailyChallenge,
+			PathwayID:      &pathwayID,
+			IdempotencyKey: "daily_challenge:test",
+		})
+		if err != nil {
+			t.Fatalf("Award failed: %v", err)
+		}
+		if awarded != (i == 0) {
+			t.Errorf("Award %d: expected awarded=%v, got %v", i+1, i == 0, awarded)
+		}
+	}
+
+	total, pathway := xpTotals(t, db, userID, pathwayID)
+	if total != 25 || pathway != 25 {
+		t.Errorf("Expected 25 XP in total and in the pathway, got %d and %d", total, pathway)
+	}
+	if heard != 1 {
+		t.Errorf("Expected listeners to hear of 1 award, got %d", heard)
+	}
+}
+
+func TestXPRepository_AwardSubmissionPaysScoreMinusCredited(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	repo := NewXPRepository(db)
+	userID := createXPTestUser(t, db)
+	exerciseID, pathwayID := createXPTestExercise(t, db, userID)
+
+	tests := []struct {
+		score      int
+		wantPoints int
+		wantTotal  int
+	}{
+		{score: 60, wantPoints: 60, wantTotal: 60},
+		// Only the improvement on the best earlier score is paid
+		{score: 100, wantPoints: 40, wantTotal: 100},
+		{score: 100, wantPoints: 0, wantTotal: 100},
+		{score: 80, wantPoints: 0, wantTotal: 100},
+	}
+	for i, tt := range tests {
+		submission := createXPTestSubmission(t, db, userID, exerciseID)
+		awarded, err := repo.AwardSubmission(submission, tt.score)
+		if err != nil {
+			t.Fatalf("Submission %d: AwardSubmission failed: %v", i+1, err)
+		}
+		if awarded != (tt.wantPoints > 0) {
+			t.Errorf("Submission %d: expected awarded=%v, got %v", i+1, tt.wantPoints > 0, awarded)
+		}
+		if submission.PointsEarned != tt.wantPoints {
+			t.Errorf("Submission %d: expected %d points, got %d", i+1, tt.wantPoints, submission.PointsEarned)
+		}
+		var stored int
+		if err := db.QueryRow(`SELECT points_earned FROM submissions WHERE id = $1`, submission.ID).Scan(&stored); err != nil {
+			t.Fatalf("Fetch points earned failed: %v", err)
+		}
+		if stored != tt.wantPoints {
+			t.Errorf("Submission %d: expected %d stored points, got %d", i+1, tt.wantPoints, stored)
+		}
+		total, pathway := xpTotals(t, db, userID, pathwayID)
+		if total != tt.wantTotal || pathway != tt.wantTotal {
+			t.Errorf("Submission %d: expected %d XP in total and in the pathway, got %d and %d", i+1, tt.wantTotal, total, pathway)
+		}
+	}
+}
+
+func TestXPRepository_AwardSubmissionCreditsOnce(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	repo := NewXPRepository(db)
+	userID := createXPTestUser(t, db)
+	exerciseID, pathwayID := createXPTestExercise(t, db, userID)
+
+	submission := createXPTestSubmission(t, db, userID, exerciseID)
+	if _, err := repo.AwardSubmission(submission, 50); err != nil {
+		t.Fatalf("AwardSubmission failed: %v", err)
+	}
+	// Regrading the same submission pays nothing more
+	awarded, err := repo.AwardSubmission(submission, 50)
+	if err != nil {
+		t.Fatalf("AwardSubmission failed: %v", err)
+	}
+	if awarded {
+		t.Error("Expected a regraded submission not to be credited again")
+	}
+	total, _ := xpTotals(t, db, userID, pathwayID)
+	if total != 50 {
+		t.Errorf("Expected 50 XP, got %d", total)
+	}
+	var entries int
+	if err := db.QueryRow(`
+		SELECT COUNT(*) FROM xp_transactions WHERE idempotency_key = $1
+	`, "submission:"+submission.ID.String()).Scan(&entries); err != nil {
+		t.Fatalf("Count ledger entries failed: %v", err)
+	}
+	if entries != 1 {
+		t.Errorf("Expected 1 ledger entry for the submission, got %d", entries)
+	}
+}
+
+func TestXPRepository_Reconcile(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	repo := NewXPRepository(db)
+	userID := createXPTestUser(t, db)
+	_, pathwayID := createXPTestExercise(t, db, userID)
+
+	if _, err := repo.Award(&models.XPTransaction{
+		UserID:         userID,
+		Amount:         30,
+		SourceType:     models.XPSourceLeagueReward,
+		PathwayID:      &pathwayID,
+		IdempotencyKey: "league_reward:test",
+	}); err != nil {
+		t.Fatalf("Award failed: %v", err)
+	}
+	if _, err := db.Exec(`UPDATE users SET total_xp = 500 WHERE id = $1`, userID); err != nil {
+		t.Fatalf("Drift total failed: %v", err)
+	}
+	if _, err := db.Exec(`
+		UPDATE user_pathway_enrollments SET xp_earned = 0 WHERE user_id = $1
+	`, userID); err != nil {
+		t.Fatalf("Drift pathway total failed: %v", err)
+	}
+
+	result, err := repo.Reconcile(true)
+	if err != nil {
+		t.Fatalf("Reconcile dry run failed: %v", err)
+	}
+	if result.UsersCorrected != 1 || result.EnrollmentsCorrected != 1 {
+		t.Errorf("Expected 1 user and 1 enrollment out of step, got %+v", result)
+	}
+	if total, _ := xpTotals(t, db, userID, pathwayID); total != 500 {
+		t.Errorf("Expected the dry run to leave the total at 500, got %d", total)
+	}
+
+	if _, err := repo.Reconcile(false); err != nil {
+		t.Fatalf("Reconcile failed: %v", err)
+	}
+	total, pathway := xpTotals(t, db, userID, pathwayID)
+	if total != 30 || pathway != 30 {
+		t.Errorf("Expected 30 XP in total and in the pathway, got %d and %d", total, pathway)
+	}
+	result, err = repo.Reconcile(true)
+	if err != nil {
+		t.Fatalf("Reconcile dry run failed: %v", err)
+	}
+	if result.UsersCorrected != 0 || result.EnrollmentsCorrected != 0 {
+		t.Errorf("Expected nothing out of step after reconciling, got %+v", result)
+	}
+}
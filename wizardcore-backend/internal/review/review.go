@@ -0,0 +1,83 @@
+// Package review schedules spaced-repetition reviews of solved exercises
+// with SM-2. A review is a re-attempt of the exercise, graded from whether
+// it was accepted and the learner's own rating of how hard it was. Days are
+// calendar days in the learner's timezone, as in package streak.
+package review
+
+import (
+	"math"
+	"time"
+)
+
+// Ratings a learner gives a review
+const (
+	RatingAgain = "again"
+	RatingHard  = "hard"
+	RatingGood  = "good"
+	RatingEasy  = "easy"
+)
+
+const (
+	// InitialEase is the ease factor of a new item
+	InitialEase = 2.5
+	// MinEase keeps hard items from being reviewed ever more often
+	MinEase = 1.3
+	// XP awards the XP for an accepted review
+	XP = 10
+)
+
+// Item is the schedule of one exercise for one learner
+type Item struct {
+	Repetitions  int
+	IntervalDays int
+	Ease         float64
+	Due          time.Time
+}
+
+// New schedules the first review of an exercise solved on a day
+func New(solved time.Time) Item {
+	return Item{IntervalDays: 1, Ease: InitialEase, Due: solved.AddDate(0, 0, 1)}
+}
+
+// Quality grades a review on the SM-2 scale of 0 to 5. A failed re-attempt
+// is a lapse whatever the rating; an accepted one the learner had to guess
+// at ("again") still counts as forgotten.
+func Quality(correct bool, rating string) int {
+	if !correct {
+		return 1
+	}
+	switch rating {
+	case RatingAgain:
+		return 2
+	case RatingHard:
+		return 3
+	case RatingEasy:
+		return 5
+	}
+	return 4
+}
+
+// Schedule applies a review of a quality done on today and sets the next
+// due day. A quality below 3 starts the repetitions over.
+func Schedule(it *Item, quality int, today time.Time) {
+	if quality < 3 {
+		it.Repetitions = 0
+		it.IntervalDays = 1
+	} else {
+		it.Repetitions++
+		switch it.Repetitions {
+		case 1:
+			it.IntervalDays = 1
+		case 2:
+			it.IntervalDays = 6
+		default:
+			it.IntervalDays = int(math.Round(float64(it.IntervalDays) * it.Ease))
+		}
+	}
+	q := float64(5 - quality)
+	it.Ease += 0.1 - q*(0.08+q*0.02)
+	if it.Ease < MinEase {
+		it.Ease = MinEase
+	}
+	it.Due = today.AddDate(0, 0, it.IntervalDays)
+}
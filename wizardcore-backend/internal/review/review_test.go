@@ -0,0 +1,63 @@
+package review
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSchedule_GrowsIntervals(t *testing.T) {
+	it := New(time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC))
+	if !it.Due.Equal(time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("Expected the first review the next day, got %s", it.Due.Format("2006-01-02"))
+	}
+
+	Schedule(&it, Quality(true, RatingGood), time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC))
+	if it.IntervalDays != 1 || !it.Due.Equal(time.Date(2026, 3, 3, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected a 1 day interval, got %d due %s", it.IntervalDays, it.Due.Format("2006-01-02"))
+	}
+	Schedule(&it, Quality(true, RatingGood), time.Date(2026, 3, 3, 0, 0, 0, 0, time.UTC))
+	if it.IntervalDays != 6 {
+		t.Errorf("Expected a 6 day interval, got %d", it.IntervalDays)
+	}
+	Schedule(&it, Quality(true, RatingEasy), time.Date(2026, 3, 9, 0, 0, 0, 0, time.UTC))
+	if it.IntervalDays != 15 || it.Ease <= InitialEase {
+		t.Errorf("Expected an easy review to grow the interval to 15 and the ease, got %d at %.2f", it.IntervalDays, it.Ease)
+	}
+}
+
+func TestSchedule_LapseStartsOver(t *testing.T) {
+	it := Item{Repetitions: 3, IntervalDays: 15, Ease: 2.5}
+	Schedule(&it, Quality(false, RatingEasy), time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC))
+	if it.Repetitions != 0 || it.IntervalDays != 1 || !it.Due.Equal(time.Date(2026, 3, 11, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected a failed review to start over tomorrow, got %+v", it)
+	}
+	if it.Ease >= 2.5 {
+		t.Errorf("Expected a failed review to lower the ease, got %.2f", it.Ease)
+	}
+
+	for i := 0; i < 10; i++ {
+		Schedule(&it, Quality(true, RatingAgain), time.Date(2026, 3, 11, 0, 0, 0, 0, time.UTC))
+	}
+	if it.Ease != MinEase || it.Repetitions != 0 {
+		t.Errorf("Expected the ease to bottom out at %.1f without repetitions, got %.2f/%d", MinEase, it.Ease, it.Repetitions)
+	}
+}
+
+func TestQuality_MapsRatings(t *testing.T) {
+	cases := []struct {
+		correct bool
+		rating  string
+		want    int
+	}{
+		{false, RatingEasy, 1},
+		{true, RatingAgain, 2},
+		{true, RatingHard, 3},
+		{true, RatingGood, 4},
+		{true, RatingEasy, 5},
+	}
+	for _, c := range cases {
+		if got := Quality(c.correct, c.rating); got != c.want {
+			t.Errorf("Quality(%v, %s) = %d, want %d", c.correct, c.rating, got, c.want)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package router
 import (
 	"database/sql"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/yourusername/wizardcore-backend/internal/config"
@@ -41,14 +42,32 @@ func Setup(db *sql.DB, cfg *config.Config, logger *zap.Logger, hub *websocket.Hu
 	exerciseRepo := repositories.NewExerciseRepository(db)
 	submissionRepo := repositories.NewSubmissionRepository(db)
 	achievementRepo := repositories.NewAchievementRepository(db)
-	progressRepo := repositories.NewProgressRepository(db)
+	xpRepo := repositories.NewXPRepository(db)
+	progressRepo := repositories.NewProgressRepository(db, xpRepo)
 	leaderboardRepo := repositories.NewLeaderboardRepository(db)
+	followRepo := repositories.NewFollowRepository(db)
+	leagueRepo := repositories.NewLeagueRepository(db, xpRepo)
+	antiCheatRepo := repositories.NewAntiCheatRepository(db)
 	matchRepo := repositories.NewMatchRepository(db)
 	searchRepo := repositories.NewSearchRepository(db)
 	creatorRepo := repositories.NewContentCreatorRepository(db)
 	// rbacRepo := repositories.NewRBACRepository(db, logger) // Not currently used
 	activityRepo := repositories.NewActivityRepository(db, logger)
 	preferencesRepo := repositories.NewPreferencesRepository(db)
+	pairRepo := repositories.NewPairSessionRepository(db)
+	matchmakingRepo := repositories.NewMatchmakingRepository(db)
+	matchInviteRepo := repositories.NewMatchInviteRepository(db)
+	notificationRepo := repositories.NewNotificationRepository(db)
+	tournamentRepo := repositories.NewTournamentRepository(db)
+	practiceCatalogRepo := repositories.NewPracticeCatalogRepository(db)
+	replayRepo := repositories.NewReplayRepository(db)
+	teamMatchRepo := repositories.NewTeamMatchRepository(db)
+	dailyChallengeRepo := repositories.NewDailyChallengeRepository(db, xpRepo)
+	streakRepo := repositories.NewStreakRepository(db)
+	activitySessionRepo := repositories.NewActivitySessionRepository(db)
+	reviewRepo := repositories.NewReviewRepository(db, xpRepo)
+	recommendationRepo := repositories.NewRecommendationRepository(db)
+	masteryRepo := repositories.NewMasteryRepository(db)
 
 	// Initialize Judge0 client
 	judge0Client := judge0.NewClient(cfg.Judge0APIURL, cfg.Judge0APIKey)
@@ -65,18 +84,64 @@ func Setup(db *sql.DB, cfg *config.Config, logger *zap.Logger, hub *websocket.Hu
 		}
 	}
 
+	// WebSocket replay buffer (shared through Redis when available)
+	replayTTL := time.Duration(cfg.WSReplayTTLMinutes) * time.Minute
+	if redisClient != nil {
+		hub.SetReplayStore(websocket.NewRedisReplayStore(redisClient, cfg.WSReplayBufferSize, replayTTL))
+	} else {
+		hub.SetReplayStore(websocket.NewMemoryReplayStore(cfg.WSReplayBufferSize, replayTTL))
+	}
+
 	// Initialize services
 	userService := services.NewUserService(userRepo, preferencesRepo)
 	pathwayService := services.NewPathwayService(pathwayRepo, userRepo)
 	exerciseService := services.NewExerciseService(exerciseRepo)
-	practiceService := services.NewPracticeService(matchRepo, userRepo, exerciseRepo)
-	progressService := services.NewProgressService(progressRepo, userRepo, pathwayRepo, exerciseRepo, activityRepo, logger)
-	submissionService := services.NewSubmissionService(submissionRepo, exerciseRepo, userRepo, judge0Client, practiceService, progressService)
+	recommendationService := services.NewRecommendationService(recommendationRepo, exerciseRepo)
+	practiceService := services.NewPracticeService(matchRepo, userRepo, exerciseRepo, practiceCatalogRepo, recommendationService, hub, logger)
+	notificationService := services.NewNotificationService(notificationRepo, hub, logger)
+	streakService := services.NewStreakService(progressRepo, streakRepo, activityRepo, notificationService, logger)
+	go streakService.Run()
+	progressService := services.NewProgressService(progressRepo, userRepo, pathwayRepo, exerciseRepo, activityRepo, streakService, logger)
+	submissionService := services.NewSubmissionService(submissionRepo, exerciseRepo, userRepo, xpRepo, judge0Client, practiceService, progressService, logger)
 	achievementService := services.NewAchievementService(achievementRepo, userRepo)
-	leaderboardService := services.NewLeaderboardService(leaderboardRepo, userRepo, redisClient)
+	leaderboardService := services.NewLeaderboardService(leaderboardRepo, userRepo, followRepo, redisClient, time.Duration(cfg.LeaderboardRefreshMinutes)*time.Minute, logger)
+	xpRepo.OnAward(leaderboardService.RecordAward)
+	go leaderboardService.Run()
 	searchService := services.NewSearchService(searchRepo)
 	creatorService := services.NewContentCreatorService(creatorRepo, userRepo)
 	activityService := services.NewActivityService(activityRepo, progressRepo, logger)
+	pairService := services.NewPairService(pairRepo, exerciseRepo, submissionService, hub, logger)
+	pairService.RegisterWebSocketHandlers()
+	matchmakingService := services.NewMatchmakingService(matchmakingRepo, matchRepo, practiceService, hub, logger)
+	go matchmakingService.Run()
+	followService := services.NewFollowService(followRepo, userRepo, activityRepo, notificationService, logger)
+	matchInviteService := services.NewMatchInviteService(matchInviteRepo, matchRepo, exerciseRepo, userRepo, followRepo, practiceService, notificationService, hub, logger)
+	go matchInviteService.Run()
+	tournamentService := services.NewTournamentService(tournamentRepo, matchRepo, exerciseRepo, practiceService, logger)
+	go tournamentService.Run()
+	matchSweeperService := services.NewMatchSweeperService(matchRepo, practiceService, logger)
+	go matchSweeperService.Run()
+	replayRetention := time.Duration(cfg.ReplayRetentionDays) * 24 * time.Hour
+	replayService := services.NewReplayService(replayRepo, matchRepo, practiceService, hub, replayRetention, cfg.ReplayMaxSnapshots, logger)
+	replayService.RegisterWebSocketHandlers()
+	go replayService.Run()
+	teamMatchService := services.NewTeamMatchService(teamMatchRepo, matchRepo, exerciseRepo, practiceService, hub, logger)
+	teamMatchService.RegisterWebSocketHandlers()
+	dailyChallengeService := services.NewDailyChallengeService(dailyChallengeRepo, hub, logger)
+	submissionService.OnGraded(dailyChallengeService.RecordSubmission)
+	activitySessionService := services.NewActivitySessionService(activitySessionRepo, logger)
+	submissionService.OnGraded(activitySessionService.RecordSubmission)
+	reviewService := services.NewReviewService(reviewRepo, streakService, logger)
+	submissionService.OnGraded(reviewService.RecordSubmission)
+	masteryService := services.NewMasteryService(masteryRepo, logger)
+	submissionService.OnGraded(masteryService.RecordSubmission)
+	submissionService.OnGraded(progressService.RecordSolvedExercise)
+	antiCheatService := services.NewAntiCheatService(antiCheatRepo, leaderboardService, cfg.AntiCheatExcludeOnFlag, logger)
+	submissionService.OnGraded(antiCheatService.RecordSubmission)
+	leagueService := services.NewLeagueService(leagueRepo, notificationService, logger)
+	xpService := services.NewXPService(xpRepo, logger)
+	submissionService.OnGraded(leagueService.RecordSubmission)
+	go leagueService.Run()
 	// rbacService := services.NewRBACService(rbacRepo, userRepo, logger) // Not currently used
 
 	// Initialize handlers
@@ -86,12 +151,28 @@ func Setup(db *sql.DB, cfg *config.Config, logger *zap.Logger, hub *websocket.Hu
 	exerciseHandler := handlers.NewExerciseHandler(exerciseService, logger)
 	submissionHandler := handlers.NewSubmissionHandler(submissionService, logger)
 	achievementHandler := handlers.NewAchievementHandler(achievementService, logger)
-	leaderboardHandler := handlers.NewLeaderboardHandler(leaderboardService, logger)
+	leaderboardHandler := handlers.NewLeaderboardHandler(leaderboardService, userService, logger)
 	progressHandler := handlers.NewProgressHandler(progressService, logger)
-	practiceHandler := handlers.NewPracticeHandler(practiceService, logger)
+	practiceHandler := handlers.NewPracticeHandler(practiceService, userService, logger)
 	searchHandler := handlers.NewSearchHandler(searchService, logger)
-	websocketHandler := handlers.NewWebSocketHandler(hub)
+	websocketHandler := handlers.NewWebSocketHandler(hub, userService, logger)
 	creatorHandler := handlers.NewContentCreatorHandler(creatorService, logger)
+	pairHandler := handlers.NewPairHandler(pairService, userService, logger)
+	matchmakingHandler := handlers.NewMatchmakingHandler(matchmakingService, userService, logger)
+	matchInviteHandler := handlers.NewMatchInviteHandler(matchInviteService, userService, logger)
+	tournamentHandler := handlers.NewTournamentHandler(tournamentService, userService, logger)
+	replayHandler := handlers.NewReplayHandler(replayService, userService, logger)
+	teamMatchHandler := handlers.NewTeamMatchHandler(teamMatchService, userService, logger)
+	dailyChallengeHandler := handlers.NewDailyChallengeHandler(dailyChallengeService, userService, logger)
+	followHandler := handlers.NewFollowHandler(followService, userService, logger)
+	leagueHandler := handlers.NewLeagueHandler(leagueService, userService, logger)
+	streakHandler := handlers.NewStreakHandler(streakService, userService, logger)
+	activitySessionHandler := handlers.NewActivitySessionHandler(activitySessionService, userService, logger)
+	reviewHandler := handlers.NewReviewHandler(reviewService, userService, logger)
+	recommendationHandler := handlers.NewRecommendationHandler(recommendationService, userService, logger)
+	masteryHandler := handlers.NewMasteryHandler(masteryService, userService, logger)
+	antiCheatHandler := handlers.NewAntiCheatHandler(antiCheatService, userService, logger)
+	xpHandler := handlers.NewXPHandler(xpService, userService, logger)
 
 	// API routes
 	api := r.Group("/api/v1")
@@ -102,6 +183,8 @@ func Setup(db *sql.DB, cfg *config.Config, logger *zap.Logger, hub *websocket.Hu
 		// Protected routes
 		protected := api.Group("")
 		protected.Use(middleware.AuthMiddleware(cfg.SupabaseJWTSecret))
+		// Per-user throttle for the routes that grade code
+		submitLimit := middleware.UserRateLimitMiddleware(cfg.SubmissionRatePerMinute, cfg.SubmissionBurst)
 		{
 			// User routes
 			protected.GET("/users/me", userHandler.GetCurrentUser)
@@ -125,9 +208,10 @@ func Setup(db *sql.DB, cfg *config.Config, logger *zap.Logger, hub *websocket.Hu
 			protected.GET("/exercises", exerciseHandler.GetExercisesByModule)
 			protected.GET("/exercises/:id", exerciseHandler.GetExercise)
 			protected.GET("/exercises/:id/stats", exerciseHandler.GetExerciseStats)
+			protected.POST("/exercises/:id/activity", activitySessionHandler.RecordEvent)
 
 			// Submission routes
-			protected.POST("/submissions", submissionHandler.CreateSubmission)
+			protected.POST("/submissions", submitLimit, submissionHandler.CreateSubmission)
 			protected.GET("/submissions/latest/:exercise_id", submissionHandler.GetLatestSubmission)
 			protected.POST("/submissions/save-draft/:exercise_id", submissionHandler.SaveDraft)
 			protected.GET("/submissions/:id", submissionHandler.GetSubmission)
@@ -137,19 +221,87 @@ func Setup(db *sql.DB, cfg *config.Config, logger *zap.Logger, hub *websocket.Hu
 
 			// Leaderboard routes
 			protected.GET("/leaderboard", leaderboardHandler.GetLeaderboard)
+			protected.GET("/users/me/leaderboard/history", leaderboardHandler.GetRankHistory)
+			protected.GET("/users/me/leaderboard/rank", leaderboardHandler.GetRank)
 
 			// Progress routes
 			protected.GET("/users/me/progress", progressHandler.GetUserProgress)
 			protected.GET("/users/me/milestones", progressHandler.GetMilestones)
 			protected.GET("/users/me/activity/weekly", progressHandler.GetWeeklyActivity)
 			protected.GET("/users/me/activity/weekly-hours", progressHandler.GetWeeklyHours)
+			protected.GET("/users/me/streak", streakHandler.GetStreak)
+			protected.GET("/users/me/reviews/due", reviewHandler.GetQueue)
+			protected.POST("/users/me/reviews/:exercise_id", reviewHandler.CompleteReview)
+			protected.GET("/users/me/recommendations", recommendationHandler.GetRecommendations)
+			protected.GET("/users/me/skills", masteryHandler.GetSkills)
 
 			// Practice routes
 			protected.GET("/practice/challenges", practiceHandler.GetChallenges)
 			protected.GET("/practice/areas", practiceHandler.GetAreas)
 			protected.GET("/users/me/practice/stats", practiceHandler.GetStats)
 			protected.GET("/users/me/matches", practiceHandler.GetRecentMatches)
+			protected.POST("/practice/challenges/duel/start", matchmakingHandler.JoinQueue)
 			protected.POST("/practice/challenges/:type/start", practiceHandler.StartChallenge)
+			protected.POST("/practice/queue", matchmakingHandler.JoinQueue)
+			protected.GET("/practice/queue", matchmakingHandler.GetStatus)
+			protected.DELETE("/practice/queue", matchmakingHandler.LeaveQueue)
+			protected.GET("/practice/leaderboard", practiceHandler.GetRatedLeaderboard)
+			protected.GET("/users/me/practice/rating-history", practiceHandler.GetRatingHistory)
+			protected.GET("/practice/matches/:id/run", practiceHandler.GetRun)
+			protected.POST("/practice/matches/:id/skip", practiceHandler.SkipExercise)
+			protected.POST("/practice/matches/:id/finish", practiceHandler.FinishRun)
+			protected.GET("/users/me/practice/personal-bests", practiceHandler.GetPersonalBests)
+			protected.GET("/practice/matches/:id/replay", replayHandler.GetReplay)
+			protected.PUT("/practice/matches/:id/replay/privacy", replayHandler.UpdatePrivacy)
+			protected.POST("/practice/invites", matchInviteHandler.CreateInvite)
+			protected.GET("/practice/invites", matchInviteHandler.ListInvites)
+			protected.POST("/practice/invites/accept", matchInviteHandler.AcceptByCode)
+			protected.GET("/practice/invites/:id", matchInviteHandler.GetInvite)
+			protected.POST("/practice/invites/:id/accept", matchInviteHandler.AcceptInvite)
+			protected.POST("/practice/invites/:id/decline", matchInviteHandler.DeclineInvite)
+			protected.DELETE("/practice/invites/:id", matchInviteHandler.CancelInvite)
+			protected.POST("/practice/parties", teamMatchHandler.CreateParty)
+			protected.GET("/practice/parties/current", teamMatchHandler.GetCurrentParty)
+			protected.POST("/practice/parties/join", teamMatchHandler.JoinParty)
+			protected.GET("/practice/parties/:id", teamMatchHandler.GetParty)
+			protected.POST("/practice/parties/:id/leave", teamMatchHandler.LeaveParty)
+			protected.POST("/practice/parties/:id/queue", teamMatchHandler.Queue)
+			protected.DELETE("/practice/parties/:id/queue", teamMatchHandler.Dequeue)
+			protected.GET("/practice/matches/:id/teams", teamMatchHandler.GetTeamMatch)
+
+			// Daily challenge routes
+			protected.GET("/daily-challenges", dailyChallengeHandler.GetArchive)
+			protected.GET("/daily-challenges/today", dailyChallengeHandler.GetToday)
+			protected.GET("/daily-challenges/:date", dailyChallengeHandler.GetChallenge)
+			protected.GET("/daily-challenges/:date/leaderboard", dailyChallengeHandler.GetLeaderboard)
+			protected.GET("/users/me/daily-challenge/streak", dailyChallengeHandler.GetStreak)
+
+			// Social routes; :id may be "me"
+			protected.POST("/users/:id/follow", followHandler.Follow)
+			protected.DELETE("/users/:id/follow", followHandler.Unfollow)
+			protected.GET("/users/:id/follow", followHandler.GetStatus)
+			protected.GET("/users/:id/followers", followHandler.GetFollowers)
+			protected.GET("/users/:id/following", followHandler.GetFollowing)
+			protected.GET("/users/me/friends", followHandler.GetFriends)
+			protected.GET("/users/me/feed", followHandler.GetFeed)
+
+			// League routes
+			protected.GET("/leagues/current", leagueHandler.GetCurrent)
+			protected.GET("/users/me/leagues/history", leagueHandler.GetHistory)
+
+			// Tournament routes
+			protected.GET("/tournaments", tournamentHandler.ListTournaments)
+			protected.GET("/tournaments/:id", tournamentHandler.GetTournament)
+			protected.GET("/tournaments/:id/bracket", tournamentHandler.GetBracket)
+			protected.POST("/tournaments/:id/register", tournamentHandler.Register)
+			protected.DELETE("/tournaments/:id/register", tournamentHandler.Unregister)
+
+			// Pair programming routes (edits flow over the WebSocket)
+			protected.POST("/pair-sessions", pairHandler.CreateSession)
+			protected.POST("/pair-sessions/join", pairHandler.JoinSession)
+			protected.GET("/pair-sessions/:id", pairHandler.GetSession)
+			protected.GET("/pair-sessions/:id/operations", pairHandler.GetOperations)
+			protected.POST("/pair-sessions/:id/submit", submitLimit, pairHandler.Submit)
 
 			// Search route
 			protected.GET("/search", searchHandler.Search)
@@ -172,6 +324,7 @@ func Setup(db *sql.DB, cfg *config.Config, logger *zap.Logger, hub *websocket.Hu
 				creator.GET("/pathways", creatorHandler.GetPathways)
 				creator.PUT("/pathways/:id", creatorHandler.UpdatePathway)
 				creator.DELETE("/pathways/:id", creatorHandler.DeletePathway)
+				creator.GET("/pathways/:id/mastery", masteryHandler.GetPathwayHeatmap)
 
 				// Modules
 				creator.POST("/modules", creatorHandler.CreateModule)
@@ -195,6 +348,28 @@ func Setup(db *sql.DB, cfg *config.Config, logger *zap.Logger, hub *websocket.Hu
 			{
 				// Content review
 				admin.POST("/reviews", creatorHandler.ReviewContent)
+
+				// Tournaments
+				admin.POST("/tournaments", tournamentHandler.CreateTournament)
+				admin.POST("/tournaments/:id/start", tournamentHandler.StartTournament)
+				admin.DELETE("/tournaments/:id", tournamentHandler.CancelTournament)
+				admin.PUT("/tournaments/:id/matches/:node/result", tournamentHandler.OverrideResult)
+
+				// Practice catalog
+				admin.GET("/practice/challenge-types", practiceHandler.ListChallengeTypes)
+				admin.PUT("/practice/challenge-types/:id", practiceHandler.UpdateChallengeType)
+				admin.GET("/practice/areas", practiceHandler.ListAreaSettings)
+				admin.PUT("/practice/areas", practiceHandler.SaveAreaSetting)
+				admin.DELETE("/practice/areas/:id", practiceHandler.DeleteAreaSetting)
+
+				// Anti-cheat review queue
+				admin.GET("/anticheat/reviews", antiCheatHandler.ListReviews)
+				admin.GET("/anticheat/reviews/:user_id", antiCheatHandler.GetReview)
+				admin.PUT("/anticheat/reviews/:user_id", antiCheatHandler.UpdateReview)
+
+				// Users the XP ledger backfill could not account for
+				admin.GET("/xp/backfill-reviews", xpHandler.ListBackfillReviews)
+				admin.PUT("/xp/backfill-reviews/:user_id", xpHandler.ResolveBackfillReview)
 			}
 		}
 	}
@@ -53,8 +53,7 @@ func (s *ActivityService) RecordExerciseSubmission(ctx context.Context, userID u
 	}
 
 	// Update daily activity record
-	today := time.Now().UTC().Truncate(24 * time.Hour)
-	err = s.progressRepo.RecordDailyActivity(userID, today, 1, xpEarned, timeSpentMinutes, 1, true)
+	_, _, err = s.progressRepo.RecordDailyActivity(userID, time.Now(), 1, xpEarned, timeSpentMinutes, 1)
 	if err != nil {
 		s.logger.Error("Failed to record daily activity",
 			zap.Error(err),
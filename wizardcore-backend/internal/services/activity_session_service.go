@@ -0,0 +1,42 @@
+package services
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yourusername/wizardcore-backend/internal/models"
+	"github.com/yourusername/wizardcore-backend/internal/repositories"
+	"go.uber.org/zap"
+)
+
+// ActivitySessionService tracks the time users spend on exercises from the
+// focus and heartbeat events their clients send, and keeps the exercises'
+// completion times up to date with it
+type ActivitySessionService struct {
+	sessionRepo *repositories.ActivitySessionRepository
+	logger      *zap.Logger
+}
+
+func NewActivitySessionService(sessionRepo *repositories.ActivitySessionRepository, logger *zap.Logger) *ActivitySessionService {
+	return &ActivitySessionService{
+		sessionRepo: sessionRepo,
+		logger:      logger,
+	}
+}
+
+// RecordEvent applies a client event on an exercise as of now. It returns
+// the user's session on the exercise, or nil if there is no such exercise.
+func (s *ActivitySessionService) RecordEvent(userID, exerciseID uuid.UUID, event string) (*models.ActivitySession, error) {
+	return s.sessionRepo.RecordEvent(userID, exerciseID, event, time.Now())
+}
+
+// RecordSubmission refreshes the completion stats of the exercise of an
+// accepted submission (registered as a graded submission listener)
+func (s *ActivitySessionService) RecordSubmission(submission *models.Submission) {
+	if !submission.IsCorrect {
+		return
+	}
+	if err := s.sessionRepo.RefreshCompletionStats(submission.ExerciseID); err != nil {
+		s.logger.Error("Failed to refresh exercise completion stats", zap.Error(err), zap.String("exercise_id", submission.ExerciseID.String()))
+	}
+}
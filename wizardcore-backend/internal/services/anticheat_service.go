@@ -0,0 +1,168 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yourusername/wizardcore-backend/internal/anticheat"
+	"github.com/yourusername/wizardcore-backend/internal/models"
+	"github.com/yourusername/wizardcore-backend/internal/repositories"
+	"go.uber.org/zap"
+)
+
+// Flags of the same reason on an account within this window are recorded
+// once
+const antiCheatFlagDedupeWindow = time.Hour
+
+// AntiCheatService looks for implausible submission patterns and sends the
+// accounts showing them to the admin review queue
+type AntiCheatService struct {
+	antiCheatRepo      *repositories.AntiCheatRepository
+	leaderboardService *LeaderboardService
+	rules              anticheat.Rules
+	// Whether accounts are excluded from leaderboards as soon as they are
+	// flagged, before an admin has looked at them
+	excludeOnFlag bool
+	logger        *zap.Logger
+}
+
+func NewAntiCheatService(antiCheatRepo *repositories.AntiCheatRepository, leaderboardService *LeaderboardService, excludeOnFlag bool, logger *zap.Logger) *AntiCheatService {
+	return &AntiCheatService{
+		antiCheatRepo:      antiCheatRepo,
+		leaderboardService: leaderboardService,
+		rules:              anticheat.DefaultRules,
+		excludeOnFlag:      excludeOnFlag,
+		logger:             logger,
+	}
+}
+
+// RecordSubmission runs the checks on a graded submission. It is
+// registered as a graded submission listener; failures are logged.
+func (s *AntiCheatService) RecordSubmission(submission *models.Submission) {
+	at := submission.CreatedAt
+	if at.IsZero() {
+		at = time.Now()
+	}
+
+	count, err := s.antiCheatRepo.CountSubmissionsSince(submission.UserID, at.Add(-s.rules.BurstWindow))
+	if err != nil {
+		s.logger.Error("Failed to check submission burst", zap.Error(err), zap.String("submission_id", submission.ID.String()))
+	} else if s.rules.Burst(count) {
+		s.flag(submission, anticheat.ReasonBurst, nil,
+			fmt.Sprintf("%d submissions within %s", count, s.rules.BurstWindow))
+	}
+
+	// Pair submissions are shared by every partner and timed by the session,
+	// so only the author's own solutions are compared
+	if submission.IsCorrect && submission.SubmissionType != "pair" && submission.SubmissionType != models.SubmissionTypeJointCopy {
+		s.checkSolveTime(submission)
+		s.checkDuplicateSource(submission)
+	}
+
+	if submission.PointsEarned > 0 {
+		recent, baseline, err := s.antiCheatRepo.GetXPWindows(submission.UserID,
+			at.Add(-s.rules.SpikeWindow), at.AddDate(0, 0, -s.rules.SpikeBaselineDays))
+		if err != nil {
+			s.logger.Error("Failed to check XP spike", zap.Error(err), zap.String("submission_id", submission.ID.String()))
+		} else if s.rules.Spike(recent, baseline) {
+			s.flag(submission, anticheat.ReasonXPSpike, nil,
+				fmt.Sprintf("%d XP within %s against %d XP over the previous %d days", recent, s.rules.SpikeWindow, baseline, s.rules.SpikeBaselineDays))
+		}
+	}
+}
+
+// checkSolveTime flags a first solve faster than anyone has solved the
+// exercise before. Solves that are not flagged extend the baseline.
+func (s *AntiCheatService) checkSolveTime(submission *models.Submission) {
+	seconds, ok, err := s.antiCheatRepo.GetSolveSeconds(submission)
+	if err != nil || !ok {
+		if err != nil {
+			s.logger.Error("Failed to measure solve time", zap.Error(err), zap.String("submission_id", submission.ID.String()))
+		}
+		return
+	}
+	baseline, samples, err := s.antiCheatRepo.GetBaseline(submission.ExerciseID)
+	if err != nil {
+		s.logger.Error("Failed to load solve baseline", zap.Error(err), zap.String("exercise_id", submission.ExerciseID.String()))
+		return
+	}
+	if s.rules.FastSolve(seconds, baseline, samples) {
+		s.flag(submission, anticheat.ReasonFastSolve, nil,
+			fmt.Sprintf("solved in %ds, fastest of %d solves is %ds", seconds, samples, baseline))
+		return
+	}
+	if err := s.antiCheatRepo.RecordSolveTime(submission.ExerciseID, seconds); err != nil {
+		s.logger.Error("Failed to record solve time", zap.Error(err), zap.String("exercise_id", submission.ExerciseID.String()))
+	}
+}
+
+// checkDuplicateSource flags an accepted solution identical, apart from
+// whitespace, to one another account submitted
+func (s *AntiCheatService) checkDuplicateSource(submission *models.Submission) {
+	fingerprint, ok := s.rules.Fingerprint(submission.SourceCode)
+	if !ok {
+		return
+	}
+	others, err := s.antiCheatRepo.FindDuplicateSource(submission.ExerciseID, submission.UserID, fingerprint)
+	if err != nil {
+		s.logger.Error("Failed to check duplicate source", zap.Error(err), zap.String("submission_id", submission.ID.String()))
+		return
+	}
+	for i := range others {
+		s.flag(submission, anticheat.ReasonDuplicateSource, &others[i],
+			fmt.Sprintf("same source as user %s", others[i]))
+	}
+}
+
+func (s *AntiCheatService) flag(submission *models.Submission, reason string, relatedUserID *uuid.UUID, details string) {
+	submissionID := submission.ID
+	recorded, err := s.antiCheatRepo.AddFlag(&models.AntiCheatFlag{
+		UserID:        submission.UserID,
+		Reason:        reason,
+		SubmissionID:  &submissionID,
+		RelatedUserID: relatedUserID,
+		Details:       &details,
+	}, antiCheatFlagDedupeWindow, s.excludeOnFlag)
+	if err != nil {
+		s.logger.Error("Failed to flag account", zap.Error(err), zap.String("user_id", submission.UserID.String()))
+		return
+	}
+	// The submission's XP already reached the live leaderboards
+	if recorded && s.excludeOnFlag && s.leaderboardService != nil {
+		s.leaderboardService.RecomputeNow()
+	}
+}
+
+// ListReviews returns the review queue filtered by status
+func (s *AntiCheatService) ListReviews(status string, limit, offset int) ([]models.AntiCheatReview, int, error) {
+	return s.antiCheatRepo.ListReviews(status, limit, offset)
+}
+
+// GetReview returns an account's review with its most recent flags, or nil
+// if the account was never flagged
+func (s *AntiCheatService) GetReview(userID uuid.UUID) (*models.AntiCheatReview, error) {
+	review, err := s.antiCheatRepo.GetReview(userID)
+	if err != nil || review == nil {
+		return review, err
+	}
+	review.Flags, err = s.antiCheatRepo.ListFlags(userID, 100)
+	if err != nil {
+		return nil, err
+	}
+	return review, nil
+}
+
+// UpdateReview records an admin's decision on a flagged account and
+// recalculates the leaderboards so exclusions take effect. It returns nil
+// if the account has no review.
+func (s *AntiCheatService) UpdateReview(userID, reviewerID uuid.UUID, req *models.UpdateAntiCheatReviewRequest) (*models.AntiCheatReview, error) {
+	updated, err := s.antiCheatRepo.UpdateReview(userID, reviewerID, req.Status, req.ExcludeFromLeaderboards, req.Notes)
+	if err != nil || !updated {
+		return nil, err
+	}
+	if s.leaderboardService != nil {
+		s.leaderboardService.RecomputeNow()
+	}
+	return s.GetReview(userID)
+}
@@ -0,0 +1,197 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yourusername/wizardcore-backend/internal/daily"
+	"github.com/yourusername/wizardcore-backend/internal/models"
+	"github.com/yourusername/wizardcore-backend/internal/repositories"
+	"github.com/yourusername/wizardcore-backend/internal/websocket"
+	"go.uber.org/zap"
+)
+
+// Days after which an exercise may be the daily challenge again, once every
+// exercise of a difficulty has had its turn
+const dailyRepeatAfterDays = 365
+
+// DailyChallengeService runs the global daily challenge. Each UTC day gets
+// one published exercise, chosen deterministically from the exercises of the
+// day's difficulty in the weekly rotation that have not been a daily
+// challenge before. Players are ranked by the time from opening the
+// challenge to their first accepted submission; solving it earns bonus XP
+// and extends a daily challenge streak kept apart from the activity streak.
+type DailyChallengeService struct {
+	dailyRepo *repositories.DailyChallengeRepository
+	hub       *websocket.Hub
+	logger    *zap.Logger
+}
+
+func NewDailyChallengeService(dailyRepo *repositories.DailyChallengeRepository, hub *websocket.Hub, logger *zap.Logger) *DailyChallengeService {
+	return &DailyChallengeService{
+		dailyRepo: dailyRepo,
+		hub:       hub,
+		logger:    logger,
+	}
+}
+
+// ensureChallenge returns the challenge of a day, choosing it on first use.
+// Concurrent callers agree on the choice because it is deterministic and
+// the first one stored wins.
+func (s *DailyChallengeService) ensureChallenge(day time.Time) (*models.DailyChallenge, error) {
+	challenge, err := s.dailyRepo.FindChallenge(day)
+	if err != nil || challenge != nil {
+		return challenge, err
+	}
+
+	repeatSince := day.AddDate(0, 0, -dailyRepeatAfterDays)
+	for _, since := range []*time.Time{nil, &repeatSince} {
+		for _, difficulty := range daily.Difficulties(day) {
+			candidates, err := s.dailyRepo.CandidateExerciseIDs(difficulty, since)
+			if err != nil {
+				return nil, err
+			}
+			exerciseID, ok := daily.Pick(day, candidates)
+			if !ok {
+				continue
+			}
+			bonusXP, ok := daily.BonusXP[difficulty]
+			if !ok {
+				bonusXP = daily.DefaultBonusXP
+			}
+			if err := s.dailyRepo.CreateChallenge(day, exerciseID, difficulty, bonusXP); err != nil {
+				return nil, err
+			}
+			return s.dailyRepo.FindChallenge(day)
+		}
+	}
+	return nil, fmt.Errorf("no exercises available for the daily challenge")
+}
+
+// GetToday returns today's challenge and starts the user's clock on it
+func (s *DailyChallengeService) GetToday(userID uuid.UUID) (*models.DailyChallenge, error) {
+	now := time.Now()
+	today := daily.Day(now)
+	challenge, err := s.ensureChallenge(today)
+	if err != nil {
+		return nil, err
+	}
+	challenge.Attempt, err = s.dailyRepo.StartAttempt(today, userID, now)
+	if err != nil {
+		return nil, err
+	}
+	return challenge, nil
+}
+
+// parseDay parses a YYYY-MM-DD date that is not in the future
+func parseDay(date string) (time.Time, error) {
+	day, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date, expected YYYY-MM-DD")
+	}
+	if day.After(daily.Day(time.Now())) {
+		return time.Time{}, fmt.Errorf("no daily challenge yet for %s", date)
+	}
+	return day, nil
+}
+
+// GetChallenge returns the challenge of a day with the user's attempt, or
+// nil if that day had none. Today's challenge is opened as by GetToday, so
+// its exercise is never shown without starting the user's clock.
+func (s *DailyChallengeService) GetChallenge(userID uuid.UUID, date string) (*models.DailyChallenge, error) {
+	day, err := parseDay(date)
+	if err != nil {
+		return nil, err
+	}
+	if day.Equal(daily.Day(time.Now())) {
+		return s.GetToday(userID)
+	}
+	challenge, err := s.dailyRepo.FindChallenge(day)
+	if err != nil || challenge == nil {
+		return nil, err
+	}
+	challenge.Attempt, err = s.dailyRepo.GetAttempt(day, userID)
+	if err != nil {
+		return nil, err
+	}
+	return challenge, nil
+}
+
+// GetArchive returns the daily challenges before today, newest first.
+// Today's challenge is left to GetToday, which starts the user's clock.
+func (s *DailyChallengeService) GetArchive(userID uuid.UUID, limit, offset int) ([]models.DailyChallenge, int, error) {
+	yesterday := daily.Day(time.Now()).AddDate(0, 0, -1)
+	return s.dailyRepo.ListChallenges(userID, yesterday, limit, offset)
+}
+
+// GetLeaderboard returns the fastest solvers of a day's challenge and the
+// user's own rank, which is 0 if they have not solved it
+func (s *DailyChallengeService) GetLeaderboard(userID uuid.UUID, date string, limit, offset int) ([]models.DailyLeaderboardEntry, int, int, error) {
+	day, err := parseDay(date)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	entries, total, err := s.dailyRepo.GetLeaderboard(day, limit, offset)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	for i := range entries {
+		entries[i].IsCurrentUser = entries[i].UserID == userID
+	}
+	rank, err := s.dailyRepo.GetRank(day, userID)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	return entries, total, rank, nil
+}
+
+// GetStreak returns the user's daily challenge streak as of today
+func (s *DailyChallengeService) GetStreak(userID uuid.UUID) (*models.DailyChallengeStreak, error) {
+	streak, err := s.dailyRepo.GetStreak(userID)
+	if err != nil {
+		return nil, err
+	}
+	streak.CurrentStreak = daily.ActiveStreak(streak.LastCompletedDate, streak.CurrentStreak, time.Now())
+	return streak, nil
+}
+
+// RecordSubmission completes today's challenge for the author of an
+// accepted submission on its exercise. Copies of a joint submission made
+// for the partner do not count. It is registered as a graded submission
+// listener; failures are logged.
+func (s *DailyChallengeService) RecordSubmission(submission *models.Submission) {
+	if !submission.IsCorrect || submission.SubmissionType == models.SubmissionTypeJointCopy {
+		return
+	}
+	now := time.Now()
+	today := daily.Day(now)
+	challenge, err := s.ensureChallenge(today)
+	if err != nil {
+		s.logger.Error("Failed to load daily challenge", zap.Error(err), zap.String("submission_id", submission.ID.String()))
+		return
+	}
+	if challenge.ExerciseID != submission.ExerciseID {
+		return
+	}
+	completion, err := s.dailyRepo.CompleteAttempt(today, submission.UserID, submission.ID, now, challenge.BonusXP)
+	if err != nil {
+		s.logger.Error("Failed to complete daily challenge", zap.Error(err), zap.String("submission_id", submission.ID.String()))
+		return
+	}
+	if completion == nil || s.hub == nil {
+		return
+	}
+	payload := websocket.DailyChallengeCompletePayload{
+		Date:          completion.Attempt.Date,
+		BonusXP:       completion.Attempt.BonusXP,
+		CurrentStreak: completion.Streak.CurrentStreak,
+		LongestStreak: completion.Streak.LongestStreak,
+	}
+	if completion.Attempt.SolveSeconds != nil {
+		payload.SolveSeconds = *completion.Attempt.SolveSeconds
+	}
+	if err := s.hub.SendToUser(submission.UserID, websocket.DailyChallengeComplete, payload); err != nil {
+		s.logger.Error("Failed to push daily challenge completion", zap.Error(err), zap.String("user_id", submission.UserID.String()))
+	}
+}
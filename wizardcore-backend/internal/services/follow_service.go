@@ -0,0 +1,141 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/yourusername/wizardcore-backend/internal/models"
+	"github.com/yourusername/wizardcore-backend/internal/repositories"
+	"go.uber.org/zap"
+)
+
+// FollowService manages follows between users. Users who follow each other
+// are friends: they see each other's follow lists and activity even when a
+// profile is not public, and share a friends leaderboard.
+type FollowService struct {
+	followRepo          *repositories.FollowRepository
+	userRepo            *repositories.UserRepository
+	activityRepo        *repositories.ActivityRepository
+	notificationService *NotificationService
+	logger              *zap.Logger
+}
+
+func NewFollowService(followRepo *repositories.FollowRepository, userRepo *repositories.UserRepository, activityRepo *repositories.ActivityRepository, notificationService *NotificationService, logger *zap.Logger) *FollowService {
+	return &FollowService{
+		followRepo:          followRepo,
+		userRepo:            userRepo,
+		activityRepo:        activityRepo,
+		notificationService: notificationService,
+		logger:              logger,
+	}
+}
+
+// Follow makes followerID follow followeeID and returns their relationship
+// afterwards, or nil if followeeID does not exist. A new follow notifies the
+// followed user.
+func (s *FollowService) Follow(followerID, followeeID uuid.UUID) (*models.FollowStatus, error) {
+	if followerID == followeeID {
+		return nil, fmt.Errorf("cannot follow yourself")
+	}
+	followee, err := s.userRepo.FindByID(followeeID)
+	if err != nil || followee == nil {
+		return nil, err
+	}
+	created, err := s.followRepo.Follow(followerID, followeeID)
+	if err != nil {
+		return nil, err
+	}
+	status, err := s.followRepo.GetStatus(followerID, followeeID)
+	if err != nil {
+		return nil, err
+	}
+	if created {
+		s.notifyFollow(followerID, followeeID, status.IsFriend)
+	}
+	return status, nil
+}
+
+func (s *FollowService) notifyFollow(followerID, followeeID uuid.UUID, friends bool) {
+	if s.notificationService == nil {
+		return
+	}
+	name := "Someone"
+	follower, err := s.userRepo.FindByID(followerID)
+	if err != nil {
+		s.logger.Error("Failed to fetch follower", zap.Error(err), zap.String("user_id", followerID.String()))
+	} else if follower != nil && follower.DisplayName != nil && *follower.DisplayName != "" {
+		name = *follower.DisplayName
+	}
+	title := "New follower"
+	message := fmt.Sprintf("%s started following you.", name)
+	if friends {
+		title = "New friend"
+		message = fmt.Sprintf("%s followed you back. You are now friends.", name)
+	}
+	icon := "👋"
+	actionURL := "/users/" + followerID.String()
+	err = s.notificationService.CreateNotification(&models.Notification{
+		UserID:    followeeID,
+		Type:      "new_follower",
+		Title:     title,
+		Message:   &message,
+		Icon:      &icon,
+		ActionURL: &actionURL,
+	})
+	if err != nil {
+		s.logger.Error("Failed to send follow notification", zap.Error(err), zap.String("user_id", followeeID.String()))
+	}
+}
+
+func (s *FollowService) Unfollow(followerID, followeeID uuid.UUID) error {
+	return s.followRepo.Unfollow(followerID, followeeID)
+}
+
+// GetStatus returns how the viewer and a user are related, or nil if the
+// user does not exist
+func (s *FollowService) GetStatus(viewerID, userID uuid.UUID) (*models.FollowStatus, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil || user == nil {
+		return nil, err
+	}
+	return s.followRepo.GetStatus(viewerID, userID)
+}
+
+// checkVisible returns ErrProfilePrivate unless the viewer may see the
+// user's follow lists
+func (s *FollowService) checkVisible(viewerID, userID uuid.UUID) error {
+	visible, err := s.followRepo.CanView(viewerID, userID)
+	if err != nil {
+		return err
+	}
+	if !visible {
+		return repositories.ErrProfilePrivate
+	}
+	return nil
+}
+
+// GetFollowers returns the users following userID as the viewer may see them
+func (s *FollowService) GetFollowers(viewerID, userID uuid.UUID, limit, offset int) ([]models.FollowUser, int, error) {
+	if err := s.checkVisible(viewerID, userID); err != nil {
+		return nil, 0, err
+	}
+	return s.followRepo.ListFollowers(userID, viewerID, limit, offset)
+}
+
+// GetFollowing returns the users userID follows as the viewer may see them
+func (s *FollowService) GetFollowing(viewerID, userID uuid.UUID, limit, offset int) ([]models.FollowUser, int, error) {
+	if err := s.checkVisible(viewerID, userID); err != nil {
+		return nil, 0, err
+	}
+	return s.followRepo.ListFollowing(userID, viewerID, limit, offset)
+}
+
+func (s *FollowService) GetFriends(userID uuid.UUID, limit, offset int) ([]models.FollowUser, int, error) {
+	return s.followRepo.ListFriends(userID, limit, offset)
+}
+
+// GetFeed returns the recent activity of the users someone follows
+func (s *FollowService) GetFeed(userID uuid.UUID, limit, offset int) ([]models.FeedActivity, error) {
+	return s.activityRepo.GetFollowingFeed(context.Background(), userID, limit, offset)
+}
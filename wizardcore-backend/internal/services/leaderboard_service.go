@@ -2,88 +2,451 @@ package services
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
+	"sort"
+	"strconv"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/yourusername/wizardcore-backend/internal/models"
 	"github.com/yourusername/wizardcore-backend/internal/repositories"
 	"github.com/yourusername/wizardcore-backend/pkg/redis"
+	"go.uber.org/zap"
 )
 
+// Timeframes that have their own leaderboards
+var leaderboardTimeframes = []string{"all", "month", "week"}
+
+// Trends compare the current rank with the rank held this long ago
+const leaderboardTrendWindow = 24 * time.Hour
+
+// Sorted sets of weekly and monthly leaderboards outlive their period by
+// this long and then expire on their own
+const leaderboardKeyGrace = 7 * 24 * time.Hour
+
+// Longest a sorted set reload may take before XP awarded meanwhile is no
+// longer collected for it
+const leaderboardLoadTimeout = 10 * time.Minute
+
+// LeaderboardService ranks learners by XP. Postgres holds the rankings
+// recalculated by Run. When Redis is configured, every leaderboard is also
+// kept in a sorted set that XP awards update as they happen, and
+// pages and ranks are read from there; each recalculation reloads the sets
+// to correct any drift.
 type LeaderboardService struct {
 	leaderboardRepo *repositories.LeaderboardRepository
 	userRepo        *repositories.UserRepository
+	followRepo      *repositories.FollowRepository
 	redisClient     *redis.Client
+	refreshInterval time.Duration
+	logger          *zap.Logger
 }
 
-func NewLeaderboardService(leaderboardRepo *repositories.LeaderboardRepository, userRepo *repositories.UserRepository, redisClient *redis.Client) *LeaderboardService {
+func NewLeaderboardService(leaderboardRepo *repositories.LeaderboardRepository, userRepo *repositories.UserRepository, followRepo *repositories.FollowRepository, redisClient *redis.Client, refreshInterval time.Duration, logger *zap.Logger) *LeaderboardService {
+	if refreshInterval <= 0 {
+		refreshInterval = 10 * time.Minute
+	}
 	return &LeaderboardService{
 		leaderboardRepo: leaderboardRepo,
 		userRepo:        userRepo,
+		followRepo:      followRepo,
 		redisClient:     redisClient,
+		refreshInterval: refreshInterval,
+		logger:          logger,
 	}
 }
 
-func (s *LeaderboardService) GetLeaderboard(timeframe string, pathwayID *string, page, perPage int) (*models.LeaderboardResponse, error) {
-	// Validate timeframe
-	if timeframe == "" {
-		timeframe = "all"
+// Run recalculates every leaderboard on start and then periodically until
+// the process exits
+func (s *LeaderboardService) Run() {
+	s.recomputeAll(time.Now())
+	ticker := time.NewTicker(s.refreshInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		s.recomputeAll(time.Now())
 	}
-	if !isValidTimeframe(timeframe) {
-		timeframe = "all"
+}
+
+// RecomputeNow recalculates every leaderboard in the background without
+// waiting for the next scheduled run
+func (s *LeaderboardService) RecomputeNow() {
+	go s.recomputeAll(time.Now())
+}
+
+// recomputeAll recalculates the global and pathway leaderboards of every
+// timeframe. Failures are logged and the remaining leaderboards still run.
+func (s *LeaderboardService) recomputeAll(now time.Time) {
+	pathwayIDs, err := s.leaderboardRepo.ListPathwayIDs()
+	if err != nil {
+		s.logger.Error("Failed to list pathways for leaderboards", zap.Error(err))
 	}
+	for _, timeframe := range leaderboardTimeframes {
+		if err := s.recompute(timeframe, nil, now); err != nil {
+			s.logger.Error("Failed to update leaderboard", zap.Error(err), zap.String("timeframe", timeframe))
+		}
+		for i := range pathwayIDs {
+			if err := s.recompute(timeframe, &pathwayIDs[i], now); err != nil {
+				s.logger.Error("Failed to update pathway leaderboard", zap.Error(err), zap.String("timeframe", timeframe), zap.String("pathway_id", pathwayIDs[i].String()))
+			}
+		}
+	}
+}
 
-	// Convert pathwayID string to UUID pointer
-	var pathwayUUID *uuid.UUID
-	if pathwayID != nil && *pathwayID != "" {
-		parsed, err := uuid.Parse(*pathwayID)
-		if err == nil {
-			pathwayUUID = &parsed
+func (s *LeaderboardService) recompute(timeframe string, pathwayID *uuid.UUID, now time.Time) error {
+	if err := s.leaderboardRepo.UpdateLeaderboard(timeframe, pathwayID, timeframeStart(timeframe, now), now, leaderboardTrendWindow); err != nil {
+		return err
+	}
+	if s.redisClient == nil {
+		return nil
+	}
+	return s.loadSortedSet(timeframe, pathwayID, now)
+}
+
+// leaderboardKey names the sorted set of a leaderboard in the period
+// containing now and returns when it expires. Weekly and monthly
+// leaderboards get a new key each period; all-time keys never expire.
+func leaderboardKey(timeframe string, pathwayID *uuid.UUID, now time.Time) (string, time.Time) {
+	scope := "global"
+	if pathwayID != nil {
+		scope = pathwayID.String()
+	}
+	start := timeframeStart(timeframe, now)
+	if start == nil {
+		return "leaderboard:zset:all:" + scope, time.Time{}
+	}
+	end := start.AddDate(0, 1, 0)
+	if timeframe == "week" {
+		end = start.AddDate(0, 0, 7)
+	}
+	return fmt.Sprintf("leaderboard:zset:%s:%s:%s", timeframe, start.Format("2006-01-02"), scope), end.Add(leaderboardKeyGrace)
+}
+
+// finishLoadScript moves the freshly loaded ranking KEYS[2] into place as
+// KEYS[1], adding the points collected in KEYS[3] while it was built, and
+// ends the load marked by KEYS[4]. The set expires at ARGV[1] unless that
+// is 0.
+const finishLoadScript = `
+redis.call('ZUNIONSTORE', KEYS[2], 2, KEYS[2], KEYS[3])
+if redis.call('EXISTS', KEYS[2]) == 1 then
+	redis.call('RENAME', KEYS[2], KEYS[1])
+	if ARGV[1] ~= '0' then
+		redis.call('EXPIREAT', KEYS[1], ARGV[1])
+	end
+else
+	redis.call('DEL', KEYS[1])
+end
+redis.call('DEL', KEYS[3], KEYS[4])
+return 1
+`
+
+// loadSortedSet replaces a leaderboard's sorted set with its freshly
+// recalculated ranking. The ranking is built under a temporary key and
+// renamed into place; XP awarded while it is built is collected and added
+// before the rename, so the load does not drop it.
+func (s *LeaderboardService) loadSortedSet(timeframe string, pathwayID *uuid.UUID, now time.Time) error {
+	key, expireAt := leaderboardKey(timeframe, pathwayID, now)
+	ctx := context.Background()
+	if err := s.redisClient.Del(ctx, key+":pending"); err != nil {
+		return fmt.Errorf("failed to start leaderboard load: %w", err)
+	}
+	if err := s.redisClient.Set(ctx, key+":loading", now.Unix(), leaderboardLoadTimeout); err != nil {
+		return fmt.Errorf("failed to start leaderboard load: %w", err)
+	}
+
+	scores, err := s.leaderboardRepo.ListScores(timeframe, pathwayID)
+	if err != nil {
+		return err
+	}
+	members := make([]redis.ScoredMember, len(scores))
+	for i, score := range scores {
+		members[i] = redis.ScoredMember{Member: score.UserID.String(), Score: float64(score.XP)}
+	}
+	tmp := key + ":tmp"
+	if err := s.redisClient.ReplaceSortedSet(ctx, tmp, members, time.Time{}); err != nil {
+		return fmt.Errorf("failed to load leaderboard into Redis: %w", err)
+	}
+	var expireUnix int64
+	if !expireAt.IsZero() {
+		expireUnix = expireAt.Unix()
+	}
+	keys := []string{key, tmp, key + ":pending", key + ":loading"}
+	if _, err := s.redisClient.Eval(ctx, finishLoadScript, keys, expireUnix); err != nil {
+		return fmt.Errorf("failed to load leaderboard into Redis: %w", err)
+	}
+	var ttl time.Duration
+	if !expireAt.IsZero() {
+		ttl = expireAt.Sub(now)
+	}
+	if err := s.redisClient.Set(ctx, key+":loaded", now.Unix(), ttl); err != nil {
+		return fmt.Errorf("failed to mark leaderboard loaded: %w", err)
+	}
+	return nil
+}
+
+// isLoaded reports whether a leaderboard's sorted set has been loaded from
+// Postgres. Until then it would be incomplete, so it is neither updated nor
+// read.
+func (s *LeaderboardService) isLoaded(ctx context.Context, key string) bool {
+	loaded, err := s.redisClient.Exists(ctx, key+":loaded")
+	if err != nil {
+		s.logger.Error("Failed to check leaderboard", zap.Error(err), zap.String("key", key))
+		return false
+	}
+	return loaded
+}
+
+// RecordAward adds XP the ledger credited to the user's global scores and
+// to the leaderboards of its pathway. Users excluded by an anti-cheat review
+// are skipped. It is registered as an XP award listener; failures are logged
+// and corrected by the next recalculation.
+func (s *LeaderboardService) RecordAward(t models.XPTransaction) {
+	if s.redisClient == nil || t.Amount == 0 || s.isExcluded(t.UserID) {
+		return
+	}
+	ctx := context.Background()
+	member := t.UserID.String()
+	points := float64(t.Amount)
+	for _, timeframe := range leaderboardTimeframes {
+		s.incrementScore(ctx, timeframe, nil, member, points, t.CreatedAt)
+		if t.PathwayID != nil {
+			s.incrementScore(ctx, timeframe, t.PathwayID, member, points, t.CreatedAt)
 		}
 	}
+}
 
-	// Calculate offset
-	offset := (page - 1) * perPage
-	if offset < 0 {
-		offset = 0
+// isExcluded reports whether an anti-cheat review keeps a user off the
+// leaderboards. Lookup failures are logged and treated as not excluded.
+func (s *LeaderboardService) isExcluded(userID uuid.UUID) bool {
+	excluded, err := s.leaderboardRepo.IsExcluded(userID)
+	if err != nil {
+		s.logger.Error("Failed to check leaderboard exclusion", zap.Error(err), zap.String("user_id", userID.String()))
+		return false
 	}
+	return excluded
+}
 
-	// Try cache if Redis client is available
-	if s.redisClient != nil {
-		cacheKey := fmt.Sprintf("leaderboard:%s:%v:%d:%d", timeframe, pathwayUUID, page, perPage)
-		ctx := context.Background()
-		cached, err := s.redisClient.Get(ctx, cacheKey)
-		if err == nil && cached != "" {
-			var response models.LeaderboardResponse
-			if err := json.Unmarshal([]byte(cached), &response); err == nil {
-				return &response, nil
+// incrementScoreScript adds ARGV[1] to member ARGV[2] of a loaded sorted
+// set KEYS[1], keeping its expiry at ARGV[3] unless that is 0. While the set
+// is being reloaded the points are also collected in KEYS[4] so the load
+// keeps them.
+const incrementScoreScript = `
+if redis.call('EXISTS', KEYS[2]) == 1 then
+	redis.call('ZINCRBY', KEYS[1], ARGV[1], ARGV[2])
+	if ARGV[3] ~= '0' then
+		redis.call('EXPIREAT', KEYS[1], ARGV[3])
+	end
+end
+if redis.call('EXISTS', KEYS[3]) == 1 then
+	redis.call('ZINCRBY', KEYS[4], ARGV[1], ARGV[2])
+	redis.call('EXPIRE', KEYS[4], ARGV[4])
+end
+return 1
+`
+
+func (s *LeaderboardService) incrementScore(ctx context.Context, timeframe string, pathwayID *uuid.UUID, member string, points float64, at time.Time) {
+	key, expireAt := leaderboardKey(timeframe, pathwayID, at)
+	var expireUnix int64
+	if !expireAt.IsZero() {
+		expireUnix = expireAt.Unix()
+	}
+	keys := []string{key, key + ":loaded", key + ":loading", key + ":pending"}
+	if _, err := s.redisClient.Eval(ctx, incrementScoreScript, keys, points, member, expireUnix, int(leaderboardLoadTimeout.Seconds())); err != nil {
+		s.logger.Error("Failed to update leaderboard", zap.Error(err), zap.String("key", key))
+	}
+}
+
+// scoreAbove is the ZCOUNT bound of scores strictly greater than score
+func scoreAbove(score float64) string {
+	return "(" + strconv.FormatFloat(score, 'f', -1, 64)
+}
+
+// leaderboardFromRedis reads a leaderboard page, or the window around the
+// user when around is positive, from its sorted set. Ranks are competition
+// ranks like the recalculated ones: tied scores share a rank and the next
+// score skips ahead. It reports false when the set is not loaded or Redis
+// fails, so the caller can fall back to Postgres.
+func (s *LeaderboardService) leaderboardFromRedis(userID uuid.UUID, timeframe string, pathwayID *uuid.UUID, page, perPage, around int) (*models.LeaderboardResponse, bool) {
+	ctx := context.Background()
+	key, _ := leaderboardKey(timeframe, pathwayID, time.Now())
+	if !s.isLoaded(ctx, key) {
+		return nil, false
+	}
+	total, err := s.redisClient.ZCard(ctx, key)
+	if err != nil {
+		s.logger.Error("Failed to count leaderboard", zap.Error(err), zap.String("key", key))
+		return nil, false
+	}
+	position, err := s.redisClient.ZRevRank(ctx, key, userID.String())
+	if redis.IsNil(err) {
+		position = -1
+	} else if err != nil {
+		s.logger.Error("Failed to read leaderboard", zap.Error(err), zap.String("key", key))
+		return nil, false
+	}
+
+	start, stop := int64((page-1)*perPage), int64(page*perPage-1)
+	if around > 0 {
+		start, stop = 0, int64(2*around)
+		if position >= 0 {
+			start, stop = max(position-int64(around), 0), position+int64(around)
+			page = int(position)/perPage + 1
+		}
+	}
+	members, err := s.redisClient.ZRevRangeWithScores(ctx, key, start, stop)
+	if err != nil {
+		s.logger.Error("Failed to read leaderboard", zap.Error(err), zap.String("key", key))
+		return nil, false
+	}
+	top, err := s.redisClient.ZRevRangeWithScores(ctx, key, 0, 0)
+	if err != nil {
+		s.logger.Error("Failed to read leaderboard", zap.Error(err), zap.String("key", key))
+		return nil, false
+	}
+
+	ranks := make([]int, len(members))
+	for i, m := range members {
+		switch {
+		case i == 0:
+			above, err := s.redisClient.ZCount(ctx, key, scoreAbove(m.Score), "+inf")
+			if err != nil {
+				s.logger.Error("Failed to rank leaderboard", zap.Error(err), zap.String("key", key))
+				return nil, false
+			}
+			ranks[i] = int(above) + 1
+		case m.Score == members[i-1].Score:
+			ranks[i] = ranks[i-1]
+		default:
+			ranks[i] = int(start) + i + 1
+		}
+	}
+	standing := &models.LeaderboardRank{Timeframe: timeframe, PathwayID: pathwayID}
+	if position >= 0 && !s.rankFromRedis(standing, userID) {
+		return nil, false
+	}
+
+	userIDs := []uuid.UUID{userID}
+	for _, m := range append(top, members...) {
+		if id, err := uuid.Parse(m.Member); err == nil {
+			userIDs = append(userIDs, id)
+		}
+	}
+	details, err := s.leaderboardRepo.GetUserEntries(timeframe, pathwayID, userIDs)
+	if err != nil {
+		s.logger.Error("Failed to load leaderboard users", zap.Error(err))
+		return nil, false
+	}
+
+	entries := []models.LeaderboardEntry{}
+	for i, m := range members {
+		id, err := uuid.Parse(m.Member)
+		if err != nil {
+			continue
+		}
+		entry, ok := details[id]
+		if !ok {
+			continue
+		}
+		entry.Rank = ranks[i]
+		entry.XP = int(m.Score)
+		entries = append(entries, entry)
+	}
+	stats := models.LeaderboardStats{TotalLearners: int(total)}
+	if len(top) > 0 {
+		stats.TopXP = int(top[0].Score)
+		if id, err := uuid.Parse(top[0].Member); err == nil {
+			stats.TopUsername = details[id].Username
+		}
+	}
+	response := &models.LeaderboardResponse{
+		Leaderboard: entries,
+		Stats:       stats,
+		Pagination: models.Pagination{
+			Total:   int(total),
+			Page:    page,
+			PerPage: perPage,
+		},
+	}
+	if entry, ok := details[userID]; ok && standing.Rank > 0 {
+		entry.Rank = standing.Rank
+		entry.XP = standing.XP
+		response.CurrentUser = &entry
+	}
+	return response, true
+}
+
+// timeframeStart returns when the current week (from Monday) or month began
+// in UTC, or nil for all time
+func timeframeStart(timeframe string, now time.Time) *time.Time {
+	y, m, d := now.UTC().Date()
+	var start time.Time
+	switch timeframe {
+	case "week":
+		start = time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+		start = start.AddDate(0, 0, -((int(start.Weekday()) + 6) % 7))
+	case "month":
+		start = time.Date(y, m, 1, 0, 0, 0, 0, time.UTC)
+	default:
+		return nil
+	}
+	return &start
+}
+
+// leaderboardTrend compares a rank with the rank held a trend window ago
+func leaderboardTrend(rank int, previousRank *int) string {
+	switch {
+	case previousRank == nil || *previousRank == rank:
+		return "same"
+	case rank < *previousRank:
+		return "up"
+	default:
+		return "down"
+	}
+}
+
+// leaderboardFromPostgres reads a leaderboard page, or the window around
+// the user when around is positive, as of the last recalculation
+func (s *LeaderboardService) leaderboardFromPostgres(userID uuid.UUID, timeframe string, pathwayID *uuid.UUID, page, perPage, around int) (*models.LeaderboardResponse, error) {
+	// The caller's own entry, which has no ID if they are not ranked
+	own, err := s.leaderboardRepo.GetUserEntries(timeframe, pathwayID, []uuid.UUID{userID})
+	if err != nil {
+		return nil, err
+	}
+	current, ranked := own[userID]
+	ranked = ranked && current.ID != uuid.Nil
+
+	// Calculate offset
+	offset, limit := (page-1)*perPage, perPage
+	if around > 0 {
+		offset, limit = 0, 2*around+1
+		if ranked {
+			position, err := s.leaderboardRepo.GetPosition(timeframe, pathwayID, userID)
+			if err != nil {
+				return nil, err
 			}
+			offset = max(position-around, 0)
+			limit = position - offset + around + 1
+			page = position/perPage + 1
 		}
 	}
 
 	// Fetch entries
-	entries, err := s.leaderboardRepo.GetLeaderboard(timeframe, pathwayUUID, perPage, offset)
+	entries, err := s.leaderboardRepo.GetLeaderboard(timeframe, pathwayID, limit, offset)
 	if err != nil {
 		return nil, err
 	}
 
 	// Fetch stats
-	stats, err := s.leaderboardRepo.GetLeaderboardStats(timeframe, pathwayUUID)
+	stats, err := s.leaderboardRepo.GetLeaderboardStats(timeframe, pathwayID)
 	if err != nil {
 		return nil, err
 	}
 
 	// Get total count for pagination
-	total, err := s.leaderboardRepo.GetTotalCount(timeframe, pathwayUUID)
+	total, err := s.leaderboardRepo.GetTotalCount(timeframe, pathwayID)
 	if err != nil {
 		return nil, err
 	}
 
-	// TODO: set trend and is_current_user for each entry
-	// For now, we'll leave them empty
-
 	response := &models.LeaderboardResponse{
 		Leaderboard: entries,
 		Stats:       *stats,
@@ -93,14 +456,170 @@ func (s *LeaderboardService) GetLeaderboard(timeframe string, pathwayID *string,
 			PerPage: perPage,
 		},
 	}
+	if ranked {
+		response.CurrentUser = &current
+	}
+	return response, nil
+}
 
-	// Cache the response
+// friendsLeaderboard ranks a user among their friends. The board is small
+// enough to build whole: XP comes from the sorted set when Redis has it
+// loaded and from the last recalculation otherwise, and the page or window
+// is cut from the result. Trends are not tracked among friends.
+func (s *LeaderboardService) friendsLeaderboard(userID uuid.UUID, timeframe string, pathwayID *uuid.UUID, page, perPage, around int) (*models.LeaderboardResponse, error) {
+	userIDs, err := s.followRepo.ListFriendIDs(userID)
+	if err != nil {
+		return nil, err
+	}
+	userIDs = append(userIDs, userID)
+	details, err := s.leaderboardRepo.GetUserEntries(timeframe, pathwayID, userIDs)
+	if err != nil {
+		return nil, err
+	}
 	if s.redisClient != nil {
-		cacheKey := fmt.Sprintf("leaderboard:%s:%v:%d:%d", timeframe, pathwayUUID, page, perPage)
-		ctx := context.Background()
-		data, err := json.Marshal(response)
+		s.liveScores(timeframe, pathwayID, userIDs, details)
+	}
+
+	var ranked []models.LeaderboardEntry
+	for _, entry := range details {
+		if entry.XP > 0 {
+			entry.PreviousRank = nil
+			ranked = append(ranked, entry)
+		}
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].XP != ranked[j].XP {
+			return ranked[i].XP > ranked[j].XP
+		}
+		return ranked[i].UserID.String() < ranked[j].UserID.String()
+	})
+	position := -1
+	for i := range ranked {
+		if i > 0 && ranked[i].XP == ranked[i-1].XP {
+			ranked[i].Rank = ranked[i-1].Rank
+		} else {
+			ranked[i].Rank = i + 1
+		}
+		if ranked[i].UserID == userID {
+			position = i
+		}
+	}
+
+	start, end := (page-1)*perPage, page*perPage
+	if around > 0 {
+		start, end = 0, 2*around+1
+		if position >= 0 {
+			start, end = max(position-around, 0), position+around+1
+			page = position/perPage + 1
+		}
+	}
+	end = min(end, len(ranked))
+	start = min(start, end)
+
+	response := &models.LeaderboardResponse{
+		Leaderboard: append([]models.LeaderboardEntry{}, ranked[start:end]...),
+		Stats:       models.LeaderboardStats{TotalLearners: len(ranked)},
+		Pagination: models.Pagination{
+			Total:   len(ranked),
+			Page:    page,
+			PerPage: perPage,
+		},
+	}
+	if len(ranked) > 0 {
+		response.Stats.TopXP = ranked[0].XP
+		response.Stats.TopUsername = ranked[0].Username
+	}
+	if position >= 0 {
+		current := ranked[position]
+		response.CurrentUser = &current
+	}
+	return response, nil
+}
+
+// liveScores replaces the XP of the given entries with their scores in the
+// leaderboard's sorted set, if it is loaded
+func (s *LeaderboardService) liveScores(timeframe string, pathwayID *uuid.UUID, userIDs []uuid.UUID, entries map[uuid.UUID]models.LeaderboardEntry) {
+	ctx := context.Background()
+	key, _ := leaderboardKey(timeframe, pathwayID, time.Now())
+	if !s.isLoaded(ctx, key) {
+		return
+	}
+	members := make([]string, len(userIDs))
+	for i, id := range userIDs {
+		members[i] = id.String()
+	}
+	scores, err := s.redisClient.ZMScore(ctx, key, members...)
+	if err != nil {
+		s.logger.Error("Failed to read leaderboard", zap.Error(err), zap.String("key", key))
+		return
+	}
+	for i, id := range userIDs {
+		if entry, ok := entries[id]; ok && i < len(scores) {
+			entry.XP = int(scores[i])
+			entries[id] = entry
+		}
+	}
+}
+
+// GetLeaderboard returns a page of a leaderboard for a user. When around is
+// positive it instead returns the user's rank with around entries above
+// and below, or the top of the board if they are not ranked; the page
+// reported is then the one containing the user. Either way the user's own
+// entry is flagged and also returned on its own. The "friends" scope ranks
+// only the user and their friends.
+func (s *LeaderboardService) GetLeaderboard(userID uuid.UUID, scope, timeframe string, pathwayID *string, page, perPage, around int) (*models.LeaderboardResponse, error) {
+	// Validate timeframe
+	if timeframe == "" {
+		timeframe = "all"
+	}
+	if !isValidTimeframe(timeframe) {
+		timeframe = "all"
+	}
+
+	// Convert pathwayID string to UUID pointer
+	var pathwayUUID *uuid.UUID
+	if pathwayID != nil && *pathwayID != "" {
+		parsed, err := uuid.Parse(*pathwayID)
 		if err == nil {
-			_ = s.redisClient.Set(ctx, cacheKey, data, 30*time.Second) // Cache for 30 seconds
+			pathwayUUID = &parsed
+		}
+	}
+
+	if page < 1 {
+		page = 1
+	}
+
+	// Friends are ranked on their own; other leaderboards are served from
+	// the sorted set when Redis has them and from Postgres otherwise
+	var response *models.LeaderboardResponse
+	if scope == "friends" {
+		var err error
+		response, err = s.friendsLeaderboard(userID, timeframe, pathwayUUID, page, perPage, around)
+		if err != nil {
+			return nil, err
+		}
+	} else if s.redisClient != nil {
+		response, _ = s.leaderboardFromRedis(userID, timeframe, pathwayUUID, page, perPage, around)
+	}
+	if response == nil {
+		var err error
+		response, err = s.leaderboardFromPostgres(userID, timeframe, pathwayUUID, page, perPage, around)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for i := range response.Leaderboard {
+		entry := &response.Leaderboard[i]
+		entry.Trend = leaderboardTrend(entry.Rank, entry.PreviousRank)
+		entry.IsCurrentUser = entry.UserID == userID
+	}
+	if current := response.CurrentUser; current != nil {
+		current.Trend = leaderboardTrend(current.Rank, current.PreviousRank)
+		current.IsCurrentUser = true
+		response.Stats.CurrentUserRank = current.Rank
+		if current.PreviousRank != nil {
+			response.Stats.CurrentUserChange = *current.PreviousRank - current.Rank
 		}
 	}
 
@@ -116,7 +635,111 @@ func isValidTimeframe(timeframe string) bool {
 	}
 }
 
-// UpdateLeaderboard triggers a leaderboard recalculation (to be called by cron)
+// UpdateLeaderboard recalculates the global and pathway leaderboards of a
+// timeframe now
 func (s *LeaderboardService) UpdateLeaderboard(timeframe string) error {
-	return s.leaderboardRepo.UpdateLeaderboard(timeframe)
+	if !isValidTimeframe(timeframe) {
+		return fmt.Errorf("invalid timeframe: %s", timeframe)
+	}
+	now := time.Now()
+	if err := s.recompute(timeframe, nil, now); err != nil {
+		return err
+	}
+	pathwayIDs, err := s.leaderboardRepo.ListPathwayIDs()
+	if err != nil {
+		return err
+	}
+	for i := range pathwayIDs {
+		if err := s.recompute(timeframe, &pathwayIDs[i], now); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetRankHistory returns how a user's rank on a leaderboard changed over the
+// last days
+func (s *LeaderboardService) GetRankHistory(userID uuid.UUID, timeframe string, pathwayID *string, days int) ([]models.LeaderboardHistoryPoint, error) {
+	if !isValidTimeframe(timeframe) {
+		timeframe = "all"
+	}
+	pathwayUUID, err := parsePathwayID(pathwayID)
+	if err != nil {
+		return nil, err
+	}
+	return s.leaderboardRepo.GetRankHistory(userID, timeframe, pathwayUUID, time.Now().AddDate(0, 0, -days))
+}
+
+// parsePathwayID parses an optional pathway filter
+func parsePathwayID(pathwayID *string) (*uuid.UUID, error) {
+	if pathwayID == nil || *pathwayID == "" {
+		return nil, nil
+	}
+	parsed, err := uuid.Parse(*pathwayID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pathway ID")
+	}
+	return &parsed, nil
+}
+
+// GetRank returns a user's standing on a leaderboard: live from Redis when
+// the leaderboard is loaded there, otherwise as of the last recalculation
+func (s *LeaderboardService) GetRank(userID uuid.UUID, timeframe string, pathwayID *string) (*models.LeaderboardRank, error) {
+	if !isValidTimeframe(timeframe) {
+		timeframe = "all"
+	}
+	pathwayUUID, err := parsePathwayID(pathwayID)
+	if err != nil {
+		return nil, err
+	}
+	standing := &models.LeaderboardRank{Timeframe: timeframe, PathwayID: pathwayUUID}
+	if s.redisClient != nil && s.rankFromRedis(standing, userID) {
+		return standing, nil
+	}
+
+	entries, err := s.leaderboardRepo.GetUserEntries(timeframe, pathwayUUID, []uuid.UUID{userID})
+	if err != nil {
+		return nil, err
+	}
+	if entry, ok := entries[userID]; ok {
+		standing.Rank = entry.Rank
+		standing.XP = entry.XP
+	}
+	standing.TotalLearners, err = s.leaderboardRepo.GetTotalCount(timeframe, pathwayUUID)
+	if err != nil {
+		return nil, err
+	}
+	return standing, nil
+}
+
+// rankFromRedis fills in a user's standing from the leaderboard's sorted
+// set, reporting false when it is not loaded or Redis fails
+func (s *LeaderboardService) rankFromRedis(standing *models.LeaderboardRank, userID uuid.UUID) bool {
+	ctx := context.Background()
+	key, _ := leaderboardKey(standing.Timeframe, standing.PathwayID, time.Now())
+	if !s.isLoaded(ctx, key) {
+		return false
+	}
+	total, err := s.redisClient.ZCard(ctx, key)
+	if err != nil {
+		s.logger.Error("Failed to count leaderboard", zap.Error(err), zap.String("key", key))
+		return false
+	}
+	standing.TotalLearners = int(total)
+	score, err := s.redisClient.ZScore(ctx, key, userID.String())
+	if redis.IsNil(err) {
+		return true
+	}
+	if err != nil {
+		s.logger.Error("Failed to read leaderboard", zap.Error(err), zap.String("key", key))
+		return false
+	}
+	above, err := s.redisClient.ZCount(ctx, key, scoreAbove(score), "+inf")
+	if err != nil {
+		s.logger.Error("Failed to rank leaderboard", zap.Error(err), zap.String("key", key))
+		return false
+	}
+	standing.Rank = int(above) + 1
+	standing.XP = int(score)
+	return true
 }
\ No newline at end of file
@@ -0,0 +1,230 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yourusername/wizardcore-backend/internal/league"
+	"github.com/yourusername/wizardcore-backend/internal/models"
+	"github.com/yourusername/wizardcore-backend/internal/repositories"
+	"go.uber.org/zap"
+)
+
+// How often the league job looks for seasons to close
+const leagueSweepInterval = 5 * time.Minute
+
+// LeagueService runs weekly league seasons. Learners join a cohort of their
+// tier with their first XP of the season and compete on the XP they earn
+// until it ends; the league job then closes the season, promoting and
+// relegating players and crediting the rewards.
+type LeagueService struct {
+	leagueRepo          *repositories.LeagueRepository
+	notificationService *NotificationService
+	rules               league.Rules
+	logger              *zap.Logger
+}
+
+func NewLeagueService(leagueRepo *repositories.LeagueRepository, notificationService *NotificationService, logger *zap.Logger) *LeagueService {
+	return &LeagueService{
+		leagueRepo:          leagueRepo,
+		notificationService: notificationService,
+		rules:               league.DefaultRules,
+		logger:              logger,
+	}
+}
+
+// Run closes seasons as they end until the process exits
+func (s *LeagueService) Run() {
+	s.sweep(time.Now())
+	ticker := time.NewTicker(leagueSweepInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		s.sweep(time.Now())
+	}
+}
+
+func (s *LeagueService) sweep(now time.Time) {
+	due, err := s.leagueRepo.ListDueSeasons(now)
+	if err != nil {
+		s.logger.Error("Failed to list league seasons to close", zap.Error(err))
+		return
+	}
+	for i := range due {
+		if err := s.closeSeason(&due[i], now); err != nil {
+			s.logger.Error("Failed to close league season", zap.Error(err), zap.String("season_id", due[i].ID.String()))
+		}
+	}
+	if _, err := s.currentSeason(now); err != nil {
+		s.logger.Error("Failed to open league season", zap.Error(err))
+	}
+}
+
+// currentSeason returns the season running now. Seasons share the window
+// of the weekly leaderboard.
+func (s *LeagueService) currentSeason(now time.Time) (*models.LeagueSeason, error) {
+	start := *timeframeStart("week", now)
+	return s.leagueRepo.EnsureSeason(start, start.Add(league.SeasonLength))
+}
+
+// rankStandings ranks a cohort's standings and marks the zone each player
+// is in
+func (s *LeagueService) rankStandings(tier int, standings []models.LeagueStanding) []models.LeagueStanding {
+	ranked := make([]league.Standing, len(standings))
+	byUser := make(map[uuid.UUID]models.LeagueStanding, len(standings))
+	for i, st := range standings {
+		ranked[i] = league.Standing{UserID: st.UserID, XP: st.XP}
+		byUser[st.UserID] = st
+	}
+	league.Rank(ranked)
+	result := make([]models.LeagueStanding, len(ranked))
+	for i, r := range ranked {
+		st := byUser[r.UserID]
+		st.Rank = r.Rank
+		st.Zone = s.rules.Zone(tier, r.Rank, len(ranked), r.XP)
+		result[i] = st
+	}
+	return result
+}
+
+// closeSeason decides every cohort of an ended season and stores the
+// results. Members are told how they finished.
+func (s *LeagueService) closeSeason(season *models.LeagueSeason, now time.Time) error {
+	cohorts, err := s.leagueRepo.ListCohorts(season.ID)
+	if err != nil {
+		return err
+	}
+	var results []league.Result
+	tiers := make(map[uuid.UUID]int)
+	for _, cohort := range cohorts {
+		standings, err := s.leagueRepo.GetCohortStandings(cohort.ID, season.StartsAt, season.EndsAt)
+		if err != nil {
+			return err
+		}
+		players := make([]league.Standing, len(standings))
+		for i, st := range standings {
+			players[i] = league.Standing{UserID: st.UserID, XP: st.XP}
+			tiers[st.UserID] = cohort.Tier
+		}
+		results = append(results, s.rules.Close(cohort.Tier, players)...)
+	}
+
+	closed, err := s.leagueRepo.CloseSeason(season.ID, results, now)
+	if err != nil || !closed {
+		return err
+	}
+	for _, result := range results {
+		s.notifyResult(tiers[result.UserID], result)
+	}
+	return nil
+}
+
+func (s *LeagueService) notifyResult(tier int, result league.Result) {
+	if s.notificationService == nil {
+		return
+	}
+	var title string
+	switch result.Outcome {
+	case league.Promoted:
+		title = fmt.Sprintf("Promoted to the %s league", league.TierName(result.NewTier))
+	case league.Relegated:
+		title = fmt.Sprintf("Moved down to the %s league", league.TierName(result.NewTier))
+	default:
+		title = fmt.Sprintf("You stay in the %s league", league.TierName(tier))
+	}
+	message := fmt.Sprintf("You finished #%d with %d XP this week.", result.Rank, result.XP)
+	if result.RewardXP > 0 {
+		message += fmt.Sprintf(" You earned %d bonus XP.", result.RewardXP)
+	}
+	icon := "🏆"
+	actionURL := "/leagues"
+	err := s.notificationService.CreateNotification(&models.Notification{
+		UserID:    result.UserID,
+		Type:      "league_result",
+		Title:     title,
+		Message:   &message,
+		Icon:      &icon,
+		ActionURL: &actionURL,
+	})
+	if err != nil {
+		s.logger.Error("Failed to send league result notification", zap.Error(err), zap.String("user_id", result.UserID.String()))
+	}
+}
+
+// RecordSubmission places the author of a submission that earned XP in a
+// cohort of the current season. It is registered as a graded submission
+// listener; failures are logged.
+func (s *LeagueService) RecordSubmission(submission *models.Submission) {
+	if submission.PointsEarned <= 0 {
+		return
+	}
+	season, err := s.currentSeason(time.Now())
+	if err != nil {
+		s.logger.Error("Failed to load league season", zap.Error(err), zap.String("user_id", submission.UserID.String()))
+		return
+	}
+	cohort, err := s.leagueRepo.GetMembership(season.ID, submission.UserID)
+	if err != nil || cohort != nil {
+		if err != nil {
+			s.logger.Error("Failed to load league membership", zap.Error(err), zap.String("user_id", submission.UserID.String()))
+		}
+		return
+	}
+	tier, err := s.leagueRepo.CurrentTier(submission.UserID)
+	if err != nil {
+		s.logger.Error("Failed to load league tier", zap.Error(err), zap.String("user_id", submission.UserID.String()))
+		return
+	}
+	if err := s.leagueRepo.Join(season.ID, submission.UserID, tier, league.CohortSize); err != nil {
+		s.logger.Error("Failed to join league", zap.Error(err), zap.String("user_id", submission.UserID.String()))
+	}
+}
+
+// GetCurrent returns the user's league in the current season with their
+// cohort's live standings
+func (s *LeagueService) GetCurrent(userID uuid.UUID) (*models.LeagueStatus, error) {
+	season, err := s.currentSeason(time.Now())
+	if err != nil {
+		return nil, err
+	}
+	status := &models.LeagueStatus{Season: *season, Standings: []models.LeagueStanding{}}
+	cohort, err := s.leagueRepo.GetMembership(season.ID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if cohort == nil {
+		status.Tier, err = s.leagueRepo.CurrentTier(userID)
+		if err != nil {
+			return nil, err
+		}
+		status.TierName = league.TierName(status.Tier)
+		return status, nil
+	}
+
+	status.Joined = true
+	status.CohortID = &cohort.ID
+	status.Tier = cohort.Tier
+	status.TierName = league.TierName(cohort.Tier)
+	standings, err := s.leagueRepo.GetCohortStandings(cohort.ID, season.StartsAt, season.EndsAt)
+	if err != nil {
+		return nil, err
+	}
+	status.Standings = s.rankStandings(cohort.Tier, standings)
+	for i := range status.Standings {
+		status.Standings[i].IsCurrentUser = status.Standings[i].UserID == userID
+	}
+	return status, nil
+}
+
+// GetHistory returns how the user finished past seasons, newest first
+func (s *LeagueService) GetHistory(userID uuid.UUID, limit, offset int) ([]models.LeagueResult, int, error) {
+	results, total, err := s.leagueRepo.ListHistory(userID, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	for i := range results {
+		results[i].TierName = league.TierName(results[i].Tier)
+		results[i].NewTierName = league.TierName(results[i].NewTier)
+	}
+	return results, total, nil
+}
@@ -0,0 +1,55 @@
+package services
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yourusername/wizardcore-backend/internal/mastery"
+	"github.com/yourusername/wizardcore-backend/internal/models"
+	"github.com/yourusername/wizardcore-backend/internal/repositories"
+	"go.uber.org/zap"
+)
+
+// MasteryService traces how well each user has mastered the skills exercises
+// are tagged with, from the outcomes of their graded submissions
+type MasteryService struct {
+	masteryRepo *repositories.MasteryRepository
+	logger      *zap.Logger
+}
+
+func NewMasteryService(masteryRepo *repositories.MasteryRepository, logger *zap.Logger) *MasteryService {
+	return &MasteryService{
+		masteryRepo: masteryRepo,
+		logger:      logger,
+	}
+}
+
+// RecordSubmission updates the user's mastery of the skills of a graded
+// submission's exercise (registered as a graded submission listener)
+func (s *MasteryService) RecordSubmission(submission *models.Submission) {
+	if err := s.masteryRepo.RecordOutcome(submission, time.Now()); err != nil {
+		s.logger.Error("Failed to record skill mastery", zap.Error(err), zap.String("submission_id", submission.ID.String()))
+	}
+}
+
+// GetSkills returns the user's mastery of every skill they have practiced
+func (s *MasteryService) GetSkills(userID uuid.UUID) (*models.SkillsRadar, error) {
+	skills, err := s.masteryRepo.ListSkills(userID)
+	if err != nil {
+		return nil, err
+	}
+	radar := &models.SkillsRadar{Skills: skills}
+	for _, skill := range skills {
+		if skill.Level == mastery.LevelMastered {
+			radar.Mastered++
+		}
+	}
+	return radar, nil
+}
+
+// GetPathwayHeatmap returns a page of the mastery heatmap of a pathway the
+// creator owns and the number of learners enrolled in it, or nil if they
+// own no such pathway
+func (s *MasteryService) GetPathwayHeatmap(pathwayID, creatorID uuid.UUID, limit, offset int) (*models.MasteryHeatmap, int, error) {
+	return s.masteryRepo.GetPathwayHeatmap(pathwayID, creatorID, limit, offset)
+}
@@ -0,0 +1,304 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yourusername/wizardcore-backend/internal/models"
+	"github.com/yourusername/wizardcore-backend/internal/repositories"
+	"github.com/yourusername/wizardcore-backend/internal/websocket"
+	"go.uber.org/zap"
+)
+
+const (
+	// How long an invite can be accepted
+	matchInviteTTL = 15 * time.Minute
+	// How often expired invites are cleaned up
+	matchInviteSweepInterval = time.Minute
+	// Longest time limit an inviter may choose
+	maxPrivateDuelMinutes = 60
+)
+
+// MatchInviteService runs private duels: a user challenges a specific user,
+// or shares an invite code, and the duel starts once the invite is accepted.
+type MatchInviteService struct {
+	inviteRepo          *repositories.MatchInviteRepository
+	matchRepo           *repositories.MatchRepository
+	exerciseRepo        *repositories.ExerciseRepository
+	userRepo            *repositories.UserRepository
+	followRepo          *repositories.FollowRepository
+	practiceService     *PracticeService
+	notificationService *NotificationService
+	hub                 *websocket.Hub
+	logger              *zap.Logger
+}
+
+func NewMatchInviteService(inviteRepo *repositories.MatchInviteRepository, matchRepo *repositories.MatchRepository, exerciseRepo *repositories.ExerciseRepository, userRepo *repositories.UserRepository, followRepo *repositories.FollowRepository, practiceService *PracticeService, notificationService *NotificationService, hub *websocket.Hub, logger *zap.Logger) *MatchInviteService {
+	return &MatchInviteService{
+		inviteRepo:          inviteRepo,
+		matchRepo:           matchRepo,
+		exerciseRepo:        exerciseRepo,
+		userRepo:            userRepo,
+		followRepo:          followRepo,
+		practiceService:     practiceService,
+		notificationService: notificationService,
+		hub:                 hub,
+		logger:              logger,
+	}
+}
+
+// CreateInvite creates a pending private duel and invites the named user to
+// it; only friends can be challenged by name. Without an invitee the invite
+// is open to whoever redeems its code.
+func (s *MatchInviteService) CreateInvite(inviterID uuid.UUID, req models.CreateMatchInviteRequest) (*models.MatchInviteDetails, error) {
+	if req.InviteeID != nil {
+		if *req.InviteeID == inviterID {
+			return nil, fmt.Errorf("cannot challenge yourself")
+		}
+		invitee, err := s.userRepo.FindByID(*req.InviteeID)
+		if err != nil {
+			return nil, err
+		}
+		if invitee == nil {
+			return nil, fmt.Errorf("invitee not found")
+		}
+		status, err := s.followRepo.GetStatus(inviterID, invitee.ID)
+		if err != nil {
+			return nil, err
+		}
+		if !status.IsFriend {
+			return nil, fmt.Errorf("you can only challenge friends")
+		}
+	}
+	if req.Difficulty != nil && !validDifficulties[*req.Difficulty] {
+		return nil, fmt.Errorf("invalid difficulty: %s", *req.Difficulty)
+	}
+	timeLimit := duelTimeLimitMinutes
+	if req.TimeLimitMinutes != nil {
+		if *req.TimeLimitMinutes <= 0 || *req.TimeLimitMinutes > maxPrivateDuelMinutes {
+			return nil, fmt.Errorf("time limit must be between 1 and %d minutes", maxPrivateDuelMinutes)
+		}
+		timeLimit = *req.TimeLimitMinutes
+	}
+
+	var exerciseID uuid.UUID
+	if req.ExerciseID != nil {
+		published, err := s.exerciseRepo.IsPublished(*req.ExerciseID)
+		if err != nil {
+			return nil, err
+		}
+		if !published {
+			return nil, fmt.Errorf("exercise not found")
+		}
+		exerciseID = *req.ExerciseID
+	} else {
+		id, err := s.exerciseRepo.FindRandomExerciseID(req.LanguageID, req.Difficulty)
+		if err != nil {
+			return nil, err
+		}
+		if id == nil {
+			return nil, fmt.Errorf("no exercise matches the requested language and difficulty")
+		}
+		exerciseID = *id
+	}
+
+	code, err := generateInviteCode()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	match := &models.PracticeMatch{
+		ID:               uuid.New(),
+		MatchType:        "duel",
+		Status:           "pending",
+		ExerciseID:       exerciseID,
+		TimeLimitMinutes: &timeLimit,
+		CreatedAt:        &now,
+	}
+	invite := &models.MatchInvite{
+		ID:         uuid.New(),
+		MatchID:    match.ID,
+		InviterID:  inviterID,
+		InviteeID:  req.InviteeID,
+		InviteCode: code,
+		Status:     "pending",
+		ExpiresAt:  now.Add(matchInviteTTL),
+	}
+	if err := s.inviteRepo.Create(invite, match); err != nil {
+		return nil, err
+	}
+
+	if invite.InviteeID != nil {
+		s.notifyInvitee(invite)
+	}
+	return &models.MatchInviteDetails{Invite: invite, Match: match}, nil
+}
+
+// GetInvite returns an invite to its inviter or invitee
+func (s *MatchInviteService) GetInvite(inviteID, userID uuid.UUID) (*models.MatchInviteDetails, error) {
+	invite, err := s.inviteRepo.FindByID(inviteID)
+	if err != nil {
+		return nil, err
+	}
+	if invite == nil || (invite.InviterID != userID && (invite.InviteeID == nil || *invite.InviteeID != userID)) {
+		return nil, fmt.Errorf("invite not found")
+	}
+	match, err := s.matchRepo.GetMatchByID(invite.MatchID)
+	if err != nil {
+		return nil, err
+	}
+	return &models.MatchInviteDetails{Invite: invite, Match: match}, nil
+}
+
+// ListInvites returns the user's pending invites, sent and received
+func (s *MatchInviteService) ListInvites(userID uuid.UUID) ([]models.MatchInvite, error) {
+	return s.inviteRepo.ListPendingForUser(userID)
+}
+
+// AcceptInvite accepts an invite addressed to the user and starts the duel
+func (s *MatchInviteService) AcceptInvite(inviteID, userID uuid.UUID) (*models.MatchInviteDetails, error) {
+	invite, err := s.inviteRepo.FindByID(inviteID)
+	if err != nil {
+		return nil, err
+	}
+	if invite == nil || invite.InviteeID == nil || *invite.InviteeID != userID {
+		return nil, fmt.Errorf("invite not found")
+	}
+	return s.accept(invite, userID)
+}
+
+// AcceptByCode redeems an invite code and starts the duel
+func (s *MatchInviteService) AcceptByCode(code string, userID uuid.UUID) (*models.MatchInviteDetails, error) {
+	invite, err := s.inviteRepo.FindByCode(strings.ToUpper(strings.TrimSpace(code)))
+	if err != nil {
+		return nil, err
+	}
+	if invite == nil {
+		return nil, fmt.Errorf("invite not found")
+	}
+	if invite.InviterID == userID {
+		return nil, fmt.Errorf("cannot accept your own invite")
+	}
+	if invite.InviteeID != nil && *invite.InviteeID != userID {
+		return nil, fmt.Errorf("invite is addressed to another user")
+	}
+	return s.accept(invite, userID)
+}
+
+func (s *MatchInviteService) accept(invite *models.MatchInvite, userID uuid.UUID) (*models.MatchInviteDetails, error) {
+	accepted, err := s.inviteRepo.Accept(invite.ID, userID)
+	if errors.Is(err, repositories.ErrInviteUnavailable) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to accept invite: %w", err)
+	}
+	match, err := s.matchRepo.GetMatchByID(accepted.MatchID)
+	if err != nil {
+		return nil, err
+	}
+	if match == nil {
+		return nil, fmt.Errorf("match not found")
+	}
+
+	s.notifyInviter(accepted)
+	s.practiceService.notifyMatchStart(match)
+	return &models.MatchInviteDetails{Invite: accepted, Match: match}, nil
+}
+
+// DeclineInvite declines an invite addressed to the user
+func (s *MatchInviteService) DeclineInvite(inviteID, userID uuid.UUID) error {
+	invite, err := s.inviteRepo.FindByID(inviteID)
+	if err != nil {
+		return err
+	}
+	if invite == nil || invite.InviteeID == nil || *invite.InviteeID != userID {
+		return fmt.Errorf("invite not found")
+	}
+	if err := s.inviteRepo.Close(invite.ID, "declined"); err != nil {
+		return err
+	}
+	invite.Status = "declined"
+	s.notifyInviter(invite)
+	return nil
+}
+
+// CancelInvite withdraws an invite the user sent
+func (s *MatchInviteService) CancelInvite(inviteID, userID uuid.UUID) error {
+	invite, err := s.inviteRepo.FindByID(inviteID)
+	if err != nil {
+		return err
+	}
+	if invite == nil || invite.InviterID != userID {
+		return fmt.Errorf("invite not found")
+	}
+	return s.inviteRepo.Close(invite.ID, "cancelled")
+}
+
+// Run expires unanswered invites until the process exits
+func (s *MatchInviteService) Run() {
+	ticker := time.NewTicker(matchInviteSweepInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		s.expire()
+	}
+}
+
+// expire cancels the matches of invites nobody answered in time and lets
+// the inviters know
+func (s *MatchInviteService) expire() {
+	invites, err := s.inviteRepo.ExpirePending(time.Now())
+	if err != nil {
+		s.logger.Error("Failed to expire match invites", zap.Error(err))
+		return
+	}
+	for i := range invites {
+		s.notifyInviter(&invites[i])
+	}
+}
+
+// notifyInvitee sends the invitee a match_invite notification
+func (s *MatchInviteService) notifyInvitee(invite *models.MatchInvite) {
+	if s.notificationService == nil {
+		return
+	}
+	name := "Someone"
+	inviter, err := s.userRepo.FindByID(invite.InviterID)
+	if err != nil {
+		s.logger.Error("Failed to load inviter", zap.Error(err), zap.String("invite_id", invite.ID.String()))
+	} else if inviter != nil && inviter.DisplayName != nil && *inviter.DisplayName != "" {
+		name = *inviter.DisplayName
+	}
+	message := fmt.Sprintf("%s challenged you to a duel. The invite expires in %d minutes.", name, int(matchInviteTTL.Minutes()))
+	icon := "⚔️"
+	actionURL := "/practice/invites/" + invite.ID.String()
+	err = s.notificationService.CreateNotification(&models.Notification{
+		UserID:    *invite.InviteeID,
+		Type:      "match_invite",
+		Title:     "Duel challenge",
+		Message:   &message,
+		Icon:      &icon,
+		ActionURL: &actionURL,
+	})
+	if err != nil {
+		s.logger.Error("Failed to send match invite notification", zap.Error(err), zap.String("invite_id", invite.ID.String()))
+	}
+}
+
+// notifyInviter tells the inviter what became of their invite
+func (s *MatchInviteService) notifyInviter(invite *models.MatchInvite) {
+	if s.hub == nil {
+		return
+	}
+	err := s.hub.SendToUser(invite.InviterID, websocket.MatchInviteUpdate, websocket.MatchInviteUpdatePayload{
+		InviteID: invite.ID.String(),
+		MatchID:  invite.MatchID.String(),
+		Status:   invite.Status,
+	})
+	if err != nil {
+		s.logger.Error("Failed to push match invite update", zap.Error(err), zap.String("invite_id", invite.ID.String()))
+	}
+}
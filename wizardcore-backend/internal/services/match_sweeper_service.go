@@ -0,0 +1,64 @@
+package services
+
+import (
+	"time"
+
+	"github.com/yourusername/wizardcore-backend/internal/repositories"
+	"go.uber.org/zap"
+)
+
+const (
+	// How often stale and overdue matches are looked for
+	matchSweepInterval = 30 * time.Second
+	// Pending matches nobody joined within this time are cancelled
+	pendingMatchTimeout = 30 * time.Minute
+	// Extra time after the time limit for submissions still being graded
+	matchExpiryGrace = time.Minute
+)
+
+// MatchSweeperService ends matches that would otherwise stay open forever:
+// pending matches nobody joined and active matches past their time limit
+type MatchSweeperService struct {
+	matchRepo       *repositories.MatchRepository
+	practiceService *PracticeService
+	logger          *zap.Logger
+}
+
+func NewMatchSweeperService(matchRepo *repositories.MatchRepository, practiceService *PracticeService, logger *zap.Logger) *MatchSweeperService {
+	return &MatchSweeperService{
+		matchRepo:       matchRepo,
+		practiceService: practiceService,
+		logger:          logger,
+	}
+}
+
+// Run sweeps matches until the process exits
+func (s *MatchSweeperService) Run() {
+	ticker := time.NewTicker(matchSweepInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		s.sweep(time.Now())
+	}
+}
+
+func (s *MatchSweeperService) sweep(now time.Time) {
+	stale, err := s.matchRepo.ListStalePendingMatches(now.Add(-pendingMatchTimeout))
+	if err != nil {
+		s.logger.Error("Failed to list stale matches", zap.Error(err))
+	}
+	for i := range stale {
+		if err := s.practiceService.CancelStaleMatch(&stale[i], now); err != nil {
+			s.logger.Error("Failed to cancel stale match", zap.Error(err), zap.String("match_id", stale[i].ID.String()))
+		}
+	}
+
+	overdue, err := s.matchRepo.ListOverdueMatches(now.Add(-matchExpiryGrace))
+	if err != nil {
+		s.logger.Error("Failed to list overdue matches", zap.Error(err))
+	}
+	for i := range overdue {
+		if err := s.practiceService.ExpireMatch(&overdue[i], now); err != nil {
+			s.logger.Error("Failed to expire match", zap.Error(err), zap.String("match_id", overdue[i].ID.String()))
+		}
+	}
+}
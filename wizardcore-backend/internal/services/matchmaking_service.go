@@ -0,0 +1,231 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yourusername/wizardcore-backend/internal/models"
+	"github.com/yourusername/wizardcore-backend/internal/repositories"
+	"github.com/yourusername/wizardcore-backend/internal/websocket"
+	"go.uber.org/zap"
+)
+
+const (
+	// How often the queue is swept for new pairings
+	matchmakingInterval = 3 * time.Second
+	// Entries waiting longer than this are dropped from the queue
+	matchmakingMaxWait = 10 * time.Minute
+	// Wait assumed when there is no recent history to estimate from
+	defaultEstimatedWait = 30 * time.Second
+	// Window of matches the wait estimate is based on
+	waitEstimateWindow = time.Hour
+	// Entries examined per sweep
+	matchmakingBatchSize = 200
+	// Duel length
+	duelTimeLimitMinutes = 10
+)
+
+var validDifficulties = map[string]bool{
+	"BEGINNER":     true,
+	"INTERMEDIATE": true,
+	"ADVANCED":     true,
+}
+
+// defaultRatingBand starts at ±100 rating points and widens by 50 every 10
+// seconds, so after a minute and a half anyone can be matched.
+var defaultRatingBand = models.RatingBand{Base: 100, Step: 50, StepSeconds: 10, Max: 600}
+
+// MatchmakingService pairs users waiting for a duel. Users are matched on
+// language and difficulty preferences and on rating, with the allowed rating
+// gap widening the longer they wait.
+type MatchmakingService struct {
+	matchmakingRepo *repositories.MatchmakingRepository
+	matchRepo       *repositories.MatchRepository
+	practiceService *PracticeService
+	hub             *websocket.Hub
+	band            models.RatingBand
+	logger          *zap.Logger
+}
+
+func NewMatchmakingService(matchmakingRepo *repositories.MatchmakingRepository, matchRepo *repositories.MatchRepository, practiceService *PracticeService, hub *websocket.Hub, logger *zap.Logger) *MatchmakingService {
+	return &MatchmakingService{
+		matchmakingRepo: matchmakingRepo,
+		matchRepo:       matchRepo,
+		practiceService: practiceService,
+		hub:             hub,
+		band:            defaultRatingBand,
+		logger:          logger,
+	}
+}
+
+// JoinQueue puts the user in the queue and tries to pair them straight away.
+// If the user is already waiting their current entry is returned.
+func (s *MatchmakingService) JoinQueue(userID uuid.UUID, req models.JoinQueueRequest) (*models.QueueStatus, error) {
+	if req.Difficulty != nil && !validDifficulties[*req.Difficulty] {
+		return nil, fmt.Errorf("invalid difficulty: %s", *req.Difficulty)
+	}
+
+	entry, err := s.matchmakingRepo.FindWaitingByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	if entry == nil {
+		stats, err := s.matchRepo.GetUserPracticeStats(userID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load rating: %w", err)
+		}
+		entry = &models.QueueEntry{
+			UserID:     userID,
+			LanguageID: req.LanguageID,
+			Difficulty: req.Difficulty,
+			Rating:     stats.Rating,
+		}
+		if err := s.matchmakingRepo.Enqueue(entry); err != nil {
+			return nil, err
+		}
+	}
+
+	match, _, err := s.tryPair(entry.ID)
+	if err != nil {
+		return nil, err
+	}
+	if match != nil {
+		entry.Status = "matched"
+		entry.MatchID = &match.ID
+		return &models.QueueStatus{Entry: entry, Match: match}, nil
+	}
+	return s.status(entry), nil
+}
+
+// LeaveQueue takes the user out of the queue
+func (s *MatchmakingService) LeaveQueue(userID uuid.UUID) error {
+	left, err := s.matchmakingRepo.Cancel(userID)
+	if err != nil {
+		return err
+	}
+	if !left {
+		return fmt.Errorf("not in the matchmaking queue")
+	}
+	return nil
+}
+
+// GetStatus returns the user's latest queue entry and, once matched, the duel
+func (s *MatchmakingService) GetStatus(userID uuid.UUID) (*models.QueueStatus, error) {
+	entry, err := s.matchmakingRepo.FindLatestByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	if entry == nil {
+		return &models.QueueStatus{}, nil
+	}
+	if entry.Status == "matched" && entry.MatchID != nil {
+		match, err := s.matchRepo.GetMatchByID(*entry.MatchID)
+		if err != nil {
+			return nil, err
+		}
+		return &models.QueueStatus{Entry: entry, Match: match}, nil
+	}
+	if entry.Status != "waiting" {
+		return &models.QueueStatus{Entry: entry}, nil
+	}
+	return s.status(entry), nil
+}
+
+// Run sweeps the queue until the process exits
+func (s *MatchmakingService) Run() {
+	ticker := time.NewTicker(matchmakingInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		s.sweep()
+	}
+}
+
+// sweep expires stale entries, retries pairing for everyone still waiting
+// with their widened bands, and pushes wait estimates to those left over
+func (s *MatchmakingService) sweep() {
+	expired, err := s.matchmakingRepo.ExpireWaitingBefore(time.Now().Add(-matchmakingMaxWait))
+	if err != nil {
+		s.logger.Error("Failed to expire matchmaking entries", zap.Error(err))
+	}
+	for _, userID := range expired {
+		if err := s.hub.SendToUser(userID, websocket.MatchmakingStatus, websocket.MatchmakingStatusPayload{Status: "expired"}); err != nil {
+			s.logger.Error("Failed to notify matchmaking expiry", zap.Error(err), zap.String("user_id", userID.String()))
+		}
+	}
+
+	entries, err := s.matchmakingRepo.ListWaiting(matchmakingBatchSize)
+	if err != nil {
+		s.logger.Error("Failed to list matchmaking queue", zap.Error(err))
+		return
+	}
+	paired := make(map[uuid.UUID]bool)
+	for i := range entries {
+		entry := &entries[i]
+		if paired[entry.UserID] {
+			continue
+		}
+		match, userIDs, err := s.tryPair(entry.ID)
+		if err != nil {
+			s.logger.Error("Failed to pair matchmaking entry", zap.Error(err), zap.String("entry_id", entry.ID.String()))
+			continue
+		}
+		if match != nil {
+			for _, userID := range userIDs {
+				paired[userID] = true
+			}
+			continue
+		}
+
+		status := s.status(entry)
+		if err := s.hub.SendLive(entry.UserID, websocket.MatchmakingStatus, websocket.MatchmakingStatusPayload{
+			Status:               "waiting",
+			WaitSeconds:          status.WaitSeconds,
+			EstimatedWaitSeconds: status.EstimatedWaitSeconds,
+			RatingBand:           status.RatingBand,
+		}); err != nil {
+			s.logger.Error("Failed to push matchmaking status", zap.Error(err), zap.String("user_id", entry.UserID.String()))
+		}
+	}
+}
+
+// tryPair pairs the entry if an opponent is available and announces the duel
+func (s *MatchmakingService) tryPair(entryID uuid.UUID) (*models.PracticeMatch, []uuid.UUID, error) {
+	match, userIDs, err := s.matchmakingRepo.PairEntry(entryID, s.band, s.practiceService.timeLimit("duel", duelTimeLimitMinutes))
+	if err != nil {
+		return nil, nil, err
+	}
+	if match != nil {
+		s.practiceService.notifyMatchStart(match)
+	}
+	return match, userIDs, nil
+}
+
+// status describes how long the entry has waited and is likely to wait
+func (s *MatchmakingService) status(entry *models.QueueEntry) *models.QueueStatus {
+	waited := time.Since(entry.EnqueuedAt)
+	return &models.QueueStatus{
+		Entry:                entry,
+		WaitSeconds:          int(waited.Seconds()),
+		EstimatedWaitSeconds: int(s.estimateRemaining(entry, waited).Seconds()),
+		RatingBand:           s.band.Width(waited),
+	}
+}
+
+// estimateRemaining estimates the remaining wait from how long recent
+// entries with the same preferences waited
+func (s *MatchmakingService) estimateRemaining(entry *models.QueueEntry, waited time.Duration) time.Duration {
+	expected := defaultEstimatedWait
+	avg, count, err := s.matchmakingRepo.AverageWaitSeconds(entry.LanguageID, entry.Difficulty, time.Now().Add(-waitEstimateWindow))
+	if err != nil {
+		s.logger.Error("Failed to estimate matchmaking wait", zap.Error(err))
+	} else if count > 0 {
+		expected = time.Duration(avg * float64(time.Second))
+	}
+
+	remaining := expected - waited
+	if remaining < 5*time.Second {
+		remaining = 5 * time.Second
+	}
+	return remaining
+}
@@ -6,14 +6,22 @@ import (
 	"github.com/google/uuid"
 	"github.com/yourusername/wizardcore-backend/internal/models"
 	"github.com/yourusername/wizardcore-backend/internal/repositories"
+	"github.com/yourusername/wizardcore-backend/internal/websocket"
+	"go.uber.org/zap"
 )
 
 type NotificationService struct {
 	notificationRepo *repositories.NotificationRepository
+	hub              *websocket.Hub
+	logger           *zap.Logger
 }
 
-func NewNotificationService(notificationRepo *repositories.NotificationRepository) *NotificationService {
-	return &NotificationService{notificationRepo: notificationRepo}
+func NewNotificationService(notificationRepo *repositories.NotificationRepository, hub *websocket.Hub, logger *zap.Logger) *NotificationService {
+	return &NotificationService{
+		notificationRepo: notificationRepo,
+		hub:              hub,
+		logger:           logger,
+	}
 }
 
 func (s *NotificationService) CreateNotification(notification *models.Notification) error {
@@ -26,7 +34,16 @@ func (s *NotificationService) CreateNotification(notification *models.Notificati
 	if notification.Title == "" {
 		return fmt.Errorf("title is required")
 	}
-	return s.notificationRepo.Create(notification)
+	if err := s.notificationRepo.Create(notification); err != nil {
+		return err
+	}
+	// Push to connected clients; offline users get it on resume
+	if s.hub != nil {
+		if err := s.hub.SendToUser(notification.UserID, websocket.NotificationEvent, notification); err != nil {
+			s.logger.Error("Failed to push notification", zap.Error(err), zap.String("user_id", notification.UserID.String()))
+		}
+	}
+	return nil
 }
 
 func (s *NotificationService) GetUserNotifications(userID uuid.UUID, limit, offset int) (*models.NotificationResponse, error) {
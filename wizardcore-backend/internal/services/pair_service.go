@@ -0,0 +1,459 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/yourusername/wizardcore-backend/internal/collab"
+	"github.com/yourusername/wizardcore-backend/internal/models"
+	"github.com/yourusername/wizardcore-backend/internal/repositories"
+	"github.com/yourusername/wizardcore-backend/internal/websocket"
+	"go.uber.org/zap"
+)
+
+const (
+	// Longest gap between a client's base revision and the server revision
+	// that is rebased; clients further behind must reload the session.
+	maxPairRebase = 500
+
+	// Attempts to store operations when another node wrote concurrently.
+	maxPairWriteAttempts = 3
+
+	inviteCodeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+	inviteCodeLength   = 8
+)
+
+// pairRequestError is an error in what a client asked of a pair session,
+// such as an operation that does not fit the buffer. Its text is sent back
+// to the client; other errors are logged and reported generically.
+type pairRequestError string
+
+func (e pairRequestError) Error() string {
+	return string(e)
+}
+
+func pairRequestErrorf(format string, args ...interface{}) error {
+	return pairRequestError(fmt.Sprintf(format, args...))
+}
+
+// PairService runs pair-programming sessions in which two learners edit one
+// buffer. Edits are operational transforms relayed through the hub: the
+// server orders them, rebases stale ones, persists them and broadcasts the
+// result so both editors converge on the same code.
+//
+// A client keeps at most one batch of operations in flight and sends the
+// next only after its previous batch is echoed back with a revision.
+type PairService struct {
+	pairRepo          *repositories.PairSessionRepository
+	exerciseRepo      *repositories.ExerciseRepository
+	submissionService *SubmissionService
+	hub               *websocket.Hub
+
+	locksMu sync.Mutex
+	locks   map[uuid.UUID]*sessionLock
+	logger  *zap.Logger
+}
+
+// sessionLock serializes the edits of a session within this process. It is
+// kept while anyone holds or waits for it.
+type sessionLock struct {
+	sync.Mutex
+	refs int
+}
+
+func NewPairService(pairRepo *repositories.PairSessionRepository, exerciseRepo *repositories.ExerciseRepository, submissionService *SubmissionService, hub *websocket.Hub, logger *zap.Logger) *PairService {
+	return &PairService{
+		pairRepo:          pairRepo,
+		exerciseRepo:      exerciseRepo,
+		submissionService: submissionService,
+		hub:               hub,
+		locks:             make(map[uuid.UUID]*sessionLock),
+		logger:            logger,
+	}
+}
+
+// RegisterWebSocketHandlers routes pair messages from the hub to the service
+func (s *PairService) RegisterWebSocketHandlers() {
+	s.hub.Handle(websocket.PairOp, s.handleOp)
+	s.hub.Handle(websocket.PairCursor, s.handleCursor)
+}
+
+// lockSession locks a session and returns the function that unlocks it
+func (s *PairService) lockSession(sessionID uuid.UUID) func() {
+	s.locksMu.Lock()
+	lock, ok := s.locks[sessionID]
+	if !ok {
+		lock = &sessionLock{}
+		s.locks[sessionID] = lock
+	}
+	lock.refs++
+	s.locksMu.Unlock()
+
+	lock.Lock()
+	return func() {
+		lock.Unlock()
+		s.locksMu.Lock()
+		defer s.locksMu.Unlock()
+		lock.refs--
+		if lock.refs == 0 {
+			delete(s.locks, sessionID)
+		}
+	}
+}
+
+// CreateSession opens a session for the host and returns it with an invite code
+func (s *PairService) CreateSession(hostUserID uuid.UUID, req models.CreatePairSessionRequest) (*models.PairSession, error) {
+	exercise, err := s.exerciseRepo.FindByID(req.ExerciseID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch exercise: %w", err)
+	}
+	if exercise == nil {
+		return nil, fmt.Errorf("exercise not found")
+	}
+
+	content := req.Content
+	if content == "" && exercise.StarterCode != nil {
+		content = *exercise.StarterCode
+	}
+
+	code, err := generateInviteCode()
+	if err != nil {
+		return nil, err
+	}
+	session := &models.PairSession{
+		ID:         uuid.New(),
+		ExerciseID: req.ExerciseID,
+		LanguageID: req.LanguageID,
+		HostUserID: hostUserID,
+		InviteCode: code,
+		Status:     "pending",
+		Content:    content,
+	}
+	if err := s.pairRepo.Create(session); err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+// JoinSession claims the partner seat of the session with the invite code
+func (s *PairService) JoinSession(userID uuid.UUID, inviteCode string) (*models.PairSession, error) {
+	session, err := s.pairRepo.FindByInviteCode(inviteCode)
+	if err != nil {
+		return nil, err
+	}
+	if session == nil {
+		return nil, fmt.Errorf("pair session not found")
+	}
+	if session.IsMember(userID) {
+		return session, nil
+	}
+
+	joined, err := s.pairRepo.SetPartner(session.ID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !joined {
+		return nil, fmt.Errorf("pair session is full or closed")
+	}
+	session.PartnerUserID = &userID
+	session.Status = "active"
+
+	if err := s.hub.SendToUser(session.HostUserID, websocket.PairJoined, websocket.PairJoinedPayload{
+		SessionID: session.ID.String(),
+		UserID:    userID.String(),
+	}); err != nil {
+		s.logger.Error("Failed to notify pair host", zap.Error(err), zap.String("session_id", session.ID.String()))
+	}
+	return session, nil
+}
+
+// GetSession returns a session the user takes part in
+func (s *PairService) GetSession(sessionID, userID uuid.UUID) (*models.PairSession, error) {
+	session, err := s.pairRepo.FindByID(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if session == nil || !session.IsMember(userID) {
+		return nil, pairRequestErrorf("pair session not found")
+	}
+	return session, nil
+}
+
+// GetOperations returns the operations applied after revision since, for
+// clients catching up without reloading the whole buffer
+func (s *PairService) GetOperations(sessionID, userID uuid.UUID, since, limit int) ([]models.PairOperation, error) {
+	if _, err := s.GetSession(sessionID, userID); err != nil {
+		return nil, err
+	}
+	return s.pairRepo.FindOperationsSince(sessionID, since, limit)
+}
+
+// ApplyOperations rebases a batch of operations the user made against
+// baseRevision onto the current buffer, stores them and returns the
+// operations as applied with the revision they produced. cursor, if set, is
+// the user's cursor after the batch and is moved the same way.
+func (s *PairService) ApplyOperations(sessionID, userID uuid.UUID, baseRevision int, ops []collab.Operation, cursor *int) ([]collab.Operation, int, *int, error) {
+	if len(ops) == 0 {
+		return nil, 0, nil, pairRequestErrorf("no operations")
+	}
+	for _, op := range ops {
+		if err := op.Validate(); err != nil {
+			return nil, 0, nil, pairRequestErrorf("invalid operation: %v", err)
+		}
+	}
+
+	defer s.lockSession(sessionID)()
+
+	for attempt := 0; attempt < maxPairWriteAttempts; attempt++ {
+		session, err := s.GetSession(sessionID, userID)
+		if err != nil {
+			return nil, 0, nil, err
+		}
+		if session.Status != "active" {
+			return nil, 0, nil, pairRequestErrorf("pair session is not active")
+		}
+		if baseRevision < 0 || baseRevision > session.Revision {
+			return nil, 0, nil, pairRequestErrorf("unknown base revision %d", baseRevision)
+		}
+		if session.Revision-baseRevision > maxPairRebase {
+			return nil, 0, nil, pairRequestErrorf("base revision too old, reload the session")
+		}
+
+		applied, newCursor := ops, cursor
+		if baseRevision < session.Revision {
+			concurrent, err := s.pairRepo.FindOperationsSince(sessionID, baseRevision, maxPairRebase)
+			if err != nil {
+				return nil, 0, nil, err
+			}
+			applied, newCursor = rebase(ops, concurrent, userID, cursor)
+		}
+
+		content := session.Content
+		for _, op := range applied {
+			if content, err = collab.Apply(content, op); err != nil {
+				return nil, 0, nil, pairRequestErrorf("operation does not fit the buffer: %v", err)
+			}
+		}
+
+		records := make([]models.PairOperation, len(applied))
+		for i, op := range applied {
+			records[i] = models.PairOperation{
+				UserID:   userID,
+				Type:     string(op.Type),
+				Position: op.Position,
+				Text:     op.Text,
+				Length:   op.Length,
+			}
+		}
+		if len(records) > 0 {
+			records[len(records)-1].CursorPosition = newCursor
+		}
+
+		err = s.pairRepo.AppendOperations(sessionID, session.Revision, content, records)
+		if errors.Is(err, repositories.ErrRevisionConflict) {
+			continue
+		}
+		if err != nil {
+			return nil, 0, nil, err
+		}
+		return applied, session.Revision + len(applied), newCursor, nil
+	}
+	return nil, 0, nil, pairRequestErrorf("pair session is busy, retry")
+}
+
+// rebase transforms ops made by userID against the operations stored since
+// their base revision. Ties between concurrent inserts go to the user with
+// the smaller ID so every node orders them the same way.
+func rebase(ops []collab.Operation, concurrent []models.PairOperation, userID uuid.UUID, cursor *int) ([]collab.Operation, *int) {
+	applied := ops
+	for _, c := range concurrent {
+		other := []collab.Operation{{
+			Type:     collab.OpType(c.Type),
+			Position: c.Position,
+			Text:     c.Text,
+			Length:   c.Length,
+		}}
+		var otherPrime []collab.Operation
+		applied, otherPrime = collab.TransformSeq(applied, other, userID.String() < c.UserID.String())
+		if cursor != nil {
+			pos := *cursor
+			for _, op := range otherPrime {
+				pos = collab.TransformCursor(pos, op)
+			}
+			cursor = &pos
+		}
+	}
+	return applied, cursor
+}
+
+// SubmitSession grades the merged buffer once and credits both partners.
+// The session is claimed while it is graded, which freezes the buffer and
+// turns other submits away, and reopened if the attempt is not accepted.
+func (s *PairService) SubmitSession(sessionID, userID uuid.UUID) ([]models.Submission, error) {
+	if _, err := s.GetSession(sessionID, userID); err != nil {
+		return nil, err
+	}
+	claimed, err := s.pairRepo.ClaimForSubmission(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if !claimed {
+		return nil, fmt.Errorf("pair session is not active")
+	}
+	released := false
+	release := func(status string) {
+		released = true
+		if err := s.pairRepo.UpdateStatus(sessionID, status); err != nil {
+			s.logger.Error("Failed to release pair session", zap.Error(err), zap.String("session_id", sessionID.String()))
+		}
+	}
+	defer func() {
+		if !released {
+			release("active")
+		}
+	}()
+
+	session, err := s.GetSession(sessionID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	submission := &models.Submission{
+		ID:             uuid.New(),
+		UserID:         userID,
+		ExerciseID:     session.ExerciseID,
+		SourceCode:     session.Content,
+		LanguageID:     session.LanguageID,
+		Status:         "pending",
+		SubmissionType: "pair",
+	}
+	var partners []uuid.UUID
+	for _, member := range session.Members() {
+		if member != userID {
+			partners = append(partners, member)
+		}
+	}
+
+	submissions, err := s.submissionService.CreateJointSubmission(submission, partners)
+	if err != nil {
+		return nil, fmt.Errorf("failed to submit pair session: %w", err)
+	}
+	submissionIDs := make([]string, len(submissions))
+	for i, sub := range submissions {
+		submissionIDs[i] = sub.ID.String()
+		if err := s.pairRepo.RecordSubmission(session.ID, sub.UserID, sub.ID); err != nil {
+			s.logger.Error("Failed to link pair submission", zap.Error(err), zap.String("session_id", session.ID.String()), zap.String("submission_id", sub.ID.String()))
+		}
+	}
+
+	if submission.IsCorrect {
+		release("submitted")
+	} else {
+		release("active")
+	}
+
+	if err := s.hub.SendToUsers(session.Members(), websocket.PairSubmitted, websocket.PairSubmittedPayload{
+		SessionID:     session.ID.String(),
+		SubmissionIDs: submissionIDs,
+		Status:        submission.Status,
+		PointsEarned:  submission.PointsEarned,
+	}); err != nil {
+		s.logger.Error("Failed to notify pair submission", zap.Error(err), zap.String("session_id", session.ID.String()))
+	}
+	return submissions, nil
+}
+
+// handleOp applies a pair_op frame and broadcasts the result to both partners
+func (s *PairService) handleOp(client *websocket.Client, msg websocket.Message) {
+	var payload websocket.PairOpPayload
+	if err := json.Unmarshal(msg.Payload, &payload); err != nil {
+		client.SendError("invalid pair_op payload")
+		return
+	}
+	sessionID, err := uuid.Parse(payload.SessionID)
+	if err != nil {
+		client.SendError("invalid session ID")
+		return
+	}
+
+	applied, revision, cursor, err := s.ApplyOperations(sessionID, client.UserID(), payload.BaseRevision, payload.Operations, payload.Cursor)
+	if err != nil {
+		s.sendRequestError(client, err, "failed to apply operations", sessionID)
+		return
+	}
+
+	session, err := s.pairRepo.FindByID(sessionID)
+	if err != nil || session == nil {
+		return
+	}
+	if err := s.hub.SendToUsers(session.Members(), websocket.PairOp, websocket.PairOpPayload{
+		SessionID:    payload.SessionID,
+		UserID:       client.UserID().String(),
+		ClientOpID:   payload.ClientOpID,
+		BaseRevision: revision - len(applied),
+		Revision:     revision,
+		Operations:   applied,
+		Cursor:       cursor,
+	}); err != nil {
+		s.logger.Error("Failed to broadcast pair operation", zap.Error(err), zap.String("session_id", session.ID.String()))
+	}
+}
+
+// sendRequestError tells a client why a pair frame failed. Only request
+// errors are described; anything else is logged and reported as fallback.
+func (s *PairService) sendRequestError(client *websocket.Client, err error, fallback string, sessionID uuid.UUID) {
+	var requestErr pairRequestError
+	if errors.As(err, &requestErr) {
+		client.SendError(requestErr.Error())
+		return
+	}
+	s.logger.Error("Failed to handle pair frame", zap.Error(err), zap.String("session_id", sessionID.String()), zap.String("user_id", client.UserID().String()))
+	client.SendError(fallback)
+}
+
+// handleCursor relays a cursor move to the partner without persisting it
+func (s *PairService) handleCursor(client *websocket.Client, msg websocket.Message) {
+	var payload websocket.PairCursorPayload
+	if err := json.Unmarshal(msg.Payload, &payload); err != nil {
+		client.SendError("invalid pair_cursor payload")
+		return
+	}
+	sessionID, err := uuid.Parse(payload.SessionID)
+	if err != nil {
+		client.SendError("invalid session ID")
+		return
+	}
+	session, err := s.GetSession(sessionID, client.UserID())
+	if err != nil {
+		s.sendRequestError(client, err, "failed to load pair session", sessionID)
+		return
+	}
+
+	payload.UserID = client.UserID().String()
+	for _, member := range session.Members() {
+		if member == client.UserID() {
+			continue
+		}
+		if err := s.hub.SendLive(member, websocket.PairCursor, payload); err != nil {
+			s.logger.Error("Failed to relay pair cursor", zap.Error(err), zap.String("session_id", session.ID.String()))
+		}
+	}
+}
+
+func generateInviteCode() (string, error) {
+	code := make([]byte, inviteCodeLength)
+	max := big.NewInt(int64(len(inviteCodeAlphabet)))
+	for i := range code {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", fmt.Errorf("failed to generate invite code: %w", err)
+		}
+		code[i] = inviteCodeAlphabet[n.Int64()]
+	}
+	return string(code), nil
+}
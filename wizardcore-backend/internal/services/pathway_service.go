@@ -80,7 +80,7 @@ func (s *PathwayService) EnrollUser(userID, pathwayID uuid.UUID) error {
 	return s.pathwayRepo.CreateEnrollment(enrollment)
 }
 
-func (s *PathwayService) UpdateEnrollmentProgress(userID, pathwayID uuid.UUID, progressPercentage, completedModules, xpEarned, streakDays int) error {
+func (s *PathwayService) UpdateEnrollmentProgress(userID, pathwayID uuid.UUID, progressPercentage, completedModules, streakDays int) error {
 	enrollment, err := s.pathwayRepo.FindEnrollment(userID, pathwayID)
 	if err != nil {
 		return fmt.Errorf("failed to find enrollment: %w", err)
@@ -91,7 +91,6 @@ func (s *PathwayService) UpdateEnrollmentProgress(userID, pathwayID uuid.UUID, p
 
 	enrollment.ProgressPercentage = progressPercentage
 	enrollment.CompletedModules = completedModules
-	enrollment.XPEarned = xpEarned
 	enrollment.StreakDays = streakDays
 	// Update last activity to now
 	now := time.Now()
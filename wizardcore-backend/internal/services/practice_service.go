@@ -2,53 +2,216 @@ package services
 
 import (
 	"fmt"
+	"math"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/yourusername/wizardcore-backend/internal/models"
 	"github.com/yourusername/wizardcore-backend/internal/repositories"
+	"github.com/yourusername/wizardcore-backend/internal/websocket"
+	"go.uber.org/zap"
 )
 
 func intPtr(i int) *int {
 	return &i
 }
 
+// MatchEndListener is called after every participant of a match has finished
+type MatchEndListener func(match *models.PracticeMatch, participants []models.MatchParticipant)
+
+// MatchSubmissionListener is called for every graded submission made in a match
+type MatchSubmissionListener func(match *models.PracticeMatch, userID, exerciseID uuid.UUID, submissionID *uuid.UUID, score int, result string)
+
+// MatchMode runs the matches of a type whose scoring does not fit a single
+// submission per participant, such as team matches
+type MatchMode interface {
+	// RecordSubmission scores a graded submission made in an active match
+	RecordSubmission(match *models.PracticeMatch, participant *models.MatchParticipant, exerciseID uuid.UUID, submissionID *uuid.UUID, score int, result string) error
+	// Expire ends an active match whose time limit has run out
+	Expire(match *models.PracticeMatch, now time.Time) error
+}
+
 type PracticeService struct {
-	matchRepo   *repositories.MatchRepository
-	userRepo    *repositories.UserRepository
-	exerciseRepo *repositories.ExerciseRepository
+	matchRepo             *repositories.MatchRepository
+	userRepo              *repositories.UserRepository
+	exerciseRepo          *repositories.ExerciseRepository
+	catalogRepo           *repositories.PracticeCatalogRepository
+	recommendationService *RecommendationService
+	hub                   *websocket.Hub
+	listeners             []MatchEndListener
+	submitted             []MatchSubmissionListener
+	modes                 map[string]MatchMode
+	logger                *zap.Logger
 }
 
-func NewPracticeService(matchRepo *repositories.MatchRepository, userRepo *repositories.UserRepository, exerciseRepo *repositories.ExerciseRepository) *PracticeService {
+func NewPracticeService(matchRepo *repositories.MatchRepository, userRepo *repositories.UserRepository, exerciseRepo *repositories.ExerciseRepository, catalogRepo *repositories.PracticeCatalogRepository, recommendationService *RecommendationService, hub *websocket.Hub, logger *zap.Logger) *PracticeService {
 	return &PracticeService{
-		matchRepo:   matchRepo,
-		userRepo:    userRepo,
-		exerciseRepo: exerciseRepo,
+		matchRepo:             matchRepo,
+		userRepo:              userRepo,
+		exerciseRepo:          exerciseRepo,
+		catalogRepo:           catalogRepo,
+		recommendationService: recommendationService,
+		hub:                   hub,
+		modes:                 make(map[string]MatchMode),
+		logger:                logger,
 	}
 }
 
-// GetChallenges returns available challenge types
+// OnMatchEnd registers a listener for finished matches. Listeners must be
+// registered before the service starts handling requests.
+func (s *PracticeService) OnMatchEnd(listener MatchEndListener) {
+	s.listeners = append(s.listeners, listener)
+}
+
+// OnMatchSubmission registers a listener for submissions made in matches.
+// Listeners must be registered before the service starts handling requests.
+func (s *PracticeService) OnMatchSubmission(listener MatchSubmissionListener) {
+	s.submitted = append(s.submitted, listener)
+}
+
+// RegisterMode hands the matches of a type to a mode. Modes must be
+// registered before the service starts handling requests.
+func (s *PracticeService) RegisterMode(matchType string, mode MatchMode) {
+	s.modes[matchType] = mode
+}
+
+// GetChallenges returns the enabled challenge types
 func (s *PracticeService) GetChallenges() ([]models.ChallengeType, error) {
-	// Hardcoded for now
-	return []models.ChallengeType{
-		{ID: "duel", Name: "1v1 Duel", Description: "Compete against another learner in real-time", Icon: "⚔️"},
-		{ID: "speed_run", Name: "Speed Run", Description: "Complete exercises as fast as possible", Icon: "⏱️"},
-		{ID: "random", Name: "Random Challenge", Description: "Get a random exercise to solve", Icon: "🎲"},
-		{ID: "endurance", Name: "Endurance", Description: "Solve as many exercises as you can in a time limit", Icon: "🏋️"},
-	}, nil
+	return s.catalogRepo.ListChallengeTypes(false)
 }
 
-// GetAreas returns practice areas with completion stats
+// GetAreas returns practice areas computed from exercise languages and tags,
+// with the number of exercises in each the user has solved
 func (s *PracticeService) GetAreas(userID uuid.UUID) ([]models.PracticeArea, error) {
-	// Hardcoded for now
-	return []models.PracticeArea{
-		{Name: "Python", ExerciseCount: 42, CompletedCount: 28, ColorGradient: "from-green-400 to-cyan-400"},
-		{Name: "C & Assembly", ExerciseCount: 18, CompletedCount: 5, ColorGradient: "from-blue-400 to-indigo-400"},
-		{Name: "JavaScript", ExerciseCount: 25, CompletedCount: 12, ColorGradient: "from-yellow-400 to-orange-400"},
-		{Name: "SQL", ExerciseCount: 15, CompletedCount: 8, ColorGradient: "from-purple-400 to-pink-400"},
-		{Name: "Reverse Engineering", ExerciseCount: 10, CompletedCount: 2, ColorGradient: "from-red-400 to-rose-400"},
-		{Name: "Rootkit Development", ExerciseCount: 8, CompletedCount: 0, ColorGradient: "from-gray-400 to-black"},
-	}, nil
+	return s.catalogRepo.ComputeAreas(userID)
+}
+
+// xpMultiplier returns the XP multiplier configured for a match type
+func (s *PracticeService) xpMultiplier(matchType string) float64 {
+	ct, err := s.catalogRepo.FindChallengeType(matchType)
+	if err != nil {
+		s.logger.Error("Failed to load challenge type", zap.Error(err), zap.String("match_type", matchType))
+		return 1
+	}
+	if ct == nil {
+		return 1
+	}
+	return ct.XPMultiplier
+}
+
+// timeLimit returns the time limit configured for a match type, or fallback
+// if it has none
+func (s *PracticeService) timeLimit(matchType string, fallback int) int {
+	ct, err := s.catalogRepo.FindChallengeType(matchType)
+	if err != nil {
+		s.logger.Error("Failed to load challenge type", zap.Error(err), zap.String("match_type", matchType))
+		return fallback
+	}
+	if ct == nil || ct.TimeLimitMinutes == nil {
+		return fallback
+	}
+	return *ct.TimeLimitMinutes
+}
+
+// applyXPMultiplier scales XP by the multiplier of the match type
+func (s *PracticeService) applyXPMultiplier(matchType string, xp int) int {
+	return int(math.Round(float64(xp) * s.xpMultiplier(matchType)))
+}
+
+// ListChallengeTypes returns every challenge type, including disabled ones
+func (s *PracticeService) ListChallengeTypes() ([]models.ChallengeType, error) {
+	return s.catalogRepo.ListChallengeTypes(true)
+}
+
+// UpdateChallengeType changes the configuration of a challenge type
+func (s *PracticeService) UpdateChallengeType(id string, req *models.UpdateChallengeTypeRequest) (*models.ChallengeType, error) {
+	ct, err := s.catalogRepo.FindChallengeType(id)
+	if err != nil {
+		return nil, err
+	}
+	if ct == nil {
+		return nil, nil
+	}
+	if req.Name != nil {
+		if *req.Name == "" {
+			return nil, fmt.Errorf("name cannot be empty")
+		}
+		ct.Name = *req.Name
+	}
+	if req.Description != nil {
+		ct.Description = *req.Description
+	}
+	if req.Icon != nil {
+		ct.Icon = *req.Icon
+	}
+	if req.TimeLimitMinutes != nil {
+		if *req.TimeLimitMinutes <= 0 || *req.TimeLimitMinutes > maxPrivateDuelMinutes {
+			return nil, fmt.Errorf("time limit must be between 1 and %d minutes", maxPrivateDuelMinutes)
+		}
+		ct.TimeLimitMinutes = req.TimeLimitMinutes
+	}
+	if req.XPMultiplier != nil {
+		if *req.XPMultiplier < 0 {
+			return nil, fmt.Errorf("xp multiplier cannot be negative")
+		}
+		ct.XPMultiplier = *req.XPMultiplier
+	}
+	if req.SortOrder != nil {
+		ct.SortOrder = *req.SortOrder
+	}
+	if req.IsEnabled != nil {
+		ct.IsEnabled = *req.IsEnabled
+	}
+	if err := s.catalogRepo.UpdateChallengeType(ct); err != nil {
+		return nil, err
+	}
+	return ct, nil
+}
+
+// ListAreaSettings returns the admin settings of practice areas
+func (s *PracticeService) ListAreaSettings() ([]models.PracticeAreaSetting, error) {
+	return s.catalogRepo.ListAreaSettings()
+}
+
+// SaveAreaSetting names, styles or hides a practice area
+func (s *PracticeService) SaveAreaSetting(req *models.UpsertPracticeAreaSettingRequest) (*models.PracticeAreaSetting, error) {
+	key := strings.TrimSpace(req.Key)
+	switch req.Kind {
+	case "language":
+		if _, err := strconv.Atoi(key); err != nil {
+			return nil, fmt.Errorf("language areas are keyed by language ID")
+		}
+	case "tag":
+		key = strings.ToLower(key)
+	default:
+		return nil, fmt.Errorf("kind must be language or tag")
+	}
+	if key == "" {
+		return nil, fmt.Errorf("key is required")
+	}
+	setting := &models.PracticeAreaSetting{
+		Kind:          req.Kind,
+		Key:           key,
+		Name:          req.Name,
+		ColorGradient: req.ColorGradient,
+		Icon:          req.Icon,
+		SortOrder:     req.SortOrder,
+		IsVisible:     true,
+	}
+	if req.IsVisible != nil {
+		setting.IsVisible = *req.IsVisible
+	}
+	if err := s.catalogRepo.UpsertAreaSetting(setting); err != nil {
+		return nil, err
+	}
+	return setting, nil
+}
+
+// DeleteAreaSetting returns a practice area to its defaults
+func (s *PracticeService) DeleteAreaSetting(id uuid.UUID) (bool, error) {
+	return s.catalogRepo.DeleteAreaSetting(id)
 }
 
 // GetStats returns practice stats for a user
@@ -66,59 +229,23 @@ func (s *PracticeService) StartChallenge(userID uuid.UUID, challengeType string)
 	var exerciseID uuid.UUID
 	var timeLimit *int
 
+	ct, err := s.catalogRepo.FindChallengeType(challengeType)
+	if err != nil {
+		return nil, err
+	}
+	if ct == nil || !ct.IsEnabled {
+		return nil, fmt.Errorf("unknown challenge type: %s", challengeType)
+	}
+
 	// Determine exercise based on challenge type
 	switch challengeType {
 	case "duel":
-		// Try to find an existing pending duel with one participant
-		existingMatch, err := s.matchRepo.FindPendingDuelWithOneParticipant()
-		if err != nil {
-			return nil, err
-		}
-		if existingMatch != nil {
-			// Join existing match
-			match := existingMatch
-			exerciseID = match.ExerciseID
-			timeLimit = match.TimeLimitMinutes
-			// Add participant
-			now := time.Now()
-			participant := &models.MatchParticipant{
-				ID:           uuid.New(),
-				MatchID:      match.ID,
-				UserID:       userID,
-				SubmissionID: nil,
-				Score:        0,
-				Rank:         nil,
-				Result:       "",
-				XPEarned:     0,
-				JoinedAt:     &now,
-				FinishedAt:   nil,
-			}
-			err = s.matchRepo.AddParticipant(participant)
-			if err != nil {
-				return nil, err
-			}
-			// Update match status to active (both participants joined)
-			match.Status = "active"
-			match.StartedAt = &now
-			err = s.matchRepo.UpdateMatch(match)
-			if err != nil {
-				return nil, err
-			}
-			return match, nil
-		}
-		// No existing match, create a new one with random exercise
-		exercise, err := s.exerciseRepo.GetRandomExercise()
-		if err != nil {
-			return nil, err
-		}
-		if exercise == nil {
-			return nil, fmt.Errorf("no exercises available")
-		}
-		exerciseID = exercise.ID
-		timeLimit = intPtr(10) // 10 minutes for duel
+		// Duels are paired by the matchmaking queue
+		return nil, fmt.Errorf("duels are started through the matchmaking queue")
+	case "team_duel":
+		return nil, fmt.Errorf("team duels are started by queueing a party")
 	case "random", "speed_run", "endurance":
-		// Get a random exercise
-		exercise, err := s.exerciseRepo.GetRandomExercise()
+		exercise, err := s.pickExercise(userID, nil)
 		if err != nil {
 			return nil, err
 		}
@@ -126,41 +253,27 @@ func (s *PracticeService) StartChallenge(userID uuid.UUID, challengeType string)
 			return nil, fmt.Errorf("no exercises available")
 		}
 		exerciseID = exercise.ID
-		// Set time limit based on challenge type
-		if challengeType == "speed_run" {
-			timeLimit = intPtr(5) // 5 minutes for speed run
-		} else if challengeType == "endurance" {
-			timeLimit = intPtr(30) // 30 minutes for endurance
-		} else {
-			timeLimit = intPtr(10) // 10 minutes for random
+		timeLimit = intPtr(duelTimeLimitMinutes)
+		if ct.TimeLimitMinutes != nil {
+			timeLimit = ct.TimeLimitMinutes
 		}
 	default:
-		return nil, fmt.Errorf("unknown challenge type: %s", challengeType)
+		return nil, fmt.Errorf("challenge type %s cannot be started", challengeType)
 	}
 
+	// Solo challenges start immediately
 	now := time.Now()
-	var startedAt *time.Time
-	var status string
-	if challengeType == "duel" {
-		// Duel starts when both participants join
-		startedAt = nil
-		status = "pending"
-	} else {
-		// Solo challenges start immediately
-		startedAt = &now
-		status = "active"
-	}
 	match := &models.PracticeMatch{
 		ID:               uuid.New(),
 		MatchType:        challengeType,
-		Status:           status,
+		Status:           "active",
 		ExerciseID:       exerciseID,
 		TimeLimitMinutes: timeLimit,
-		StartedAt:        startedAt,
+		StartedAt:        &now,
 		EndedAt:          nil,
 		CreatedAt:        &now,
 	}
-	err := s.matchRepo.CreateMatch(match)
+	err = s.matchRepo.CreateMatch(match)
 	if err != nil {
 		return nil, err
 	}
@@ -181,11 +294,25 @@ func (s *PracticeService) StartChallenge(userID uuid.UUID, challengeType string)
 	if err != nil {
 		return nil, err
 	}
+	if isRunMode(challengeType) {
+		// Serve the first exercise of the run
+		err = s.matchRepo.CreateSplit(&models.MatchSplit{
+			MatchID:    match.ID,
+			UserID:     userID,
+			ExerciseID: exerciseID,
+			Sequence:   1,
+			ServedAt:   now,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
 	return match, nil
 }
 
-// RecordMatchResult records the result of a completed match
-func (s *PracticeService) RecordMatchResult(matchID uuid.UUID, userID uuid.UUID, score int, result string, xpEarned int, submissionID *uuid.UUID) error {
+// RecordMatchResult records the result of a completed match. In speed runs
+// and endurance runs it records the result of the current exercise instead.
+func (s *PracticeService) RecordMatchResult(matchID uuid.UUID, userID uuid.UUID, score int, result string, xpEarned int, submissionID *uuid.UUID, exerciseID uuid.UUID) error {
 	// Fetch match
 	match, err := s.matchRepo.GetMatchByID(matchID)
 	if err != nil {
@@ -203,8 +330,21 @@ func (s *PracticeService) RecordMatchResult(matchID uuid.UUID, userID uuid.UUID,
 	if participant == nil {
 		return fmt.Errorf("participant not found")
 	}
+	if match.Status != "active" {
+		return fmt.Errorf("match is not active")
+	}
+	for _, listener := range s.submitted {
+		listener(match, userID, exerciseID, submissionID, score, result)
+	}
+	if mode, ok := s.modes[match.MatchType]; ok {
+		return mode.RecordSubmission(match, participant, exerciseID, submissionID, score, result)
+	}
+	if isRunMode(match.MatchType) {
+		return s.recordSplit(match, participant, exerciseID, score, result, submissionID)
+	}
 
 	now := time.Now()
+	xpEarned = s.applyXPMultiplier(match.MatchType, xpEarned)
 	participant.SubmissionID = submissionID
 	participant.Score = score
 	participant.Result = result
@@ -230,15 +370,42 @@ func (s *PracticeService) RecordMatchResult(matchID uuid.UUID, userID uuid.UUID,
 		}
 	}
 	if allFinished {
-		match.Status = "completed"
-		match.EndedAt = &now
-		err = s.matchRepo.UpdateMatch(match)
-		if err != nil {
+		if err := s.completeMatch(match, participants, now); err != nil {
 			return err
 		}
 	}
 
-	// Update user practice stats
+	return s.recordStats(match, userID, result, score, xpEarned, now)
+}
+
+// completeMatch ends a match whose participants have all finished: duels are
+// rated, the players are told the results and match end listeners are called.
+// Only the first of concurrent callers completes the match; the others
+// return without effect.
+func (s *PracticeService) completeMatch(match *models.PracticeMatch, participants []models.MatchParticipant, now time.Time) error {
+	var duel *models.DuelOutcome
+	if match.MatchType == "duel" && len(participants) == 2 {
+		duel = &models.DuelOutcome{
+			PlayerA: participants[0].UserID,
+			PlayerB: participants[1].UserID,
+			ScoreA:  duelScore(participants[0], participants[1]),
+		}
+	}
+	completed, ratings, err := s.matchRepo.CompleteMatch(match.ID, now, duel)
+	if err != nil || !completed {
+		return err
+	}
+	match.Status = "completed"
+	match.EndedAt = &now
+	s.notifyMatchEnd(match, participants, ratings)
+	for _, listener := range s.listeners {
+		listener(match, participants)
+	}
+	return nil
+}
+
+// recordStats adds a participant's result to their practice stats
+func (s *PracticeService) recordStats(match *models.PracticeMatch, userID uuid.UUID, result string, score, xpEarned int, now time.Time) error {
 	stats, err := s.matchRepo.GetUserPracticeStats(userID)
 	if err != nil {
 		return err
@@ -248,7 +415,7 @@ func (s *PracticeService) RecordMatchResult(matchID uuid.UUID, userID uuid.UUID,
 
 	// Update based on match type
 	switch match.MatchType {
-	case "duel":
+	case "duel", "team_duel":
 		stats.DuelsTotal++
 		switch result {
 		case "win":
@@ -258,37 +425,616 @@ func (s *PracticeService) RecordMatchResult(matchID uuid.UUID, userID uuid.UUID,
 		case "draw":
 			stats.DuelsDraw++
 		}
-	case "speed_run":
-		stats.SpeedRunsCompleted++
-		// Compute completion time in seconds
-		var startTime time.Time
-		if match.StartedAt != nil {
-			startTime = *match.StartedAt
-		} else if match.CreatedAt != nil {
-			startTime = *match.CreatedAt
-		} else {
-			startTime = now // fallback
-		}
-		completionTime := int(now.Sub(startTime).Seconds())
-		if stats.BestSpeedRunTime == nil || completionTime < *stats.BestSpeedRunTime {
-			stats.BestSpeedRunTime = &completionTime
-		}
-		// Update average completion time (simplified: just set to latest)
-		// For proper average we'd need to store total time and count, but we'll skip for now
-		stats.AvgCompletionTime = &completionTime
 	case "random":
 		stats.RandomChallengesCompleted++
-	case "endurance":
-		// Endurance stats not defined; could add later
+		if result == "win" && match.StartedAt != nil {
+			stats.RecordCompletion(int(now.Sub(*match.StartedAt).Seconds()))
+		}
 	}
 	stats.TotalPracticeXP += xpEarned
 	stats.PracticeScore += score
 
 	// Save updated stats
-	err = s.matchRepo.UpdateUserPracticeStats(stats)
+	return s.matchRepo.UpdateUserPracticeStats(stats)
+}
+
+// ExpireMatch ends an active match whose time limit has run out. Runs are
+// finished with the splits their players completed. In other matches a
+// participant who never submitted forfeits, unless nobody submitted, in
+// which case a duel is drawn. Matches of a registered mode are expired by
+// the mode.
+func (s *PracticeService) ExpireMatch(match *models.PracticeMatch, now time.Time) error {
+	if mode, ok := s.modes[match.MatchType]; ok {
+		return mode.Expire(match, now)
+	}
+	participants, err := s.matchRepo.GetParticipantsByMatchID(match.ID)
+	if err != nil {
+		return err
+	}
+	if isRunMode(match.MatchType) {
+		for i := range participants {
+			if participants[i].FinishedAt != nil {
+				continue
+			}
+			if _, err := s.finishRun(match, &participants[i], now); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	anyFinished := false
+	for _, p := range participants {
+		if p.FinishedAt != nil {
+			anyFinished = true
+			break
+		}
+	}
+	raced := false
+	for i := range participants {
+		p := &participants[i]
+		if p.FinishedAt != nil {
+			continue
+		}
+		p.Score = 0
+		p.XPEarned = 0
+		p.Result = "loss"
+		if !anyFinished && match.MatchType == "duel" {
+			p.Result = "draw"
+		}
+		p.FinishedAt = &now
+		// A participant whose submission lands while the match expires
+		// keeps that result
+		finished, err := s.matchRepo.FinishParticipant(p)
+		if err != nil {
+			return err
+		}
+		if !finished {
+			raced = true
+			continue
+		}
+		// Solo challenges that ran out count for nothing
+		if match.MatchType == "duel" {
+			if err := s.recordStats(match, p.UserID, p.Result, 0, 0, now); err != nil {
+				s.logger.Error("Failed to record forfeit stats", zap.Error(err), zap.String("match_id", match.ID.String()), zap.String("user_id", p.UserID.String()))
+			}
+		}
+	}
+	if raced {
+		if participants, err = s.matchRepo.GetParticipantsByMatchID(match.ID); err != nil {
+			return err
+		}
+	}
+	return s.completeMatch(match, participants, now)
+}
+
+// CancelStaleMatch cancels a match nobody joined and tells its participants
+func (s *PracticeService) CancelStaleMatch(match *models.PracticeMatch, now time.Time) error {
+	cancelled, err := s.matchRepo.CancelPendingMatch(match.ID, now)
+	if err != nil || !cancelled {
+		return err
+	}
+	match.Status = "cancelled"
+	match.EndedAt = &now
+
+	participants, err := s.matchRepo.GetParticipantsByMatchID(match.ID)
+	if err != nil {
+		return err
+	}
+	if s.hub == nil {
+		return nil
+	}
+	userIDs := make([]uuid.UUID, 0, len(participants))
+	for _, p := range participants {
+		userIDs = append(userIDs, p.UserID)
+	}
+	if err := s.hub.SendToUsers(userIDs, websocket.MatchCancelled, websocket.MatchCancelledPayload{
+		MatchID: match.ID.String(),
+		Reason:  "no_opponent",
+	}); err != nil {
+		s.logger.Error("Failed to push match cancellation", zap.Error(err), zap.String("match_id", match.ID.String()))
+	}
+	return nil
+}
+
+// Number of exercises in a speed run
+const speedRunLength = 5
+
+// isRunMode reports whether a challenge serves a sequence of exercises
+func isRunMode(matchType string) bool {
+	return matchType == "speed_run" || matchType == "endurance"
+}
+
+// runDeadline returns when a run's time limit is up
+func runDeadline(match *models.PracticeMatch) time.Time {
+	start := time.Now()
+	if match.StartedAt != nil {
+		start = *match.StartedAt
+	}
+	limit := 0
+	if match.TimeLimitMinutes != nil {
+		limit = *match.TimeLimitMinutes
+	}
+	return start.Add(time.Duration(limit) * time.Minute)
+}
+
+// runScore computes a run's final score from its splits. Endurance scores
+// the points of every solved exercise. A speed run adds a bonus of one point
+// per second left on the clock if all its exercises were solved.
+func runScore(mode string, splits []models.MatchSplit, remaining time.Duration) (score, solved int, complete bool) {
+	for _, split := range splits {
+		if split.Result == "solved" {
+			score += split.Score
+			solved++
+		}
+	}
+	if mode == "speed_run" && solved >= speedRunLength {
+		complete = true
+		if remaining > 0 {
+			score += int(remaining.Seconds())
+		}
+	}
+	return score, solved, complete
+}
+
+// recordSplit records a submission against the current exercise of a run.
+// A solved exercise closes its split and serves the next one.
+func (s *PracticeService) recordSplit(match *models.PracticeMatch, participant *models.MatchParticipant, exerciseID uuid.UUID, score int, result string, submissionID *uuid.UUID) error {
+	if participant.FinishedAt != nil {
+		return fmt.Errorf("run has already finished")
+	}
+	now := time.Now()
+	if !now.Before(runDeadline(match)) {
+		_, err := s.finishRun(match, participant, now)
+		return err
+	}
+
+	splits, err := s.matchRepo.GetSplits(match.ID, participant.UserID)
+	if err != nil {
+		return err
+	}
+	if len(splits) == 0 || splits[len(splits)-1].Result != "pending" {
+		return fmt.Errorf("no exercise in progress")
+	}
+	current := &splits[len(splits)-1]
+	if current.ExerciseID != exerciseID {
+		return fmt.Errorf("submission is not for the current exercise")
+	}
+
+	current.Attempts++
+	current.SubmissionID = submissionID
+	if result != "win" {
+		return s.matchRepo.UpdateSplit(current)
+	}
+
+	seconds := int(now.Sub(current.ServedAt).Seconds())
+	current.Result = "solved"
+	current.Score = score
+	current.CompletedAt = &now
+	current.SplitSeconds = &seconds
+	if err := s.matchRepo.UpdateSplit(current); err != nil {
+		return err
+	}
+
+	stats, err := s.matchRepo.GetUserPracticeStats(participant.UserID)
 	if err != nil {
 		return err
 	}
+	stats.UserID = participant.UserID
+	stats.RecordCompletion(seconds)
+	if err := s.matchRepo.UpdateUserPracticeStats(stats); err != nil {
+		return err
+	}
 
+	_, solved, complete := runScore(match.MatchType, splits, 0)
+	if complete {
+		_, err := s.finishRun(match, participant, now)
+		return err
+	}
+	next, err := s.serveNext(match, participant.UserID, splits)
+	if err != nil {
+		return err
+	}
+	if next == nil {
+		// Out of exercises
+		_, err := s.finishRun(match, participant, now)
+		return err
+	}
+	s.notifySplit(match, participant.UserID, current, next, solved)
 	return nil
-}
\ No newline at end of file
+}
+
+// pickExercise picks a solo practice exercise for a user other than the
+// given ones, from their recommendations. It returns nil if there are none
+// left.
+func (s *PracticeService) pickExercise(userID uuid.UUID, exclude []uuid.UUID) (*models.Exercise, error) {
+	if s.recommendationService == nil {
+		return s.exerciseRepo.GetRandomExerciseExcluding(exclude)
+	}
+	return s.recommendationService.PickPracticeExercise(userID, exclude)
+}
+
+// serveNext serves an exercise the user has not seen in this run. It returns
+// nil if there are none left.
+func (s *PracticeService) serveNext(match *models.PracticeMatch, userID uuid.UUID, splits []models.MatchSplit) (*models.MatchSplit, error) {
+	served := make([]uuid.UUID, len(splits))
+	for i, split := range splits {
+		served[i] = split.ExerciseID
+	}
+	exercise, err := s.pickExercise(userID, served)
+	if err != nil {
+		return nil, err
+	}
+	if exercise == nil {
+		return nil, nil
+	}
+	next := &models.MatchSplit{
+		MatchID:    match.ID,
+		UserID:     userID,
+		ExerciseID: exercise.ID,
+		Sequence:   len(splits) + 1,
+		Result:     "pending",
+		ServedAt:   time.Now(),
+	}
+	if err := s.matchRepo.CreateSplit(next); err != nil {
+		return nil, err
+	}
+	// The match points at the exercise currently being solved
+	match.ExerciseID = exercise.ID
+	if err := s.matchRepo.UpdateMatch(match); err != nil {
+		return nil, err
+	}
+	return next, nil
+}
+
+// SkipExercise gives up on the current exercise of a run and serves the next
+func (s *PracticeService) SkipExercise(matchID, userID uuid.UUID) (*models.PracticeRun, error) {
+	match, participant, err := s.runParticipant(matchID, userID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if !now.Before(runDeadline(match)) {
+		return s.finishRun(match, participant, now)
+	}
+
+	splits, err := s.matchRepo.GetSplits(matchID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(splits) == 0 || splits[len(splits)-1].Result != "pending" {
+		return nil, fmt.Errorf("no exercise in progress")
+	}
+	current := &splits[len(splits)-1]
+	seconds := int(now.Sub(current.ServedAt).Seconds())
+	current.Result = "skipped"
+	current.CompletedAt = &now
+	current.SplitSeconds = &seconds
+	if err := s.matchRepo.UpdateSplit(current); err != nil {
+		return nil, err
+	}
+
+	next, err := s.serveNext(match, userID, splits)
+	if err != nil {
+		return nil, err
+	}
+	if next == nil {
+		return s.finishRun(match, participant, now)
+	}
+	_, solved, _ := runScore(match.MatchType, splits, 0)
+	s.notifySplit(match, userID, current, next, solved)
+	return s.GetRun(matchID, userID)
+}
+
+// FinishRun ends a run early
+func (s *PracticeService) FinishRun(matchID, userID uuid.UUID) (*models.PracticeRun, error) {
+	match, participant, err := s.runParticipant(matchID, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.finishRun(match, participant, time.Now())
+}
+
+// GetRun returns the state of a run. It does not change the run: a run whose
+// time is up but that has not been finished yet reports no time remaining
+// until the match sweeper or the player's next action finishes it.
+func (s *PracticeService) GetRun(matchID, userID uuid.UUID) (*models.PracticeRun, error) {
+	match, err := s.matchRepo.GetMatchByID(matchID)
+	if err != nil {
+		return nil, err
+	}
+	if match == nil || !isRunMode(match.MatchType) {
+		return nil, fmt.Errorf("run not found")
+	}
+	participant, err := s.matchRepo.GetParticipantByMatchAndUser(matchID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if participant == nil {
+		return nil, fmt.Errorf("run not found")
+	}
+	splits, err := s.matchRepo.GetSplits(matchID, userID)
+	if err != nil {
+		return nil, err
+	}
+	run := &models.PracticeRun{
+		Match:    match,
+		Splits:   splits,
+		Finished: participant.FinishedAt != nil,
+	}
+	if run.Finished {
+		run.Score = participant.Score
+		return run, nil
+	}
+	if remaining := time.Until(runDeadline(match)); remaining > 0 {
+		run.RemainingSeconds = int(remaining.Seconds())
+	}
+	run.Score, _, _ = runScore(match.MatchType, splits, 0)
+	if len(splits) > 0 && splits[len(splits)-1].Result == "pending" {
+		run.CurrentExerciseID = &splits[len(splits)-1].ExerciseID
+	}
+	return run, nil
+}
+
+// runParticipant loads an unfinished run and the user's participation in it
+func (s *PracticeService) runParticipant(matchID, userID uuid.UUID) (*models.PracticeMatch, *models.MatchParticipant, error) {
+	match, err := s.matchRepo.GetMatchByID(matchID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if match == nil || !isRunMode(match.MatchType) {
+		return nil, nil, fmt.Errorf("run not found")
+	}
+	participant, err := s.matchRepo.GetParticipantByMatchAndUser(matchID, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if participant == nil {
+		return nil, nil, fmt.Errorf("run not found")
+	}
+	if participant.FinishedAt != nil {
+		return nil, nil, fmt.Errorf("run has already finished")
+	}
+	return match, participant, nil
+}
+
+// finishRun closes a run: open splits are marked unfinished, the final score
+// is computed from the splits, and stats and personal bests are updated
+func (s *PracticeService) finishRun(match *models.PracticeMatch, participant *models.MatchParticipant, now time.Time) (*models.PracticeRun, error) {
+	splits, err := s.matchRepo.GetSplits(match.ID, participant.UserID)
+	if err != nil {
+		return nil, err
+	}
+	xp := 0
+	for i := range splits {
+		switch splits[i].Result {
+		case "pending":
+			splits[i].Result = "unfinished"
+			if err := s.matchRepo.UpdateSplit(&splits[i]); err != nil {
+				return nil, err
+			}
+		case "solved":
+			xp += splits[i].Score
+		}
+	}
+
+	deadline := runDeadline(match)
+	end := now
+	if end.After(deadline) {
+		end = deadline
+	}
+	elapsed := 0
+	if match.StartedAt != nil {
+		elapsed = int(end.Sub(*match.StartedAt).Seconds())
+	}
+	score, solved, complete := runScore(match.MatchType, splits, deadline.Sub(end))
+	xp = s.applyXPMultiplier(match.MatchType, xp)
+
+	participant.Score = score
+	participant.XPEarned = xp
+	participant.FinishedAt = &now
+	participant.Result = "loss"
+	if complete || (match.MatchType == "endurance" && solved > 0) {
+		participant.Result = "win"
+	}
+	// Only the first caller to finish the run records it; a concurrent one
+	// returns the run as that caller left it
+	finished, err := s.matchRepo.FinishParticipant(participant)
+	if err != nil {
+		return nil, err
+	}
+	if !finished {
+		return s.GetRun(match.ID, participant.UserID)
+	}
+	match.Status = "completed"
+	match.EndedAt = &now
+	if err := s.matchRepo.UpdateMatch(match); err != nil {
+		return nil, err
+	}
+
+	stats, err := s.matchRepo.GetUserPracticeStats(participant.UserID)
+	if err != nil {
+		return nil, err
+	}
+	stats.UserID = participant.UserID
+	switch match.MatchType {
+	case "speed_run":
+		if complete {
+			stats.SpeedRunsCompleted++
+			if stats.BestSpeedRunTime == nil || elapsed < *stats.BestSpeedRunTime {
+				stats.BestSpeedRunTime = &elapsed
+			}
+		}
+	case "endurance":
+		stats.EnduranceRunsCompleted++
+		if solved > stats.BestEnduranceSolved {
+			stats.BestEnduranceSolved = solved
+		}
+		if score > stats.BestEnduranceScore {
+			stats.BestEnduranceScore = score
+		}
+	}
+	stats.TotalPracticeXP += xp
+	stats.PracticeScore += score
+	if err := s.matchRepo.UpdateUserPracticeStats(stats); err != nil {
+		return nil, err
+	}
+
+	best := &models.PersonalBest{
+		UserID:     participant.UserID,
+		Mode:       match.MatchType,
+		BestScore:  score,
+		MostSolved: solved,
+		MatchID:    &match.ID,
+		AchievedAt: now,
+	}
+	if complete {
+		best.BestTimeSeconds = &elapsed
+	}
+	improved, err := s.matchRepo.SavePersonalBest(best)
+	if err != nil {
+		s.logger.Error("Failed to save personal best", zap.Error(err), zap.String("match_id", match.ID.String()), zap.String("user_id", participant.UserID.String()))
+	}
+
+	s.notifyMatchEnd(match, []models.MatchParticipant{*participant}, nil)
+	return &models.PracticeRun{
+		Match:           match,
+		Splits:          splits,
+		Score:           score,
+		Finished:        true,
+		NewPersonalBest: improved,
+	}, nil
+}
+
+// GetPersonalBests returns the user's personal bests per practice mode
+func (s *PracticeService) GetPersonalBests(userID uuid.UUID) ([]models.PersonalBest, error) {
+	return s.matchRepo.GetPersonalBests(userID)
+}
+
+// notifySplit pushes a practice_split event after an exercise of a run is
+// solved or skipped
+func (s *PracticeService) notifySplit(match *models.PracticeMatch, userID uuid.UUID, split *models.MatchSplit, next *models.MatchSplit, solved int) {
+	if s.hub == nil {
+		return
+	}
+	payload := websocket.PracticeSplitPayload{
+		MatchID:        match.ID.String(),
+		Sequence:       split.Sequence,
+		Result:         split.Result,
+		Solved:         solved,
+		NextExerciseID: next.ExerciseID.String(),
+	}
+	if split.SplitSeconds != nil {
+		payload.SplitSeconds = *split.SplitSeconds
+	}
+	if err := s.hub.SendToUser(userID, websocket.PracticeSplit, payload); err != nil {
+		s.logger.Error("Failed to push practice split", zap.Error(err), zap.String("user_id", userID.String()))
+	}
+}
+
+// provisionalDeviation is the rating deviation above which a player's duel
+// rating is still provisional and kept off the rated leaderboard
+const provisionalDeviation = 110.0
+
+// duelScore returns the Glicko score of a against b: 1 for a win, 0.5 for a
+// draw and 0 for a loss. If both solved the exercise the faster one wins; if
+// neither did, the higher test score wins.
+func duelScore(a, b models.MatchParticipant) float64 {
+	aWon, bWon := a.Result == "win", b.Result == "win"
+	switch {
+	case aWon && !bWon:
+		return 1
+	case bWon && !aWon:
+		return 0
+	case aWon && bWon && a.FinishedAt != nil && b.FinishedAt != nil:
+		if a.FinishedAt.Before(*b.FinishedAt) {
+			return 1
+		}
+		if b.FinishedAt.Before(*a.FinishedAt) {
+			return 0
+		}
+		return 0.5
+	case a.Score > b.Score:
+		return 1
+	case b.Score > a.Score:
+		return 0
+	default:
+		return 0.5
+	}
+}
+
+// GetRatingHistory returns a user's duel rating changes for charts
+func (s *PracticeService) GetRatingHistory(userID uuid.UUID, limit int) ([]models.DuelRatingChange, error) {
+	return s.matchRepo.GetRatingHistory(userID, limit)
+}
+
+// GetRatedLeaderboard returns established duel players ordered by rating
+func (s *PracticeService) GetRatedLeaderboard(currentUserID uuid.UUID, limit, offset int) ([]models.DuelLeaderboardEntry, int, error) {
+	entries, total, err := s.matchRepo.GetRatedLeaderboard(provisionalDeviation, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	for i := range entries {
+		entries[i].IsCurrentUser = entries[i].UserID == currentUserID
+	}
+	return entries, total, nil
+}
+
+// notifyMatchStart pushes a match_start event to every participant
+func (s *PracticeService) notifyMatchStart(match *models.PracticeMatch) {
+	if s.hub == nil {
+		return
+	}
+	participants, err := s.matchRepo.GetParticipantsByMatchID(match.ID)
+	if err != nil {
+		s.logger.Error("Failed to load participants for match start", zap.Error(err), zap.String("match_id", match.ID.String()))
+		return
+	}
+	timeLimit := 0
+	if match.TimeLimitMinutes != nil {
+		timeLimit = *match.TimeLimitMinutes
+	}
+	payload := websocket.MatchStartPayload{
+		MatchID:    match.ID.String(),
+		ExerciseID: match.ExerciseID.String(),
+		TimeLimit:  timeLimit,
+	}
+	var userIDs []uuid.UUID
+	for _, p := range participants {
+		payload.Participants = append(payload.Participants, p.UserID.String())
+		userIDs = append(userIDs, p.UserID)
+	}
+	if err := s.hub.SendToUsers(userIDs, websocket.MatchStart, payload); err != nil {
+		s.logger.Error("Failed to push match start", zap.Error(err), zap.String("match_id", match.ID.String()))
+	}
+}
+
+// notifyMatchEnd pushes a match_end event with the final results
+func (s *PracticeService) notifyMatchEnd(match *models.PracticeMatch, participants []models.MatchParticipant, ratings []models.DuelRatingChange) {
+	if s.hub == nil {
+		return
+	}
+	payload := websocket.MatchEndPayload{MatchID: match.ID.String()}
+	var userIDs []uuid.UUID
+	for _, p := range participants {
+		result := websocket.ParticipantResult{
+			UserID: p.UserID.String(),
+			Score:  p.Score,
+			Result: p.Result,
+			XP:     p.XPEarned,
+		}
+		for _, change := range ratings {
+			if change.UserID == p.UserID {
+				after := change.RatingAfter
+				delta := change.RatingAfter - change.RatingBefore
+				result.Rating = &after
+				result.RatingChange = &delta
+			}
+		}
+		payload.Results = append(payload.Results, result)
+		userIDs = append(userIDs, p.UserID)
+	}
+	if err := s.hub.SendToUsers(userIDs, websocket.MatchEnd, payload); err != nil {
+		s.logger.Error("Failed to push match end", zap.Error(err), zap.String("match_id", match.ID.String()))
+	}
+}
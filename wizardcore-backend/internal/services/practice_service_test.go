@@ -0,0 +1,137 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/yourusername/wizardcore-backend/internal/models"
+)
+
+func splitsWithResults(results ...string) []models.MatchSplit {
+	splits := make([]models.MatchSplit, len(results))
+	for i, result := range results {
+		splits[i] = models.MatchSplit{Sequence: i + 1, Result: result, Score: 10}
+	}
+	return splits
+}
+
+func TestRunScore(t *testing.T) {
+	tests := []struct {
+		name         string
+		mode         string
+		splits       []models.MatchSplit
+		remaining    time.Duration
+		wantScore    int
+		wantSolved   int
+		wantComplete bool
+	}{
+		{
+			name:       "endurance counts solved splits only",
+			mode:       "endurance",
+			splits:     splitsWithResults("solved", "skipped", "solved", "unfinished"),
+			remaining:  time.Minute,
+			wantScore:  20,
+			wantSolved: 2,
+		},
+		{
+			name:       "unfinished speed run earns no time bonus",
+			mode:       "speed_run",
+			splits:     splitsWithResults("solved", "solved", "skipped", "solved", "unfinished"),
+			remaining:  time.Minute,
+			wantScore:  30,
+			wantSolved: 3,
+		},
+		{
+			name:         "completed speed run earns the seconds left",
+			mode:         "speed_run",
+			splits:       splitsWithResults("solved", "solved", "solved", "solved", "solved"),
+			remaining:    90 * time.Second,
+			wantScore:    140,
+			wantSolved:   5,
+			wantComplete: true,
+		},
+		{
+			name:         "speed run completed at the deadline",
+			mode:         "speed_run",
+			splits:       splitsWithResults("solved", "solved", "solved", "solved", "solved"),
+			remaining:    -time.Second,
+			wantScore:    50,
+			wantSolved:   5,
+			wantComplete: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			score, solved, complete := runScore(tt.mode, tt.splits, tt.remaining)
+			if score != tt.wantScore || solved != tt.wantSolved || complete != tt.wantComplete {
+				t.Errorf("Expected (%d, %d, %v), got (%d, %d, %v)",
+					tt.wantScore, tt.wantSolved, tt.wantComplete, score, solved, complete)
+			}
+		})
+	}
+}
+
+func TestDuelScore(t *testing.T) {
+	start := time.Date(2026, 3, 4, 12, 0, 0, 0, time.UTC)
+	early, late := start.Add(time.Minute), start.Add(2*time.Minute)
+
+	tests := []struct {
+		name string
+		a, b models.MatchParticipant
+		want float64
+	}{
+		{
+			name: "only a won",
+			a:    models.MatchParticipant{Result: "win", Score: 50, FinishedAt: &late},
+			b:    models.MatchParticipant{Result: "loss", Score: 80, FinishedAt: &early},
+			want: 1,
+		},
+		{
+			name: "only b won",
+			a:    models.MatchParticipant{Result: "loss", Score: 80},
+			b:    models.MatchParticipant{Result: "win", Score: 50},
+			want: 0,
+		},
+		{
+			name: "both won, a faster",
+			a:    models.MatchParticipant{Result: "win", Score: 100, FinishedAt: &early},
+			b:    models.MatchParticipant{Result: "win", Score: 100, FinishedAt: &late},
+			want: 1,
+		},
+		{
+			name: "both won, b faster",
+			a:    models.MatchParticipant{Result: "win", Score: 100, FinishedAt: &late},
+			b:    models.MatchParticipant{Result: "win", Score: 100, FinishedAt: &early},
+			want: 0,
+		},
+		{
+			name: "both won at the same time",
+			a:    models.MatchParticipant{Result: "win", Score: 100, FinishedAt: &early},
+			b:    models.MatchParticipant{Result: "win", Score: 100, FinishedAt: &early},
+			want: 0.5,
+		},
+		{
+			name: "neither won, higher test score wins",
+			a:    models.MatchParticipant{Result: "loss", Score: 40},
+			b:    models.MatchParticipant{Result: "loss", Score: 60},
+			want: 0,
+		},
+		{
+			name: "neither won with equal scores",
+			a:    models.MatchParticipant{Result: "draw"},
+			b:    models.MatchParticipant{Result: "draw"},
+			want: 0.5,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := duelScore(tt.a, tt.b); got != tt.want {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+			// The score is symmetric
+			if got := duelScore(tt.b, tt.a); got != 1-tt.want {
+				t.Errorf("Expected %v with the players swapped, got %v", 1-tt.want, got)
+			}
+		})
+	}
+}
@@ -12,22 +12,24 @@ import (
 )
 
 type ProgressService struct {
-	progressRepo *repositories.ProgressRepository
-	userRepo     *repositories.UserRepository
-	pathwayRepo  *repositories.PathwayRepository
-	exerciseRepo *repositories.ExerciseRepository
-	activityRepo *repositories.ActivityRepository
-	logger       *zap.Logger
+	progressRepo  *repositories.ProgressRepository
+	userRepo      *repositories.UserRepository
+	pathwayRepo   *repositories.PathwayRepository
+	exerciseRepo  *repositories.ExerciseRepository
+	activityRepo  *repositories.ActivityRepository
+	streakService *StreakService
+	logger        *zap.Logger
 }
 
-func NewProgressService(progressRepo *repositories.ProgressRepository, userRepo *repositories.UserRepository, pathwayRepo *repositories.PathwayRepository, exerciseRepo *repositories.ExerciseRepository, activityRepo *repositories.ActivityRepository, logger *zap.Logger) *ProgressService {
+func NewProgressService(progressRepo *repositories.ProgressRepository, userRepo *repositories.UserRepository, pathwayRepo *repositories.PathwayRepository, exerciseRepo *repositories.ExerciseRepository, activityRepo *repositories.ActivityRepository, streakService *StreakService, logger *zap.Logger) *ProgressService {
 	return &ProgressService{
-		progressRepo: progressRepo,
-		userRepo:     userRepo,
-		pathwayRepo:  pathwayRepo,
-		exerciseRepo: exerciseRepo,
-		activityRepo: activityRepo,
-		logger:       logger,
+		progressRepo:  progressRepo,
+		userRepo:      userRepo,
+		pathwayRepo:   pathwayRepo,
+		exerciseRepo:  exerciseRepo,
+		activityRepo:  activityRepo,
+		streakService: streakService,
+		logger:        logger,
 	}
 }
 
@@ -58,8 +60,12 @@ func (s *ProgressService) GetWeeklyHours(userID uuid.UUID) (*models.WeeklyHours,
 	return s.progressRepo.GetWeeklyHours(userID)
 }
 
-// RecordSubmissionActivity records activity from a submission (to be called by submission service)
-func (s *ProgressService) RecordSubmissionActivity(userID uuid.UUID, exerciseID uuid.UUID, xpEarned int, timeSpentMinutes int) error {
+// RecordSubmissionActivity records activity from a submission (to be called by submission service).
+// The XP itself is credited through the ledger by the submission service. Only submissions that
+// earn XP are recorded, so failed attempts and resubmissions of a solved exercise do not extend
+// the streak. Time on task is not counted here: the day's minutes come from focus and heartbeat
+// sessions, which ActivitySessionRepository.RecordEvent adds as they are tracked.
+func (s *ProgressService) RecordSubmissionActivity(userID uuid.UUID, exerciseID uuid.UUID, xpEarned int) error {
 	ctx := context.Background()
 
 	// Get exercise details for activity title
@@ -73,9 +79,8 @@ func (s *ProgressService) RecordSubmissionActivity(userID uuid.UUID, exerciseID
 		exercise = &models.Exercise{Title: "Unknown Exercise"}
 	}
 
-	// Record daily activity
-	today := time.Now().UTC().Truncate(24 * time.Hour)
-	err = s.progressRepo.RecordDailyActivity(userID, today, 1, xpEarned, timeSpentMinutes, 1, true)
+	// Record daily activity and extend the streak
+	err = s.streakService.RecordActivity(userID, time.Now(), 1, xpEarned, 0, 1)
 	if err != nil {
 		s.logger.Error("Failed to record daily activity",
 			zap.Error(err),
@@ -85,7 +90,7 @@ func (s *ProgressService) RecordSubmissionActivity(userID uuid.UUID, exerciseID
 	}
 
 	// Create activity record
-	err = s.activityRepo.CreateExerciseSubmissionActivity(ctx, userID, exerciseID, exercise.Title, xpEarned, timeSpentMinutes)
+	err = s.activityRepo.CreateExerciseSubmissionActivity(ctx, userID, exerciseID, exercise.Title, xpEarned, 0)
 	if err != nil {
 		s.logger.Error("Failed to create exercise submission activity",
 			zap.Error(err),
@@ -96,35 +101,59 @@ func (s *ProgressService) RecordSubmissionActivity(userID uuid.UUID, exerciseID
 		s.logger.Warn("Continuing despite activity recording failure")
 	}
 
-	// Update user's total XP
-	user, err := s.userRepo.FindByID(userID)
+	s.logger.Info("Submission activity recorded",
+		zap.String("user_id", userID.String()),
+		zap.String("exercise_id", exerciseID.String()),
+		zap.Int("xp_earned", xpEarned),
+	)
+
+	return nil
+}
+
+// RecordSolvedExercise updates the module progress of the author of an
+// accepted submission (registered as a graded submission listener), and
+// records the module and pathway it completes, if any
+func (s *ProgressService) RecordSolvedExercise(submission *models.Submission) {
+	if !submission.IsCorrect {
+		return
+	}
+	completion, err := s.progressRepo.RecordExerciseSolved(submission.UserID, submission.ExerciseID, time.Now())
 	if err != nil {
-		s.logger.Error("Failed to get user for XP update",
+		s.logger.Error("Failed to record module progress",
 			zap.Error(err),
-			zap.String("user_id", userID.String()),
+			zap.String("user_id", submission.UserID.String()),
+			zap.String("exercise_id", submission.ExerciseID.String()),
+		)
+		return
+	}
+	if completion == nil {
+		return
+	}
+
+	ctx := context.Background()
+	err = s.activityRepo.CreateModuleCompletionActivity(ctx, submission.UserID, completion.ModuleID, completion.ModuleTitle, completion.XPAwarded)
+	if err != nil {
+		s.logger.Error("Failed to create module completion activity",
+			zap.Error(err),
+			zap.String("user_id", submission.UserID.String()),
+			zap.String("module_id", completion.ModuleID.String()),
 		)
-		// Continue without XP update
-	} else {
-		user.TotalXP += xpEarned
-		err = s.userRepo.Update(user)
+	}
+	if completion.PathwayCompleted {
+		err = s.activityRepo.CreatePathwayCompletionActivity(ctx, submission.UserID, *completion.PathwayID, completion.PathwayTitle)
 		if err != nil {
-			s.logger.Error("Failed to update user XP",
+			s.logger.Error("Failed to create pathway completion activity",
 				zap.Error(err),
-				zap.String("user_id", userID.String()),
+				zap.String("user_id", submission.UserID.String()),
+				zap.String("pathway_id", completion.PathwayID.String()),
 			)
-			// Continue without XP update
 		}
 	}
 
-	// TODO: Update user_module_progress if exercise is part of a module
-	// TODO: Check and award milestones
-
-	s.logger.Info("Submission activity recorded",
-		zap.String("user_id", userID.String()),
-		zap.String("exercise_id", exerciseID.String()),
-		zap.Int("xp_earned", xpEarned),
-		zap.Int("time_spent_minutes", timeSpentMinutes),
+	s.logger.Info("Module completed",
+		zap.String("user_id", submission.UserID.String()),
+		zap.String("module_id", completion.ModuleID.String()),
+		zap.Int("xp_awarded", completion.XPAwarded),
+		zap.Bool("pathway_completed", completion.PathwayCompleted),
 	)
-
-	return nil
 }
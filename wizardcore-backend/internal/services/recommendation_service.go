@@ -0,0 +1,100 @@
+package services
+
+import (
+	"math/rand"
+
+	"github.com/google/uuid"
+	"github.com/yourusername/wizardcore-backend/internal/models"
+	"github.com/yourusername/wizardcore-backend/internal/recommend"
+	"github.com/yourusername/wizardcore-backend/internal/repositories"
+)
+
+// Random practice picks among this many of the best recommendations, so
+// repeated runs do not serve the same exercises
+const practicePickPool = 5
+
+// RecommendationService suggests what learners should practice next
+type RecommendationService struct {
+	recommendationRepo *repositories.RecommendationRepository
+	exerciseRepo       *repositories.ExerciseRepository
+}
+
+func NewRecommendationService(recommendationRepo *repositories.RecommendationRepository, exerciseRepo *repositories.ExerciseRepository) *RecommendationService {
+	return &RecommendationService{
+		recommendationRepo: recommendationRepo,
+		exerciseRepo:       exerciseRepo,
+	}
+}
+
+func (s *RecommendationService) rank(userID uuid.UUID, n int) ([]recommend.Recommendation, error) {
+	profile, err := s.recommendationRepo.GetProfile(userID)
+	if err != nil {
+		return nil, err
+	}
+	candidates, err := s.recommendationRepo.ListCandidates(userID)
+	if err != nil {
+		return nil, err
+	}
+	return recommend.Rank(profile, candidates, n), nil
+}
+
+// Recommend returns the best next exercises for a learner, best first
+func (s *RecommendationService) Recommend(userID uuid.UUID, limit int) ([]models.ExerciseRecommendation, error) {
+	ranked, err := s.rank(userID, limit)
+	if err != nil {
+		return nil, err
+	}
+	recommendations := make([]models.ExerciseRecommendation, len(ranked))
+	for i, r := range ranked {
+		rec := models.ExerciseRecommendation{
+			ExerciseID: r.ExerciseID,
+			Title:      r.Title,
+			Difficulty: r.Difficulty,
+			Tags:       r.Tags,
+			Score:      r.Score,
+			Reasons:    r.Reasons,
+		}
+		if rec.Tags == nil {
+			rec.Tags = []string{}
+		}
+		if r.ModuleTitle != "" {
+			rec.ModuleTitle = &r.ModuleTitle
+		}
+		if r.PathwayTitle != "" {
+			rec.PathwayTitle = &r.PathwayTitle
+		}
+		if len(r.Reasons) > 0 {
+			rec.Reason = r.Reasons[0]
+		} else {
+			rec.Reason = "Something new to practice"
+			rec.Reasons = []string{rec.Reason}
+		}
+		recommendations[i] = rec
+	}
+	return recommendations, nil
+}
+
+// PickPracticeExercise picks an exercise for a learner to practice, other
+// than the given ones, from their best recommendations. It falls back to a
+// random exercise when there is nothing to recommend, and returns nil if
+// there are no exercises left.
+func (s *RecommendationService) PickPracticeExercise(userID uuid.UUID, exclude []uuid.UUID) (*models.Exercise, error) {
+	excluded := make(map[uuid.UUID]bool, len(exclude))
+	for _, id := range exclude {
+		excluded[id] = true
+	}
+	ranked, err := s.rank(userID, practicePickPool+len(exclude))
+	if err != nil {
+		return nil, err
+	}
+	var pool []uuid.UUID
+	for _, r := range ranked {
+		if !excluded[r.ExerciseID] && len(pool) < practicePickPool {
+			pool = append(pool, r.ExerciseID)
+		}
+	}
+	if len(pool) == 0 {
+		return s.exerciseRepo.GetRandomExerciseExcluding(exclude)
+	}
+	return s.exerciseRepo.FindByID(pool[rand.Intn(len(pool))])
+}
@@ -0,0 +1,416 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yourusername/wizardcore-backend/internal/collab"
+	"github.com/yourusername/wizardcore-backend/internal/models"
+	"github.com/yourusername/wizardcore-backend/internal/repositories"
+	"github.com/yourusername/wizardcore-backend/internal/websocket"
+	"go.uber.org/zap"
+)
+
+const (
+	// Minimum time between stored snapshots of a participant. Updates in
+	// between are coalesced and only the latest is stored.
+	replaySnapshotInterval = 2 * time.Second
+	// Snapshots stored as deltas between two keyframes
+	replayKeyframeInterval = 20
+	// Largest code update that is recorded
+	maxReplayCodeBytes = 64 * 1024
+	// Recorders that saw no update for this long are dropped
+	replayIdleTimeout = 2 * time.Hour
+	// How often replays past their retention are deleted
+	replayPurgeInterval = time.Hour
+)
+
+type replayKey struct {
+	matchID uuid.UUID
+	userID  uuid.UUID
+}
+
+// replayRecorder holds what is needed to append a participant's next
+// snapshot: the last stored code to diff against and the latest update not
+// stored yet
+type replayRecorder struct {
+	mu            sync.Mutex
+	key           replayKey
+	startedAt     time.Time
+	recording     bool
+	code          string
+	languageID    int
+	snapshots     int
+	sinceKeyframe int
+	storedAt      time.Time
+	seenAt        time.Time
+	pending       *replaySnapshot
+}
+
+type replaySnapshot struct {
+	code       string
+	languageID int
+	at         time.Time
+}
+
+// ReplayService records code_update frames and match submissions into a
+// timeline that can be replayed after the match
+type ReplayService struct {
+	replayRepo   *repositories.ReplayRepository
+	matchRepo    *repositories.MatchRepository
+	hub          *websocket.Hub
+	retention    time.Duration
+	maxSnapshots int
+
+	recordersMu sync.Mutex
+	recorders   map[replayKey]*replayRecorder
+	logger      *zap.Logger
+}
+
+// NewReplayService creates the service. Replays are deleted after retention
+// and at most maxSnapshots snapshots are kept per participant and match.
+func NewReplayService(replayRepo *repositories.ReplayRepository, matchRepo *repositories.MatchRepository, practiceService *PracticeService, hub *websocket.Hub, retention time.Duration, maxSnapshots int, logger *zap.Logger) *ReplayService {
+	s := &ReplayService{
+		replayRepo:   replayRepo,
+		matchRepo:    matchRepo,
+		hub:          hub,
+		retention:    retention,
+		maxSnapshots: maxSnapshots,
+		recorders:    make(map[replayKey]*replayRecorder),
+		logger:       logger,
+	}
+	practiceService.OnMatchSubmission(s.recordSubmission)
+	practiceService.OnMatchEnd(s.onMatchEnd)
+	return s
+}
+
+// RegisterWebSocketHandlers routes code updates from the hub to the service.
+// Code updates are recorded and no longer broadcast to every client.
+func (s *ReplayService) RegisterWebSocketHandlers() {
+	s.hub.Handle(websocket.CodeUpdate, s.handleCodeUpdate)
+}
+
+func (s *ReplayService) handleCodeUpdate(client *websocket.Client, msg websocket.Message) {
+	var payload websocket.CodeUpdatePayload
+	if err := json.Unmarshal(msg.Payload, &payload); err != nil {
+		client.SendError("invalid code_update payload")
+		return
+	}
+	matchID, err := uuid.Parse(payload.MatchID)
+	if err != nil {
+		client.SendError("invalid match ID")
+		return
+	}
+	if len(payload.Code) > maxReplayCodeBytes {
+		client.SendError("code is too large to record")
+		return
+	}
+	s.recordSnapshot(matchID, client.UserID(), payload.Code, payload.LanguageID, time.Now())
+}
+
+// recorder returns the recorder of a participant, loading the match the
+// first time. Users who are not playing the match get a recorder that does
+// not record, so repeated updates don't hit the database.
+func (s *ReplayService) recorder(matchID, userID uuid.UUID) (*replayRecorder, error) {
+	key := replayKey{matchID: matchID, userID: userID}
+	s.recordersMu.Lock()
+	rec, ok := s.recorders[key]
+	s.recordersMu.Unlock()
+	if ok {
+		return rec, nil
+	}
+
+	rec = &replayRecorder{key: key}
+	match, err := s.matchRepo.GetMatchByID(matchID)
+	if err != nil {
+		return nil, err
+	}
+	if match != nil && match.Status == "active" && match.StartedAt != nil {
+		participant, err := s.matchRepo.GetParticipantByMatchAndUser(matchID, userID)
+		if err != nil {
+			return nil, err
+		}
+		if participant != nil && participant.FinishedAt == nil {
+			count, err := s.replayRepo.CountSnapshots(matchID, userID)
+			if err != nil {
+				return nil, err
+			}
+			rec.recording = true
+			rec.startedAt = *match.StartedAt
+			rec.snapshots = count
+		}
+	}
+
+	s.recordersMu.Lock()
+	defer s.recordersMu.Unlock()
+	if existing, ok := s.recorders[key]; ok {
+		return existing, nil
+	}
+	s.recorders[key] = rec
+	return rec, nil
+}
+
+// recordSnapshot stores a code update unless it repeats the latest one or
+// comes too soon after the last stored snapshot, in which case it is kept
+// until the interval has passed
+func (s *ReplayService) recordSnapshot(matchID, userID uuid.UUID, code string, languageID int, now time.Time) {
+	rec, err := s.recorder(matchID, userID)
+	if err != nil {
+		s.logger.Error("Failed to load replay recorder", zap.Error(err), zap.String("match_id", matchID.String()))
+		return
+	}
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+
+	rec.seenAt = now
+	if !rec.recording {
+		return
+	}
+	latest, latestLanguage := rec.code, rec.languageID
+	if rec.pending != nil {
+		latest, latestLanguage = rec.pending.code, rec.pending.languageID
+	}
+	if code == latest && languageID == latestLanguage {
+		return
+	}
+	if now.Sub(rec.storedAt) < replaySnapshotInterval {
+		rec.pending = &replaySnapshot{code: code, languageID: languageID, at: now}
+		return
+	}
+	rec.pending = nil
+	s.storeSnapshot(rec, replaySnapshot{code: code, languageID: languageID, at: now})
+}
+
+// storeSnapshot appends a snapshot as a keyframe or as a delta against the
+// previous one. rec must be locked.
+func (s *ReplayService) storeSnapshot(rec *replayRecorder, snap replaySnapshot) {
+	if rec.snapshots >= s.maxSnapshots {
+		return
+	}
+	event := &models.ReplayEvent{
+		MatchID:    rec.key.matchID,
+		UserID:     rec.key.userID,
+		EventType:  "snapshot",
+		OffsetMs:   replayOffset(rec.startedAt, snap.at),
+		LanguageID: intPtr(snap.languageID),
+	}
+	keyframe := rec.sinceKeyframe == 0 || rec.sinceKeyframe >= replayKeyframeInterval || snap.languageID != rec.languageID
+	if keyframe {
+		code := snap.code
+		event.Code = &code
+	} else {
+		event.Operations = collab.Diff(rec.code, snap.code)
+	}
+	if err := s.replayRepo.AddEvent(event); err != nil {
+		s.logger.Error("Failed to record replay snapshot", zap.Error(err), zap.String("match_id", rec.key.matchID.String()))
+		return
+	}
+	if keyframe {
+		rec.sinceKeyframe = 0
+	}
+	rec.code = snap.code
+	rec.languageID = snap.languageID
+	rec.snapshots++
+	rec.sinceKeyframe++
+	rec.storedAt = snap.at
+}
+
+// flush stores the pending snapshot if it is due, or now if force is set.
+// rec must be locked.
+func (s *ReplayService) flush(rec *replayRecorder, now time.Time, force bool) {
+	if rec.pending == nil || (!force && now.Sub(rec.storedAt) < replaySnapshotInterval) {
+		return
+	}
+	snap := *rec.pending
+	rec.pending = nil
+	s.storeSnapshot(rec, snap)
+}
+
+// recordSubmission adds a graded submission to the timeline, after the code
+// the participant had typed before submitting
+func (s *ReplayService) recordSubmission(match *models.PracticeMatch, userID, exerciseID uuid.UUID, submissionID *uuid.UUID, score int, result string) {
+	if match.StartedAt == nil {
+		return
+	}
+	rec, err := s.recorder(match.ID, userID)
+	if err != nil {
+		s.logger.Error("Failed to load replay recorder", zap.Error(err), zap.String("match_id", match.ID.String()))
+		return
+	}
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+
+	s.flush(rec, time.Now(), true)
+	event := &models.ReplayEvent{
+		MatchID:      match.ID,
+		UserID:       userID,
+		EventType:    "submission",
+		OffsetMs:     replayOffset(*match.StartedAt, time.Now()),
+		ExerciseID:   &exerciseID,
+		SubmissionID: submissionID,
+		Result:       &result,
+		Score:        &score,
+	}
+	if err := s.replayRepo.AddEvent(event); err != nil {
+		s.logger.Error("Failed to record replay submission", zap.Error(err), zap.String("match_id", match.ID.String()))
+	}
+	// A submission ends the participant's part of a single-exercise match
+	if !isRunMode(match.MatchType) {
+		rec.recording = false
+	}
+}
+
+// onMatchEnd stores what is still pending for the match and drops its
+// recorders
+func (s *ReplayService) onMatchEnd(match *models.PracticeMatch, participants []models.MatchParticipant) {
+	s.recordersMu.Lock()
+	var ended []*replayRecorder
+	for key, rec := range s.recorders {
+		if key.matchID == match.ID {
+			ended = append(ended, rec)
+			delete(s.recorders, key)
+		}
+	}
+	s.recordersMu.Unlock()
+
+	now := time.Now()
+	for _, rec := range ended {
+		rec.mu.Lock()
+		s.flush(rec, now, true)
+		rec.recording = false
+		rec.mu.Unlock()
+	}
+}
+
+// Run stores coalesced snapshots once they are due and deletes replays past
+// their retention
+func (s *ReplayService) Run() {
+	flushTicker := time.NewTicker(replaySnapshotInterval)
+	defer flushTicker.Stop()
+	purgeTicker := time.NewTicker(replayPurgeInterval)
+	defer purgeTicker.Stop()
+
+	s.purge()
+	for {
+		select {
+		case now := <-flushTicker.C:
+			s.flushDue(now)
+		case <-purgeTicker.C:
+			s.purge()
+		}
+	}
+}
+
+// flushDue stores pending snapshots that are due and drops idle recorders
+func (s *ReplayService) flushDue(now time.Time) {
+	s.recordersMu.Lock()
+	recorders := make([]*replayRecorder, 0, len(s.recorders))
+	for _, rec := range s.recorders {
+		recorders = append(recorders, rec)
+	}
+	s.recordersMu.Unlock()
+
+	var idle []replayKey
+	for _, rec := range recorders {
+		rec.mu.Lock()
+		s.flush(rec, now, false)
+		if rec.pending == nil && now.Sub(rec.seenAt) > replayIdleTimeout {
+			idle = append(idle, rec.key)
+		}
+		rec.mu.Unlock()
+	}
+
+	if len(idle) > 0 {
+		s.recordersMu.Lock()
+		for _, key := range idle {
+			delete(s.recorders, key)
+		}
+		s.recordersMu.Unlock()
+	}
+}
+
+func (s *ReplayService) purge() {
+	if s.retention <= 0 {
+		return
+	}
+	if _, err := s.replayRepo.DeleteBefore(time.Now().Add(-s.retention)); err != nil {
+		s.logger.Error("Failed to purge match replays", zap.Error(err))
+	}
+}
+
+// GetReplay returns the timeline of an ended match. Participants can always
+// watch it; others only if no participant keeps it private. It returns nil
+// if the match does not exist or the user may not see it.
+func (s *ReplayService) GetReplay(matchID, userID uuid.UUID) (*models.MatchReplay, error) {
+	match, err := s.matchRepo.GetMatchByID(matchID)
+	if err != nil {
+		return nil, err
+	}
+	if match == nil {
+		return nil, nil
+	}
+	participants, err := s.matchRepo.GetParticipantsByMatchID(matchID)
+	if err != nil {
+		return nil, err
+	}
+	private, err := s.replayRepo.IsPrivate(matchID)
+	if err != nil {
+		return nil, err
+	}
+	isParticipant := false
+	for _, p := range participants {
+		if p.UserID == userID {
+			isParticipant = true
+			break
+		}
+	}
+	if private && !isParticipant {
+		return nil, nil
+	}
+	if match.Status != "completed" && match.Status != "cancelled" {
+		return nil, fmt.Errorf("replay is available once the match has ended")
+	}
+
+	events, err := s.replayRepo.GetEvents(matchID)
+	if err != nil {
+		return nil, err
+	}
+	replay := &models.MatchReplay{
+		Match:        match,
+		Participants: participants,
+		IsPrivate:    private,
+		Events:       events,
+	}
+	if match.StartedAt != nil && match.EndedAt != nil {
+		replay.DurationMs = replayOffset(*match.StartedAt, *match.EndedAt)
+	}
+	if n := len(events); n > 0 && events[n-1].OffsetMs > replay.DurationMs {
+		replay.DurationMs = events[n-1].OffsetMs
+	}
+	return replay, nil
+}
+
+// SetPrivate sets whether the user keeps the replay of a match to its
+// participants
+func (s *ReplayService) SetPrivate(matchID, userID uuid.UUID, private bool) error {
+	ok, err := s.replayRepo.SetPrivate(matchID, userID, private)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("match not found")
+	}
+	return nil
+}
+
+// replayOffset returns the milliseconds from start to at, never negative
+func replayOffset(start, at time.Time) int {
+	offset := at.Sub(start).Milliseconds()
+	if offset < 0 {
+		return 0
+	}
+	return int(offset)
+}
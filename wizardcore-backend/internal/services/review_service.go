@@ -0,0 +1,68 @@
+package services
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yourusername/wizardcore-backend/internal/models"
+	"github.com/yourusername/wizardcore-backend/internal/repositories"
+	"go.uber.org/zap"
+)
+
+// ReviewService schedules spaced-repetition reviews of the exercises users
+// have solved. A review is completed with a new submission on the exercise
+// and the user's rating of how hard it was.
+type ReviewService struct {
+	reviewRepo    *repositories.ReviewRepository
+	streakService *StreakService
+	logger        *zap.Logger
+}
+
+func NewReviewService(reviewRepo *repositories.ReviewRepository, streakService *StreakService, logger *zap.Logger) *ReviewService {
+	return &ReviewService{
+		reviewRepo:    reviewRepo,
+		streakService: streakService,
+		logger:        logger,
+	}
+}
+
+// RecordSubmission schedules reviews of the exercise of an accepted
+// submission (registered as a graded submission listener)
+func (s *ReviewService) RecordSubmission(submission *models.Submission) {
+	if !submission.IsCorrect {
+		return
+	}
+	if err := s.reviewRepo.EnsureItem(submission.UserID, submission.ExerciseID, time.Now()); err != nil {
+		s.logger.Error("Failed to schedule review", zap.Error(err), zap.String("submission_id", submission.ID.String()))
+	}
+}
+
+// GetQueue returns a page of the reviews the user has due today
+func (s *ReviewService) GetQueue(userID uuid.UUID, limit, offset int) (*models.ReviewQueue, error) {
+	items, today, total, err := s.reviewRepo.ListDue(userID, time.Now(), limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	return &models.ReviewQueue{Date: today.Format("2006-01-02"), Items: items, TotalDue: total}, nil
+}
+
+// CompleteReview completes the user's due review of an exercise and counts
+// it as activity for the day. It returns nil if the user has no review of
+// the exercise.
+func (s *ReviewService) CompleteReview(userID, exerciseID uuid.UUID, req *models.CompleteReviewRequest) (*models.ReviewResult, error) {
+	now := time.Now()
+	result, err := s.reviewRepo.CompleteReview(userID, exerciseID, req.SubmissionID, req.Rating, now)
+	if err != nil || result == nil {
+		return nil, err
+	}
+	completed := 0
+	if result.Correct {
+		completed = 1
+	}
+	if s.streakService != nil {
+		if err := s.streakService.RecordActivity(userID, now, completed, result.XPAwarded, 0, 1); err != nil {
+			s.logger.Error("Failed to record review activity", zap.Error(err), zap.String("user_id", userID.String()))
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,171 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yourusername/wizardcore-backend/internal/models"
+	"github.com/yourusername/wizardcore-backend/internal/repositories"
+	"github.com/yourusername/wizardcore-backend/internal/streak"
+	"go.uber.org/zap"
+)
+
+// How often the streak job runs. Users' days end at different times, so it
+// runs through the day rather than once at midnight UTC.
+const streakSweepInterval = time.Hour
+
+// StreakService keeps daily activity streaks, counted in each user's
+// timezone. Activity extends a streak as it is recorded; the streak job
+// spends freezes on missed days, resets streaks that ran out of them and
+// warns users whose streak is at risk.
+type StreakService struct {
+	progressRepo        *repositories.ProgressRepository
+	streakRepo          *repositories.StreakRepository
+	activityRepo        *repositories.ActivityRepository
+	notificationService *NotificationService
+	logger              *zap.Logger
+}
+
+func NewStreakService(progressRepo *repositories.ProgressRepository, streakRepo *repositories.StreakRepository, activityRepo *repositories.ActivityRepository, notificationService *NotificationService, logger *zap.Logger) *StreakService {
+	return &StreakService{
+		progressRepo:        progressRepo,
+		streakRepo:          streakRepo,
+		activityRepo:        activityRepo,
+		notificationService: notificationService,
+		logger:              logger,
+	}
+}
+
+// Run settles streaks periodically until the process exits
+func (s *StreakService) Run() {
+	s.sweep(time.Now())
+	ticker := time.NewTicker(streakSweepInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		s.sweep(time.Now())
+	}
+}
+
+func (s *StreakService) sweep(now time.Time) {
+	streaks, err := s.streakRepo.ListActiveStreaks()
+	if err != nil {
+		s.logger.Error("Failed to list streaks", zap.Error(err))
+		return
+	}
+	for i := range streaks {
+		stored := &streaks[i]
+		loc := streak.Location(stored.Timezone)
+		today := streak.Day(now, loc)
+		state := repositories.StreakState(stored)
+		if stored.LastDay != nil && stored.LastDay.AddDate(0, 0, 1).Before(today) {
+			var change streak.Change
+			state, change, err = s.streakRepo.Settle(stored.UserID, today)
+			if err != nil {
+				s.logger.Error("Failed to settle streak", zap.Error(err), zap.String("user_id", stored.UserID.String()))
+				continue
+			}
+			s.notifyChange(stored.UserID, state, change)
+			if change.Broken {
+				s.notify(stored.UserID, "streak_lost", "Streak lost",
+					fmt.Sprintf("Your %d day streak has ended. Practice today to start a new one.", stored.CurrentStreak))
+			}
+		}
+		if streak.AtRisk(state, now, loc) && (stored.RiskNotifiedOn == nil || stored.RiskNotifiedOn.Before(today)) {
+			s.notifyAtRisk(stored.UserID, state)
+			if err := s.streakRepo.MarkRiskNotified(stored.UserID, today); err != nil {
+				s.logger.Error("Failed to mark streak warning", zap.Error(err), zap.String("user_id", stored.UserID.String()))
+			}
+		}
+	}
+}
+
+// RecordActivity adds activity to the user's day and extends their streak.
+// Milestones are added to the activity feed.
+func (s *StreakService) RecordActivity(userID uuid.UUID, at time.Time, exercisesCompleted, xpEarned, timeSpentMinutes, submissionsCount int) error {
+	state, change, err := s.progressRepo.RecordDailyActivity(userID, at, exercisesCompleted, xpEarned, timeSpentMinutes, submissionsCount)
+	if err != nil {
+		return err
+	}
+	if change.Extended && streak.Milestone(state.Current) {
+		if err := s.activityRepo.CreateStreakActivity(context.Background(), userID, state.Current); err != nil {
+			s.logger.Error("Failed to record streak activity", zap.Error(err), zap.String("user_id", userID.String()))
+		}
+	}
+	s.notifyChange(userID, state, change)
+	return nil
+}
+
+// GetStatus returns the user's streak as of now, or nil if there is no
+// such user
+func (s *StreakService) GetStatus(userID uuid.UUID) (*models.StreakStatus, error) {
+	stored, err := s.streakRepo.GetStreak(userID)
+	if err != nil || stored == nil {
+		return nil, err
+	}
+	now := time.Now()
+	loc := streak.Location(stored.Timezone)
+	today := streak.Day(now, loc)
+	// Settle a copy so a streak the job has not reached yet shows as it is
+	state := repositories.StreakState(stored)
+	streak.Settle(&state, today)
+
+	status := &models.StreakStatus{
+		CurrentStreak:    state.Current,
+		LongestStreak:    state.Longest,
+		FreezesAvailable: state.Freezes,
+		MaxFreezes:       streak.MaxFreezes,
+		Timezone:         loc.String(),
+		AtRisk:           streak.AtRisk(state, now, loc),
+		NextFreezeIn:     streak.FreezeEvery - state.Current%streak.FreezeEvery,
+	}
+	if stored.LastActivityDate != nil {
+		date := stored.LastActivityDate.Format("2006-01-02")
+		status.LastActiveDate = &date
+		status.ActiveToday = stored.LastActivityDate.Equal(today)
+	}
+	return status, nil
+}
+
+func (s *StreakService) notifyChange(userID uuid.UUID, state streak.State, change streak.Change) {
+	if change.FreezesUsed > 0 {
+		title := "Streak freeze used"
+		if change.FreezesUsed > 1 {
+			title = fmt.Sprintf("%d streak freezes used", change.FreezesUsed)
+		}
+		s.notify(userID, "streak_freeze_used", title,
+			fmt.Sprintf("Your %d day streak is safe. You have %d freezes left.", state.Current, state.Freezes))
+	}
+	if change.FreezeEarned {
+		s.notify(userID, "streak_freeze_earned", "Streak freeze earned",
+			fmt.Sprintf("%d days in a row! A freeze will cover a day you miss.", state.Current))
+	}
+}
+
+func (s *StreakService) notifyAtRisk(userID uuid.UUID, state streak.State) {
+	message := fmt.Sprintf("Practice today to keep your %d day streak.", state.Current)
+	if state.Freezes > 0 {
+		message = fmt.Sprintf("Practice today to keep your %d day streak without spending a freeze.", state.Current)
+	}
+	s.notify(userID, "streak_at_risk", "Your streak is at risk", message)
+}
+
+func (s *StreakService) notify(userID uuid.UUID, notificationType, title, message string) {
+	if s.notificationService == nil {
+		return
+	}
+	icon := "🔥"
+	actionURL := "/dashboard"
+	err := s.notificationService.CreateNotification(&models.Notification{
+		UserID:    userID,
+		Type:      notificationType,
+		Title:     title,
+		Message:   &message,
+		Icon:      &icon,
+		ActionURL: &actionURL,
+	})
+	if err != nil {
+		s.logger.Error("Failed to send streak notification", zap.Error(err), zap.String("user_id", userID.String()), zap.String("type", notificationType))
+	}
+}
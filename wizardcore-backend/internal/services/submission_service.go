@@ -8,46 +8,72 @@ import (
 	"github.com/yourusername/wizardcore-backend/internal/models"
 	"github.com/yourusername/wizardcore-backend/internal/repositories"
 	"github.com/yourusername/wizardcore-backend/pkg/judge0"
+	"go.uber.org/zap"
 )
 
+// SubmissionListener is called for every graded submission once its author
+// has been credited
+type SubmissionListener func(submission *models.Submission)
+
 type SubmissionService struct {
 	submissionRepo  *repositories.SubmissionRepository
 	exerciseRepo    *repositories.ExerciseRepository
 	userRepo        *repositories.UserRepository
+	xpRepo          *repositories.XPRepository
 	judge0Client    *judge0.Client
 	practiceService *PracticeService
 	progressService *ProgressService
+	listeners       []SubmissionListener
+	logger          *zap.Logger
 }
 
-func NewSubmissionService(submissionRepo *repositories.SubmissionRepository, exerciseRepo *repositories.ExerciseRepository, userRepo *repositories.UserRepository, judge0Client *judge0.Client, practiceService *PracticeService, progressService *ProgressService) *SubmissionService {
+func NewSubmissionService(submissionRepo *repositories.SubmissionRepository, exerciseRepo *repositories.ExerciseRepository, userRepo *repositories.UserRepository, xpRepo *repositories.XPRepository, judge0Client *judge0.Client, practiceService *PracticeService, progressService *ProgressService, logger *zap.Logger) *SubmissionService {
 	return &SubmissionService{
 		submissionRepo:  submissionRepo,
 		exerciseRepo:    exerciseRepo,
 		userRepo:        userRepo,
+		xpRepo:          xpRepo,
 		judge0Client:    judge0Client,
 		practiceService: practiceService,
 		progressService: progressService,
+		logger:          logger,
 	}
 }
 
+// OnGraded registers a listener for graded submissions. Listeners must be
+// registered before the service starts handling requests.
+func (s *SubmissionService) OnGraded(listener SubmissionListener) {
+	s.listeners = append(s.listeners, listener)
+}
+
 func (s *SubmissionService) CreateSubmission(submission *models.Submission) error {
 	return s.CreateSubmissionWithMatch(submission, nil)
 }
 
 func (s *SubmissionService) CreateSubmissionWithMatch(submission *models.Submission, matchID *uuid.UUID) error {
+	_, err := s.grade(submission, matchID)
+	return err
+}
+
+// grade runs a submission against the exercise's test cases, stores the
+// result and credits its author. It returns the score the code earned;
+// the submission's PointsEarned is only the part of it the author had not
+// already been credited for on the exercise, so re-submitting a solution
+// does not pay again.
+func (s *SubmissionService) grade(submission *models.Submission, matchID *uuid.UUID) (int, error) {
 	// Fetch exercise to get test cases
 	exercise, err := s.exerciseRepo.FindByID(submission.ExerciseID)
 	if err != nil {
-		return fmt.Errorf("failed to fetch exercise: %w", err)
+		return 0, fmt.Errorf("failed to fetch exercise: %w", err)
 	}
 	if exercise == nil {
-		return fmt.Errorf("exercise not found")
+		return 0, fmt.Errorf("exercise not found")
 	}
 
 	// Fetch test cases
 	testCases, err := s.exerciseRepo.FindTestCases(submission.ExerciseID)
 	if err != nil {
-		return fmt.Errorf("failed to fetch test cases: %w", err)
+		return 0, fmt.Errorf("failed to fetch test cases: %w", err)
 	}
 
 	// Prepare submission fields
@@ -59,7 +85,7 @@ func (s *SubmissionService) CreateSubmissionWithMatch(submission *models.Submiss
 
 	// Create submission record (pending)
 	if err := s.submissionRepo.Create(submission); err != nil {
-		return fmt.Errorf("failed to create submission record: %w", err)
+		return 0, fmt.Errorf("failed to create submission record: %w", err)
 	}
 
 	// Evaluate each test case
@@ -91,7 +117,7 @@ func (s *SubmissionService) CreateSubmissionWithMatch(submission *models.Submiss
 			submission.Stderr = &errMsg
 			// Update submission with error
 			s.submissionRepo.Update(submission)
-			return fmt.Errorf("judge0 submission failed: %w", err)
+			return 0, fmt.Errorf("judge0 submission failed: %w", err)
 		}
 
 		// Determine if test passed
@@ -118,66 +144,95 @@ func (s *SubmissionService) CreateSubmissionWithMatch(submission *models.Submiss
 	} else {
 		submission.Status = "wrong_answer"
 	}
-	submission.PointsEarned = totalPoints
+	// Points are credited with the XP once the graded submission is stored
+	submission.PointsEarned = 0
 
 	// Update submission in DB
 	if err := s.submissionRepo.Update(submission); err != nil {
-		return fmt.Errorf("failed to update submission: %w", err)
+		return 0, fmt.Errorf("failed to update submission: %w", err)
 	}
 
-	// Update exercise stats
-	s.exerciseRepo.UpdateStats(
-		submission.ExerciseID,
-		exercise.ConcurrentSolvers,
-		exercise.TotalSubmissions+1,
-		exercise.TotalCompletions,
-		exercise.AvgCompletionTime,
-	)
-
-	// Update user XP (simplified)
-	user, err := s.userRepo.FindByID(submission.UserID)
-	if err != nil {
-		// Log error but continue
-		fmt.Printf("failed to fetch user for XP update: %v\n", err)
-	} else if user != nil {
-		user.TotalXP += submission.PointsEarned
-		if err := s.userRepo.Update(user); err != nil {
-			fmt.Printf("failed to update user XP: %v\n", err)
+	// Update exercise stats. Completions and solve times are counted by the
+	// activity session listener.
+	if err := s.exerciseRepo.IncrementSubmissions(submission.ExerciseID); err != nil {
+		s.logger.Error("Failed to update exercise stats", zap.Error(err), zap.String("exercise_id", submission.ExerciseID.String()))
+	}
+
+	s.creditSubmission(submission, totalPoints)
+
+	// If matchID is provided, record match result
+	if matchID != nil && s.practiceService != nil {
+		result := "loss"
+		if submission.IsCorrect {
+			result = "win"
+		}
+		err = s.practiceService.RecordMatchResult(*matchID, submission.UserID, totalPoints, result, submission.PointsEarned, &submission.ID, submission.ExerciseID)
+		if err != nil {
+			// Log error but don't fail the submission
+			s.logger.Error("Failed to record match result", zap.Error(err), zap.String("match_id", matchID.String()), zap.String("submission_id", submission.ID.String()))
 		}
 	}
 
-	// Record submission activity for progress tracking
-	if s.progressService != nil && submission.PointsEarned > 0 {
-		// Estimate time spent - in a real app, this would come from the frontend
-		// or we'd calculate it based on submission timestamps
-		estimatedTimeMinutes := 5 // Default estimate
+	return totalPoints, nil
+}
+
+// creditSubmission credits the author with the part of score they have not
+// yet earned on the exercise, through the XP ledger, records the activity for
+// progress tracking and calls the graded submission listeners. Failures are
+// logged, not returned; a submission that could not be credited earns
+// nothing rather than risk paying twice.
+func (s *SubmissionService) creditSubmission(submission *models.Submission, score int) {
+	if _, err := s.xpRepo.AwardSubmission(submission, score); err != nil {
+		s.logger.Error("Failed to award submission XP", zap.Error(err), zap.String("submission_id", submission.ID.String()))
+		submission.PointsEarned = 0
+	}
 
-		err = s.progressService.RecordSubmissionActivity(
+	// Record submission activity for progress tracking. Submissions that earn
+	// nothing are not activity for the day.
+	if s.progressService != nil && submission.PointsEarned > 0 {
+		err := s.progressService.RecordSubmissionActivity(
 			submission.UserID,
 			submission.ExerciseID,
 			submission.PointsEarned,
-			estimatedTimeMinutes,
 		)
 		if err != nil {
 			// Log error but don't fail the submission
-			fmt.Printf("failed to record submission activity: %v\n", err)
+			s.logger.Error("Failed to record submission activity", zap.Error(err), zap.String("submission_id", submission.ID.String()))
 		}
 	}
 
-	// If matchID is provided, record match result
-	if matchID != nil && s.practiceService != nil {
-		result := "loss"
-		if submission.IsCorrect {
-			result = "win"
+	for _, listener := range s.listeners {
+		listener(submission)
+	}
+}
+
+// CreateJointSubmission grades code written together by several users. The
+// submission is graded once for its author; each partner then receives a
+// copy of the graded result, credited with whatever part of the score they
+// had not already earned on the exercise.
+func (s *SubmissionService) CreateJointSubmission(submission *models.Submission, partnerIDs []uuid.UUID) ([]models.Submission, error) {
+	score, err := s.grade(submission, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	submissions := []models.Submission{*submission}
+	for _, partnerID := range partnerIDs {
+		if partnerID == submission.UserID {
+			continue
 		}
-		err = s.practiceService.RecordMatchResult(*matchID, submission.UserID, submission.PointsEarned, result, submission.PointsEarned, &submission.ID)
-		if err != nil {
-			// Log error but don't fail the submission
-			fmt.Printf("failed to record match result: %v\n", err)
+		copied := *submission
+		copied.ID = uuid.New()
+		copied.UserID = partnerID
+		copied.SubmissionType = models.SubmissionTypeJointCopy
+		copied.PointsEarned = 0
+		if err := s.submissionRepo.Create(&copied); err != nil {
+			return submissions, fmt.Errorf("failed to create partner submission: %w", err)
 		}
+		s.creditSubmission(&copied, score)
+		submissions = append(submissions, copied)
 	}
-
-	return nil
+	return submissions, nil
 }
 
 func (s *SubmissionService) GetSubmissionByID(id uuid.UUID) (*models.Submission, error) {
@@ -0,0 +1,578 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yourusername/wizardcore-backend/internal/models"
+	"github.com/yourusername/wizardcore-backend/internal/repositories"
+	"github.com/yourusername/wizardcore-backend/internal/websocket"
+	"go.uber.org/zap"
+)
+
+const (
+	minTeamSize = 2
+	maxTeamSize = 5
+
+	// Time limit of a team match when the challenge type sets none
+	teamDuelTimeLimitMinutes = 20
+)
+
+// TeamMatchService runs team duels. Players form a party with an invite
+// code, the leader queues the full party and it is paired with another
+// queued party of the same size and scoring. Both teams play the same set of
+// exercises, one per team member; each member scores their best result on
+// every exercise and the team scores the best or the sum of its members.
+// Every member gets the result of their team.
+//
+// Parties, teams and matches each have a hub channel ("party:<id>",
+// "team:<id>", "match:<id>") that their members can join for chat; live
+// team scores are published on the match channel.
+type TeamMatchService struct {
+	teamRepo        *repositories.TeamMatchRepository
+	matchRepo       *repositories.MatchRepository
+	exerciseRepo    *repositories.ExerciseRepository
+	practiceService *PracticeService
+	hub             *websocket.Hub
+
+	locksMu sync.Mutex
+	locks   map[uuid.UUID]*sync.Mutex
+	logger  *zap.Logger
+}
+
+func NewTeamMatchService(teamRepo *repositories.TeamMatchRepository, matchRepo *repositories.MatchRepository, exerciseRepo *repositories.ExerciseRepository, practiceService *PracticeService, hub *websocket.Hub, logger *zap.Logger) *TeamMatchService {
+	s := &TeamMatchService{
+		teamRepo:        teamRepo,
+		matchRepo:       matchRepo,
+		exerciseRepo:    exerciseRepo,
+		practiceService: practiceService,
+		hub:             hub,
+		locks:           make(map[uuid.UUID]*sync.Mutex),
+		logger:          logger,
+	}
+	practiceService.RegisterMode("team_duel", s)
+	return s
+}
+
+// RegisterWebSocketHandlers lets party, team and match members join their
+// hub channels
+func (s *TeamMatchService) RegisterWebSocketHandlers() {
+	s.hub.AuthorizeChannels("party", s.memberOf(s.teamRepo.IsPartyMember))
+	s.hub.AuthorizeChannels("team", s.memberOf(s.teamRepo.IsTeamMember))
+	s.hub.AuthorizeChannels("match", s.memberOf(func(matchID, userID uuid.UUID) (bool, error) {
+		participant, err := s.matchRepo.GetParticipantByMatchAndUser(matchID, userID)
+		return participant != nil, err
+	}))
+}
+
+// memberOf turns a membership check into a channel authorizer
+func (s *TeamMatchService) memberOf(isMember func(id, userID uuid.UUID) (bool, error)) websocket.ChannelAuthorizer {
+	return func(userID uuid.UUID, id string) bool {
+		parsed, err := uuid.Parse(id)
+		if err != nil {
+			return false
+		}
+		ok, err := isMember(parsed, userID)
+		if err != nil {
+			s.logger.Error("Failed to authorize channel", zap.Error(err), zap.String("id", id))
+			return false
+		}
+		return ok
+	}
+}
+
+func (s *TeamMatchService) matchLock(matchID uuid.UUID) *sync.Mutex {
+	s.locksMu.Lock()
+	defer s.locksMu.Unlock()
+	lock, ok := s.locks[matchID]
+	if !ok {
+		lock = &sync.Mutex{}
+		s.locks[matchID] = lock
+	}
+	return lock
+}
+
+func (s *TeamMatchService) releaseLock(matchID uuid.UUID) {
+	s.locksMu.Lock()
+	defer s.locksMu.Unlock()
+	delete(s.locks, matchID)
+}
+
+// CreateParty opens a party led by the user
+func (s *TeamMatchService) CreateParty(userID uuid.UUID, req models.CreatePartyRequest) (*models.MatchParty, error) {
+	if req.TeamSize < minTeamSize || req.TeamSize > maxTeamSize {
+		return nil, fmt.Errorf("team size must be between %d and %d", minTeamSize, maxTeamSize)
+	}
+	if req.Scoring == "" {
+		req.Scoring = "sum"
+	}
+	if req.Scoring != "best" && req.Scoring != "sum" {
+		return nil, fmt.Errorf("scoring must be best or sum")
+	}
+	code, err := generateInviteCode()
+	if err != nil {
+		return nil, err
+	}
+	party := &models.MatchParty{
+		ID:         uuid.New(),
+		LeaderID:   userID,
+		InviteCode: code,
+		TeamSize:   req.TeamSize,
+		Scoring:    req.Scoring,
+	}
+	if err := s.teamRepo.CreateParty(party); err != nil {
+		return nil, err
+	}
+	return s.teamRepo.FindParty(party.ID)
+}
+
+// GetParty returns a party the user is a member of
+func (s *TeamMatchService) GetParty(partyID, userID uuid.UUID) (*models.MatchParty, error) {
+	party, err := s.teamRepo.FindParty(partyID)
+	if err != nil {
+		return nil, err
+	}
+	if party == nil || !party.IsMember(userID) {
+		return nil, fmt.Errorf("party not found")
+	}
+	return party, nil
+}
+
+// GetCurrentParty returns the party the user is in, or nil
+func (s *TeamMatchService) GetCurrentParty(userID uuid.UUID) (*models.MatchParty, error) {
+	return s.teamRepo.FindActiveParty(userID)
+}
+
+// JoinParty adds the user to the party with the invite code
+func (s *TeamMatchService) JoinParty(userID uuid.UUID, code string) (*models.MatchParty, error) {
+	party, err := s.teamRepo.FindPartyByCode(code)
+	if err != nil {
+		return nil, err
+	}
+	if party == nil {
+		return nil, fmt.Errorf("invalid invite code")
+	}
+	if _, err := s.teamRepo.JoinParty(party.ID, userID); err != nil {
+		return nil, err
+	}
+	party, err = s.teamRepo.FindParty(party.ID)
+	if err != nil {
+		return nil, err
+	}
+	s.notifyParty(party)
+	return party, nil
+}
+
+// LeaveParty removes the user from a party. A queued party leaves the queue.
+func (s *TeamMatchService) LeaveParty(partyID, userID uuid.UUID) error {
+	party, err := s.teamRepo.LeaveParty(partyID, userID)
+	if err != nil {
+		return err
+	}
+	s.notifyParty(party)
+	if party.Status == "disbanded" {
+		s.hub.CloseChannel(websocket.ChannelName("party", party.ID))
+	}
+	return nil
+}
+
+// Queue puts a full party in the team match queue and pairs it if another
+// party is waiting. Only the leader can queue the party.
+func (s *TeamMatchService) Queue(partyID, userID uuid.UUID) (*models.MatchParty, error) {
+	party, err := s.leaderParty(partyID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(party.Members) < party.TeamSize {
+		return nil, fmt.Errorf("party needs %d members to queue", party.TeamSize)
+	}
+	if err := s.teamRepo.SetQueued(partyID, true); err != nil {
+		return nil, err
+	}
+	if err := s.tryPair(partyID); err != nil {
+		s.logger.Error("Failed to pair party", zap.Error(err), zap.String("party_id", partyID.String()))
+	}
+	party, err = s.teamRepo.FindParty(partyID)
+	if err != nil {
+		return nil, err
+	}
+	if party.Status == "queued" {
+		s.notifyParty(party)
+	}
+	return party, nil
+}
+
+// Dequeue takes a party out of the queue. Only the leader can dequeue it.
+func (s *TeamMatchService) Dequeue(partyID, userID uuid.UUID) (*models.MatchParty, error) {
+	if _, err := s.leaderParty(partyID, userID); err != nil {
+		return nil, err
+	}
+	if err := s.teamRepo.SetQueued(partyID, false); err != nil {
+		return nil, err
+	}
+	party, err := s.teamRepo.FindParty(partyID)
+	if err != nil {
+		return nil, err
+	}
+	s.notifyParty(party)
+	return party, nil
+}
+
+// leaderParty returns a party led by the user
+func (s *TeamMatchService) leaderParty(partyID, userID uuid.UUID) (*models.MatchParty, error) {
+	party, err := s.GetParty(partyID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if party.LeaderID != userID {
+		return nil, fmt.Errorf("only the party leader can do this")
+	}
+	return party, nil
+}
+
+// tryPair starts a team match between a queued party and the party that has
+// waited longest for an opponent, if there is one
+func (s *TeamMatchService) tryPair(partyID uuid.UUID) error {
+	party, err := s.teamRepo.FindParty(partyID)
+	if err != nil || party == nil {
+		return err
+	}
+	exerciseIDs, err := s.exerciseRepo.FindRandomExerciseIDs(party.TeamSize)
+	if err != nil {
+		return err
+	}
+	if len(exerciseIDs) == 0 {
+		return fmt.Errorf("no exercises available")
+	}
+	timeLimit := s.practiceService.timeLimit("team_duel", teamDuelTimeLimitMinutes)
+	tm, err := s.teamRepo.PairParty(partyID, exerciseIDs, timeLimit)
+	if err != nil || tm == nil {
+		return err
+	}
+	s.notifyTeamMatchStart(tm)
+	return nil
+}
+
+// GetTeamMatch returns a team match the user plays in
+func (s *TeamMatchService) GetTeamMatch(matchID, userID uuid.UUID) (*models.TeamMatch, error) {
+	tm, err := s.teamRepo.GetTeamMatch(matchID)
+	if err != nil {
+		return nil, err
+	}
+	if tm == nil || tm.TeamOf(userID) == nil {
+		return nil, fmt.Errorf("team match not found")
+	}
+	tm.Match, err = s.matchRepo.GetMatchByID(matchID)
+	if err != nil {
+		return nil, err
+	}
+	scoreTeams(tm)
+	return tm, nil
+}
+
+// loadActive reloads a team match that is still being played, or returns
+// nil if it has ended
+func (s *TeamMatchService) loadActive(matchID uuid.UUID) (*models.TeamMatch, error) {
+	match, err := s.matchRepo.GetMatchByID(matchID)
+	if err != nil {
+		return nil, err
+	}
+	if match == nil || match.Status != "active" {
+		return nil, nil
+	}
+	tm, err := s.teamRepo.GetTeamMatch(matchID)
+	if err != nil {
+		return nil, err
+	}
+	if tm == nil {
+		return nil, fmt.Errorf("match %s is not a team match", matchID)
+	}
+	tm.Match = match
+	return tm, nil
+}
+
+// RecordSubmission keeps the member's best score on an exercise of the set.
+// A member who has solved every exercise is finished, and the match ends
+// when every member of both teams is.
+func (s *TeamMatchService) RecordSubmission(match *models.PracticeMatch, participant *models.MatchParticipant, exerciseID uuid.UUID, submissionID *uuid.UUID, score int, result string) error {
+	lock := s.matchLock(match.ID)
+	lock.Lock()
+	defer lock.Unlock()
+
+	tm, err := s.loadActive(match.ID)
+	if err != nil {
+		return err
+	}
+	if tm == nil {
+		return fmt.Errorf("match is not active")
+	}
+	inSet := false
+	for _, id := range tm.ExerciseIDs {
+		if id == exerciseID {
+			inSet = true
+			break
+		}
+	}
+	if !inSet {
+		return fmt.Errorf("exercise is not part of this match")
+	}
+	member := teamMember(tm, participant.UserID)
+	if member == nil {
+		return fmt.Errorf("participant not found")
+	}
+	if member.FinishedAt != nil {
+		return fmt.Errorf("participant has already finished")
+	}
+
+	total, solved, err := s.teamRepo.RecordExerciseResult(match.ID, member.UserID, exerciseID, score, result == "win", submissionID)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	member.Score = total
+	member.XPEarned = s.practiceService.applyXPMultiplier(match.MatchType, total)
+	if submissionID != nil {
+		member.SubmissionID = submissionID
+	}
+	if solved >= len(tm.ExerciseIDs) {
+		member.FinishedAt = &now
+	}
+	if err := s.matchRepo.UpdateParticipant(member); err != nil {
+		return err
+	}
+
+	scoreTeams(tm)
+	if err := s.hub.Publish(websocket.ChannelName("match", match.ID), websocket.TeamScore, teamScorePayload(tm)); err != nil {
+		s.logger.Error("Failed to publish team score", zap.Error(err), zap.String("match_id", match.ID.String()))
+	}
+	for _, team := range tm.Teams {
+		for _, p := range team.Members {
+			if p.FinishedAt == nil {
+				return nil
+			}
+		}
+	}
+	defer s.releaseLock(match.ID)
+	return s.finish(tm, now)
+}
+
+// Expire ends a team match whose time limit has run out. Members who are
+// still playing keep the scores they have.
+func (s *TeamMatchService) Expire(match *models.PracticeMatch, now time.Time) error {
+	lock := s.matchLock(match.ID)
+	lock.Lock()
+	defer lock.Unlock()
+	defer s.releaseLock(match.ID)
+
+	tm, err := s.loadActive(match.ID)
+	if err != nil || tm == nil {
+		return err
+	}
+	for i := range tm.Teams {
+		for j := range tm.Teams[i].Members {
+			p := &tm.Teams[i].Members[j]
+			if p.FinishedAt != nil {
+				continue
+			}
+			p.FinishedAt = &now
+			if err := s.matchRepo.UpdateParticipant(p); err != nil {
+				return err
+			}
+		}
+	}
+	return s.finish(tm, now)
+}
+
+// finish ranks the teams, gives every member the result of their team and
+// completes the match
+func (s *TeamMatchService) finish(tm *models.TeamMatch, now time.Time) error {
+	scoreTeams(tm)
+	best, winners := 0, 0
+	for i, team := range tm.Teams {
+		if i == 0 || team.Score > best {
+			best, winners = team.Score, 0
+		}
+		if team.Score == best {
+			winners++
+		}
+	}
+
+	var participants []models.MatchParticipant
+	for i := range tm.Teams {
+		team := &tm.Teams[i]
+		result := "loss"
+		if team.Score == best {
+			result = "win"
+			if winners > 1 {
+				result = "draw"
+			}
+		}
+		team.Result = &result
+		rank := 1
+		for _, other := range tm.Teams {
+			if other.Score > team.Score {
+				rank++
+			}
+		}
+		for j := range team.Members {
+			p := &team.Members[j]
+			p.Result = result
+			p.Rank = intPtr(rank)
+			if err := s.matchRepo.UpdateParticipant(p); err != nil {
+				return err
+			}
+			participants = append(participants, *p)
+		}
+	}
+	if err := s.teamRepo.SaveTeamResults(tm.Match.ID, tm.Teams); err != nil {
+		return err
+	}
+	for _, p := range participants {
+		if err := s.practiceService.recordStats(tm.Match, p.UserID, p.Result, p.Score, p.XPEarned, now); err != nil {
+			s.logger.Error("Failed to record team match stats", zap.Error(err), zap.String("match_id", tm.Match.ID.String()), zap.String("user_id", p.UserID.String()))
+		}
+	}
+	if err := s.practiceService.completeMatch(tm.Match, participants, now); err != nil {
+		return err
+	}
+
+	userIDs := make([]uuid.UUID, 0, len(participants))
+	for _, p := range participants {
+		userIDs = append(userIDs, p.UserID)
+	}
+	if err := s.hub.SendToUsers(userIDs, websocket.TeamScore, teamScorePayload(tm)); err != nil {
+		s.logger.Error("Failed to push team results", zap.Error(err), zap.String("match_id", tm.Match.ID.String()))
+	}
+	s.hub.CloseChannel(websocket.ChannelName("match", tm.Match.ID))
+	for _, team := range tm.Teams {
+		s.hub.CloseChannel(websocket.ChannelName("team", team.ID))
+		if team.PartyID == nil {
+			continue
+		}
+		party, err := s.teamRepo.FindParty(*team.PartyID)
+		if err != nil {
+			s.logger.Error("Failed to load party after team match", zap.Error(err), zap.String("party_id", team.PartyID.String()))
+			continue
+		}
+		if party != nil {
+			s.notifyParty(party)
+		}
+	}
+	return nil
+}
+
+// teamMember returns the participant entry of a user in a team match
+func teamMember(tm *models.TeamMatch, userID uuid.UUID) *models.MatchParticipant {
+	for i := range tm.Teams {
+		for j := range tm.Teams[i].Members {
+			if tm.Teams[i].Members[j].UserID == userID {
+				return &tm.Teams[i].Members[j]
+			}
+		}
+	}
+	return nil
+}
+
+// scoreTeams sets each team's score to the best or the sum of its members'
+// scores
+func scoreTeams(tm *models.TeamMatch) {
+	for i := range tm.Teams {
+		team := &tm.Teams[i]
+		team.Score = 0
+		for _, p := range team.Members {
+			if tm.Scoring == "best" {
+				if p.Score > team.Score {
+					team.Score = p.Score
+				}
+			} else {
+				team.Score += p.Score
+			}
+		}
+	}
+}
+
+// teamScorePayload lists the teams of a match ordered by team number
+func teamScorePayload(tm *models.TeamMatch) websocket.TeamScorePayload {
+	return websocket.TeamScorePayload{MatchID: tm.Match.ID.String(), Teams: teamResults(tm)}
+}
+
+func teamResults(tm *models.TeamMatch) []websocket.TeamScoreResult {
+	results := make([]websocket.TeamScoreResult, 0, len(tm.Teams))
+	for _, team := range tm.Teams {
+		r := websocket.TeamScoreResult{
+			TeamID: team.ID.String(),
+			Number: team.Number,
+			Score:  team.Score,
+		}
+		if team.Result != nil {
+			r.Result = *team.Result
+		}
+		for _, p := range team.Members {
+			r.Members = append(r.Members, p.UserID.String())
+		}
+		results = append(results, r)
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].Number < results[j].Number })
+	return results
+}
+
+// notifyTeamMatchStart tells every player their team and the exercise set
+func (s *TeamMatchService) notifyTeamMatchStart(tm *models.TeamMatch) {
+	exerciseIDs := make([]string, 0, len(tm.ExerciseIDs))
+	for _, id := range tm.ExerciseIDs {
+		exerciseIDs = append(exerciseIDs, id.String())
+	}
+	timeLimit := 0
+	if tm.Match.TimeLimitMinutes != nil {
+		timeLimit = *tm.Match.TimeLimitMinutes
+	}
+	teams := teamResults(tm)
+	for _, team := range tm.Teams {
+		userIDs := make([]uuid.UUID, 0, len(team.Members))
+		for _, p := range team.Members {
+			userIDs = append(userIDs, p.UserID)
+		}
+		if err := s.hub.SendToUsers(userIDs, websocket.TeamMatchStart, websocket.TeamMatchStartPayload{
+			MatchID:     tm.Match.ID.String(),
+			TeamID:      team.ID.String(),
+			Scoring:     tm.Scoring,
+			TimeLimit:   timeLimit,
+			ExerciseIDs: exerciseIDs,
+			Teams:       teams,
+		}); err != nil {
+			s.logger.Error("Failed to push team match start", zap.Error(err), zap.String("match_id", tm.Match.ID.String()), zap.String("team_id", team.ID.String()))
+		}
+		if team.PartyID == nil {
+			continue
+		}
+		party, err := s.teamRepo.FindParty(*team.PartyID)
+		if err != nil {
+			s.logger.Error("Failed to load party for team match start", zap.Error(err), zap.String("party_id", team.PartyID.String()))
+			continue
+		}
+		if party != nil {
+			s.notifyParty(party)
+		}
+	}
+}
+
+// notifyParty pushes the state of a party to its members
+func (s *TeamMatchService) notifyParty(party *models.MatchParty) {
+	payload := websocket.PartyUpdatePayload{
+		PartyID: party.ID.String(),
+		Status:  party.Status,
+		Members: []string{},
+	}
+	for _, m := range party.Members {
+		payload.Members = append(payload.Members, m.UserID.String())
+	}
+	if party.MatchID != nil && party.Status == "in_match" {
+		payload.MatchID = party.MatchID.String()
+	}
+	if err := s.hub.SendToUsers(party.MemberIDs(), websocket.PartyUpdate, payload); err != nil {
+		s.logger.Error("Failed to push party update", zap.Error(err), zap.String("party_id", party.ID.String()))
+	}
+}
@@ -0,0 +1,790 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yourusername/wizardcore-backend/internal/models"
+	"github.com/yourusername/wizardcore-backend/internal/repositories"
+	"github.com/yourusername/wizardcore-backend/internal/tournament"
+	"go.uber.org/zap"
+)
+
+// How often tournaments due to start are checked for
+const tournamentSweepInterval = 30 * time.Second
+
+var validTournamentFormats = map[string]bool{
+	tournament.SingleElimination: true,
+	tournament.DoubleElimination: true,
+	tournament.Swiss:             true,
+}
+
+// TournamentService runs tournaments. Every bracket match is played as a
+// practice duel; when a duel ends the bracket moves on and the next duels
+// are started.
+type TournamentService struct {
+	tournamentRepo  *repositories.TournamentRepository
+	matchRepo       *repositories.MatchRepository
+	exerciseRepo    *repositories.ExerciseRepository
+	practiceService *PracticeService
+
+	// Brackets are read, advanced and written back as a whole, so changes
+	// to one tournament are serialized
+	locksMu sync.Mutex
+	locks   map[uuid.UUID]*sync.Mutex
+	logger  *zap.Logger
+}
+
+func NewTournamentService(tournamentRepo *repositories.TournamentRepository, matchRepo *repositories.MatchRepository, exerciseRepo *repositories.ExerciseRepository, practiceService *PracticeService, logger *zap.Logger) *TournamentService {
+	s := &TournamentService{
+		tournamentRepo:  tournamentRepo,
+		matchRepo:       matchRepo,
+		exerciseRepo:    exerciseRepo,
+		practiceService: practiceService,
+		locks:           make(map[uuid.UUID]*sync.Mutex),
+		logger:          logger,
+	}
+	practiceService.OnMatchEnd(s.onMatchEnd)
+	return s
+}
+
+func (s *TournamentService) tournamentLock(tournamentID uuid.UUID) *sync.Mutex {
+	s.locksMu.Lock()
+	defer s.locksMu.Unlock()
+	lock, ok := s.locks[tournamentID]
+	if !ok {
+		lock = &sync.Mutex{}
+		s.locks[tournamentID] = lock
+	}
+	return lock
+}
+
+// CreateTournament creates a tournament open for registration
+func (s *TournamentService) CreateTournament(creatorID uuid.UUID, req models.CreateTournamentRequest) (*models.Tournament, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, fmt.Errorf("name is required")
+	}
+	if !validTournamentFormats[req.Format] {
+		return nil, fmt.Errorf("invalid format: %s", req.Format)
+	}
+	if req.Difficulty != nil && !validDifficulties[*req.Difficulty] {
+		return nil, fmt.Errorf("invalid difficulty: %s", *req.Difficulty)
+	}
+	timeLimit := duelTimeLimitMinutes
+	if req.TimeLimitMinutes != nil {
+		if *req.TimeLimitMinutes <= 0 || *req.TimeLimitMinutes > maxPrivateDuelMinutes {
+			return nil, fmt.Errorf("time limit must be between 1 and %d minutes", maxPrivateDuelMinutes)
+		}
+		timeLimit = *req.TimeLimitMinutes
+	}
+	if req.MaxParticipants != nil && *req.MaxParticipants < minTournamentPlayers(req.Format) {
+		return nil, fmt.Errorf("a %s tournament needs room for at least %d participants", req.Format, minTournamentPlayers(req.Format))
+	}
+	if req.SwissRounds != nil && (req.Format != tournament.Swiss || *req.SwissRounds < 1) {
+		return nil, fmt.Errorf("swiss_rounds must be positive and only applies to swiss tournaments")
+	}
+	opensAt := req.RegistrationOpensAt
+	if opensAt.IsZero() {
+		opensAt = time.Now()
+	}
+	if !opensAt.Before(req.RegistrationClosesAt) {
+		return nil, fmt.Errorf("registration must close after it opens")
+	}
+	if req.StartsAt.Before(req.RegistrationClosesAt) {
+		return nil, fmt.Errorf("tournament cannot start before registration closes")
+	}
+
+	t := &models.Tournament{
+		Name:                 name,
+		Description:          req.Description,
+		Format:               req.Format,
+		Status:               "registration",
+		LanguageID:           req.LanguageID,
+		Difficulty:           req.Difficulty,
+		TimeLimitMinutes:     timeLimit,
+		MaxParticipants:      req.MaxParticipants,
+		SwissRounds:          req.SwissRounds,
+		RegistrationOpensAt:  opensAt,
+		RegistrationClosesAt: req.RegistrationClosesAt,
+		StartsAt:             req.StartsAt,
+		CreatedBy:            &creatorID,
+	}
+	if err := s.tournamentRepo.Create(t); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+func minTournamentPlayers(format string) int {
+	if format == tournament.DoubleElimination {
+		return 3
+	}
+	return 2
+}
+
+// ListTournaments returns tournaments, optionally filtered by status
+func (s *TournamentService) ListTournaments(status *string, limit, offset int) ([]models.Tournament, int, error) {
+	return s.tournamentRepo.List(status, limit, offset)
+}
+
+// GetTournament returns a tournament and whether the user is registered
+func (s *TournamentService) GetTournament(tournamentID, userID uuid.UUID) (*models.Tournament, error) {
+	t, err := s.tournamentRepo.FindByID(tournamentID)
+	if err != nil {
+		return nil, err
+	}
+	if t == nil {
+		return nil, fmt.Errorf("tournament not found")
+	}
+	t.IsRegistered, err = s.tournamentRepo.IsRegistered(tournamentID, userID)
+	if err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// Register signs the user up while registration is open
+func (s *TournamentService) Register(tournamentID, userID uuid.UUID) error {
+	t, err := s.openForRegistration(tournamentID)
+	if err != nil {
+		return err
+	}
+	if time.Now().Before(t.RegistrationOpensAt) {
+		return fmt.Errorf("registration has not opened yet")
+	}
+	registered, err := s.tournamentRepo.IsRegistered(tournamentID, userID)
+	if err != nil {
+		return err
+	}
+	if registered {
+		return fmt.Errorf("already registered")
+	}
+	added, err := s.tournamentRepo.Register(tournamentID, userID)
+	if err != nil {
+		return err
+	}
+	if !added {
+		return fmt.Errorf("tournament is full")
+	}
+	return nil
+}
+
+// Unregister withdraws the user while registration is open
+func (s *TournamentService) Unregister(tournamentID, userID uuid.UUID) error {
+	if _, err := s.openForRegistration(tournamentID); err != nil {
+		return err
+	}
+	removed, err := s.tournamentRepo.Unregister(tournamentID, userID)
+	if err != nil {
+		return err
+	}
+	if !removed {
+		return fmt.Errorf("not registered")
+	}
+	return nil
+}
+
+func (s *TournamentService) openForRegistration(tournamentID uuid.UUID) (*models.Tournament, error) {
+	t, err := s.tournamentRepo.FindByID(tournamentID)
+	if err != nil {
+		return nil, err
+	}
+	if t == nil {
+		return nil, fmt.Errorf("tournament not found")
+	}
+	if t.Status != "registration" || !time.Now().Before(t.RegistrationClosesAt) {
+		return nil, fmt.Errorf("registration is closed")
+	}
+	return t, nil
+}
+
+// GetBracket returns a tournament with its participants, bracket matches
+// and, for Swiss, the standings
+func (s *TournamentService) GetBracket(tournamentID uuid.UUID) (*models.TournamentBracket, error) {
+	t, err := s.tournamentRepo.FindByID(tournamentID)
+	if err != nil {
+		return nil, err
+	}
+	if t == nil {
+		return nil, fmt.Errorf("tournament not found")
+	}
+	participants, err := s.tournamentRepo.GetParticipants(tournamentID)
+	if err != nil {
+		return nil, err
+	}
+	matches, err := s.tournamentRepo.GetMatches(tournamentID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range matches {
+		matches[i].Status = tournamentMatchStatus(&matches[i])
+	}
+
+	bracket := &models.TournamentBracket{
+		Tournament:   t,
+		Participants: participants,
+		Matches:      matches,
+	}
+	if t.Format == tournament.Swiss && len(matches) > 0 {
+		for i, st := range tournament.SwissStandings(seededPlayers(participants), toNodes(matches)) {
+			bracket.Standings = append(bracket.Standings, models.TournamentStanding{
+				Rank:     i + 1,
+				UserID:   st.Player,
+				Seed:     st.Seed,
+				Points:   st.Points,
+				Buchholz: st.Buchholz,
+				Wins:     st.Wins,
+				Draws:    st.Draws,
+				Losses:   st.Losses,
+			})
+		}
+	}
+	return bracket, nil
+}
+
+// StartTournament closes registration, seeds the participants and starts
+// the first duels. Tournaments without enough participants are cancelled.
+func (s *TournamentService) StartTournament(tournamentID uuid.UUID) (*models.Tournament, error) {
+	lock := s.tournamentLock(tournamentID)
+	lock.Lock()
+	defer lock.Unlock()
+
+	t, err := s.tournamentRepo.FindByID(tournamentID)
+	if err != nil {
+		return nil, err
+	}
+	if t == nil {
+		return nil, fmt.Errorf("tournament not found")
+	}
+	if t.Status != "registration" {
+		return nil, fmt.Errorf("tournament has already started")
+	}
+
+	participants, err := s.tournamentRepo.GetParticipants(tournamentID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if len(participants) < minTournamentPlayers(t.Format) {
+		t.Status = "cancelled"
+		t.CompletedAt = &now
+		if err := s.tournamentRepo.UpdateProgress(t); err != nil {
+			return nil, err
+		}
+		return t, nil
+	}
+
+	seeded, err := s.seed(tournamentID, participants)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.tournamentRepo.SetSeeds(tournamentID, seeded); err != nil {
+		return nil, err
+	}
+
+	var nodes []tournament.Node
+	switch t.Format {
+	case tournament.SingleElimination:
+		nodes, err = tournament.SingleEliminationBracket(seeded)
+	case tournament.DoubleElimination:
+		nodes, err = tournament.DoubleEliminationBracket(seeded)
+	case tournament.Swiss:
+		if t.SwissRounds == nil {
+			rounds := tournament.SwissRounds(len(seeded))
+			t.SwissRounds = &rounds
+		}
+		standings := tournament.SwissStandings(seeded, nil)
+		pairs, bye := tournament.SwissPairings(standings, nil)
+		nodes = tournament.SwissRoundNodes(1, pairs, bye)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	matches := fromNodes(tournamentID, 0, nodes, now)
+	if err := s.tournamentRepo.SaveMatches(matches); err != nil {
+		return nil, err
+	}
+	t.Status = "in_progress"
+	t.CurrentRound = 1
+	if err := s.tournamentRepo.UpdateProgress(t); err != nil {
+		return nil, err
+	}
+	s.startReadyMatches(t, matches)
+	return t, nil
+}
+
+// seed orders participants by duel rating, then practice score, then
+// registration time
+func (s *TournamentService) seed(tournamentID uuid.UUID, participants []models.TournamentParticipant) ([]uuid.UUID, error) {
+	ratings, err := s.tournamentRepo.GetSeedingRatings(tournamentID)
+	if err != nil {
+		return nil, err
+	}
+	ordered := make([]models.TournamentParticipant, len(participants))
+	copy(ordered, participants)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		a, b := ratings[ordered[i].UserID], ratings[ordered[j].UserID]
+		if a[0] != b[0] {
+			return a[0] > b[0]
+		}
+		if a[1] != b[1] {
+			return a[1] > b[1]
+		}
+		return ordered[i].RegisteredAt.Before(ordered[j].RegisteredAt)
+	})
+	seeded := make([]uuid.UUID, len(ordered))
+	for i, p := range ordered {
+		seeded[i] = p.UserID
+	}
+	return seeded, nil
+}
+
+// OverrideResult sets the winner of a bracket match, whether or not its duel
+// has finished. A nil winner records a draw, which only Swiss allows.
+func (s *TournamentService) OverrideResult(tournamentID uuid.UUID, nodeIndex int, winnerID *uuid.UUID) (*models.TournamentBracket, error) {
+	lock := s.tournamentLock(tournamentID)
+	lock.Lock()
+	t, matches, err := s.loadInProgress(tournamentID)
+	if err == nil {
+		winner := uuid.Nil
+		if winnerID != nil {
+			winner = *winnerID
+		}
+		err = s.decide(t, matches, nodeIndex, winner, true)
+	}
+	lock.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	return s.GetBracket(tournamentID)
+}
+
+// CancelTournament stops a tournament. Duels already under way are played
+// out but no longer count.
+func (s *TournamentService) CancelTournament(tournamentID uuid.UUID) error {
+	lock := s.tournamentLock(tournamentID)
+	lock.Lock()
+	defer lock.Unlock()
+
+	t, err := s.tournamentRepo.FindByID(tournamentID)
+	if err != nil {
+		return err
+	}
+	if t == nil {
+		return fmt.Errorf("tournament not found")
+	}
+	if t.Status == "completed" || t.Status == "cancelled" {
+		return fmt.Errorf("tournament has already ended")
+	}
+	now := time.Now()
+	t.Status = "cancelled"
+	t.CompletedAt = &now
+	return s.tournamentRepo.UpdateProgress(t)
+}
+
+// Run starts tournaments when their start time comes, until the process exits
+func (s *TournamentService) Run() {
+	ticker := time.NewTicker(tournamentSweepInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		s.startDue()
+	}
+}
+
+func (s *TournamentService) startDue() {
+	due, err := s.tournamentRepo.ListDueToStart(time.Now())
+	if err != nil {
+		s.logger.Error("Failed to list tournaments due to start", zap.Error(err))
+		return
+	}
+	for _, t := range due {
+		if _, err := s.StartTournament(t.ID); err != nil {
+			s.logger.Error("Failed to start tournament", zap.Error(err), zap.String("tournament_id", t.ID.String()))
+		}
+	}
+}
+
+// onMatchEnd moves the bracket on when a tournament duel finishes
+func (s *TournamentService) onMatchEnd(match *models.PracticeMatch, participants []models.MatchParticipant) {
+	node, err := s.tournamentRepo.FindMatchByPracticeMatch(match.ID)
+	if err != nil {
+		s.logger.Error("Failed to look up tournament match", zap.Error(err), zap.String("match_id", match.ID.String()))
+		return
+	}
+	if node == nil {
+		return
+	}
+
+	lock := s.tournamentLock(node.TournamentID)
+	lock.Lock()
+	defer lock.Unlock()
+
+	t, matches, err := s.loadInProgress(node.TournamentID)
+	if err != nil {
+		return
+	}
+	node = &matches[node.NodeIndex]
+	if node.Decided || node.MatchID == nil || *node.MatchID != match.ID {
+		// Overridden or replaced in the meantime
+		return
+	}
+
+	var p1, p2 *models.MatchParticipant
+	for i := range participants {
+		switch {
+		case node.Player1ID != nil && participants[i].UserID == *node.Player1ID:
+			p1 = &participants[i]
+		case node.Player2ID != nil && participants[i].UserID == *node.Player2ID:
+			p2 = &participants[i]
+		}
+	}
+	if p1 == nil || p2 == nil {
+		s.logger.Warn("Tournament duel is missing a player", zap.String("match_id", match.ID.String()))
+		return
+	}
+
+	winner := uuid.Nil
+	switch score := duelScore(*p1, *p2); {
+	case score == 1:
+		winner = p1.UserID
+	case score == 0:
+		winner = p2.UserID
+	case t.Format != tournament.Swiss:
+		// Elimination matches need a winner: the better seed goes through
+		winner, err = s.betterSeed(t.ID, p1.UserID, p2.UserID)
+		if err != nil {
+			s.logger.Error("Failed to break tournament tie", zap.Error(err), zap.String("tournament_id", t.ID.String()))
+			return
+		}
+	}
+	if err := s.decide(t, matches, node.NodeIndex, winner, false); err != nil {
+		s.logger.Error("Failed to advance tournament", zap.Error(err), zap.String("tournament_id", t.ID.String()))
+	}
+}
+
+func (s *TournamentService) betterSeed(tournamentID, a, b uuid.UUID) (uuid.UUID, error) {
+	participants, err := s.tournamentRepo.GetParticipants(tournamentID)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	// Participants come in seed order
+	for _, p := range participants {
+		if p.UserID == a || p.UserID == b {
+			return p.UserID, nil
+		}
+	}
+	return a, nil
+}
+
+func (s *TournamentService) loadInProgress(tournamentID uuid.UUID) (*models.Tournament, []models.TournamentMatch, error) {
+	t, err := s.tournamentRepo.FindByID(tournamentID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if t == nil {
+		return nil, nil, fmt.Errorf("tournament not found")
+	}
+	if t.Status != "in_progress" {
+		return nil, nil, fmt.Errorf("tournament is not in progress")
+	}
+	matches, err := s.tournamentRepo.GetMatches(tournamentID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return t, matches, nil
+}
+
+// decide records the result of a bracket match, stores every match that
+// changed, and then finishes the round or the tournament and starts the
+// duels that became ready. Callers hold the tournament lock.
+func (s *TournamentService) decide(t *models.Tournament, matches []models.TournamentMatch, nodeIndex int, winner uuid.UUID, override bool) error {
+	if nodeIndex < 0 || nodeIndex >= len(matches) {
+		return fmt.Errorf("match %d does not exist", nodeIndex)
+	}
+	nodes := toNodes(matches)
+	before := toNodes(matches)
+
+	var changed []int
+	var err error
+	if override {
+		changed, err = tournament.Override(nodes, nodeIndex, winner)
+	} else {
+		changed, err = tournament.Report(nodes, nodeIndex, winner)
+	}
+	if err != nil {
+		return err
+	}
+	if len(changed) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	if override {
+		matches[nodeIndex].Overridden = true
+		// A duel still under way for the overridden match no longer counts
+		s.cancelDuel(matches[nodeIndex].MatchID, before[nodeIndex].Done)
+	}
+	var updated []models.TournamentMatch
+	for _, i := range changed {
+		// A later match whose players changed needs a new duel
+		if i != nodeIndex && !nodes[i].Done && nodes[i].Slots != before[i].Slots && matches[i].MatchID != nil {
+			s.cancelDuel(matches[i].MatchID, false)
+			matches[i].MatchID = nil
+		}
+		applyNode(&matches[i], &nodes[i], now)
+		updated = append(updated, matches[i])
+	}
+	if err := s.tournamentRepo.SaveMatches(updated); err != nil {
+		return err
+	}
+
+	if t.Format == tournament.Swiss {
+		matches, err = s.advanceSwiss(t, matches, now)
+		if err != nil {
+			return err
+		}
+	} else {
+		if err := s.updateEliminated(t, nodes); err != nil {
+			s.logger.Error("Failed to update eliminated players", zap.Error(err), zap.String("tournament_id", t.ID.String()))
+		}
+		if champion, done := tournament.Champion(nodes); done {
+			return s.finish(t, champion, now)
+		}
+	}
+	if t.Status == "in_progress" {
+		s.startReadyMatches(t, matches)
+	}
+	return nil
+}
+
+// advanceSwiss pairs the next round once every match of the current round
+// is decided, or finishes the tournament after the last round
+func (s *TournamentService) advanceSwiss(t *models.Tournament, matches []models.TournamentMatch, now time.Time) ([]models.TournamentMatch, error) {
+	nodes := toNodes(matches)
+	for i := range nodes {
+		if nodes[i].Round == t.CurrentRound && !nodes[i].Done {
+			return matches, nil
+		}
+	}
+
+	participants, err := s.tournamentRepo.GetParticipants(t.ID)
+	if err != nil {
+		return nil, err
+	}
+	standings := tournament.SwissStandings(seededPlayers(participants), nodes)
+	if t.SwissRounds == nil || t.CurrentRound >= *t.SwissRounds {
+		return matches, s.finish(t, standings[0].Player, now)
+	}
+
+	pairs, bye := tournament.SwissPairings(standings, nodes)
+	t.CurrentRound++
+	next := fromNodes(t.ID, len(matches), tournament.SwissRoundNodes(t.CurrentRound, pairs, bye), now)
+	if err := s.tournamentRepo.SaveMatches(next); err != nil {
+		return nil, err
+	}
+	if err := s.tournamentRepo.UpdateProgress(t); err != nil {
+		return nil, err
+	}
+	return append(matches, next...), nil
+}
+
+func (s *TournamentService) updateEliminated(t *models.Tournament, nodes []tournament.Node) error {
+	eliminated := tournament.Eliminated(nodes)
+	out := make(map[uuid.UUID]bool, len(eliminated))
+	for _, id := range eliminated {
+		out[id] = true
+	}
+	participants, err := s.tournamentRepo.GetParticipants(t.ID)
+	if err != nil {
+		return err
+	}
+	// An override can bring a player back, so statuses are recomputed
+	var active []uuid.UUID
+	for _, p := range participants {
+		if p.Seed != nil && !out[p.UserID] {
+			active = append(active, p.UserID)
+		}
+	}
+	if err := s.tournamentRepo.SetParticipantStatus(t.ID, active, "active"); err != nil {
+		return err
+	}
+	return s.tournamentRepo.SetParticipantStatus(t.ID, eliminated, "eliminated")
+}
+
+func (s *TournamentService) finish(t *models.Tournament, champion uuid.UUID, now time.Time) error {
+	t.Status = "completed"
+	t.WinnerID = &champion
+	t.CompletedAt = &now
+	if err := s.tournamentRepo.UpdateProgress(t); err != nil {
+		return err
+	}
+	return s.tournamentRepo.SetParticipantStatus(t.ID, []uuid.UUID{champion}, "champion")
+}
+
+// startReadyMatches starts a duel for every match whose players are known
+func (s *TournamentService) startReadyMatches(t *models.Tournament, matches []models.TournamentMatch) {
+	for i := range matches {
+		m := &matches[i]
+		if m.Decided || m.MatchID != nil || m.Player1ID == nil || m.Player2ID == nil {
+			continue
+		}
+		exerciseID, err := s.exerciseRepo.FindRandomExerciseID(t.LanguageID, t.Difficulty)
+		if err != nil {
+			s.logger.Error("Failed to pick tournament exercise", zap.Error(err), zap.String("tournament_id", t.ID.String()))
+			return
+		}
+		if exerciseID == nil {
+			s.logger.Warn("No exercise available for tournament", zap.String("tournament_id", t.ID.String()))
+			return
+		}
+		now := time.Now()
+		timeLimit := t.TimeLimitMinutes
+		duel := &models.PracticeMatch{
+			ID:               uuid.New(),
+			MatchType:        "duel",
+			Status:           "active",
+			ExerciseID:       *exerciseID,
+			TimeLimitMinutes: &timeLimit,
+			StartedAt:        &now,
+			CreatedAt:        &now,
+		}
+		if err := s.tournamentRepo.StartMatch(m, duel); err != nil {
+			s.logger.Error("Failed to start tournament duel", zap.Error(err), zap.String("tournament_id", t.ID.String()))
+			continue
+		}
+		s.practiceService.notifyMatchStart(duel)
+	}
+}
+
+// cancelDuel cancels the duel of a bracket match unless it already finished
+func (s *TournamentService) cancelDuel(matchID *uuid.UUID, decided bool) {
+	if matchID == nil || decided {
+		return
+	}
+	match, err := s.matchRepo.GetMatchByID(*matchID)
+	if err != nil || match == nil {
+		return
+	}
+	if match.Status == "completed" || match.Status == "cancelled" {
+		return
+	}
+	now := time.Now()
+	match.Status = "cancelled"
+	match.EndedAt = &now
+	if err := s.matchRepo.UpdateMatch(match); err != nil {
+		s.logger.Error("Failed to cancel tournament duel", zap.Error(err), zap.String("match_id", matchID.String()))
+	}
+}
+
+func tournamentMatchStatus(m *models.TournamentMatch) string {
+	switch {
+	case m.Decided && (m.Player1ID == nil || m.Player2ID == nil):
+		return "bye"
+	case m.Decided:
+		return "completed"
+	case m.MatchID != nil:
+		return "active"
+	case m.Player1ID != nil && m.Player2ID != nil:
+		return "ready"
+	default:
+		return "pending"
+	}
+}
+
+// seededPlayers returns the seeded participants, best seed first
+func seededPlayers(participants []models.TournamentParticipant) []uuid.UUID {
+	var seeded []uuid.UUID
+	for _, p := range participants {
+		if p.Seed != nil {
+			seeded = append(seeded, p.UserID)
+		}
+	}
+	return seeded
+}
+
+func toNodes(matches []models.TournamentMatch) []tournament.Node {
+	nodes := make([]tournament.Node, len(matches))
+	for i, m := range matches {
+		n := tournament.Node{
+			Bracket:  m.Bracket,
+			Round:    m.Round,
+			Position: m.Position,
+			Done:     m.Decided,
+		}
+		n.Slots[0].Filled = m.Slot1Filled
+		n.Slots[1].Filled = m.Slot2Filled
+		if m.Player1ID != nil {
+			n.Slots[0].Player = *m.Player1ID
+		}
+		if m.Player2ID != nil {
+			n.Slots[1].Player = *m.Player2ID
+		}
+		if m.WinnerID != nil {
+			n.Winner = *m.WinnerID
+		}
+		if m.NextNode != nil && m.NextSlot != nil {
+			n.Next = &tournament.Link{Node: *m.NextNode, Slot: *m.NextSlot}
+		}
+		if m.LoserNextNode != nil && m.LoserNextSlot != nil {
+			n.LoserNext = &tournament.Link{Node: *m.LoserNextNode, Slot: *m.LoserNextSlot}
+		}
+		nodes[i] = n
+	}
+	return nodes
+}
+
+// fromNodes creates bracket matches for new nodes, the first of which gets
+// node index offset
+func fromNodes(tournamentID uuid.UUID, offset int, nodes []tournament.Node, now time.Time) []models.TournamentMatch {
+	matches := make([]models.TournamentMatch, len(nodes))
+	for i := range nodes {
+		n := &nodes[i]
+		m := models.TournamentMatch{
+			TournamentID: tournamentID,
+			NodeIndex:    offset + i,
+			Bracket:      n.Bracket,
+			Round:        n.Round,
+			Position:     n.Position,
+		}
+		if n.Next != nil {
+			node, slot := offset+n.Next.Node, n.Next.Slot
+			m.NextNode, m.NextSlot = &node, &slot
+		}
+		if n.LoserNext != nil {
+			node, slot := offset+n.LoserNext.Node, n.LoserNext.Slot
+			m.LoserNextNode, m.LoserNextSlot = &node, &slot
+		}
+		applyNode(&m, n, now)
+		matches[i] = m
+	}
+	return matches
+}
+
+// applyNode copies the state of a node onto its bracket match
+func applyNode(m *models.TournamentMatch, n *tournament.Node, now time.Time) {
+	m.Slot1Filled = n.Slots[0].Filled
+	m.Slot2Filled = n.Slots[1].Filled
+	m.Player1ID = playerPtr(n.Slots[0].Player)
+	m.Player2ID = playerPtr(n.Slots[1].Player)
+	m.WinnerID = playerPtr(n.Winner)
+	if n.Done && !m.Decided {
+		m.DecidedAt = &now
+	}
+	if !n.Done {
+		m.DecidedAt = nil
+	}
+	m.Decided = n.Done
+}
+
+func playerPtr(id uuid.UUID) *uuid.UUID {
+	if id == uuid.Nil {
+		return nil
+	}
+	return &id
+}
@@ -0,0 +1,41 @@
+package services
+
+import (
+	"github.com/google/uuid"
+	"github.com/yourusername/wizardcore-backend/internal/models"
+	"github.com/yourusername/wizardcore-backend/internal/repositories"
+	"go.uber.org/zap"
+)
+
+// XPService handles the admin side of the XP ledger
+type XPService struct {
+	xpRepo *repositories.XPRepository
+	logger *zap.Logger
+}
+
+func NewXPService(xpRepo *repositories.XPRepository, logger *zap.Logger) *XPService {
+	return &XPService{
+		xpRepo: xpRepo,
+		logger: logger,
+	}
+}
+
+// ListBackfillReviews returns the users queued by the ledger backfill,
+// filtered by whether they were resolved
+func (s *XPService) ListBackfillReviews(resolved *bool, limit, offset int) ([]models.XPBackfillReview, int, error) {
+	return s.xpRepo.ListBackfillReviews(resolved, limit, offset)
+}
+
+// ResolveBackfillReview posts a reviewed user's opening balance and closes
+// the review. It returns nil if the user has no backfill review.
+func (s *XPService) ResolveBackfillReview(userID, resolverID uuid.UUID, req *models.ResolveXPBackfillReviewRequest) (*models.XPBackfillReview, error) {
+	resolved, err := s.xpRepo.ResolveBackfillReview(userID, resolverID, *req.OpeningBalance)
+	if err != nil || !resolved {
+		return nil, err
+	}
+	s.logger.Info("Resolved XP backfill review",
+		zap.String("user_id", userID.String()),
+		zap.String("resolved_by", resolverID.String()),
+		zap.Int("opening_balance", *req.OpeningBalance))
+	return s.xpRepo.GetBackfillReview(userID)
+}
@@ -0,0 +1,125 @@
+// Package streak keeps the arithmetic of daily activity streaks. Days are
+// calendar days in the user's own timezone, represented as midnight UTC of
+// that date so they compare and store like DATE values.
+package streak
+
+import "time"
+
+const (
+	// A streak freeze is earned every FreezeEvery days of streak
+	FreezeEvery = 7
+	// MaxFreezes is how many freezes a user can hold at once
+	MaxFreezes = 2
+	// RiskHour is the local hour from which a streak not yet extended today
+	// is at risk
+	RiskHour = 20
+)
+
+// State is a user's streak. LastDay is the last day that counted towards
+// it, either active or covered by a freeze.
+type State struct {
+	Current int
+	Longest int
+	Freezes int
+	LastDay *time.Time
+}
+
+// Change describes what settling or recording did to a streak
+type Change struct {
+	// The streak grew by one day
+	Extended bool
+	// The streak was lost to a missed day
+	Broken bool
+	// Freezes spent covering missed days
+	FreezesUsed int
+	// A freeze was earned by reaching a multiple of FreezeEvery
+	FreezeEarned bool
+}
+
+// Location returns the timezone with an IANA name, or UTC if the name is
+// empty or unknown
+func Location(name string) *time.Location {
+	if name == "" {
+		return time.UTC
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// Day returns the calendar day of t in loc
+func Day(t time.Time, loc *time.Location) time.Time {
+	y, m, d := t.In(loc).Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+// Settle brings a streak up to today before anything is recorded on it.
+// Days missed since LastDay, not counting today, are covered by freezes
+// while there are enough of them; otherwise the streak drops to zero and
+// the freezes are kept.
+func Settle(s *State, today time.Time) Change {
+	var c Change
+	if s.Current == 0 || s.LastDay == nil {
+		return c
+	}
+	missed := int(today.Sub(*s.LastDay).Hours()/24) - 1
+	if missed <= 0 {
+		return c
+	}
+	if missed > s.Freezes {
+		s.Current = 0
+		c.Broken = true
+		return c
+	}
+	s.Freezes -= missed
+	covered := today.AddDate(0, 0, -1)
+	s.LastDay = &covered
+	c.FreezesUsed = missed
+	return c
+}
+
+// Record counts activity on today towards the streak. Activity on a day
+// already counted changes nothing.
+func Record(s *State, today time.Time) Change {
+	c := Settle(s, today)
+	if s.LastDay != nil && !s.LastDay.Before(today) {
+		return c
+	}
+	if s.Current > 0 && s.LastDay != nil && s.LastDay.AddDate(0, 0, 1).Equal(today) {
+		s.Current++
+	} else {
+		s.Current = 1
+	}
+	s.LastDay = &today
+	if s.Current > s.Longest {
+		s.Longest = s.Current
+	}
+	c.Extended = true
+	if s.Current%FreezeEvery == 0 && s.Freezes < MaxFreezes {
+		s.Freezes++
+		c.FreezeEarned = true
+	}
+	return c
+}
+
+// AtRisk reports whether a streak will be lost, or cost a freeze, unless
+// there is activity before the end of the user's day: it was last extended
+// yesterday and it is RiskHour or later.
+func AtRisk(s State, now time.Time, loc *time.Location) bool {
+	if s.Current == 0 || s.LastDay == nil {
+		return false
+	}
+	today := Day(now, loc)
+	return s.LastDay.AddDate(0, 0, 1).Equal(today) && now.In(loc).Hour() >= RiskHour
+}
+
+// Milestone reports whether a streak length is worth celebrating
+func Milestone(days int) bool {
+	switch days {
+	case 3, 7, 14, 30, 50:
+		return true
+	}
+	return days > 0 && days%100 == 0
+}
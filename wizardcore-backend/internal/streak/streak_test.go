@@ -0,0 +1,75 @@
+package streak
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDay_UsesUserTimezone(t *testing.T) {
+	// 23:30 UTC on 4 March is already 5 March in Tokyo and still 4 March in
+	// New York
+	at := time.Date(2026, 3, 4, 23, 30, 0, 0, time.UTC)
+	if got := Day(at, Location("Asia/Tokyo")); !got.Equal(time.Date(2026, 3, 5, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected 2026-03-05 in Tokyo, got %s", got.Format("2006-01-02"))
+	}
+	if got := Day(at, Location("America/New_York")); !got.Equal(time.Date(2026, 3, 4, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected 2026-03-04 in New York, got %s", got.Format("2006-01-02"))
+	}
+	if Location("Not/AZone") != time.UTC {
+		t.Error("Expected unknown zones to fall back to UTC")
+	}
+}
+
+func TestRecord_ExtendsResetsAndEarnsFreezes(t *testing.T) {
+	var s State
+	if c := Record(&s, time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)); !c.Extended || s.Current != 1 {
+		t.Fatalf("Expected a new streak of 1, got %d (%+v)", s.Current, c)
+	}
+	if c := Record(&s, time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)); c.Extended || s.Current != 1 {
+		t.Errorf("Expected a second activity on the same day to change nothing, got %d (%+v)", s.Current, c)
+	}
+	for d := 2; d <= 7; d++ {
+		Record(&s, time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC).AddDate(0, 0, d-1))
+	}
+	if s.Current != 7 || s.Freezes != 1 {
+		t.Fatalf("Expected a 7 day streak with a freeze, got %d with %d", s.Current, s.Freezes)
+	}
+
+	// Missing the 8th is covered by the freeze
+	c := Record(&s, time.Date(2026, 3, 9, 0, 0, 0, 0, time.UTC))
+	if c.FreezesUsed != 1 || s.Current != 8 || s.Freezes != 0 {
+		t.Errorf("Expected the freeze to keep the streak, got %d with %d freezes (%+v)", s.Current, s.Freezes, c)
+	}
+
+	// Two missed days without freezes break it
+	c = Record(&s, time.Date(2026, 3, 12, 0, 0, 0, 0, time.UTC))
+	if !c.Broken || s.Current != 1 || s.Longest != 8 {
+		t.Errorf("Expected the streak to restart at 1 keeping the longest 8, got %d/%d (%+v)", s.Current, s.Longest, c)
+	}
+}
+
+func TestSettle_AndAtRisk(t *testing.T) {
+	last := time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC)
+	s := State{Current: 5, Longest: 5, Freezes: 1, LastDay: &last}
+
+	if c := Settle(&s, time.Date(2026, 3, 11, 0, 0, 0, 0, time.UTC)); c != (Change{}) {
+		t.Errorf("Expected nothing to settle the day after, got %+v", c)
+	}
+
+	tokyo := Location("Asia/Tokyo")
+	evening := time.Date(2026, 3, 11, 12, 0, 0, 0, time.UTC) // 21:00 in Tokyo
+	if !AtRisk(s, evening, tokyo) {
+		t.Error("Expected the streak to be at risk in the evening")
+	}
+	if AtRisk(s, evening, time.UTC) {
+		t.Error("Expected the streak not to be at risk at noon")
+	}
+
+	// Two missed days with one freeze
+	if c := Settle(&s, time.Date(2026, 3, 13, 0, 0, 0, 0, time.UTC)); !c.Broken || c.FreezesUsed != 0 {
+		t.Errorf("Expected the streak to break without using the freeze, got %+v", c)
+	}
+	if s.Current != 0 || s.Freezes != 1 {
+		t.Errorf("Expected a broken streak to keep its freeze, got %d with %d", s.Current, s.Freezes)
+	}
+}
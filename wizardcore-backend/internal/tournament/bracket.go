@@ -0,0 +1,359 @@
+// Package tournament builds and advances tournament brackets. It works on
+// plain in-memory nodes so the rules can be tested without a database; the
+// caller loads the nodes, applies a result and stores what changed.
+package tournament
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/google/uuid"
+)
+
+// Tournament formats
+const (
+	SingleElimination = "single_elimination"
+	DoubleElimination = "double_elimination"
+	Swiss             = "swiss"
+)
+
+// Bracket names
+const (
+	Winners    = "winners"
+	Losers     = "losers"
+	GrandFinal = "grand_final"
+	SwissRound = "swiss"
+)
+
+// ErrNotReady is returned when a result is reported for a node whose players
+// are not both known yet, or that already has a result.
+var ErrNotReady = errors.New("match is not ready to be decided")
+
+// Slot is one side of a node. Filled is set once the feeding node has been
+// decided; Player stays uuid.Nil if nobody comes through, as with a bye.
+type Slot struct {
+	Player uuid.UUID
+	Filled bool
+}
+
+// Link points at a slot of a later node
+type Link struct {
+	Node int
+	Slot int
+}
+
+// Node is one match of a bracket. Links refer to indexes in the node slice.
+// A decided node with two players and no winner is a draw, which only Swiss
+// allows.
+type Node struct {
+	Bracket   string
+	Round     int
+	Position  int
+	Slots     [2]Slot
+	Winner    uuid.UUID
+	Done      bool
+	Next      *Link
+	LoserNext *Link
+}
+
+// Ready reports whether both players are known and the node is undecided
+func (n *Node) Ready() bool {
+	return !n.Done && n.Slots[0].Filled && n.Slots[1].Filled &&
+		n.Slots[0].Player != uuid.Nil && n.Slots[1].Player != uuid.Nil
+}
+
+// Has reports whether the player plays in the node
+func (n *Node) Has(player uuid.UUID) bool {
+	return player != uuid.Nil && (n.Slots[0].Player == player || n.Slots[1].Player == player)
+}
+
+// Loser returns the player who lost a decided node, or uuid.Nil for a bye,
+// an empty node or a draw
+func (n *Node) Loser() uuid.UUID {
+	if !n.Done || n.Winner == uuid.Nil {
+		return uuid.Nil
+	}
+	if n.Slots[0].Player == n.Winner {
+		return n.Slots[1].Player
+	}
+	return n.Slots[0].Player
+}
+
+// SeedOrder returns seeds 1..size in bracket order, so that adjacent pairs
+// are first-round matches and the top seeds can only meet late. size must be
+// a power of two.
+func SeedOrder(size int) []int {
+	order := []int{1}
+	for len(order) < size {
+		n := len(order) * 2
+		next := make([]int, 0, n)
+		for _, seed := range order {
+			next = append(next, seed, n+1-seed)
+		}
+		order = next
+	}
+	return order
+}
+
+// bracketSize returns the smallest power of two holding n players and the
+// number of rounds it takes
+func bracketSize(n int) (size, rounds int) {
+	size = 1
+	for size < n {
+		size *= 2
+		rounds++
+	}
+	return size, rounds
+}
+
+// SingleEliminationBracket builds a single-elimination bracket for players
+// ordered best seed first. Missing players become byes for the top seeds.
+func SingleEliminationBracket(players []uuid.UUID) ([]Node, error) {
+	if len(players) < 2 {
+		return nil, fmt.Errorf("single elimination needs at least 2 players")
+	}
+	nodes, _ := winnersBracket(players)
+	Advance(nodes)
+	return nodes, nil
+}
+
+// winnersBracket lays out a seeded elimination bracket and returns the nodes
+// and the index of the first node of every round
+func winnersBracket(players []uuid.UUID) ([]Node, []int) {
+	size, rounds := bracketSize(len(players))
+	order := SeedOrder(size)
+
+	var nodes []Node
+	roundStart := make([]int, rounds+1)
+	for round := 1; round <= rounds; round++ {
+		roundStart[round] = len(nodes)
+		count := size >> round
+		for pos := 0; pos < count; pos++ {
+			node := Node{Bracket: Winners, Round: round, Position: pos}
+			if round == 1 {
+				for slot := 0; slot < 2; slot++ {
+					seed := order[2*pos+slot]
+					node.Slots[slot].Filled = true
+					if seed <= len(players) {
+						node.Slots[slot].Player = players[seed-1]
+					}
+				}
+			}
+			if round < rounds {
+				node.Next = &Link{Node: roundStart[round] + count + pos/2, Slot: pos % 2}
+			}
+			nodes = append(nodes, node)
+		}
+	}
+	return nodes, roundStart
+}
+
+// DoubleEliminationBracket builds a double-elimination bracket for players
+// ordered best seed first. Losers of the winners bracket drop into the losers
+// bracket, and the two bracket winners meet once in the grand final; there
+// is no bracket reset.
+func DoubleEliminationBracket(players []uuid.UUID) ([]Node, error) {
+	if len(players) < 3 {
+		return nil, fmt.Errorf("double elimination needs at least 3 players")
+	}
+	nodes, winnersStart := winnersBracket(players)
+	size, rounds := bracketSize(len(players))
+
+	// The losers bracket has two rounds per winners round after the first.
+	// Odd rounds pair up survivors; even rounds bring in the losers of the
+	// next winners round.
+	losersStart := make([]int, 2*(rounds-1)+1)
+	for j := 1; j < rounds; j++ {
+		count := size >> (j + 1)
+		for _, lr := range []int{2*j - 1, 2 * j} {
+			losersStart[lr] = len(nodes)
+			for pos := 0; pos < count; pos++ {
+				nodes = append(nodes, Node{Bracket: Losers, Round: lr, Position: pos})
+			}
+		}
+	}
+	grandFinal := len(nodes)
+	nodes = append(nodes, Node{Bracket: GrandFinal, Round: 1})
+
+	for j := 1; j < rounds; j++ {
+		count := size >> (j + 1)
+		odd, even := losersStart[2*j-1], losersStart[2*j]
+		for pos := 0; pos < count; pos++ {
+			if j == 1 {
+				// Losers of winners round 1 pair up
+				nodes[winnersStart[1]+2*pos].LoserNext = &Link{Node: odd + pos, Slot: 0}
+				nodes[winnersStart[1]+2*pos+1].LoserNext = &Link{Node: odd + pos, Slot: 1}
+			}
+			nodes[odd+pos].Next = &Link{Node: even + pos, Slot: 0}
+			// Drop-ins come in reverse order every other round so that
+			// players who met in the winners bracket don't meet again at once
+			drop := pos
+			if j%2 == 1 {
+				drop = count - 1 - pos
+			}
+			nodes[winnersStart[j+1]+drop].LoserNext = &Link{Node: even + pos, Slot: 1}
+			if j < rounds-1 {
+				nodes[even+pos].Next = &Link{Node: losersStart[2*j+1] + pos/2, Slot: pos % 2}
+			} else {
+				nodes[even+pos].Next = &Link{Node: grandFinal, Slot: 1}
+			}
+		}
+	}
+	nodes[winnersStart[rounds]].Next = &Link{Node: grandFinal, Slot: 0}
+
+	Advance(nodes)
+	return nodes, nil
+}
+
+// Advance settles every node that can be decided without playing: a node
+// with one player passes them on, a node with none passes on nobody. It
+// returns the indexes of the nodes that changed.
+func Advance(nodes []Node) []int {
+	changed := make(map[int]bool)
+	for progress := true; progress; {
+		progress = false
+		for i := range nodes {
+			n := &nodes[i]
+			if n.Done || !n.Slots[0].Filled || !n.Slots[1].Filled {
+				continue
+			}
+			a, b := n.Slots[0].Player, n.Slots[1].Player
+			if a != uuid.Nil && b != uuid.Nil {
+				continue
+			}
+			n.Done = true
+			n.Winner = a
+			if a == uuid.Nil {
+				n.Winner = b
+			}
+			changed[i] = true
+			for _, j := range propagate(nodes, i) {
+				changed[j] = true
+			}
+			progress = true
+		}
+	}
+	return sortedKeys(changed)
+}
+
+// propagate moves the winner and loser of a decided node into the slots
+// they link to
+func propagate(nodes []Node, i int) []int {
+	n := &nodes[i]
+	var changed []int
+	if n.Next != nil {
+		nodes[n.Next.Node].Slots[n.Next.Slot] = Slot{Player: n.Winner, Filled: true}
+		changed = append(changed, n.Next.Node)
+	}
+	if n.LoserNext != nil {
+		nodes[n.LoserNext.Node].Slots[n.LoserNext.Slot] = Slot{Player: n.Loser(), Filled: true}
+		changed = append(changed, n.LoserNext.Node)
+	}
+	return changed
+}
+
+// Report records the result of a played node and moves the players on. A
+// nil winner is a draw, which is only allowed in Swiss. It returns the
+// indexes of the nodes that changed.
+func Report(nodes []Node, i int, winner uuid.UUID) ([]int, error) {
+	if i < 0 || i >= len(nodes) {
+		return nil, fmt.Errorf("match %d does not exist", i)
+	}
+	n := &nodes[i]
+	if !n.Ready() {
+		return nil, ErrNotReady
+	}
+	if winner == uuid.Nil && n.Bracket != SwissRound {
+		return nil, fmt.Errorf("elimination matches cannot end in a draw")
+	}
+	if winner != uuid.Nil && !n.Has(winner) {
+		return nil, fmt.Errorf("winner does not play in this match")
+	}
+	n.Done = true
+	n.Winner = winner
+	changed := map[int]bool{i: true}
+	for _, j := range propagate(nodes, i) {
+		changed[j] = true
+	}
+	for _, j := range Advance(nodes) {
+		changed[j] = true
+	}
+	return sortedKeys(changed), nil
+}
+
+// Override replaces the result of a decided node. The nodes its players
+// moved on to must not have been decided yet. It returns the indexes of the
+// nodes that changed.
+func Override(nodes []Node, i int, winner uuid.UUID) ([]int, error) {
+	if i < 0 || i >= len(nodes) {
+		return nil, fmt.Errorf("match %d does not exist", i)
+	}
+	n := &nodes[i]
+	if !n.Done {
+		return Report(nodes, i, winner)
+	}
+	if n.Slots[0].Player == uuid.Nil || n.Slots[1].Player == uuid.Nil {
+		return nil, fmt.Errorf("a bye cannot be overridden")
+	}
+	if winner == uuid.Nil && n.Bracket != SwissRound {
+		return nil, fmt.Errorf("elimination matches cannot end in a draw")
+	}
+	if winner != uuid.Nil && !n.Has(winner) {
+		return nil, fmt.Errorf("winner does not play in this match")
+	}
+	for _, link := range []*Link{n.Next, n.LoserNext} {
+		if link != nil && nodes[link.Node].Done {
+			return nil, fmt.Errorf("a later match has already been decided")
+		}
+	}
+	if winner == n.Winner {
+		return nil, nil
+	}
+	n.Winner = winner
+	changed := map[int]bool{i: true}
+	for _, j := range propagate(nodes, i) {
+		changed[j] = true
+	}
+	for _, j := range Advance(nodes) {
+		changed[j] = true
+	}
+	return sortedKeys(changed), nil
+}
+
+// Champion returns the winner of an elimination bracket once its final has
+// been decided
+func Champion(nodes []Node) (uuid.UUID, bool) {
+	for i := range nodes {
+		n := &nodes[i]
+		if n.Bracket != SwissRound && n.Next == nil && n.LoserNext == nil {
+			return n.Winner, n.Done
+		}
+	}
+	return uuid.Nil, false
+}
+
+// Eliminated returns the players knocked out of an elimination bracket: those
+// who lost a node that has nowhere to send its loser
+func Eliminated(nodes []Node) []uuid.UUID {
+	var out []uuid.UUID
+	for i := range nodes {
+		n := &nodes[i]
+		if n.Bracket == SwissRound || n.LoserNext != nil {
+			continue
+		}
+		if loser := n.Loser(); loser != uuid.Nil {
+			out = append(out, loser)
+		}
+	}
+	return out
+}
+
+func sortedKeys(m map[int]bool) []int {
+	keys := make([]int, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Ints(keys)
+	return keys
+}
@@ -0,0 +1,177 @@
+package tournament
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func players(n int) []uuid.UUID {
+	ids := make([]uuid.UUID, n)
+	for i := range ids {
+		ids[i] = uuid.New()
+	}
+	return ids
+}
+
+// playOut decides every ready node in favour of the better seed until the
+// bracket is finished
+func playOut(t *testing.T, nodes []Node, seeds []uuid.UUID) {
+	t.Helper()
+	rank := make(map[uuid.UUID]int)
+	for i, p := range seeds {
+		rank[p] = i
+	}
+	for played := true; played; {
+		played = false
+		for i := range nodes {
+			if !nodes[i].Ready() {
+				continue
+			}
+			a, b := nodes[i].Slots[0].Player, nodes[i].Slots[1].Player
+			winner := a
+			if rank[b] < rank[a] {
+				winner = b
+			}
+			if _, err := Report(nodes, i, winner); err != nil {
+				t.Fatalf("Report(%d): %v", i, err)
+			}
+			played = true
+		}
+	}
+}
+
+func TestSeedOrder(t *testing.T) {
+	got := SeedOrder(8)
+	want := []int{1, 8, 4, 5, 2, 7, 3, 6}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+}
+
+func TestSingleElimination_ByesGoToTopSeeds(t *testing.T) {
+	seeds := players(5)
+	nodes, err := SingleEliminationBracket(seeds)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(nodes) != 7 {
+		t.Fatalf("Expected 7 nodes for 8 slots, got %d", len(nodes))
+	}
+	byes := 0
+	for _, n := range nodes {
+		if n.Round == 1 && n.Done {
+			byes++
+			if n.Winner != seeds[0] && n.Winner != seeds[1] && n.Winner != seeds[2] {
+				t.Errorf("Expected a top-3 seed to get a bye, got %v", n.Winner)
+			}
+		}
+	}
+	if byes != 3 {
+		t.Errorf("Expected 3 byes, got %d", byes)
+	}
+
+	playOut(t, nodes, seeds)
+	champion, done := Champion(nodes)
+	if !done || champion != seeds[0] {
+		t.Errorf("Expected top seed to win, got %v (done=%v)", champion, done)
+	}
+	if out := Eliminated(nodes); len(out) != 4 {
+		t.Errorf("Expected 4 eliminated players, got %d", len(out))
+	}
+}
+
+func TestDoubleElimination_EveryoneLosesTwiceExceptChampion(t *testing.T) {
+	for _, n := range []int{3, 4, 6, 8, 13, 16} {
+		seeds := players(n)
+		nodes, err := DoubleEliminationBracket(seeds)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// Let the worse seed win every winners bracket match so everyone
+		// drops into the losers bracket at some point
+		rank := make(map[uuid.UUID]int)
+		for i, p := range seeds {
+			rank[p] = i
+		}
+		losses := make(map[uuid.UUID]int)
+		for played := true; played; {
+			played = false
+			for i := range nodes {
+				if !nodes[i].Ready() {
+					continue
+				}
+				a, b := nodes[i].Slots[0].Player, nodes[i].Slots[1].Player
+				winner := a
+				if (rank[b] < rank[a]) == (nodes[i].Bracket != Winners) {
+					winner = b
+				}
+				if _, err := Report(nodes, i, winner); err != nil {
+					t.Fatalf("n=%d Report(%d): %v", n, i, err)
+				}
+				losses[nodes[i].Loser()]++
+				played = true
+			}
+		}
+
+		champion, done := Champion(nodes)
+		if !done {
+			t.Fatalf("n=%d: bracket did not finish", n)
+		}
+		if len(Eliminated(nodes)) != n-1 {
+			t.Errorf("n=%d: expected %d eliminated, got %d", n, n-1, len(Eliminated(nodes)))
+		}
+		for _, p := range seeds {
+			if p == champion {
+				if losses[p] > 1 {
+					t.Errorf("n=%d: champion lost %d times", n, losses[p])
+				}
+				continue
+			}
+			if losses[p] != 2 && !(losses[p] == 1 && nodes[len(nodes)-1].Loser() == p) {
+				t.Errorf("n=%d: player seeded %d lost %d times", n, rank[p]+1, losses[p])
+			}
+		}
+	}
+}
+
+func TestReport_RejectsUnknownWinnerAndDraw(t *testing.T) {
+	seeds := players(4)
+	nodes, _ := SingleEliminationBracket(seeds)
+	if _, err := Report(nodes, 0, uuid.New()); err == nil {
+		t.Error("Expected an error for a winner not in the match")
+	}
+	if _, err := Report(nodes, 0, uuid.Nil); err == nil {
+		t.Error("Expected an error for a draw in elimination")
+	}
+	if _, err := Report(nodes, 2, seeds[0]); err != ErrNotReady {
+		t.Errorf("Expected ErrNotReady for the final, got %v", err)
+	}
+}
+
+func TestOverride_MovesNewWinnerOn(t *testing.T) {
+	seeds := players(4)
+	nodes, _ := SingleEliminationBracket(seeds)
+	// Round 1: seed 1 vs seed 4, seed 2 vs seed 3
+	if _, err := Report(nodes, 0, seeds[0]); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Override(nodes, 0, seeds[3]); err != nil {
+		t.Fatal(err)
+	}
+	if got := nodes[2].Slots[0].Player; got != seeds[3] {
+		t.Errorf("Expected overridden winner in the final, got %v", got)
+	}
+
+	if _, err := Report(nodes, 1, seeds[1]); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Report(nodes, 2, seeds[1]); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Override(nodes, 0, seeds[0]); err == nil {
+		t.Error("Expected override to fail once the final is decided")
+	}
+}
@@ -0,0 +1,200 @@
+package tournament
+
+import (
+	"sort"
+
+	"github.com/google/uuid"
+)
+
+// Points awarded per Swiss game
+const (
+	WinPoints  = 1.0
+	DrawPoints = 0.5
+	ByePoints  = 1.0
+)
+
+// Pairing attempts before Swiss pairing gives up avoiding rematches
+const swissSearchBudget = 100000
+
+// Standing is a player's Swiss score. Buchholz, the sum of the opponents'
+// points, breaks ties between players on equal points.
+type Standing struct {
+	Player   uuid.UUID `json:"player"`
+	Seed     int       `json:"seed"`
+	Points   float64   `json:"points"`
+	Buchholz float64   `json:"buchholz"`
+	Wins     int       `json:"wins"`
+	Draws    int       `json:"draws"`
+	Losses   int       `json:"losses"`
+	HadBye   bool      `json:"had_bye"`
+}
+
+// SwissRounds returns the number of rounds needed to find a clear winner
+// among n players
+func SwissRounds(n int) int {
+	_, rounds := bracketSize(n)
+	if rounds < 1 {
+		rounds = 1
+	}
+	return rounds
+}
+
+// SwissStandings scores the decided Swiss nodes. players are ordered best
+// seed first; the result is ordered by points, Buchholz and then seed.
+func SwissStandings(players []uuid.UUID, nodes []Node) []Standing {
+	byPlayer := make(map[uuid.UUID]*Standing, len(players))
+	standings := make([]Standing, len(players))
+	for i, player := range players {
+		standings[i] = Standing{Player: player, Seed: i + 1}
+		byPlayer[player] = &standings[i]
+	}
+	opponents := make(map[uuid.UUID][]uuid.UUID)
+
+	for i := range nodes {
+		n := &nodes[i]
+		if n.Bracket != SwissRound || !n.Done {
+			continue
+		}
+		a, b := n.Slots[0].Player, n.Slots[1].Player
+		if a == uuid.Nil || b == uuid.Nil {
+			if s := byPlayer[n.Winner]; s != nil {
+				s.Points += ByePoints
+				s.HadBye = true
+			}
+			continue
+		}
+		opponents[a] = append(opponents[a], b)
+		opponents[b] = append(opponents[b], a)
+		sa, sb := byPlayer[a], byPlayer[b]
+		if sa == nil || sb == nil {
+			continue
+		}
+		switch n.Winner {
+		case uuid.Nil:
+			sa.Points += DrawPoints
+			sb.Points += DrawPoints
+			sa.Draws++
+			sb.Draws++
+		case a:
+			sa.Points += WinPoints
+			sa.Wins++
+			sb.Losses++
+		default:
+			sb.Points += WinPoints
+			sb.Wins++
+			sa.Losses++
+		}
+	}
+
+	for i := range standings {
+		for _, opponent := range opponents[standings[i].Player] {
+			if s := byPlayer[opponent]; s != nil {
+				standings[i].Buchholz += s.Points
+			}
+		}
+	}
+	sort.SliceStable(standings, func(i, j int) bool {
+		a, b := standings[i], standings[j]
+		if a.Points != b.Points {
+			return a.Points > b.Points
+		}
+		if a.Buchholz != b.Buchholz {
+			return a.Buchholz > b.Buchholz
+		}
+		return a.Seed < b.Seed
+	})
+	return standings
+}
+
+// SwissPairings pairs players for the next round. standings must be ordered
+// as returned by SwissStandings. Players are paired with the closest-ranked
+// opponent they have not met yet; with an odd count, the lowest-ranked player
+// without a bye sits out. Rematches are only allowed if there is no other
+// way to pair everyone.
+func SwissPairings(standings []Standing, nodes []Node) (pairs [][2]uuid.UUID, bye uuid.UUID) {
+	ranked := make([]uuid.UUID, 0, len(standings))
+	for _, s := range standings {
+		ranked = append(ranked, s.Player)
+	}
+	if len(ranked)%2 == 1 {
+		pick := len(standings) - 1
+		for i := len(standings) - 1; i >= 0; i-- {
+			if !standings[i].HadBye {
+				pick = i
+				break
+			}
+		}
+		bye = ranked[pick]
+		ranked = append(ranked[:pick:pick], ranked[pick+1:]...)
+	}
+
+	met := make(map[[2]uuid.UUID]bool)
+	for i := range nodes {
+		n := &nodes[i]
+		if n.Bracket == SwissRound && n.Slots[0].Player != uuid.Nil && n.Slots[1].Player != uuid.Nil {
+			met[[2]uuid.UUID{n.Slots[0].Player, n.Slots[1].Player}] = true
+			met[[2]uuid.UUID{n.Slots[1].Player, n.Slots[0].Player}] = true
+		}
+	}
+
+	budget := swissSearchBudget
+	if pairs, ok := pairRemaining(ranked, met, &budget); ok {
+		return pairs, bye
+	}
+	// No rematch-free pairing: pair neighbours in rank order
+	pairs = nil
+	for i := 0; i+1 < len(ranked); i += 2 {
+		pairs = append(pairs, [2]uuid.UUID{ranked[i], ranked[i+1]})
+	}
+	return pairs, bye
+}
+
+// pairRemaining pairs the top remaining player with the best-ranked opponent
+// that still leaves a valid pairing for the rest, backtracking as needed
+func pairRemaining(ranked []uuid.UUID, met map[[2]uuid.UUID]bool, budget *int) ([][2]uuid.UUID, bool) {
+	if len(ranked) == 0 {
+		return nil, true
+	}
+	top := ranked[0]
+	for i := 1; i < len(ranked); i++ {
+		if *budget <= 0 {
+			return nil, false
+		}
+		*budget--
+		if met[[2]uuid.UUID{top, ranked[i]}] {
+			continue
+		}
+		rest := make([]uuid.UUID, 0, len(ranked)-2)
+		rest = append(rest, ranked[1:i]...)
+		rest = append(rest, ranked[i+1:]...)
+		if pairs, ok := pairRemaining(rest, met, budget); ok {
+			return append([][2]uuid.UUID{{top, ranked[i]}}, pairs...), true
+		}
+	}
+	return nil, false
+}
+
+// SwissRoundNodes lays out the nodes of a Swiss round. A bye is a node with
+// one player that is decided straight away.
+func SwissRoundNodes(round int, pairs [][2]uuid.UUID, bye uuid.UUID) []Node {
+	nodes := make([]Node, 0, len(pairs)+1)
+	for pos, pair := range pairs {
+		nodes = append(nodes, Node{
+			Bracket:  SwissRound,
+			Round:    round,
+			Position: pos,
+			Slots:    [2]Slot{{Player: pair[0], Filled: true}, {Player: pair[1], Filled: true}},
+		})
+	}
+	if bye != uuid.Nil {
+		nodes = append(nodes, Node{
+			Bracket:  SwissRound,
+			Round:    round,
+			Position: len(pairs),
+			Slots:    [2]Slot{{Player: bye, Filled: true}, {Filled: true}},
+			Winner:   bye,
+			Done:     true,
+		})
+	}
+	return nodes
+}
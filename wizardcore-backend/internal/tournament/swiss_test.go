@@ -0,0 +1,79 @@
+package tournament
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestSwiss_NoRematchesAndByesRotate(t *testing.T) {
+	seeds := players(7)
+	rounds := SwissRounds(len(seeds))
+	if rounds != 3 {
+		t.Fatalf("Expected 3 rounds for 7 players, got %d", rounds)
+	}
+	rank := make(map[uuid.UUID]int)
+	for i, p := range seeds {
+		rank[p] = i
+	}
+
+	var nodes []Node
+	byes := make(map[uuid.UUID]int)
+	for round := 1; round <= rounds; round++ {
+		pairs, bye := SwissPairings(SwissStandings(seeds, nodes), nodes)
+		if bye == uuid.Nil {
+			t.Fatalf("Expected a bye with an odd player count")
+		}
+		byes[bye]++
+		for _, pair := range pairs {
+			for i := range nodes {
+				if nodes[i].Has(pair[0]) && nodes[i].Has(pair[1]) {
+					t.Errorf("Round %d: rematch between seeds %d and %d", round, rank[pair[0]]+1, rank[pair[1]]+1)
+				}
+			}
+		}
+		start := len(nodes)
+		nodes = append(nodes, SwissRoundNodes(round, pairs, bye)...)
+		for i := start; i < len(nodes); i++ {
+			if !nodes[i].Ready() {
+				continue
+			}
+			a, b := nodes[i].Slots[0].Player, nodes[i].Slots[1].Player
+			winner := a
+			if rank[b] < rank[a] {
+				winner = b
+			}
+			if _, err := Report(nodes, i, winner); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	for player, n := range byes {
+		if n > 1 {
+			t.Errorf("Seed %d had %d byes", rank[player]+1, n)
+		}
+	}
+	standings := SwissStandings(seeds, nodes)
+	if standings[0].Player != seeds[0] || standings[0].Points != 3 {
+		t.Errorf("Expected top seed on 3 points first, got seed %d on %.1f", standings[0].Seed, standings[0].Points)
+	}
+}
+
+func TestSwissStandings_DrawsAndBuchholz(t *testing.T) {
+	seeds := players(4)
+	nodes := SwissRoundNodes(1, [][2]uuid.UUID{{seeds[0], seeds[1]}, {seeds[2], seeds[3]}}, uuid.Nil)
+	if _, err := Report(nodes, 0, uuid.Nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Report(nodes, 1, seeds[3]); err != nil {
+		t.Fatal(err)
+	}
+	standings := SwissStandings(seeds, nodes)
+	if standings[0].Player != seeds[3] || standings[0].Points != 1 {
+		t.Errorf("Expected seed 4 first on 1 point, got %+v", standings[0])
+	}
+	if standings[1].Points != 0.5 || standings[1].Buchholz != 0.5 || standings[1].Draws != 1 {
+		t.Errorf("Expected a drawn player on 0.5 with Buchholz 0.5, got %+v", standings[1])
+	}
+}
@@ -0,0 +1,150 @@
+package websocket
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+// Longest chat line relayed, in characters
+const maxChatLength = 1000
+
+// ChannelAuthorizer decides whether a user may join the channel of a kind
+// with the given ID
+type ChannelAuthorizer func(userID uuid.UUID, id string) bool
+
+// ChannelName returns the name of the channel of a kind for an ID, such as
+// "team:<id>"
+func ChannelName(kind string, id uuid.UUID) string {
+	return kind + ":" + id.String()
+}
+
+// AuthorizeChannels registers who may join channels of a kind. Channels of a
+// kind without an authorizer cannot be joined.
+func (h *Hub) AuthorizeChannels(kind string, authorize ChannelAuthorizer) {
+	h.authorizersMu.Lock()
+	defer h.authorizersMu.Unlock()
+	h.authorizers[kind] = authorize
+}
+
+// dispatchChannel handles channel joins, leaves and chat lines from a client
+func (h *Hub) dispatchChannel(client *Client, msg Message) {
+	switch msg.Type {
+	case ChatMessage:
+		var payload ChatPayload
+		if err := json.Unmarshal(msg.Payload, &payload); err != nil {
+			client.SendError("invalid chat payload")
+			return
+		}
+		text := strings.TrimSpace(payload.Text)
+		if text == "" || utf8.RuneCountInString(text) > maxChatLength {
+			client.SendError(fmt.Sprintf("chat messages must be 1 to %d characters", maxChatLength))
+			return
+		}
+		if !h.IsSubscribed(client, payload.Channel) {
+			client.SendError("not a member of this channel")
+			return
+		}
+		if err := h.Publish(payload.Channel, ChatMessage, ChatPayload{
+			Channel: payload.Channel,
+			UserID:  client.userID.String(),
+			Text:    text,
+			SentAt:  time.Now(),
+		}); err != nil {
+			client.SendError("failed to send chat message")
+		}
+	default:
+		var payload ChannelPayload
+		if err := json.Unmarshal(msg.Payload, &payload); err != nil {
+			client.SendError("invalid channel payload")
+			return
+		}
+		if msg.Type == ChannelLeave {
+			h.Unsubscribe(client, payload.Channel)
+			client.sendControl(ChannelJoined, ChannelPayload{Channel: payload.Channel, Joined: false})
+			return
+		}
+		if !h.authorize(client.userID, payload.Channel) {
+			client.SendError("not allowed to join this channel")
+			return
+		}
+		h.Subscribe(client, payload.Channel)
+		client.sendControl(ChannelJoined, ChannelPayload{Channel: payload.Channel, Joined: true})
+	}
+}
+
+// authorize asks the authorizer of the channel's kind
+func (h *Hub) authorize(userID uuid.UUID, channel string) bool {
+	kind, id, ok := strings.Cut(channel, ":")
+	if !ok || id == "" {
+		return false
+	}
+	h.authorizersMu.RLock()
+	authorize, ok := h.authorizers[kind]
+	h.authorizersMu.RUnlock()
+	return ok && authorize(userID, id)
+}
+
+// Subscribe adds a connection to a channel
+func (h *Hub) Subscribe(client *Client, channel string) {
+	h.channelsMu.Lock()
+	defer h.channelsMu.Unlock()
+	if _, ok := h.channels[channel]; !ok {
+		h.channels[channel] = make(map[*Client]bool)
+	}
+	h.channels[channel][client] = true
+}
+
+// Unsubscribe removes a connection from a channel
+func (h *Hub) Unsubscribe(client *Client, channel string) {
+	h.channelsMu.Lock()
+	defer h.channelsMu.Unlock()
+	if members, ok := h.channels[channel]; ok {
+		delete(members, client)
+		if len(members) == 0 {
+			delete(h.channels, channel)
+		}
+	}
+}
+
+// IsSubscribed reports whether a connection has joined a channel
+func (h *Hub) IsSubscribed(client *Client, channel string) bool {
+	h.channelsMu.RLock()
+	defer h.channelsMu.RUnlock()
+	return h.channels[channel][client]
+}
+
+// CloseChannel unsubscribes every connection from a channel
+func (h *Hub) CloseChannel(channel string) {
+	h.channelsMu.Lock()
+	defer h.channelsMu.Unlock()
+	delete(h.channels, channel)
+}
+
+// Publish delivers a transient event to every connection subscribed to a
+// channel. Like SendLive, it is not sequenced.
+func (h *Hub) Publish(channel string, msgType MessageType, payload interface{}) error {
+	msg := Message{Type: msgType}
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return fmt.Errorf("failed to marshal payload: %w", err)
+		}
+		msg.Payload = data
+	}
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("failed to marshal message: %w", err)
+	}
+
+	h.channelsMu.RLock()
+	defer h.channelsMu.RUnlock()
+	for client := range h.channels[channel] {
+		client.enqueue(data)
+	}
+	return nil
+}
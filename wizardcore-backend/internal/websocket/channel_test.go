@@ -0,0 +1,113 @@
+package websocket
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func testClient(h *Hub) *Client {
+	return &Client{hub: h, send: make(chan []byte, 16), userID: uuid.New()}
+}
+
+// frame sends a client frame through the hub's dispatcher
+func frame(t *testing.T, h *Hub, client *Client, msgType MessageType, payload interface{}) {
+	t.Helper()
+	data, err := json.Marshal(payload)
+	if err != nil {
+		t.Fatalf("marshal payload: %v", err)
+	}
+	raw, err := json.Marshal(Message{Type: msgType, Payload: data})
+	if err != nil {
+		t.Fatalf("marshal message: %v", err)
+	}
+	h.dispatch(client, raw)
+}
+
+// drain returns the types of the frames queued for a client
+func drain(client *Client) []MessageType {
+	var types []MessageType
+	for {
+		select {
+		case data := <-client.send:
+			var msg Message
+			json.Unmarshal(data, &msg)
+			types = append(types, msg.Type)
+		default:
+			return types
+		}
+	}
+}
+
+func TestChannels_JoinRequiresAuthorization(t *testing.T) {
+	h := NewHub()
+	member, outsider := testClient(h), testClient(h)
+	team := uuid.New()
+	h.AuthorizeChannels("team", func(userID uuid.UUID, id string) bool {
+		return userID == member.userID && id == team.String()
+	})
+	channel := ChannelName("team", team)
+
+	frame(t, h, member, ChannelJoin, ChannelPayload{Channel: channel})
+	frame(t, h, outsider, ChannelJoin, ChannelPayload{Channel: channel})
+	frame(t, h, outsider, ChannelJoin, ChannelPayload{Channel: ChannelName("match", team)})
+
+	if got := drain(member); len(got) != 1 || got[0] != ChannelJoined {
+		t.Errorf("member frames = %v, want [channel_joined]", got)
+	}
+	if got := drain(outsider); len(got) != 2 || got[0] != Error || got[1] != Error {
+		t.Errorf("outsider frames = %v, want two errors", got)
+	}
+	if !h.IsSubscribed(member, channel) || h.IsSubscribed(outsider, channel) {
+		t.Error("only the member should be subscribed")
+	}
+}
+
+func TestChannels_ChatReachesSubscribersOnly(t *testing.T) {
+	h := NewHub()
+	alice, bob, carol := testClient(h), testClient(h), testClient(h)
+	channel := ChannelName("team", uuid.New())
+	h.Subscribe(alice, channel)
+	h.Subscribe(bob, channel)
+
+	frame(t, h, alice, ChatMessage, ChatPayload{Channel: channel, Text: "  go left  "})
+	frame(t, h, carol, ChatMessage, ChatPayload{Channel: channel, Text: "let me in"})
+	frame(t, h, alice, ChatMessage, ChatPayload{Channel: channel, Text: "   "})
+
+	data := <-bob.send
+	var msg Message
+	if err := json.Unmarshal(data, &msg); err != nil || msg.Type != ChatMessage {
+		t.Fatalf("bob got %s, want a chat message", data)
+	}
+	var chat ChatPayload
+	json.Unmarshal(msg.Payload, &chat)
+	if chat.Text != "go left" || chat.UserID != alice.userID.String() {
+		t.Errorf("chat = %+v, want trimmed text from alice", chat)
+	}
+	if got := drain(bob); len(got) != 0 {
+		t.Errorf("bob got extra frames %v", got)
+	}
+	if got := drain(alice); len(got) != 2 || got[0] != ChatMessage || got[1] != Error {
+		t.Errorf("alice frames = %v, want her own message and an error", got)
+	}
+	if got := drain(carol); len(got) != 1 || got[0] != Error {
+		t.Errorf("carol frames = %v, want an error", got)
+	}
+}
+
+func TestChannels_RemovedClientLeavesChannels(t *testing.T) {
+	h := NewHub()
+	client := testClient(h)
+	h.clients[client] = true
+	channel := ChannelName("match", uuid.New())
+	h.Subscribe(client, channel)
+
+	h.removeClient(client)
+	if h.IsSubscribed(client, channel) {
+		t.Error("removed client is still subscribed")
+	}
+	if len(h.channels) != 0 {
+		t.Errorf("empty channel was kept: %v", h.channels)
+	}
+}
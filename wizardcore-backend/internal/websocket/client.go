@@ -1,6 +1,7 @@
 package websocket
 
 import (
+	"encoding/json"
 	"log"
 	"sync"
 	"time"
@@ -21,6 +22,10 @@ const (
 
 	// Maximum message size allowed from peer.
 	maxMessageSize = 512 * 1024 // 512KB
+
+	// Time a new connection has to send resume before the sequenced events
+	// held back for it are delivered.
+	resumeGrace = 5 * time.Second
 )
 
 // Client represents a WebSocket connection
@@ -41,17 +46,36 @@ type Client struct {
 
 	// Mutex for protecting matchID
 	mu sync.RWMutex
+
+	// Guards send against writes after it is closed.
+	sendMu sync.Mutex
+	closed bool
+
+	// Sequenced events held back while the connection may still resume, so
+	// they are delivered after the older events the resume replays. Guarded
+	// by sendMu.
+	holding  bool
+	resuming bool
+	held     []heldEvent
+}
+
+type heldEvent struct {
+	seq  uint64
+	data []byte
 }
 
 // NewClient creates a new client
 func NewClient(hub *Hub, conn *websocket.Conn, userID uuid.UUID) *Client {
-	return &Client{
+	c := &Client{
 		hub:     hub,
 		conn:    conn,
 		send:    make(chan []byte, 256),
 		userID:  userID,
 		matchID: nil,
+		holding: true,
 	}
+	time.AfterFunc(resumeGrace, c.endGrace)
+	return c
 }
 
 // ReadPump pumps messages from the WebSocket connection to the hub.
@@ -76,7 +100,7 @@ func (c *Client) ReadPump() {
 			}
 			break
 		}
-		c.hub.Broadcast <- message
+		c.hub.dispatch(c, message)
 	}
 }
 
@@ -98,19 +122,9 @@ func (c *Client) WritePump() {
 				return
 			}
 
-			w, err := c.conn.NextWriter(websocket.TextMessage)
-			if err != nil {
-				return
-			}
-			w.Write(message)
-
-			// Add queued messages to the current WebSocket message.
-			n := len(c.send)
-			for i := 0; i < n; i++ {
-				w.Write(<-c.send)
-			}
-
-			if err := w.Close(); err != nil {
+			// Each message gets its own frame so clients can decode
+			// sequenced events one at a time.
+			if err := c.conn.WriteMessage(websocket.TextMessage, message); err != nil {
 				return
 			}
 		case <-ticker.C:
@@ -122,6 +136,115 @@ func (c *Client) WritePump() {
 	}
 }
 
+// UserID returns the internal user ID of the connection
+func (c *Client) UserID() uuid.UUID {
+	return c.userID
+}
+
+// enqueue queues a frame for the write pump without blocking. It returns
+// false when the buffer is full or the client is already closed.
+func (c *Client) enqueue(message []byte) bool {
+	c.sendMu.Lock()
+	defer c.sendMu.Unlock()
+	return c.enqueueLocked(message)
+}
+
+func (c *Client) enqueueLocked(message []byte) bool {
+	if c.closed {
+		return false
+	}
+	select {
+	case c.send <- message:
+		return true
+	default:
+		return false
+	}
+}
+
+// deliver queues a sequenced event, or holds it back while the connection
+// may still resume. It returns false when the event was dropped.
+func (c *Client) deliver(seq uint64, message []byte) bool {
+	c.sendMu.Lock()
+	defer c.sendMu.Unlock()
+	if !c.holding {
+		return c.enqueueLocked(message)
+	}
+	if c.closed || len(c.held) >= cap(c.send) {
+		return false
+	}
+	c.held = append(c.held, heldEvent{seq: seq, data: message})
+	return true
+}
+
+// beginResume holds back sequenced events until endResume
+func (c *Client) beginResume() {
+	c.sendMu.Lock()
+	defer c.sendMu.Unlock()
+	c.holding = true
+	c.resuming = true
+}
+
+// endResume queues the events held back during a resume, skipping those up
+// to after that the replay already delivered
+func (c *Client) endResume(after uint64) {
+	c.sendMu.Lock()
+	defer c.sendMu.Unlock()
+	c.releaseLocked(after)
+}
+
+// endGrace delivers the events held back for a connection that has not
+// started resuming
+func (c *Client) endGrace() {
+	c.sendMu.Lock()
+	defer c.sendMu.Unlock()
+	if c.holding && !c.resuming {
+		c.releaseLocked(0)
+	}
+}
+
+func (c *Client) releaseLocked(after uint64) {
+	for _, event := range c.held {
+		if event.seq > after {
+			c.enqueueLocked(event.data)
+		}
+	}
+	c.held = nil
+	c.holding = false
+	c.resuming = false
+}
+
+// closeSend closes the send channel once.
+func (c *Client) closeSend() {
+	c.sendMu.Lock()
+	defer c.sendMu.Unlock()
+	if !c.closed {
+		c.closed = true
+		close(c.send)
+	}
+}
+
+// sendControl queues an unsequenced control frame for this connection only.
+func (c *Client) sendControl(msgType MessageType, payload interface{}) {
+	msg := Message{Type: msgType}
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return
+		}
+		msg.Payload = data
+	}
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return
+	}
+	c.enqueue(data)
+}
+
+// SendError queues an error frame for this connection only.
+func (c *Client) SendError(message string) {
+	c.sendControl(Error, ErrorPayload{Message: message})
+}
+
 // SetMatchID sets the match ID for the client
 func (c *Client) SetMatchID(matchID *uuid.UUID) {
 	c.mu.Lock()
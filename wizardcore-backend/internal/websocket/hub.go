@@ -1,17 +1,28 @@
 package websocket
 
 import (
+	"encoding/json"
+	"fmt"
 	"log"
 	"sync"
 
 	"github.com/google/uuid"
 )
 
+// InboundHandler handles a decoded message received from a client.
+type InboundHandler func(client *Client, msg Message)
+
 // Hub maintains the set of active clients and broadcasts messages.
 type Hub struct {
 	// Registered clients.
 	clients map[*Client]bool
 
+	// Connected clients per user. A user may have several open sockets.
+	userClients map[uuid.UUID]map[*Client]bool
+
+	// Mutex for clients and userClients
+	clientsMu sync.RWMutex
+
 	// Inbound messages from the clients.
 	Broadcast chan []byte
 
@@ -26,53 +37,272 @@ type Hub struct {
 
 	// Mutex for rooms
 	roomsMu sync.RWMutex
+
+	// Sequenced outbound events kept for resuming clients.
+	replay ReplayStore
+
+	// Handlers for inbound message types.
+	handlers   map[MessageType]InboundHandler
+	handlersMu sync.RWMutex
+
+	// Named channels (team chat, match rooms): channel -> set of clients
+	channels   map[string]map[*Client]bool
+	channelsMu sync.RWMutex
+
+	// Who may join the channels of each kind
+	authorizers   map[string]ChannelAuthorizer
+	authorizersMu sync.RWMutex
 }
 
 // NewHub creates a new hub
 func NewHub() *Hub {
 	return &Hub{
-		Broadcast:  make(chan []byte),
-		Register:   make(chan *Client),
-		Unregister: make(chan *Client),
-		clients:    make(map[*Client]bool),
-		rooms:      make(map[uuid.UUID]map[*Client]bool),
+		Broadcast:   make(chan []byte),
+		Register:    make(chan *Client),
+		Unregister:  make(chan *Client),
+		clients:     make(map[*Client]bool),
+		userClients: make(map[uuid.UUID]map[*Client]bool),
+		rooms:       make(map[uuid.UUID]map[*Client]bool),
+		replay:      NewMemoryReplayStore(defaultReplayBufferSize, defaultReplayTTL),
+		handlers:    make(map[MessageType]InboundHandler),
+		channels:    make(map[string]map[*Client]bool),
+		authorizers: make(map[string]ChannelAuthorizer),
 	}
 }
 
+// SetReplayStore replaces the replay store. Call before serving connections.
+func (h *Hub) SetReplayStore(store ReplayStore) {
+	h.replay = store
+}
+
+// Handle registers a handler for an inbound message type. Messages without a
+// handler keep the legacy behaviour of being broadcast to every client.
+func (h *Hub) Handle(msgType MessageType, handler InboundHandler) {
+	h.handlersMu.Lock()
+	defer h.handlersMu.Unlock()
+	h.handlers[msgType] = handler
+}
+
 // Run starts the hub
 func (h *Hub) Run() {
 	for {
 		select {
 		case client := <-h.Register:
+			h.clientsMu.Lock()
 			h.clients[client] = true
-		case client := <-h.Unregister:
-			if _, ok := h.clients[client]; ok {
-				delete(h.clients, client)
-				close(client.send)
-				// Remove from any room
-				h.roomsMu.Lock()
-				for matchID, room := range h.rooms {
-					if _, ok := room[client]; ok {
-						delete(room, client)
-						if len(room) == 0 {
-							delete(h.rooms, matchID)
-						}
-					}
-				}
-				h.roomsMu.Unlock()
+			if _, ok := h.userClients[client.userID]; !ok {
+				h.userClients[client.userID] = make(map[*Client]bool)
 			}
+			h.userClients[client.userID][client] = true
+			h.clientsMu.Unlock()
+		case client := <-h.Unregister:
+			h.removeClient(client)
 		case message := <-h.Broadcast:
 			// Broadcast to all clients
+			var slow []*Client
+			h.clientsMu.RLock()
 			for client := range h.clients {
-				select {
-				case client.send <- message:
-				default:
-					close(client.send)
-					delete(h.clients, client)
+				if !client.enqueue(message) {
+					slow = append(slow, client)
 				}
 			}
+			h.clientsMu.RUnlock()
+			for _, client := range slow {
+				h.removeClient(client)
+			}
+		}
+	}
+}
+
+// removeClient forgets a client and closes its send channel. It is safe to
+// call more than once for the same client.
+func (h *Hub) removeClient(client *Client) {
+	h.clientsMu.Lock()
+	if _, ok := h.clients[client]; !ok {
+		h.clientsMu.Unlock()
+		return
+	}
+	delete(h.clients, client)
+	if conns, ok := h.userClients[client.userID]; ok {
+		delete(conns, client)
+		if len(conns) == 0 {
+			delete(h.userClients, client.userID)
 		}
 	}
+	client.closeSend()
+	h.clientsMu.Unlock()
+
+	// Remove from any room
+	h.roomsMu.Lock()
+	for matchID, room := range h.rooms {
+		if _, ok := room[client]; ok {
+			delete(room, client)
+			if len(room) == 0 {
+				delete(h.rooms, matchID)
+			}
+		}
+	}
+	h.roomsMu.Unlock()
+
+	h.channelsMu.Lock()
+	for channel, members := range h.channels {
+		if members[client] {
+			delete(members, client)
+			if len(members) == 0 {
+				delete(h.channels, channel)
+			}
+		}
+	}
+	h.channelsMu.Unlock()
+}
+
+// SendToUser delivers an event to every connection of a user. The event is
+// stamped with the user's next sequence number and kept for replay, so it
+// reaches the user after a reconnect even if no socket is open right now.
+func (h *Hub) SendToUser(userID uuid.UUID, msgType MessageType, payload interface{}) error {
+	msg := Message{Type: msgType}
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return fmt.Errorf("failed to marshal payload: %w", err)
+		}
+		msg.Payload = data
+	}
+
+	stamped, err := h.replay.Append(userID, msg)
+	if err != nil {
+		// Deliver live anyway; the client will resync on its next resume.
+		log.Printf("websocket: failed to store event for replay: %v", err)
+		stamped = msg
+	}
+	data, err := json.Marshal(stamped)
+	if err != nil {
+		return fmt.Errorf("failed to marshal message: %w", err)
+	}
+
+	h.clientsMu.RLock()
+	defer h.clientsMu.RUnlock()
+	for client := range h.userClients[userID] {
+		// A full buffer only drops the live copy; the client can resume.
+		client.deliver(stamped.Seq, data)
+	}
+	return nil
+}
+
+// SendLive delivers a transient event, such as a cursor move, to the user's
+// open connections only. It is not sequenced and is lost if nobody listens.
+func (h *Hub) SendLive(userID uuid.UUID, msgType MessageType, payload interface{}) error {
+	msg := Message{Type: msgType}
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return fmt.Errorf("failed to marshal payload: %w", err)
+		}
+		msg.Payload = data
+	}
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("failed to marshal message: %w", err)
+	}
+
+	h.clientsMu.RLock()
+	defer h.clientsMu.RUnlock()
+	for client := range h.userClients[userID] {
+		client.enqueue(data)
+	}
+	return nil
+}
+
+// SendToUsers delivers the same event to several users.
+func (h *Hub) SendToUsers(userIDs []uuid.UUID, msgType MessageType, payload interface{}) error {
+	for _, userID := range userIDs {
+		if err := h.SendToUser(userID, msgType, payload); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// IsOnline reports whether the user has at least one open connection.
+func (h *Hub) IsOnline(userID uuid.UUID) bool {
+	h.clientsMu.RLock()
+	defer h.clientsMu.RUnlock()
+	return len(h.userClients[userID]) > 0
+}
+
+// dispatch routes a raw inbound frame from a client.
+func (h *Hub) dispatch(client *Client, raw []byte) {
+	var msg Message
+	if err := json.Unmarshal(raw, &msg); err != nil {
+		h.Broadcast <- raw
+		return
+	}
+
+	if msg.Type == Resume {
+		var payload ResumePayload
+		if len(msg.Payload) > 0 {
+			if err := json.Unmarshal(msg.Payload, &payload); err != nil {
+				client.SendError("invalid resume payload")
+				return
+			}
+		}
+		h.resume(client, payload.LastSeq)
+		return
+	}
+	// A client that talks without resuming first will not resume
+	client.endGrace()
+
+	switch msg.Type {
+	case Ping:
+		client.sendControl(Pong, nil)
+		return
+	case ChannelJoin, ChannelLeave, ChatMessage:
+		h.dispatchChannel(client, msg)
+		return
+	}
+
+	h.handlersMu.RLock()
+	handler, ok := h.handlers[msg.Type]
+	h.handlersMu.RUnlock()
+	if ok {
+		handler(client, msg)
+		return
+	}
+	h.Broadcast <- raw
+}
+
+// resume redelivers the events a client missed after lastSeq. The client
+// holds back live events until the replay is queued so they arrive after it,
+// and the backlog is read without holding the hub's locks.
+func (h *Hub) resume(client *Client, lastSeq uint64) {
+	client.beginResume()
+
+	missed, current, complete, err := h.replay.Since(client.userID, lastSeq)
+	if err != nil {
+		log.Printf("websocket: failed to read replay buffer: %v", err)
+		client.sendControl(ResyncRequired, ResumedPayload{LastSeq: current})
+		client.endResume(0)
+		return
+	}
+	if !complete {
+		client.sendControl(ResyncRequired, ResumedPayload{LastSeq: current})
+		client.endResume(0)
+		return
+	}
+	for _, msg := range missed {
+		data, err := json.Marshal(msg)
+		if err != nil {
+			continue
+		}
+		if !client.enqueue(data) {
+			client.sendControl(ResyncRequired, ResumedPayload{LastSeq: current})
+			client.endResume(0)
+			return
+		}
+	}
+	client.sendControl(Resumed, ResumedPayload{LastSeq: current, Replayed: len(missed)})
+	// Live events stamped before the backlog was read were replayed with it
+	client.endResume(current)
 }
 
 // JoinRoom adds a client to a match room
@@ -103,20 +333,20 @@ func (h *Hub) LeaveRoom(client *Client, matchID uuid.UUID) {
 
 // BroadcastToRoom sends a message to all clients in a room
 func (h *Hub) BroadcastToRoom(matchID uuid.UUID, message []byte) {
+	var slow []*Client
 	h.roomsMu.RLock()
-	defer h.roomsMu.RUnlock()
-
 	if room, ok := h.rooms[matchID]; ok {
 		for client := range room {
-			select {
-			case client.send <- message:
-			default:
-				close(client.send)
-				delete(h.clients, client)
-				delete(room, client)
+			if !client.enqueue(message) {
+				slow = append(slow, client)
 			}
 		}
 	}
+	h.roomsMu.RUnlock()
+
+	for _, client := range slow {
+		h.removeClient(client)
+	}
 }
 
 // GetRoomClients returns clients in a room
@@ -135,11 +365,15 @@ func (h *Hub) GetRoomClients(matchID uuid.UUID) []*Client {
 
 // LogStats logs hub statistics (for debugging)
 func (h *Hub) LogStats() {
+	h.clientsMu.RLock()
+	clientCount := len(h.clients)
+	h.clientsMu.RUnlock()
+
 	h.roomsMu.RLock()
 	defer h.roomsMu.RUnlock()
 
-	log.Printf("Hub stats: %d clients, %d rooms", len(h.clients), len(h.rooms))
+	log.Printf("Hub stats: %d clients, %d rooms", clientCount, len(h.rooms))
 	for matchID, room := range h.rooms {
 		log.Printf("  Room %s: %d clients", matchID, len(room))
 	}
-}
\ No newline at end of file
+}
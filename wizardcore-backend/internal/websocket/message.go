@@ -2,6 +2,9 @@ package websocket
 
 import (
 	"encoding/json"
+	"time"
+
+	"github.com/yourusername/wizardcore-backend/internal/collab"
 )
 
 // MessageType represents the type of WebSocket message
@@ -18,20 +21,79 @@ const (
 	MatchStart MessageType = "match_start"
 	// MatchEnd indicates the match has ended
 	MatchEnd MessageType = "match_end"
+	// MatchCancelled indicates the match was called off before it started
+	MatchCancelled MessageType = "match_cancelled"
 	// Error indicates an error message
 	Error MessageType = "error"
 	// Ping is used for keep-alive
 	Ping MessageType = "ping"
 	// Pong is used for keep-alive response
 	Pong MessageType = "pong"
+	// NotificationEvent carries a newly created user notification
+	NotificationEvent MessageType = "notification"
+	// Resume is sent by a reconnecting client with the last sequence it saw
+	Resume MessageType = "resume"
+	// Resumed acknowledges a resume after missed events were redelivered
+	Resumed MessageType = "resumed"
+	// ResyncRequired tells the client its gap can no longer be replayed
+	ResyncRequired MessageType = "resync_required"
+	// MatchmakingStatus reports a queued user's wait or the queue entry expiring
+	MatchmakingStatus MessageType = "matchmaking_status"
+	// MatchInviteUpdate tells the inviter that a duel invite was answered or expired
+	MatchInviteUpdate MessageType = "match_invite_update"
+	// PracticeSplit reports a finished exercise of a run and serves the next
+	PracticeSplit MessageType = "practice_split"
+	// PairOp carries edit operations on a pair-programming buffer
+	PairOp MessageType = "pair_op"
+	// PairCursor carries a pair partner's cursor position
+	PairCursor MessageType = "pair_cursor"
+	// PairJoined tells the host that a partner joined the session
+	PairJoined MessageType = "pair_joined"
+	// PairSubmitted tells both partners that the joint code was submitted
+	PairSubmitted MessageType = "pair_submitted"
+	// ChannelJoin subscribes the connection to a channel
+	ChannelJoin MessageType = "channel_join"
+	// ChannelLeave unsubscribes the connection from a channel
+	ChannelLeave MessageType = "channel_leave"
+	// ChannelJoined acknowledges a channel join or leave
+	ChannelJoined MessageType = "channel_joined"
+	// ChatMessage carries a chat line posted to a channel
+	ChatMessage MessageType = "chat"
+	// PartyUpdate tells party members that the party changed
+	PartyUpdate MessageType = "party_update"
+	// TeamMatchStart tells the players of a team match their team and exercises
+	TeamMatchStart MessageType = "team_match_start"
+	// TeamScore carries the live team scores of a team match
+	TeamScore MessageType = "team_score"
+	// DailyChallengeComplete tells a player they solved today's challenge
+	DailyChallengeComplete MessageType = "daily_challenge_complete"
 )
 
-// Message represents a WebSocket message
+// Message represents a WebSocket message.
+// Seq is set on every event the server delivers to a user and increases
+// monotonically per user; control frames (pong, resumed, errors) carry none.
 type Message struct {
 	Type    MessageType     `json:"type"`
+	Seq     uint64          `json:"seq,omitempty"`
 	Payload json.RawMessage `json:"payload,omitempty"`
 }
 
+// ResumePayload payload for Resume
+type ResumePayload struct {
+	LastSeq uint64 `json:"last_seq"`
+}
+
+// ResumedPayload payload for Resumed and ResyncRequired
+type ResumedPayload struct {
+	LastSeq  uint64 `json:"last_seq"`
+	Replayed int    `json:"replayed"`
+}
+
+// ErrorPayload payload for Error
+type ErrorPayload struct {
+	Message string `json:"message"`
+}
+
 // MatchJoinPayload payload for MatchJoin
 type MatchJoinPayload struct {
 	MatchID string `json:"match_id"`
@@ -60,10 +122,137 @@ type MatchEndPayload struct {
 	Results []ParticipantResult `json:"results"`
 }
 
+// MatchCancelledPayload payload for MatchCancelled
+type MatchCancelledPayload struct {
+	MatchID string `json:"match_id"`
+	Reason  string `json:"reason"` // no_opponent
+}
+
 // ParticipantResult result of a participant
 type ParticipantResult struct {
 	UserID string `json:"user_id"`
 	Score  int    `json:"score"`
 	Result string `json:"result"` // win, loss, draw
 	XP     int    `json:"xp"`
-}
\ No newline at end of file
+	// Duel rating after the match and its change, for rated duels
+	Rating       *float64 `json:"rating,omitempty"`
+	RatingChange *float64 `json:"rating_change,omitempty"`
+}
+
+// PairOpPayload payload for PairOp.
+// Clients send operations made against BaseRevision with their own
+// ClientOpID; the server echoes the transformed operations to both partners
+// with the Revision they produced so the sender can acknowledge its edit.
+type PairOpPayload struct {
+	SessionID    string             `json:"session_id"`
+	UserID       string             `json:"user_id,omitempty"`
+	ClientOpID   string             `json:"client_op_id,omitempty"`
+	BaseRevision int                `json:"base_revision"`
+	Revision     int                `json:"revision,omitempty"`
+	Operations   []collab.Operation `json:"operations"`
+	Cursor       *int               `json:"cursor,omitempty"`
+}
+
+// PairCursorPayload payload for PairCursor
+type PairCursorPayload struct {
+	SessionID string `json:"session_id"`
+	UserID    string `json:"user_id,omitempty"`
+	Revision  int    `json:"revision"`
+	Position  int    `json:"position"`
+}
+
+// PairJoinedPayload payload for PairJoined
+type PairJoinedPayload struct {
+	SessionID string `json:"session_id"`
+	UserID    string `json:"user_id"`
+}
+
+// PairSubmittedPayload payload for PairSubmitted
+type PairSubmittedPayload struct {
+	SessionID     string   `json:"session_id"`
+	SubmissionIDs []string `json:"submission_ids"`
+	Status        string   `json:"status"`
+	PointsEarned  int      `json:"points_earned"`
+}
+
+// MatchmakingStatusPayload payload for MatchmakingStatus
+type MatchmakingStatusPayload struct {
+	Status               string  `json:"status"` // waiting, expired
+	WaitSeconds          int     `json:"wait_seconds,omitempty"`
+	EstimatedWaitSeconds int     `json:"estimated_wait_seconds,omitempty"`
+	RatingBand           float64 `json:"rating_band,omitempty"`
+}
+
+// MatchInviteUpdatePayload payload for MatchInviteUpdate
+type MatchInviteUpdatePayload struct {
+	InviteID string `json:"invite_id"`
+	MatchID  string `json:"match_id"`
+	Status   string `json:"status"` // accepted, declined, expired
+}
+
+// PracticeSplitPayload payload for PracticeSplit
+type PracticeSplitPayload struct {
+	MatchID        string `json:"match_id"`
+	Sequence       int    `json:"sequence"`
+	Result         string `json:"result"` // solved, skipped
+	SplitSeconds   int    `json:"split_seconds"`
+	Solved         int    `json:"solved"`
+	NextExerciseID string `json:"next_exercise_id"`
+}
+
+// ChannelPayload payload for ChannelJoin, ChannelLeave and ChannelJoined
+type ChannelPayload struct {
+	Channel string `json:"channel"`
+	Joined  bool   `json:"joined,omitempty"`
+}
+
+// ChatPayload payload for ChatMessage. Clients send Channel and Text; the
+// hub fills in the sender and time.
+type ChatPayload struct {
+	Channel string    `json:"channel"`
+	UserID  string    `json:"user_id,omitempty"`
+	Text    string    `json:"text"`
+	SentAt  time.Time `json:"sent_at"`
+}
+
+// PartyUpdatePayload payload for PartyUpdate
+type PartyUpdatePayload struct {
+	PartyID string   `json:"party_id"`
+	Status  string   `json:"status"` // forming, queued, in_match, disbanded
+	Members []string `json:"members"`
+	MatchID string   `json:"match_id,omitempty"`
+}
+
+// TeamMatchStartPayload payload for TeamMatchStart
+type TeamMatchStartPayload struct {
+	MatchID     string            `json:"match_id"`
+	TeamID      string            `json:"team_id"`
+	Scoring     string            `json:"scoring"` // best, sum
+	TimeLimit   int               `json:"time_limit"`
+	ExerciseIDs []string          `json:"exercise_ids"`
+	Teams       []TeamScoreResult `json:"teams"`
+}
+
+// TeamScorePayload payload for TeamScore
+type TeamScorePayload struct {
+	MatchID string            `json:"match_id"`
+	Teams   []TeamScoreResult `json:"teams"`
+}
+
+// TeamScoreResult is a team's standing in a team match
+type TeamScoreResult struct {
+	TeamID  string   `json:"team_id"`
+	Number  int      `json:"number"`
+	Score   int      `json:"score"`
+	Members []string `json:"members"`
+	Result  string   `json:"result,omitempty"`
+}
+
+// DailyChallengeCompletePayload payload for DailyChallengeComplete
+type DailyChallengeCompletePayload struct {
+	Date          string `json:"date"`
+	SolveSeconds  int    `json:"solve_seconds"`
+	BonusXP       int    `json:"bonus_xp"`
+	CurrentStreak int    `json:"current_streak"`
+	LongestStreak int    `json:"longest_streak"`
+}
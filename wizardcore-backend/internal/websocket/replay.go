@@ -0,0 +1,240 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yourusername/wizardcore-backend/pkg/redis"
+)
+
+const (
+	// Default number of events kept per user for replay.
+	defaultReplayBufferSize = 200
+
+	// Default time a user's replay buffer survives without new events.
+	defaultReplayTTL = 30 * time.Minute
+)
+
+// ReplayStore assigns per-user sequence numbers to outbound events and keeps
+// a bounded window of them so a reconnecting client can catch up.
+type ReplayStore interface {
+	// Append stamps msg with the user's next sequence number, stores it and
+	// returns the stamped message.
+	Append(userID uuid.UUID, msg Message) (Message, error)
+
+	// Since returns the stored events with a sequence greater than lastSeq
+	// and the user's current sequence. complete is false when the buffer no
+	// longer holds every event after lastSeq.
+	Since(userID uuid.UUID, lastSeq uint64) (msgs []Message, current uint64, complete bool, err error)
+}
+
+// MemoryReplayStore is a process-local ReplayStore.
+type MemoryReplayStore struct {
+	size int
+	ttl  time.Duration
+
+	mu      sync.Mutex
+	buffers map[uuid.UUID]*replayBuffer
+	appends int
+}
+
+type replayBuffer struct {
+	seq       uint64
+	msgs      []Message
+	touchedAt time.Time
+}
+
+// NewMemoryReplayStore creates an in-memory replay store keeping up to size
+// events per user. Buffers idle for longer than ttl are discarded.
+func NewMemoryReplayStore(size int, ttl time.Duration) *MemoryReplayStore {
+	if size <= 0 {
+		size = defaultReplayBufferSize
+	}
+	if ttl <= 0 {
+		ttl = defaultReplayTTL
+	}
+	return &MemoryReplayStore{
+		size:    size,
+		ttl:     ttl,
+		buffers: make(map[uuid.UUID]*replayBuffer),
+	}
+}
+
+// Append implements ReplayStore
+func (s *MemoryReplayStore) Append(userID uuid.UUID, msg Message) (Message, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	s.appends++
+	if s.appends%1024 == 0 {
+		s.pruneLocked(now)
+	}
+
+	buf, ok := s.buffers[userID]
+	if !ok {
+		buf = &replayBuffer{}
+		s.buffers[userID] = buf
+	}
+	buf.seq++
+	buf.touchedAt = now
+	msg.Seq = buf.seq
+	buf.msgs = append(buf.msgs, msg)
+	if len(buf.msgs) > s.size {
+		buf.msgs = buf.msgs[len(buf.msgs)-s.size:]
+	}
+	return msg, nil
+}
+
+// Since implements ReplayStore
+func (s *MemoryReplayStore) Since(userID uuid.UUID, lastSeq uint64) ([]Message, uint64, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	buf, ok := s.buffers[userID]
+	if !ok {
+		return nil, 0, lastSeq == 0, nil
+	}
+	msgs, complete := eventsAfter(buf.msgs, buf.seq, lastSeq)
+	return msgs, buf.seq, complete, nil
+}
+
+// pruneLocked drops the events of idle buffers. Their sequences are kept so
+// they keep increasing for a client resuming soon after; a buffer still idle
+// and empty at the next prune is deleted, and a client resuming after that
+// is told to resync.
+func (s *MemoryReplayStore) pruneLocked(now time.Time) {
+	for userID, buf := range s.buffers {
+		if now.Sub(buf.touchedAt) <= s.ttl {
+			continue
+		}
+		if buf.msgs == nil {
+			delete(s.buffers, userID)
+			continue
+		}
+		buf.msgs = nil
+	}
+}
+
+// RedisReplayStore is a ReplayStore shared by every API instance through
+// Redis, so a client can resume against a different node than it left.
+type RedisReplayStore struct {
+	client *redis.Client
+	size   int
+	ttl    time.Duration
+}
+
+// NewRedisReplayStore creates a Redis-backed replay store keeping up to size
+// events per user for ttl after the last event.
+func NewRedisReplayStore(client *redis.Client, size int, ttl time.Duration) *RedisReplayStore {
+	if size <= 0 {
+		size = defaultReplayBufferSize
+	}
+	if ttl <= 0 {
+		ttl = defaultReplayTTL
+	}
+	return &RedisReplayStore{client: client, size: size, ttl: ttl}
+}
+
+// The keys of a user share a hash tag so the scripts, which touch both, run
+// on a single Redis Cluster slot
+func replaySeqKey(userID uuid.UUID) string {
+	return fmt.Sprintf("ws:{%s}:seq", userID)
+}
+
+func replayListKey(userID uuid.UUID) string {
+	return fmt.Sprintf("ws:{%s}:replay", userID)
+}
+
+// Allocates the next sequence and stores the event in one step, so events
+// appended concurrently by different nodes are stored in sequence order.
+// The event arrives marshalled without its seq, which is spliced in. The
+// sequence key never expires so sequences keep increasing after the buffer
+// does.
+const replayAppendScript = `
+local seq = redis.call('INCR', KEYS[1])
+redis.call('RPUSH', KEYS[2], '{"seq":' .. seq .. ',' .. string.sub(ARGV[1], 2))
+redis.call('LTRIM', KEYS[2], -tonumber(ARGV[2]), -1)
+redis.call('EXPIRE', KEYS[2], ARGV[3])
+return seq
+`
+
+// Reads the current sequence together with the buffer it describes
+const replaySinceScript = `
+return {redis.call('GET', KEYS[1]) or '0', redis.call('LRANGE', KEYS[2], 0, -1)}
+`
+
+// Append implements ReplayStore
+func (s *RedisReplayStore) Append(userID uuid.UUID, msg Message) (Message, error) {
+	msg.Seq = 0
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return msg, fmt.Errorf("failed to marshal message: %w", err)
+	}
+	ttl := int64(s.ttl / time.Second)
+	if ttl < 1 {
+		ttl = 1
+	}
+	result, err := s.client.Eval(context.Background(), replayAppendScript,
+		[]string{replaySeqKey(userID), replayListKey(userID)}, string(data), s.size, ttl)
+	if err != nil {
+		return msg, fmt.Errorf("failed to store message: %w", err)
+	}
+	seq, ok := result.(int64)
+	if !ok {
+		return msg, fmt.Errorf("unexpected sequence reply %v", result)
+	}
+	msg.Seq = uint64(seq)
+	return msg, nil
+}
+
+// Since implements ReplayStore
+func (s *RedisReplayStore) Since(userID uuid.UUID, lastSeq uint64) ([]Message, uint64, bool, error) {
+	result, err := s.client.Eval(context.Background(), replaySinceScript,
+		[]string{replaySeqKey(userID), replayListKey(userID)})
+	if err != nil {
+		return nil, 0, false, fmt.Errorf("failed to read replay buffer: %w", err)
+	}
+	reply, ok := result.([]interface{})
+	if !ok || len(reply) != 2 {
+		return nil, 0, false, fmt.Errorf("unexpected replay reply %v", result)
+	}
+	raw, _ := reply[0].(string)
+	current, err := strconv.ParseUint(raw, 10, 64)
+	if err != nil {
+		return nil, 0, false, fmt.Errorf("invalid sequence value: %w", err)
+	}
+	items, _ := reply[1].([]interface{})
+	stored := make([]Message, 0, len(items))
+	for _, item := range items {
+		data, _ := item.(string)
+		var msg Message
+		if err := json.Unmarshal([]byte(data), &msg); err != nil {
+			continue
+		}
+		stored = append(stored, msg)
+	}
+	msgs, complete := eventsAfter(stored, current, lastSeq)
+	return msgs, current, complete, nil
+}
+
+// eventsAfter returns the buffered events newer than lastSeq and whether the
+// buffer covers the whole gap between lastSeq and current.
+func eventsAfter(buffer []Message, current, lastSeq uint64) ([]Message, bool) {
+	if lastSeq >= current {
+		return nil, lastSeq == current
+	}
+	var out []Message
+	for _, msg := range buffer {
+		if msg.Seq > lastSeq {
+			out = append(out, msg)
+		}
+	}
+	complete := len(out) > 0 && out[0].Seq == lastSeq+1
+	return out, complete
+}
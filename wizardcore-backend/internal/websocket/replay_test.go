@@ -0,0 +1,152 @@
+package websocket
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestMemoryReplayStore_SequencesPerUser(t *testing.T) {
+	store := NewMemoryReplayStore(10, time.Minute)
+	alice := uuid.New()
+	bob := uuid.New()
+
+	for i := 1; i <= 3; i++ {
+		msg, err := store.Append(alice, Message{Type: MatchStart})
+		if err != nil {
+			t.Fatalf("Append failed: %v", err)
+		}
+		if msg.Seq != uint64(i) {
+			t.Errorf("Expected seq %d, got %d", i, msg.Seq)
+		}
+	}
+	msg, _ := store.Append(bob, Message{Type: MatchStart})
+	if msg.Seq != 1 {
+		t.Errorf("Expected independent sequence for second user, got %d", msg.Seq)
+	}
+}
+
+func TestMemoryReplayStore_Since(t *testing.T) {
+	store := NewMemoryReplayStore(3, time.Minute)
+	userID := uuid.New()
+	for i := 0; i < 5; i++ {
+		store.Append(userID, Message{Type: NotificationEvent})
+	}
+
+	missed, current, complete, err := store.Since(userID, 3)
+	if err != nil {
+		t.Fatalf("Since failed: %v", err)
+	}
+	if current != 5 {
+		t.Errorf("Expected current seq 5, got %d", current)
+	}
+	if !complete || len(missed) != 2 || missed[0].Seq != 4 {
+		t.Errorf("Expected events 4 and 5, got %+v (complete=%v)", missed, complete)
+	}
+
+	// Event 2 has been evicted from a buffer of three
+	if _, _, complete, _ := store.Since(userID, 1); complete {
+		t.Error("Expected incomplete replay once the gap exceeds the buffer")
+	}
+
+	// Nothing missed
+	missed, _, complete, _ = store.Since(userID, 5)
+	if !complete || len(missed) != 0 {
+		t.Errorf("Expected empty complete replay, got %d events (complete=%v)", len(missed), complete)
+	}
+
+	// Client ahead of the server (e.g. after a restart) must resync
+	if _, _, complete, _ := store.Since(userID, 9); complete {
+		t.Error("Expected incomplete replay when client sequence is ahead")
+	}
+}
+
+func TestMemoryReplayStore_SequenceSurvivesPruning(t *testing.T) {
+	store := NewMemoryReplayStore(10, time.Minute)
+	userID := uuid.New()
+	store.Append(userID, Message{Type: NotificationEvent})
+	store.Append(userID, Message{Type: NotificationEvent})
+
+	store.mu.Lock()
+	store.pruneLocked(time.Now().Add(2 * time.Minute))
+	store.mu.Unlock()
+
+	// A client that saw everything before the buffer went idle is up to date
+	if missed, _, complete, _ := store.Since(userID, 2); !complete || len(missed) != 0 {
+		t.Errorf("Expected a complete empty replay after pruning, got %d events (complete=%v)", len(missed), complete)
+	}
+	msg, _ := store.Append(userID, Message{Type: NotificationEvent})
+	if msg.Seq != 3 {
+		t.Errorf("Expected the sequence to continue at 3 after pruning, got %d", msg.Seq)
+	}
+}
+
+func TestMemoryReplayStore_DeletesEmptyIdleBuffers(t *testing.T) {
+	store := NewMemoryReplayStore(10, time.Minute)
+	idle, active := uuid.New(), uuid.New()
+	store.Append(idle, Message{Type: NotificationEvent})
+	store.Append(active, Message{Type: NotificationEvent})
+
+	store.mu.Lock()
+	store.pruneLocked(time.Now().Add(2 * time.Minute))
+	store.buffers[active].touchedAt = time.Now().Add(2 * time.Minute)
+	store.pruneLocked(time.Now().Add(2 * time.Minute))
+	_, idleKept := store.buffers[idle]
+	_, activeKept := store.buffers[active]
+	store.mu.Unlock()
+
+	if idleKept {
+		t.Error("Expected the empty idle buffer to be deleted")
+	}
+	if !activeKept {
+		t.Error("Expected the recently used buffer to be kept")
+	}
+}
+
+func TestHub_ResumeReplaysBeforeLiveEvents(t *testing.T) {
+	h := NewHub()
+	client := &Client{hub: h, send: make(chan []byte, 16), userID: uuid.New(), holding: true}
+	h.clients[client] = true
+	h.userClients[client.userID] = map[*Client]bool{client: true}
+
+	// Missed while disconnected
+	h.replay.Append(client.userID, Message{Type: NotificationEvent})
+	h.replay.Append(client.userID, Message{Type: NotificationEvent})
+	// Sent after the new connection opened but before it resumed
+	if err := h.SendToUser(client.userID, MatchStart, nil); err != nil {
+		t.Fatalf("SendToUser failed: %v", err)
+	}
+	if len(client.send) != 0 {
+		t.Fatalf("Expected the live event to be held until the resume, got %d frames", len(client.send))
+	}
+
+	frame(t, h, client, Resume, ResumePayload{LastSeq: 0})
+
+	var got []string
+	for len(client.send) > 0 {
+		var msg Message
+		json.Unmarshal(<-client.send, &msg)
+		got = append(got, fmt.Sprintf("%s:%d", msg.Type, msg.Seq))
+	}
+	want := []string{
+		fmt.Sprintf("%s:1", NotificationEvent),
+		fmt.Sprintf("%s:2", NotificationEvent),
+		fmt.Sprintf("%s:3", MatchStart),
+		fmt.Sprintf("%s:0", Resumed),
+	}
+	// The live event was already stored when the backlog was read, so it is
+	// replayed in order and its held copy dropped
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("Expected frames %v, got %v", want, got)
+	}
+
+	// Once resumed, events are delivered straight away
+	h.SendToUser(client.userID, MatchEnd, nil)
+	if len(client.send) != 1 {
+		t.Errorf("Expected live delivery after the resume, got %d frames", len(client.send))
+	}
+}
@@ -50,6 +50,119 @@ func (c *Client) Exists(ctx context.Context, key string) (bool, error) {
 	return result > 0, nil
 }
 
+func (c *Client) Incr(ctx context.Context, key string) (int64, error) {
+	return c.client.Incr(ctx, key).Result()
+}
+
+func (c *Client) Expire(ctx context.Context, key string, expiration time.Duration) error {
+	return c.client.Expire(ctx, key, expiration).Err()
+}
+
+func (c *Client) RPush(ctx context.Context, key string, values ...interface{}) error {
+	return c.client.RPush(ctx, key, values...).Err()
+}
+
+func (c *Client) LTrim(ctx context.Context, key string, start, stop int64) error {
+	return c.client.LTrim(ctx, key, start, stop).Err()
+}
+
+func (c *Client) LRange(ctx context.Context, key string, start, stop int64) ([]string, error) {
+	return c.client.LRange(ctx, key, start, stop).Result()
+}
+
+// ScoredMember is a sorted set member with its score
+type ScoredMember struct {
+	Member string
+	Score  float64
+}
+
+func (c *Client) ExpireAt(ctx context.Context, key string, tm time.Time) error {
+	return c.client.ExpireAt(ctx, key, tm).Err()
+}
+
+func (c *Client) ZAdd(ctx context.Context, key string, members ...ScoredMember) error {
+	zs := make([]redis.Z, len(members))
+	for i, m := range members {
+		zs[i] = redis.Z{Score: m.Score, Member: m.Member}
+	}
+	return c.client.ZAdd(ctx, key, zs...).Err()
+}
+
+func (c *Client) ZIncrBy(ctx context.Context, key string, increment float64, member string) (float64, error) {
+	return c.client.ZIncrBy(ctx, key, increment, member).Result()
+}
+
+// ZScore returns a member's score; the error satisfies IsNil if the member
+// is absent
+func (c *Client) ZScore(ctx context.Context, key, member string) (float64, error) {
+	return c.client.ZScore(ctx, key, member).Result()
+}
+
+// ZRevRank returns a member's zero-based position, highest score first;
+// the error satisfies IsNil if the member is absent
+func (c *Client) ZRevRank(ctx context.Context, key, member string) (int64, error) {
+	return c.client.ZRevRank(ctx, key, member).Result()
+}
+
+// ZMScore returns the scores of several members, 0 for those absent
+func (c *Client) ZMScore(ctx context.Context, key string, members ...string) ([]float64, error) {
+	return c.client.ZMScore(ctx, key, members...).Result()
+}
+
+func (c *Client) ZCard(ctx context.Context, key string) (int64, error) {
+	return c.client.ZCard(ctx, key).Result()
+}
+
+// ZCount counts members with scores between min and max, which take the
+// Redis range syntax ("(10", "+inf")
+func (c *Client) ZCount(ctx context.Context, key, min, max string) (int64, error) {
+	return c.client.ZCount(ctx, key, min, max).Result()
+}
+
+// ZRevRangeWithScores returns the members ranked start..stop, highest score
+// first
+func (c *Client) ZRevRangeWithScores(ctx context.Context, key string, start, stop int64) ([]ScoredMember, error) {
+	zs, err := c.client.ZRevRangeWithScores(ctx, key, start, stop).Result()
+	if err != nil {
+		return nil, err
+	}
+	members := make([]ScoredMember, len(zs))
+	for i, z := range zs {
+		members[i] = ScoredMember{Member: fmt.Sprint(z.Member), Score: z.Score}
+	}
+	return members, nil
+}
+
+// ReplaceSortedSet atomically replaces the contents of a sorted set. A zero
+// expireAt leaves the key without expiry.
+func (c *Client) ReplaceSortedSet(ctx context.Context, key string, members []ScoredMember, expireAt time.Time) error {
+	zs := make([]redis.Z, len(members))
+	for i, m := range members {
+		zs[i] = redis.Z{Score: m.Score, Member: m.Member}
+	}
+	_, err := c.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, key)
+		if len(zs) > 0 {
+			pipe.ZAdd(ctx, key, zs...)
+			if !expireAt.IsZero() {
+				pipe.ExpireAt(ctx, key, expireAt)
+			}
+		}
+		return nil
+	})
+	return err
+}
+
+// Eval runs a Lua script atomically on the server
+func (c *Client) Eval(ctx context.Context, script string, keys []string, args ...interface{}) (interface{}, error) {
+	return c.client.Eval(ctx, script, keys, args...).Result()
+}
+
+// IsNil reports whether err is the "key does not exist" reply.
+func IsNil(err error) bool {
+	return err == redis.Nil
+}
+
 func (c *Client) Close() error {
 	return c.client.Close()
 }
\ No newline at end of file